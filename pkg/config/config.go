@@ -17,26 +17,30 @@ import (
 
 var (
 	// default
-	defaultConfigName         = "config"
-	defaultPeeringConfigName  = "peering"
-	defaultProfilesConfigName = "profiles"
+	defaultConfigName          = "config"
+	defaultPeeringConfigName   = "peering"
+	defaultProfilesConfigName  = "profiles"
+	defaultAPITokensConfigName = "apitokens"
 
 	// flags
-	configName         = flag.StringP("config", "c", defaultConfigName, "Filename of the config file without the file extension")
-	peeringConfigName  = flag.StringP("peeringConfig", "n", defaultPeeringConfigName, "Filename of the peering config file without the file extension")
-	profilesConfigName = flag.String("profilesConfig", defaultProfilesConfigName, "Filename of the profiles config file without the file extension")
-	configDirPath      = flag.StringP("config-dir", "d", ".", "Path to the directory containing the config file")
+	configName          = flag.StringP("config", "c", defaultConfigName, "Filename of the config file without the file extension")
+	peeringConfigName   = flag.StringP("peeringConfig", "n", defaultPeeringConfigName, "Filename of the peering config file without the file extension")
+	profilesConfigName  = flag.String("profilesConfig", defaultProfilesConfigName, "Filename of the profiles config file without the file extension")
+	apiTokensConfigName = flag.String("apiTokensConfig", defaultAPITokensConfigName, "Filename of the HTTP API tokens config file without the file extension")
+	configDirPath       = flag.StringP("config-dir", "d", ".", "Path to the directory containing the config file")
 
 	// Viper
-	NodeConfig     = viper.New()
-	PeeringConfig  = viper.New()
-	ProfilesConfig = viper.New()
+	NodeConfig      = viper.New()
+	PeeringConfig   = viper.New()
+	ProfilesConfig  = viper.New()
+	APITokensConfig = viper.New()
 
 	peeringConfigHotReloadAllowed = true
 	peeringConfigHotReloadLock    syncutils.Mutex
 
 	// a list of flags which should be printed via --help
 	nonHiddenFlags = map[string]struct{}{
+		"apiTokensConfig":     {},
 		"config":              {},
 		"config-dir":          {},
 		"node.disablePlugins": {},
@@ -68,11 +72,13 @@ func FetchConfig() error {
 	NodeConfig.SetEnvKeyReplacer(dotReplacer)
 	PeeringConfig.SetEnvKeyReplacer(dotReplacer)
 	ProfilesConfig.SetEnvKeyReplacer(dotReplacer)
+	APITokensConfig.SetEnvKeyReplacer(dotReplacer)
 
 	// ensure that envs are read in too
 	NodeConfig.AutomaticEnv()
 	PeeringConfig.AutomaticEnv()
 	ProfilesConfig.AutomaticEnv()
+	APITokensConfig.AutomaticEnv()
 
 	err := parameter.LoadConfigFile(NodeConfig, *configDirPath, *configName, true, !hasFlag(defaultConfigName))
 	if err != nil {
@@ -89,9 +95,19 @@ func FetchConfig() error {
 		return err
 	}
 
+	err = parameter.LoadConfigFile(APITokensConfig, *configDirPath, *apiTokensConfigName, false, !hasFlag(defaultAPITokensConfigName))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// APITokensConfigLocation returns the directory and the file name without the file extension of the API tokens config file.
+func APITokensConfigLocation() (dir string, name string) {
+	return *configDirPath, *apiTokensConfigName
+}
+
 func PrintConfig(ignoreSettingsAtPrint ...[]string) {
 	parameter.PrintConfig(NodeConfig, ignoreSettingsAtPrint...)
 	parameter.PrintConfig(PeeringConfig)
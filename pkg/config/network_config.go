@@ -9,6 +9,10 @@ type PeerConfig struct {
 	ID         string `json:"identity" mapstructure:"identity"`
 	Alias      string `json:"alias" mapstructure:"alias"`
 	PreferIPv6 bool   `json:"preferIPv6" mapstructure:"preferIPv6"`
+	// the pinned base64 encoded ed25519 public key of the peer, used to establish a secure channel
+	PublicKey string `json:"publicKey" mapstructure:"publicKey"`
+	// whether the connection to the peer fails if no secure channel can be established
+	DisablePlaintext bool `json:"disablePlaintext" mapstructure:"disablePlaintext"`
 }
 
 const (
@@ -18,6 +22,14 @@ const (
 	CfgNetGossipBindAddress = "network.gossip.bindAddress"
 	// the number of seconds to wait before trying to reconnect to a disconnected peer
 	CfgNetGossipReconnectAttemptIntervalSeconds = "network.gossip.reconnectAttemptIntervalSeconds"
+	// whether to establish secure channels to static peers with a pinned public key
+	CfgNetGossipSecureChannelEnabled = "network.gossip.secureChannel.enabled"
+	// the private key seed of the secure channel; base64 encoded 256-bit string, a random key is used if empty
+	CfgNetGossipSecureChannelPrivateKeySeed = "network.gossip.secureChannel.privateKeySeed"
+	// the maximum number of milestone cone requests per minute a peer is answered, further requests are dropped, 0 means unlimited
+	CfgNetGossipMilestoneConeRequestsPerMinute = "network.gossip.milestoneCone.requestsPerMinute"
+	// the maximum number of transactions sent in reply to a milestone cone request, 0 means unlimited
+	CfgNetGossipMilestoneConeMaxTransactions = "network.gossip.milestoneCone.maxTransactions"
 
 	// enable inbound connections from unknown peers
 	CfgPeeringAcceptAnyConnection = "acceptAnyConnection"
@@ -42,6 +54,19 @@ const (
 	CfgNetAutopeeringSaltLifetime = "network.autopeering.saltLifetime"
 	// maximum percentage of dropped packets in one minute before an autopeered neighbor gets dropped
 	CfgNetAutopeeringMaxDroppedPacketsPercentage = "network.autopeering.maxDroppedPacketsPercentage"
+
+	// the interval in seconds in which the connected peers are scored
+	CfgNetPeerScoringIntervalSeconds = "network.peerScoring.intervalSeconds"
+	// the score (0-100) below which a peer gets evicted
+	CfgNetPeerScoringMinScore = "network.peerScoring.minScore"
+	// the number of minutes an evicted peer is blacklisted
+	CfgNetPeerScoringBlacklistDurationMinutes = "network.peerScoring.blacklistDurationMinutes"
+	// whether static peers are evicted as well
+	CfgNetPeerScoringEvictStaticPeers = "network.peerScoring.evictStaticPeers"
+	// the latency in milliseconds of answered requests up to which the latency gets the best score
+	CfgNetPeerScoringTargetLatencyMilliseconds = "network.peerScoring.targetLatencyMilliseconds"
+	// the age in seconds of the latest heartbeat up to which the heartbeat gets the best score
+	CfgNetPeerScoringMaxHeartbeatAgeSeconds = "network.peerScoring.maxHeartbeatAgeSeconds"
 )
 
 func init() {
@@ -50,6 +75,10 @@ func init() {
 	flag.Bool(CfgNetPreferIPv6, false, "defines if IPv6 is preferred for peers added through the API")
 	flag.String(CfgNetGossipBindAddress, "0.0.0.0:15600", "the bind address of the gossip TCP server")
 	flag.Int(CfgNetGossipReconnectAttemptIntervalSeconds, 60, "the number of seconds to wait before trying to reconnect to a disconnected peer")
+	flag.Bool(CfgNetGossipSecureChannelEnabled, false, "whether to establish secure channels to static peers with a pinned public key")
+	flag.String(CfgNetGossipSecureChannelPrivateKeySeed, "", "the private key seed of the secure channel; base64 encoded 256-bit string, a random key is used if empty")
+	flag.Int(CfgNetGossipMilestoneConeRequestsPerMinute, 120, "the maximum number of milestone cone requests per minute a peer is answered, further requests are dropped, 0 means unlimited")
+	flag.Int(CfgNetGossipMilestoneConeMaxTransactions, 10000, "the maximum number of transactions sent in reply to a milestone cone request, 0 means unlimited")
 
 	// peering
 	flag.Bool(CfgPeeringAcceptAnyConnection, false, "enable inbound connections from unknown peers")
@@ -70,4 +99,11 @@ func init() {
 	flag.Int(CfgNetAutopeeringOutboundPeers, 2, "the number of outbound autopeers")
 	flag.Int(CfgNetAutopeeringSaltLifetime, 30, "lifetime (in minutes) of the private and public local salt")
 	flag.Int(CfgNetAutopeeringMaxDroppedPacketsPercentage, 0, "maximum percentage of dropped packets in one minute before an autopeered neighbor gets dropped (0 = disable)")
+
+	flag.Int(CfgNetPeerScoringIntervalSeconds, 60, "the interval in seconds in which the connected peers are scored")
+	flag.Float64(CfgNetPeerScoringMinScore, 0, "the score (0-100) below which a peer gets evicted (0 = disable)")
+	flag.Int(CfgNetPeerScoringBlacklistDurationMinutes, 30, "the number of minutes an evicted peer is blacklisted")
+	flag.Bool(CfgNetPeerScoringEvictStaticPeers, false, "whether static peers are evicted as well, they are reconnected after the blacklisting expired")
+	flag.Int(CfgNetPeerScoringTargetLatencyMilliseconds, 2000, "the latency in milliseconds of answered requests up to which the latency gets the best score")
+	flag.Int(CfgNetPeerScoringMaxHeartbeatAgeSeconds, 300, "the age in seconds of the latest heartbeat up to which the heartbeat gets the best score")
 }
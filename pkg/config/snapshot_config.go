@@ -17,6 +17,14 @@ const (
 	CfgLocalSnapshotsPath = "snapshots.local.path"
 	// URL to load the local snapshot file from
 	CfgLocalSnapshotsDownloadURLs = "snapshots.local.downloadURLs"
+	// the minimum amount of download sources which must serve the same snapshot
+	CfgLocalSnapshotsDownloadMinSources = "snapshots.local.downloadMinSources"
+	// the snapshot milestone hash the downloaded snapshot must match
+	CfgLocalSnapshotsPinnedHash = "snapshots.local.pinnedHash"
+	// the hex encoded SHA-256 digest the downloaded snapshot file must match
+	CfgLocalSnapshotsPinnedFileHash = "snapshots.local.pinnedFileHash"
+	// paths to the delta snapshot files which are applied on top of the local snapshot
+	CfgLocalSnapshotsDeltaPaths = "snapshots.local.deltaPaths"
 	// path to the global snapshot file containing the ledger state
 	CfgGlobalSnapshotPath = "snapshots.global.path"
 	// paths to the spent addresses files
@@ -38,6 +46,10 @@ func init() {
 	flag.Int(CfgLocalSnapshotsIntervalUnsynced, 1000, "interval, in milestone transactions, at which snapshot files are created if the ledger is not fully synchronized")
 	flag.String(CfgLocalSnapshotsPath, "snapshots/mainnet/export.bin", "path to the local snapshot file")
 	flag.StringSlice(CfgLocalSnapshotsDownloadURLs, []string{}, "URLs to load the local snapshot file from. Provide multiple URLs as fall back sources")
+	flag.Int(CfgLocalSnapshotsDownloadMinSources, 0, "the minimum amount of download sources which must serve the same snapshot (0 = 2 if multiple URLs are configured, otherwise 1). All reachable sources must agree on the snapshot")
+	flag.String(CfgLocalSnapshotsPinnedHash, "", "the snapshot milestone hash the downloaded snapshot must match. If set, only sources serving this snapshot are used")
+	flag.String(CfgLocalSnapshotsPinnedFileHash, "", "the hex encoded SHA-256 digest the downloaded snapshot file must match. If not set, the file must be identical on all reachable sources")
+	flag.StringSlice(CfgLocalSnapshotsDeltaPaths, []string{}, "paths to the delta snapshot files which are applied in the given order on top of the local snapshot")
 	flag.String(CfgGlobalSnapshotPath, "snapshotMainnet.txt", "path to the global snapshot file containing the ledger state")
 	flag.StringSlice(CfgGlobalSnapshotSpentAddressesPaths, []string{
 		"previousEpochsSpentAddresses1.txt",
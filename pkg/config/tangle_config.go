@@ -7,11 +7,14 @@ import (
 const (
 	// the path to the database folder
 	CfgDatabasePath = "db.path"
+	// the database engine (bolt, badger or inmemory)
+	CfgDatabaseEngine = "db.engine"
 	// ignore the check for corrupted databases (should only be used for debug reasons)
 	CfgDatabaseDebug = "db.debug"
 )
 
 func init() {
 	flag.String(CfgDatabasePath, "mainnetdb", "the path to the database folder")
+	flag.String(CfgDatabaseEngine, "bolt", "the database engine (bolt, badger or inmemory)")
 	flag.Bool(CfgDatabaseDebug, false, "ignore the check for corrupted databases (should only be used for debug reasons)")
 }
@@ -29,8 +29,46 @@ const (
 	CfgWebAPILimitsMaxGetTrytes = "httpAPI.limits.getTrytes"
 	// the maximum number of parameters in an API call
 	CfgWebAPILimitsMaxRequestsList = "httpAPI.limits.requestsList"
+	// the maximum number of addresses that may be returned by the getLedgerStats endpoint
+	CfgWebAPILimitsMaxRichListAddresses = "httpAPI.limits.richListAddresses"
+	// the commands which are limited as expensive requests
+	CfgWebAPILimitsExpensiveCommands = "httpAPI.limits.expensiveCommands"
+	// the maximum number of requests per minute of a client, 0 means unlimited
+	CfgWebAPILimitsRequestsPerMinute = "httpAPI.limits.requestsPerMinute"
+	// the maximum number of expensive requests per minute of a client, 0 means unlimited
+	CfgWebAPILimitsExpensiveRequestsPerMinute = "httpAPI.limits.expensiveRequestsPerMinute"
+	// the maximum number of requests which are processed concurrently, 0 means unlimited
+	CfgWebAPILimitsMaxConcurrentRequests = "httpAPI.limits.maxConcurrentRequests"
+	// the maximum number of expensive requests which are processed concurrently, 0 means unlimited
+	CfgWebAPILimitsMaxConcurrentExpensiveRequests = "httpAPI.limits.maxConcurrentExpensiveRequests"
+	// the maximum number of concurrent websocket subscription connections, 0 disables the websocket endpoint
+	CfgWebAPIWebSocketMaxConnections = "httpAPI.websocket.maxConnections"
+	// the maximum number of hashes and addresses a websocket connection may filter for
+	CfgWebAPIWebSocketMaxFilterEntries = "httpAPI.websocket.maxFilterEntries"
+	// the maximum number of messages queued for a websocket connection before the connection is closed
+	CfgWebAPIWebSocketSendQueueSize = "httpAPI.websocket.sendQueueSize"
+	// the API tokens in the API tokens config file
+	CfgWebAPITokens = "tokens"
 )
 
+// APITokenConfig defines a named bearer token which grants access to a set of HTTP API commands.
+type APITokenConfig struct {
+	// the name of the token used in logs and error messages
+	Name string `json:"name" mapstructure:"name"`
+	// the token+salt as a sha256 hash (see the "pwdhash" tool)
+	TokenHash string `json:"tokenHash" mapstructure:"tokenHash"`
+	// the salt used for hashing the token
+	TokenSalt string `json:"tokenSalt" mapstructure:"tokenSalt"`
+	// the allowed commands or permission groups ("read", "attach", "admin")
+	Permissions []string `json:"permissions" mapstructure:"permissions"`
+	// the maximum number of requests per minute, 0 means unlimited
+	RequestsPerMinute int `json:"requestsPerMinute" mapstructure:"requestsPerMinute"`
+	// the maximum number of transactions attached with PoW per minute, 0 means unlimited
+	PoWPerMinute int `json:"powPerMinute" mapstructure:"powPerMinute"`
+	// whether the token was revoked
+	Revoked bool `json:"revoked" mapstructure:"revoked"`
+}
+
 func init() {
 	flag.String(CfgWebAPIBindAddress, "0.0.0.0:14265", "the bind address on which the HTTP API listens on")
 	flag.StringSlice(CfgWebAPIPermitRemoteAccess,
@@ -56,4 +94,22 @@ func init() {
 	flag.Int(CfgWebAPILimitsMaxFindTransactions, 1000, "the maximum number of transactions that may be returned by the findTransactions endpoint")
 	flag.Int(CfgWebAPILimitsMaxGetTrytes, 1000, "the maximum number of trytes that may be returned by the getTrytes endpoint")
 	flag.Int(CfgWebAPILimitsMaxRequestsList, 1000, "the maximum number of parameters in an API call")
+	flag.Int(CfgWebAPILimitsMaxRichListAddresses, 1000, "the maximum number of addresses that may be returned by the getLedgerStats endpoint")
+	flag.StringSlice(CfgWebAPILimitsExpensiveCommands,
+		[]string{
+			"getLedgerState",
+			"getLedgerDiffExt",
+			"getLedgerStats",
+			"searchConfirmedApprover",
+			"attachToTangle",
+			"promoteTransaction",
+			"reattachTransaction",
+		}, "the commands which are limited as expensive requests")
+	flag.Int(CfgWebAPIWebSocketMaxConnections, 100, "the maximum number of concurrent websocket subscription connections, 0 disables the websocket endpoint")
+	flag.Int(CfgWebAPIWebSocketMaxFilterEntries, 1000, "the maximum number of hashes and addresses a websocket connection may filter for")
+	flag.Int(CfgWebAPIWebSocketSendQueueSize, 1000, "the maximum number of messages queued for a websocket connection before the connection is closed")
+	flag.Int(CfgWebAPILimitsRequestsPerMinute, 0, "the maximum number of requests per minute of a client, 0 means unlimited")
+	flag.Int(CfgWebAPILimitsExpensiveRequestsPerMinute, 60, "the maximum number of expensive requests per minute of a client, 0 means unlimited")
+	flag.Int(CfgWebAPILimitsMaxConcurrentRequests, 0, "the maximum number of requests which are processed concurrently, 0 means unlimited")
+	flag.Int(CfgWebAPILimitsMaxConcurrentExpensiveRequests, 2, "the maximum number of expensive requests which are processed concurrently, 0 means unlimited")
 }
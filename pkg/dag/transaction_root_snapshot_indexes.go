@@ -20,6 +20,14 @@ func UpdateOutdatedRootSnapshotIndexes(outdatedTransactions aingle.Hashes, lsmi
 	}
 }
 
+// IsBelowMaxDepth checks the below max depth criteria for the given transaction.
+// The transaction is below max depth if the OTRSI to LSMI delta is over belowMaxDepth.
+func IsBelowMaxDepth(cachedTxMeta *tangle.CachedMetadata, lsmi milestone.Index, belowMaxDepth milestone.Index) bool {
+	_, ortsi := GetTransactionRootSnapshotIndexes(cachedTxMeta, lsmi) // meta pass +1
+
+	return (lsmi - ortsi) > belowMaxDepth
+}
+
 // GetTransactionRootSnapshotIndexes searches the transaction root snapshot indexes for a given transaction.
 func GetTransactionRootSnapshotIndexes(cachedTxMeta *tangle.CachedMetadata, lsmi milestone.Index) (youngestTxRootSnapshotIndex milestone.Index, oldestTxRootSnapshotIndex milestone.Index) {
 	defer cachedTxMeta.Release(true) // meta -1
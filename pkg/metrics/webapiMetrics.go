@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"go.uber.org/atomic"
+)
+
+var (
+	SharedWebAPIMetrics = &WebAPIMetrics{}
+)
+
+// WebAPIMetrics defines metrics about the requests to the web API over the entire runtime of the node.
+type WebAPIMetrics struct {
+	// The number of requests rejected because the client exceeded its rate limit.
+	RateLimitedRequests atomic.Uint32
+	// The number of expensive requests rejected because the client exceeded its rate limit.
+	RateLimitedExpensiveRequests atomic.Uint32
+	// The number of requests rejected because too many requests were processed concurrently.
+	ConcurrencyLimitedRequests atomic.Uint32
+	// The number of expensive requests rejected because too many expensive requests were processed concurrently.
+	ConcurrencyLimitedExpensiveRequests atomic.Uint32
+}
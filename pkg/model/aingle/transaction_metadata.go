@@ -21,6 +21,44 @@ const (
 	TransactionMetadataIsValue     = 5
 )
 
+// Conflict is the reason why a bundle was excluded from the ledger by a milestone.
+type Conflict uint8
+
+const (
+	// ConflictNone means the bundle was not conflicting.
+	ConflictNone Conflict = iota
+	// ConflictInsufficientBalance means an input address of the bundle did not have enough funds in the previous ledger state.
+	ConflictInsufficientBalance
+	// ConflictSpentFromInput means an input address of the bundle was already spent from by a bundle confirmed by an earlier milestone.
+	// It is kept to decode stored metadata, the white-flag confirmation only distinguishes the reasons known from the ledger state and the cone mutations.
+	ConflictSpentFromInput
+	// ConflictInvalidBundle means the bundle would create a balance above the total supply.
+	ConflictInvalidBundle
+	// ConflictInconsistentCone means the bundle conflicts with a bundle which was applied before in the same milestone cone.
+	ConflictInconsistentCone
+	// ConflictUnknown means the transaction is conflicting, but the reason was not recorded.
+	ConflictUnknown
+)
+
+func (c Conflict) String() string {
+	switch c {
+	case ConflictNone:
+		return "none"
+	case ConflictInsufficientBalance:
+		return "insufficient_balance"
+	case ConflictSpentFromInput:
+		return "spent_from_input"
+	case ConflictInvalidBundle:
+		return "invalid_bundle"
+	case ConflictInconsistentCone:
+		return "inconsistent_cone"
+	case ConflictUnknown:
+		return "unknown"
+	default:
+		return "unknown"
+	}
+}
+
 type TransactionMetadata struct {
 	objectstorage.StorableObjectFlags
 	syncutils.RWMutex
@@ -53,6 +91,9 @@ type TransactionMetadata struct {
 
 	// bundleHash is the bundle of the transaction
 	bundleHash Hash
+
+	// conflict is the reason why the transaction was excluded from the ledger
+	conflict Conflict
 }
 
 func NewTransactionMetadata(txHash Hash) *TransactionMetadata {
@@ -163,12 +204,24 @@ func (m *TransactionMetadata) IsConflicting() bool {
 	return m.metadata.HasFlag(TransactionMetadataConflicting)
 }
 
-func (m *TransactionMetadata) SetConflicting(conflicting bool) {
+// GetConflict returns the reason why the transaction was excluded from the ledger.
+func (m *TransactionMetadata) GetConflict() Conflict {
+	m.RLock()
+	defer m.RUnlock()
+
+	return m.conflict
+}
+
+// SetConflicting marks the transaction as conflicting for the given reason.
+// ConflictNone resets the conflicting flag.
+func (m *TransactionMetadata) SetConflicting(conflict Conflict) {
 	m.Lock()
 	defer m.Unlock()
 
-	if conflicting != m.metadata.HasFlag(TransactionMetadataConflicting) {
+	conflicting := conflict != ConflictNone
+	if conflicting != m.metadata.HasFlag(TransactionMetadataConflicting) || conflict != m.conflict {
 		m.metadata = m.metadata.ModifyFlag(TransactionMetadataConflicting, conflicting)
+		m.conflict = conflict
 		m.SetModified(true)
 	}
 }
@@ -232,6 +285,7 @@ func (m *TransactionMetadata) ObjectStorageValue() (data []byte) {
 		49 bytes hash trunk
 		49 bytes hash branch
 		49 bytes hash bundle
+		1 byte  conflict
 	*/
 
 	value := make([]byte, 21)
@@ -244,6 +298,7 @@ func (m *TransactionMetadata) ObjectStorageValue() (data []byte) {
 	value = append(value, m.trunkHash...)
 	value = append(value, m.branchHash...)
 	value = append(value, m.bundleHash...)
+	value = append(value, byte(m.conflict))
 
 	return value
 }
@@ -262,6 +317,7 @@ func (m *TransactionMetadata) UnmarshalObjectStorageValue(data []byte) (consumed
 		49 bytes hash trunk
 		49 bytes hash branch
 		49 bytes hash bundle
+		1 byte  conflict
 	*/
 
 	m.metadata = bitmask.BitMask(data[0])
@@ -275,11 +331,24 @@ func (m *TransactionMetadata) UnmarshalObjectStorageValue(data []byte) (consumed
 		// ToDo: Remove at next DbVersion update
 		m.rootSnapshotCalculationIndex = milestone.Index(binary.LittleEndian.Uint32(data[17:21]))
 
-		if len(data) == 21+49+49+49 {
+		if len(data) >= 21+49+49+49 {
 			m.trunkHash = Hash(data[21 : 21+49])
 			m.branchHash = Hash(data[21+49 : 21+49+49])
 			m.bundleHash = Hash(data[21+49+49 : 21+49+49+49])
 		}
+
+		// the conflict is stored after the optional hashes
+		switch len(data) {
+		case 21 + 1:
+			m.conflict = Conflict(data[21])
+		case 21 + 49 + 49 + 49 + 1:
+			m.conflict = Conflict(data[21+49+49+49])
+		}
+	}
+
+	if m.conflict == ConflictNone && m.metadata.HasFlag(TransactionMetadataConflicting) {
+		// the reason was not stored by older versions
+		m.conflict = ConflictUnknown
 	}
 
 	return len(data), nil
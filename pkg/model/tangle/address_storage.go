@@ -3,6 +3,7 @@ package tangle
 import (
 	"time"
 
+	"github.com/iotaledger/hive.go/byteutils"
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/objectstorage"
 
@@ -94,6 +95,20 @@ func GetTransactionHashesForAddress(address aingle.Hash, valueOnly bool, forceRe
 	return txHashes
 }
 
+// GetTransactionHashesForAddressPage returns at most limit transaction hashes of the given address in ascending order,
+// starting after the given transaction hash, and whether there are further transactions after the page.
+// address +-0
+func GetTransactionHashesForAddressPage(address aingle.Hash, valueOnly bool, afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+
+	prefix := databaseKeyPrefixForAddress(address)
+	valuePrefix := byteutils.ConcatBytes(prefix, []byte{aingle.AddressTxIsValue})
+	if valueOnly {
+		return getHashPage(addressesStorage, afterTxHash, limit, valuePrefix)
+	}
+
+	return getHashPage(addressesStorage, afterTxHash, limit, byteutils.ConcatBytes(prefix, []byte{0}), valuePrefix)
+}
+
 // AddressConsumer consumes the given address during looping through all addresses in the persistence layer.
 type AddressConsumer func(address aingle.Hash, txHash aingle.Hash, isValue bool) bool
 
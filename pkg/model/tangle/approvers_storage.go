@@ -73,6 +73,14 @@ func GetApproverHashes(txHash aingle.Hash, maxFind ...int) aingle.Hashes {
 	return approverHashes
 }
 
+// GetApproverHashesPage returns at most limit approver hashes of the given transaction in ascending order,
+// starting after the given approver hash, and whether there are further approvers after the page.
+// approvers +-0
+func GetApproverHashesPage(txHash aingle.Hash, afterApproverHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+
+	return getHashPage(approversStorage, afterApproverHash, limit, txHash)
+}
+
 // ApproverConsumer consumes the given approver during looping through all approvers in the persistence layer.
 type ApproverConsumer func(txHash aingle.Hash, approverHash aingle.Hash) bool
 
@@ -0,0 +1,179 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+)
+
+// BalanceChange is the change of the balance of an address caused by a milestone.
+type BalanceChange struct {
+	// MilestoneIndex is the index of the milestone which changed the balance.
+	MilestoneIndex milestone.Index
+	// Delta is the change of the balance.
+	Delta int64
+	// Balance is the balance of the address after the milestone was applied.
+	Balance uint64
+}
+
+// databaseKeyForLedgerDiffAddress returns the key of the address index of a ledger diff.
+// The milestone index is stored in big endian, so the entries of an address are ordered by index.
+func databaseKeyForLedgerDiffAddress(address aingle.Hash, milestoneIndex milestone.Index) []byte {
+	key := make([]byte, 53)
+	copy(key, address[:49])
+	binary.BigEndian.PutUint32(key[49:], uint32(milestoneIndex))
+	return key
+}
+
+func ledgerDiffAddressFromDatabaseKey(key []byte) (aingle.Hash, milestone.Index) {
+	return copyHash(key[:49]), milestone.Index(binary.BigEndian.Uint32(key[49:53]))
+}
+
+// deleteLedgerDiffAddressesForMilestone removes the address index entries of the ledger diff of the given milestone.
+// WriteLockLedger must be held while entering this function.
+func deleteLedgerDiffAddressesForMilestone(index milestone.Index) error {
+
+	diffAddressBatch := ledgerDiffAddressStore.Batched()
+
+	keyPrefix := databaseKeyForMilestoneIndex(index)
+	if err := ledgerDiffStore.IterateKeys(keyPrefix, func(key kvstore.Key) bool {
+		diffAddressBatch.Delete(databaseKeyForLedgerDiffAddress(aingle.Hash(key[len(keyPrefix):len(keyPrefix)+49]), index))
+		return true
+	}); err != nil {
+		diffAddressBatch.Cancel()
+		return errors.Wrap(NewDatabaseError(err), "failed to read ledger diff")
+	}
+
+	if err := diffAddressBatch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger diff address index")
+	}
+
+	return nil
+}
+
+// buildLedgerDiffAddressIndex creates the address index entries for all stored ledger diffs.
+func buildLedgerDiffAddressIndex() error {
+
+	WriteLockLedger()
+	defer WriteUnlockLedger()
+
+	// the index is rebuilt from scratch
+	if err := ledgerDiffAddressStore.Clear(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to clear ledger diff address index")
+	}
+
+	diffAddressBatch := ledgerDiffAddressStore.Batched()
+	batchSize := 0
+
+	var innerErr error
+	if err := ledgerDiffStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		diffAddressBatch.Set(databaseKeyForLedgerDiffAddress(aingle.Hash(key[4:53]), milestoneIndexFromBytes(key[:4])), append([]byte{}, value...))
+		batchSize++
+
+		if batchSize >= migrationBatchSize {
+			if err := diffAddressBatch.Commit(); err != nil {
+				innerErr = err
+				return false
+			}
+			diffAddressBatch = ledgerDiffAddressStore.Batched()
+			batchSize = 0
+		}
+		return true
+	}); err != nil {
+		diffAddressBatch.Cancel()
+		return errors.Wrap(NewDatabaseError(err), "failed to read ledger diff")
+	}
+
+	if innerErr != nil {
+		return errors.Wrap(NewDatabaseError(innerErr), "failed to store ledger diff address index")
+	}
+
+	if err := diffAddressBatch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store ledger diff address index")
+	}
+
+	return nil
+}
+
+// GetBalanceHistoryWithoutLocking returns the balance changes of the given address caused by the milestones
+// from fromIndex to toIndex in ascending order, and the balance of the address before fromIndex.
+// Ledger diffs are only available for milestones after the pruning index.
+// ReadLockLedger must be held while entering this function.
+func GetBalanceHistoryWithoutLocking(address aingle.Hash, fromIndex milestone.Index, toIndex milestone.Index) ([]*BalanceChange, uint64, error) {
+
+	if fromIndex > toIndex {
+		return nil, 0, fmt.Errorf("from index is bigger than to index: %d > %d", fromIndex, toIndex)
+	}
+
+	if toIndex > ledgerMilestoneIndex {
+		return nil, 0, fmt.Errorf("to index is too new. maximum: %d, actual: %d", ledgerMilestoneIndex, toIndex)
+	}
+
+	// collect all changes since fromIndex, the newer ones are needed to calculate the balances backwards from the current ledger state
+	var changes []*BalanceChange
+
+	consumer := func(key kvstore.Key, value kvstore.Value) bool {
+		_, msIndex := ledgerDiffAddressFromDatabaseKey(key)
+		if msIndex < fromIndex {
+			return true
+		}
+
+		changes = append(changes, &BalanceChange{MilestoneIndex: msIndex, Delta: diffFromBytes(value)})
+		return true
+	}
+
+	var err error
+	keyPrefix := databaseKeyForAddress(address)
+	if seeker, ok := ledgerDiffAddressStore.(keySeeker); ok {
+		err = seeker.IterateFrom(keyPrefix, databaseKeyForLedgerDiffAddress(address, fromIndex), consumer)
+	} else {
+		err = ledgerDiffAddressStore.Iterate(keyPrefix, consumer)
+	}
+	if err != nil {
+		return nil, 0, errors.Wrap(NewDatabaseError(err), "failed to read ledger diff address index")
+	}
+
+	// the in-memory store doesn't iterate the keys in order
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].MilestoneIndex < changes[j].MilestoneIndex
+	})
+
+	balance, _, err := GetBalanceForAddressWithoutLocking(address)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		changes[i].Balance = balance
+
+		previousBalance := int64(balance) - changes[i].Delta
+		if previousBalance < 0 {
+			return nil, 0, fmt.Errorf("ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", changes[i].MilestoneIndex, address.Trytes(), balance, changes[i].Delta)
+		}
+		balance = uint64(previousBalance)
+	}
+
+	// drop the changes after toIndex
+	count := sort.Search(len(changes), func(i int) bool {
+		return changes[i].MilestoneIndex > toIndex
+	})
+
+	return changes[:count], balance, nil
+}
+
+// GetBalanceHistory returns the balance changes of the given address caused by the milestones
+// from fromIndex to toIndex in ascending order, and the balance of the address before fromIndex.
+func GetBalanceHistory(address aingle.Hash, fromIndex milestone.Index, toIndex milestone.Index) ([]*BalanceChange, uint64, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	return GetBalanceHistoryWithoutLocking(address, fromIndex, toIndex)
+}
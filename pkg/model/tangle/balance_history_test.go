@@ -0,0 +1,106 @@
+package tangle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+func TestGetBalanceHistory(t *testing.T) {
+	for _, engine := range []DatabaseEngine{EngineBolt, EngineBadger, EngineInMemory} {
+		t.Run(string(engine), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "database")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			db, err := openDatabase(dir, engine)
+			require.NoError(t, err)
+			defer db.Close()
+
+			testGetBalanceHistory(t, db)
+		})
+	}
+}
+
+func testGetBalanceHistory(t *testing.T, db database) {
+
+	tangleStore, snapshotStore, spentStore := db.Stores()
+	ConfigureStorages(tangleStore, snapshotStore, spentStore, profile.Profile1GB.Caches)
+	defer ShutdownStorages()
+
+	addrA, addrB, addrC := testHash(1), testHash(2), testHash(3)
+
+	assert.NoError(t, StoreLedgerBalancesInDatabase(map[string]uint64{string(addrA): consts.TotalSupply}, 1))
+
+	WriteLockLedger()
+	assert.NoError(t, ApplyLedgerDiffWithoutLocking(map[string]int64{string(addrA): -100, string(addrB): 100}, 2))
+	assert.NoError(t, ApplyLedgerDiffWithoutLocking(map[string]int64{string(addrB): -40, string(addrC): 40}, 3))
+	assert.NoError(t, ApplyLedgerDiffWithoutLocking(map[string]int64{string(addrA): -10, string(addrB): 10}, 4))
+	WriteUnlockLedger()
+
+	// the entries of an address are ordered by milestone index
+	assert.Equal(t, -1, bytes.Compare(databaseKeyForLedgerDiffAddress(addrB, 255), databaseKeyForLedgerDiffAddress(addrB, 256)))
+
+	changes, startBalance, err := GetBalanceHistory(addrB, 2, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), startBalance)
+	assert.Equal(t, []*BalanceChange{
+		{MilestoneIndex: 2, Delta: 100, Balance: 100},
+		{MilestoneIndex: 3, Delta: -40, Balance: 60},
+		{MilestoneIndex: 4, Delta: 10, Balance: 70},
+	}, changes)
+
+	changes, startBalance, err = GetBalanceHistory(addrB, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), startBalance)
+	assert.Equal(t, []*BalanceChange{{MilestoneIndex: 3, Delta: -40, Balance: 60}}, changes)
+
+	changes, startBalance, err = GetBalanceHistory(addrA, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.TotalSupply-100, startBalance)
+	assert.Empty(t, changes)
+
+	_, _, err = GetBalanceHistory(addrB, 2, 5)
+	assert.Error(t, err)
+
+	_, _, err = GetBalanceHistory(addrB, 4, 3)
+	assert.Error(t, err)
+
+	// the address index of pruned ledger diffs is removed
+	assert.NoError(t, DeleteLedgerDiffForMilestone(2))
+
+	changes, startBalance, err = GetBalanceHistory(addrB, 1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), startBalance)
+	assert.Len(t, changes, 2)
+
+	result, err := CheckDatabase(false, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Found[InconsistencyLedgerDiffAddressOfMissingDiff])
+	assert.Equal(t, 0, result.Found[InconsistencyMissingLedgerDiffAddress])
+
+	// the address index is rebuilt by the database migration
+	assert.NoError(t, ledgerDiffAddressStore.Clear())
+
+	result, err = CheckDatabase(false, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Found[InconsistencyMissingLedgerDiffAddress])
+
+	assert.NoError(t, migrateVersionTwoToVersionThree())
+
+	changes, startBalance, err = GetBalanceHistory(addrB, 3, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), startBalance)
+	assert.Equal(t, []*BalanceChange{
+		{MilestoneIndex: 3, Delta: -40, Balance: 60},
+		{MilestoneIndex: 4, Delta: 10, Balance: 70},
+	}, changes)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/iotaledger/hive.go/byteutils"
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/objectstorage"
 
@@ -162,6 +163,17 @@ func GetBundleTransactionHashes(bundleHash aingle.Hash, forceRelease bool, maxFi
 	return bundleTransactionHashes
 }
 
+// GetBundleTransactionHashesPage returns at most limit transaction hashes of the given bundle hash in ascending order,
+// starting after the given transaction hash, and whether there are further transactions after the page.
+// bundleTx +-0
+func GetBundleTransactionHashesPage(bundleHash aingle.Hash, afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+
+	prefix := databaseKeyPrefixForBundleHash(bundleHash)
+	return getHashPage(bundleTransactionsStorage, afterTxHash, limit,
+		byteutils.ConcatBytes(prefix, []byte{0}),
+		byteutils.ConcatBytes(prefix, []byte{BundleTxIsTail}))
+}
+
 // bundleTx +1
 func GetAllBundleTransactionHashes(maxFind ...int) aingle.Hashes {
 	var bundleTransactionHashes aingle.Hashes
@@ -0,0 +1,141 @@
+package tangle
+
+import (
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+// DatabaseEngine is the key-value store used to persist the node data.
+type DatabaseEngine string
+
+const (
+	// EngineBolt stores the data in bbolt database files.
+	EngineBolt DatabaseEngine = "bolt"
+	// EngineBadger stores the data in badger databases (LSM tree).
+	EngineBadger DatabaseEngine = "badger"
+	// EngineInMemory keeps the data in memory only. All data is lost on shutdown.
+	EngineInMemory DatabaseEngine = "inmemory"
+)
+
+var (
+	ErrUnknownDatabaseEngine  = errors.New("unknown database engine")
+	ErrDatabaseEngineMismatch = errors.New("database was created with a different engine")
+	ErrDatabaseInUse          = errors.New("database is in use by another process")
+)
+
+// database is the interface every database engine has to implement.
+// Every engine provides three separate stores for the tangle, the snapshot and the spent addresses.
+type database interface {
+	// Stores returns the tangle, snapshot and spent addresses stores.
+	Stores() (tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore)
+	// Close syncs the databases to disk and closes them.
+	Close() error
+	// SupportsCleanup returns whether the engine is able to reclaim unused space.
+	SupportsCleanup() bool
+	// Cleanup reclaims unused space. It returns ErrNothingToCleanUp if nothing was freed.
+	Cleanup() error
+	// Sizes returns the size of the tangle, snapshot and spent addresses databases.
+	Sizes() (tangle int64, snapshot int64, spent int64)
+	// Snapshot returns a consistent read-only view of the current state of the databases.
+	// Writes after the snapshot was taken are not visible within the snapshot.
+	Snapshot() (databaseSnapshot, error)
+}
+
+// databaseSnapshot is a consistent read-only view of the tangle, snapshot and spent addresses stores.
+// It must be released after use.
+type databaseSnapshot interface {
+	// Stores returns the read-only tangle, snapshot and spent addresses stores.
+	Stores() (tangleStore readOnlyStore, snapshotStore readOnlyStore, spentStore readOnlyStore)
+	// Release releases the resources held by the snapshot.
+	Release()
+}
+
+// readOnlyStore is a store of a database snapshot.
+type readOnlyStore interface {
+	// WithRealm returns the records of the given realm.
+	WithRealm(realm kvstore.Realm) recordIterator
+}
+
+// recordIterator iterates over the records of a store.
+// It is satisfied by every kvstore.KVStore.
+type recordIterator interface {
+	// Iterate iterates over all keys and values with the provided prefix.
+	Iterate(prefix kvstore.KeyPrefix, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error
+	// IterateKeys iterates over all keys with the provided prefix.
+	IterateKeys(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyConsumerFunc) error
+}
+
+// keySeeker is implemented by the stores which are able to start an iteration at a given key.
+type keySeeker interface {
+	// IterateFrom iterates in ascending order over the keys and values with the provided prefix,
+	// starting at the first key which is equal to or greater than start.
+	IterateFrom(prefix kvstore.KeyPrefix, start kvstore.Key, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error
+}
+
+// DatabaseEngineFromString parses the given engine name.
+func DatabaseEngineFromString(engine string) (DatabaseEngine, error) {
+	switch DatabaseEngine(engine) {
+	case EngineBolt, EngineBadger, EngineInMemory:
+		return DatabaseEngine(engine), nil
+	default:
+		return "", errors.Wrapf(ErrUnknownDatabaseEngine, "%s", engine)
+	}
+}
+
+// DetectDatabaseEngine returns the engine of an existing database in the given directory.
+// It returns an empty engine if there is no database yet.
+func DetectDatabaseEngine(directory string) DatabaseEngine {
+	if _, err := os.Stat(path.Join(directory, TangleDbFilename)); err == nil {
+		return EngineBolt
+	}
+	if _, err := os.Stat(path.Join(directory, TangleDbDirectory)); err == nil {
+		return EngineBadger
+	}
+	return ""
+}
+
+func openDatabase(directory string, engine DatabaseEngine) (database, error) {
+
+	if engine != EngineInMemory {
+		if existing := DetectDatabaseEngine(directory); existing != "" && existing != engine {
+			return nil, errors.Wrapf(ErrDatabaseEngineMismatch, "existing: %s, configured: %s", existing, engine)
+		}
+	}
+
+	switch engine {
+	case EngineBolt:
+		return newBoltDatabase(directory)
+	case EngineBadger:
+		return newBadgerDatabase(directory)
+	case EngineInMemory:
+		return newInMemoryDatabase(), nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownDatabaseEngine, "%s", engine)
+	}
+}
+
+// fileSize returns the size of the file, or 0 if it does not exist.
+func fileSize(filePath string) int64 {
+	if info, err := os.Stat(filePath); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// closeAll closes all given close functions and returns the first error.
+func closeAll(closeFuncs ...func() error) error {
+	var firstErr error
+	for _, closeFunc := range closeFuncs {
+		if err := closeFunc(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return errors.Wrap(firstErr, "failed to close databases")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+var (
+	// ErrBackupTargetExists is returned if there is already a database in the backup directory.
+	ErrBackupTargetExists = errors.New("backup directory already contains a database")
+)
+
+// backupStores holds a store of the source snapshot and the corresponding store of the backup.
+type backupStores struct {
+	source   readOnlyStore
+	target   kvstore.KVStore
+	prefixes []byte
+}
+
+// BackupProgressFunc is called after every batch of records written to the backup.
+type BackupProgressFunc func(current int64, total int64)
+
+// BackupDatabases writes a consistent copy of the tangle, snapshot and spent addresses databases
+// of the running node to the target directory.
+//
+// The storages are flushed and a snapshot of the databases is taken while the ledger and the spent addresses are locked,
+// so the backup contains all records of the caches and a ledger state which matches the stored ledger diffs and spent addresses.
+// The locks are released before the records are copied.
+// The backup uses the engine of the running databases, in-memory databases are backed up to bolt.
+func BackupDatabases(targetDirectory string, onProgress BackupProgressFunc, abortSignal <-chan struct{}) error {
+
+	snapshot, err := snapshotDatabasesWithLockedLedger()
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to take a snapshot of the database")
+	}
+	defer snapshot.Release()
+
+	targetEngine := dbEngine
+	if targetEngine == EngineInMemory {
+		targetEngine = EngineBolt
+	}
+
+	// the running node marks its database as corrupted, the consistent backup is not.
+	return backupDatabase(snapshot, targetDirectory, targetEngine, true, onProgress, abortSignal)
+}
+
+// snapshotDatabasesWithLockedLedger flushes the storages and takes a snapshot of the databases of the running node
+// while no ledger changes or spent addresses are written.
+func snapshotDatabasesWithLockedLedger() (databaseSnapshot, error) {
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	ReadLockSpentAddresses()
+	defer ReadUnlockSpentAddresses()
+
+	// the milestones, metadata, bundles and spent addresses which are only held in the caches are written first
+	FlushStorages()
+
+	return db.Snapshot()
+}
+
+// BackupDatabase writes a copy of the database in the source directory to the target directory.
+// The database must not be in use by a running node.
+func BackupDatabase(sourceDirectory string, targetDirectory string, onProgress BackupProgressFunc, abortSignal <-chan struct{}) error {
+
+	sourceEngine := DetectDatabaseEngine(sourceDirectory)
+	if sourceEngine == "" {
+		return errors.Wrapf(ErrSourceDatabaseNotFound, "%s", sourceDirectory)
+	}
+
+	sourceDb, err := openDatabase(sourceDirectory, sourceEngine)
+	if err != nil {
+		return errors.Wrap(err, "failed to open source database")
+	}
+	defer sourceDb.Close()
+
+	snapshot, err := sourceDb.Snapshot()
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to take a snapshot of the source database")
+	}
+	defer snapshot.Release()
+
+	return backupDatabase(snapshot, targetDirectory, sourceEngine, false, onProgress, abortSignal)
+}
+
+// backupDatabase copies all records of the source database snapshot to a new database in the target directory.
+// The target database is marked as corrupted until all records were copied.
+// If markHealthy is set, the corrupted marker of the source database is not taken over.
+func backupDatabase(source databaseSnapshot, targetDirectory string, targetEngine DatabaseEngine, markHealthy bool, onProgress BackupProgressFunc, abortSignal <-chan struct{}) error {
+
+	if DetectDatabaseEngine(targetDirectory) != "" {
+		return errors.Wrapf(ErrBackupTargetExists, "%s", targetDirectory)
+	}
+
+	sourceTangleStore, sourceSnapshotStore, sourceSpentStore := source.Stores()
+	sourceHealthStore := sourceTangleStore.WithRealm([]byte{StorePrefixHealth})
+
+	allStores := []*backupStores{
+		{source: sourceTangleStore, prefixes: TangleDatabasePrefixes},
+		{source: sourceSnapshotStore, prefixes: SnapshotDatabasePrefixes},
+		{source: sourceSpentStore, prefixes: SpentAddressesDatabasePrefixes},
+	}
+
+	var total int64
+	for _, stores := range allStores {
+		for _, prefix := range stores.prefixes {
+			count, err := countRecords(stores.source.WithRealm([]byte{prefix}))
+			if err != nil {
+				return errors.Wrap(NewDatabaseError(err), "failed to read source database")
+			}
+			total += int64(count)
+		}
+	}
+
+	targetDb, err := openDatabase(targetDirectory, targetEngine)
+	if err != nil {
+		return errors.Wrap(err, "failed to open target database")
+	}
+	defer targetDb.Close()
+
+	targetTangleStore, targetSnapshotStore, targetSpentStore := targetDb.Stores()
+	targetHealthStore := targetTangleStore.WithRealm([]byte{StorePrefixHealth})
+	allStores[0].target = targetTangleStore
+	allStores[1].target = targetSnapshotStore
+	allStores[2].target = targetSpentStore
+
+	if err := targetHealthStore.Set([]byte(healthKeyCorrupted), []byte{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set database health status")
+	}
+
+	var current int64
+	onBatchCommitted := func(count int) {
+		current += int64(count)
+		if onProgress != nil {
+			onProgress(current, total)
+		}
+	}
+
+	copyPrefix := func(source recordIterator, target kvstore.KVStore, prefix byte) error {
+		if _, err := copyRecords(source, target, onBatchCommitted, abortSignal); err != nil {
+			if err == ErrOperationAborted {
+				return err
+			}
+			return errors.Wrapf(err, "prefix %d", prefix)
+		}
+		return nil
+	}
+
+	for _, stores := range allStores {
+		for _, prefix := range stores.prefixes {
+			if prefix == StorePrefixHealth {
+				// the health markers are written after all records were copied
+				continue
+			}
+
+			if err := copyPrefix(stores.source.WithRealm([]byte{prefix}), stores.target.WithRealm([]byte{prefix}), prefix); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := targetHealthStore.Delete([]byte(healthKeyCorrupted)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set database health status")
+	}
+
+	if err := copyPrefix(sourceHealthStore, targetHealthStore, StorePrefixHealth); err != nil {
+		return err
+	}
+
+	if markHealthy {
+		if err := targetHealthStore.Delete([]byte(healthKeyCorrupted)); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to set database health status")
+		}
+	}
+
+	return nil
+}
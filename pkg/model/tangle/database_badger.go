@@ -0,0 +1,220 @@
+package tangle
+
+import (
+	"path"
+	"runtime"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+
+	"github.com/iotaledger/hive.go/byteutils"
+	"github.com/iotaledger/hive.go/kvstore"
+	badgerstore "github.com/iotaledger/hive.go/kvstore/badger"
+)
+
+const (
+	TangleDbDirectory         = "tangle"
+	SnapshotDbDirectory       = "snapshot"
+	SpentAddressesDbDirectory = "spent"
+
+	// badgerGCDiscardRatio is the ratio of stale data in a value log file which triggers a rewrite.
+	badgerGCDiscardRatio = 0.7
+)
+
+// badgerDatabase stores the data in three badger databases.
+// Badger is a LSM tree based store which allows concurrent writers.
+type badgerDatabase struct {
+	tangleDb   *badger.DB
+	snapshotDb *badger.DB
+	spentDb    *badger.DB
+}
+
+func badgerDB(directory string) (*badger.DB, error) {
+	opts := badger.DefaultOptions(directory)
+	opts.Logger = nil
+	opts.TableLoadingMode = options.MemoryMap
+	opts.ValueLogLoadingMode = options.MemoryMap
+	opts.NumVersionsToKeep = 1
+	opts.CompactL0OnClose = true
+	// same as bolt with NoSync, the databases are synced on shutdown
+	opts.SyncWrites = false
+
+	if runtime.GOOS == "windows" {
+		opts = opts.WithTruncate(true)
+	}
+
+	return badgerstore.CreateDB(directory, opts)
+}
+
+func newBadgerDatabase(directory string) (*badgerDatabase, error) {
+	var err error
+
+	db := &badgerDatabase{}
+	if db.tangleDb, err = badgerDB(path.Join(directory, TangleDbDirectory)); err != nil {
+		return nil, err
+	}
+	if db.snapshotDb, err = badgerDB(path.Join(directory, SnapshotDbDirectory)); err != nil {
+		_ = db.tangleDb.Close()
+		return nil, err
+	}
+	if db.spentDb, err = badgerDB(path.Join(directory, SpentAddressesDbDirectory)); err != nil {
+		_ = closeAll(db.tangleDb.Close, db.snapshotDb.Close)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *badgerDatabase) Stores() (kvstore.KVStore, kvstore.KVStore, kvstore.KVStore) {
+	return newBadgerStore(db.tangleDb), newBadgerStore(db.snapshotDb), newBadgerStore(db.spentDb)
+}
+
+func (db *badgerDatabase) Close() error {
+	return closeAll(db.tangleDb.Close, db.snapshotDb.Close, db.spentDb.Close)
+}
+
+func (db *badgerDatabase) SupportsCleanup() bool {
+	return true
+}
+
+// Cleanup runs the value log garbage collection of all databases until no more files can be rewritten.
+func (db *badgerDatabase) Cleanup() error {
+
+	cleanedUp := false
+	for _, badgerDb := range []*badger.DB{db.tangleDb, db.snapshotDb, db.spentDb} {
+		for {
+			if err := badgerDb.RunValueLogGC(badgerGCDiscardRatio); err != nil {
+				if err == badger.ErrNoRewrite {
+					break
+				}
+				return err
+			}
+			cleanedUp = true
+		}
+	}
+
+	if !cleanedUp {
+		return ErrNothingToCleanUp
+	}
+	return nil
+}
+
+func (db *badgerDatabase) Sizes() (tangle int64, snapshot int64, spent int64) {
+	size := func(badgerDb *badger.DB) int64 {
+		lsm, vlog := badgerDb.Size()
+		return lsm + vlog
+	}
+	return size(db.tangleDb), size(db.snapshotDb), size(db.spentDb)
+}
+
+// Snapshot opens a read-only transaction on every database.
+func (db *badgerDatabase) Snapshot() (databaseSnapshot, error) {
+	return &badgerSnapshot{txns: []*badger.Txn{
+		db.tangleDb.NewTransaction(false),
+		db.snapshotDb.NewTransaction(false),
+		db.spentDb.NewTransaction(false),
+	}}, nil
+}
+
+// badgerSnapshot holds a read-only transaction on the tangle, snapshot and spent addresses databases.
+type badgerSnapshot struct {
+	txns []*badger.Txn
+}
+
+func (s *badgerSnapshot) Stores() (readOnlyStore, readOnlyStore, readOnlyStore) {
+	return &badgerSnapshotStore{txn: s.txns[0]}, &badgerSnapshotStore{txn: s.txns[1]}, &badgerSnapshotStore{txn: s.txns[2]}
+}
+
+func (s *badgerSnapshot) Release() {
+	for _, txn := range s.txns {
+		txn.Discard()
+	}
+	s.txns = nil
+}
+
+// badgerSnapshotStore reads the keys of a read-only transaction.
+type badgerSnapshotStore struct {
+	txn   *badger.Txn
+	realm []byte
+}
+
+func (s *badgerSnapshotStore) WithRealm(realm kvstore.Realm) recordIterator {
+	// the realm of the badger KVStore is prepended to the keys
+	return &badgerSnapshotStore{txn: s.txn, realm: realm}
+}
+
+func (s *badgerSnapshotStore) iterate(prefix kvstore.KeyPrefix, prefetchValues bool, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error {
+	iteratorOptions := badger.DefaultIteratorOptions
+	iteratorOptions.Prefix = byteutils.ConcatBytes(s.realm, prefix)
+	iteratorOptions.PrefetchValues = prefetchValues
+
+	it := s.txn.NewIterator(iteratorOptions)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+
+		var value []byte
+		if prefetchValues {
+			var err error
+			if value, err = item.ValueCopy(nil); err != nil {
+				return err
+			}
+		}
+
+		if !kvConsumerFunc(item.KeyCopy(nil)[len(s.realm):], value) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *badgerSnapshotStore) Iterate(prefix kvstore.KeyPrefix, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error {
+	return s.iterate(prefix, true, kvConsumerFunc)
+}
+
+func (s *badgerSnapshotStore) IterateKeys(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	return s.iterate(prefix, false, func(key kvstore.Key, _ kvstore.Value) bool {
+		return consumerFunc(key)
+	})
+}
+
+// badgerStore is a badger KVStore which is able to seek to a key.
+type badgerStore struct {
+	kvstore.KVStore
+	db *badger.DB
+}
+
+func newBadgerStore(db *badger.DB) *badgerStore {
+	return &badgerStore{KVStore: badgerstore.New(db), db: db}
+}
+
+func (s *badgerStore) WithRealm(realm kvstore.Realm) kvstore.KVStore {
+	return &badgerStore{KVStore: s.KVStore.WithRealm(realm), db: s.db}
+}
+
+func (s *badgerStore) IterateFrom(prefix kvstore.KeyPrefix, start kvstore.Key, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error {
+	realm := s.Realm()
+
+	return s.db.View(func(txn *badger.Txn) error {
+		iteratorOptions := badger.DefaultIteratorOptions
+		iteratorOptions.Prefix = byteutils.ConcatBytes(realm, prefix)
+
+		it := txn.NewIterator(iteratorOptions)
+		defer it.Close()
+
+		for it.Seek(byteutils.ConcatBytes(realm, start)); it.Valid(); it.Next() {
+			item := it.Item()
+
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if !kvConsumerFunc(item.KeyCopy(nil)[len(realm):], value) {
+				break
+			}
+		}
+		return nil
+	})
+}
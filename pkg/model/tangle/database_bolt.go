@@ -0,0 +1,194 @@
+package tangle
+
+import (
+	"bytes"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/bolt"
+)
+
+const (
+	TangleDbFilename         = "tangle.db"
+	SnapshotDbFilename       = "snapshot.db"
+	SpentAddressesDbFilename = "spent.db"
+
+	// boltOpenTimeout is the time to wait for the file lock of a database which is opened by another process.
+	boltOpenTimeout = 2 * time.Second
+)
+
+// boltDatabase stores the data in three bbolt database files.
+type boltDatabase struct {
+	directory  string
+	tangleDb   *bbolt.DB
+	snapshotDb *bbolt.DB
+	spentDb    *bbolt.DB
+}
+
+func boltDB(directory string, filename string) (*bbolt.DB, error) {
+	opts := &bbolt.Options{
+		NoSync:  true,
+		Timeout: boltOpenTimeout,
+	}
+
+	boltDb, err := bolt.CreateDB(directory, filename, opts)
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, errors.Wrapf(ErrDatabaseInUse, "%s", path.Join(directory, filename))
+		}
+		return nil, err
+	}
+	return boltDb, nil
+}
+
+func newBoltDatabase(directory string) (*boltDatabase, error) {
+	var err error
+
+	db := &boltDatabase{directory: directory}
+	if db.tangleDb, err = boltDB(directory, TangleDbFilename); err != nil {
+		return nil, err
+	}
+	if db.snapshotDb, err = boltDB(directory, SnapshotDbFilename); err != nil {
+		_ = db.tangleDb.Close()
+		return nil, err
+	}
+	if db.spentDb, err = boltDB(directory, SpentAddressesDbFilename); err != nil {
+		_ = closeAll(db.tangleDb.Close, db.snapshotDb.Close)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *boltDatabase) Stores() (kvstore.KVStore, kvstore.KVStore, kvstore.KVStore) {
+	return newBoltStore(db.tangleDb), newBoltStore(db.snapshotDb), newBoltStore(db.spentDb)
+}
+
+func (db *boltDatabase) Close() error {
+	closeFunc := func(boltDb *bbolt.DB) func() error {
+		return func() error {
+			if err := boltDb.Sync(); err != nil {
+				return err
+			}
+			return boltDb.Close()
+		}
+	}
+	return closeAll(closeFunc(db.tangleDb), closeFunc(db.snapshotDb), closeFunc(db.spentDb))
+}
+
+func (db *boltDatabase) SupportsCleanup() bool {
+	// Bolt does not support cleaning up anything
+	return false
+}
+
+func (db *boltDatabase) Cleanup() error {
+	// Bolt does not support cleaning up anything
+	return ErrNothingToCleanUp
+}
+
+func (db *boltDatabase) Sizes() (tangle int64, snapshot int64, spent int64) {
+	return fileSize(path.Join(db.directory, TangleDbFilename)),
+		fileSize(path.Join(db.directory, SnapshotDbFilename)),
+		fileSize(path.Join(db.directory, SpentAddressesDbFilename))
+}
+
+// Snapshot opens a read-only transaction on every database.
+// Bolt has to wait for open read-only transactions before it is able to grow the database files,
+// so the snapshot should not be held longer than needed.
+func (db *boltDatabase) Snapshot() (databaseSnapshot, error) {
+	snapshot := &boltSnapshot{}
+	for _, boltDb := range []*bbolt.DB{db.tangleDb, db.snapshotDb, db.spentDb} {
+		tx, err := boltDb.Begin(false)
+		if err != nil {
+			snapshot.Release()
+			return nil, err
+		}
+		snapshot.txs = append(snapshot.txs, tx)
+	}
+	return snapshot, nil
+}
+
+// boltSnapshot holds a read-only transaction on the tangle, snapshot and spent addresses databases.
+type boltSnapshot struct {
+	txs []*bbolt.Tx
+}
+
+func (s *boltSnapshot) Stores() (readOnlyStore, readOnlyStore, readOnlyStore) {
+	return &boltSnapshotStore{tx: s.txs[0]}, &boltSnapshotStore{tx: s.txs[1]}, &boltSnapshotStore{tx: s.txs[2]}
+}
+
+func (s *boltSnapshot) Release() {
+	for _, tx := range s.txs {
+		_ = tx.Rollback()
+	}
+	s.txs = nil
+}
+
+// boltSnapshotStore reads the buckets of a read-only transaction.
+type boltSnapshotStore struct {
+	tx     *bbolt.Tx
+	bucket []byte
+}
+
+func (s *boltSnapshotStore) WithRealm(realm kvstore.Realm) recordIterator {
+	// every realm of the bolt KVStore is stored in its own bucket
+	return &boltSnapshotStore{tx: s.tx, bucket: realm}
+}
+
+func (s *boltSnapshotStore) Iterate(prefix kvstore.KeyPrefix, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error {
+	b := s.tx.Bucket(s.bucket)
+	if b == nil {
+		return nil
+	}
+
+	// the keys and values are only valid during the transaction
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if !kvConsumerFunc(append([]byte{}, k...), append([]byte{}, v...)) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *boltSnapshotStore) IterateKeys(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	return s.Iterate(prefix, func(key kvstore.Key, _ kvstore.Value) bool {
+		return consumerFunc(key)
+	})
+}
+
+// boltStore is a bolt KVStore which is able to seek to a key.
+type boltStore struct {
+	kvstore.KVStore
+	db *bbolt.DB
+}
+
+func newBoltStore(db *bbolt.DB) *boltStore {
+	return &boltStore{KVStore: bolt.New(db), db: db}
+}
+
+func (s *boltStore) WithRealm(realm kvstore.Realm) kvstore.KVStore {
+	return &boltStore{KVStore: s.KVStore.WithRealm(realm), db: s.db}
+}
+
+func (s *boltStore) IterateFrom(prefix kvstore.KeyPrefix, start kvstore.Key, kvConsumerFunc kvstore.IteratorKeyValueConsumerFunc) error {
+	return s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(s.Realm())
+		if b == nil {
+			return nil
+		}
+
+		// the keys and values are only valid during the transaction
+		c := b.Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if !kvConsumerFunc(append([]byte{}, k...), append([]byte{}, v...)) {
+				break
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,642 @@
+package tangle
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+)
+
+// InconsistencyCategory is the kind of an inconsistency found by the database check.
+type InconsistencyCategory int
+
+const (
+	// InconsistencyBundleTxOfMissingTx is a bundle transaction entry of a transaction that does not exist.
+	InconsistencyBundleTxOfMissingTx InconsistencyCategory = iota
+	// InconsistencyMissingBundleTx is a transaction without a bundle transaction entry.
+	InconsistencyMissingBundleTx
+	// InconsistencyBundleWithMissingTx is a bundle which references a transaction that does not exist.
+	InconsistencyBundleWithMissingTx
+	// InconsistencyApproverOfMissingTx is an approver entry of an approvee or approver that does not exist.
+	InconsistencyApproverOfMissingTx
+	// InconsistencyMissingApprover is a transaction without an approver entry for its trunk or branch.
+	InconsistencyMissingApprover
+	// InconsistencyTagOfMissingTx is a tag entry of a transaction that does not exist.
+	InconsistencyTagOfMissingTx
+	// InconsistencyMissingTag is a transaction without a tag entry.
+	InconsistencyMissingTag
+	// InconsistencyAddressOfMissingTx is an address entry of a transaction that does not exist.
+	InconsistencyAddressOfMissingTx
+	// InconsistencyMissingAddress is a transaction without an address entry.
+	InconsistencyMissingAddress
+	// InconsistencyMilestoneBundleMissing is a milestone whose bundle does not exist.
+	InconsistencyMilestoneBundleMissing
+	// InconsistencyMilestoneBundleInvalid is a milestone whose bundle is no milestone of the same index.
+	InconsistencyMilestoneBundleInvalid
+	// InconsistencyLedgerDiffNotBalanced is a ledger diff of a milestone that does not sum up to zero.
+	InconsistencyLedgerDiffNotBalanced
+	// InconsistencyLedgerSupplyMismatch is a snapshot or ledger state that does not match the total supply.
+	InconsistencyLedgerSupplyMismatch
+	// InconsistencyLedgerBalanceMismatch is an address whose ledger balance differs from the
+	// snapshot balance plus the ledger diffs since the snapshot.
+	InconsistencyLedgerBalanceMismatch
+	// InconsistencyLedgerDiffAddressOfMissingDiff is a ledger diff address index entry of a ledger diff that does not exist.
+	InconsistencyLedgerDiffAddressOfMissingDiff
+	// InconsistencyMissingLedgerDiffAddress is a ledger diff without an address index entry.
+	InconsistencyMissingLedgerDiffAddress
+)
+
+// InconsistencyCategories are all categories in the order they are checked.
+var InconsistencyCategories = []InconsistencyCategory{
+	InconsistencyBundleTxOfMissingTx,
+	InconsistencyMissingBundleTx,
+	InconsistencyBundleWithMissingTx,
+	InconsistencyApproverOfMissingTx,
+	InconsistencyMissingApprover,
+	InconsistencyTagOfMissingTx,
+	InconsistencyMissingTag,
+	InconsistencyAddressOfMissingTx,
+	InconsistencyMissingAddress,
+	InconsistencyMilestoneBundleMissing,
+	InconsistencyMilestoneBundleInvalid,
+	InconsistencyLedgerDiffNotBalanced,
+	InconsistencyLedgerSupplyMismatch,
+	InconsistencyLedgerBalanceMismatch,
+	InconsistencyLedgerDiffAddressOfMissingDiff,
+	InconsistencyMissingLedgerDiffAddress,
+}
+
+func (c InconsistencyCategory) String() string {
+	switch c {
+	case InconsistencyBundleTxOfMissingTx:
+		return "bundle transactions of missing transactions"
+	case InconsistencyMissingBundleTx:
+		return "transactions without bundle transaction"
+	case InconsistencyBundleWithMissingTx:
+		return "bundles with missing transactions"
+	case InconsistencyApproverOfMissingTx:
+		return "approvers of missing transactions"
+	case InconsistencyMissingApprover:
+		return "transactions without approver"
+	case InconsistencyTagOfMissingTx:
+		return "tags of missing transactions"
+	case InconsistencyMissingTag:
+		return "transactions without tag"
+	case InconsistencyAddressOfMissingTx:
+		return "addresses of missing transactions"
+	case InconsistencyMissingAddress:
+		return "transactions without address"
+	case InconsistencyMilestoneBundleMissing:
+		return "milestones with missing bundle"
+	case InconsistencyMilestoneBundleInvalid:
+		return "milestones with invalid bundle"
+	case InconsistencyLedgerDiffNotBalanced:
+		return "ledger diffs not summing up to zero"
+	case InconsistencyLedgerSupplyMismatch:
+		return "ledger states not matching the total supply"
+	case InconsistencyLedgerBalanceMismatch:
+		return "ledger balances not matching snapshot and diffs"
+	case InconsistencyLedgerDiffAddressOfMissingDiff:
+		return "ledger diff addresses of missing ledger diffs"
+	case InconsistencyMissingLedgerDiffAddress:
+		return "ledger diffs without address"
+	default:
+		return "unknown"
+	}
+}
+
+// Fixable returns whether the inconsistency only affects an index and can be fixed by the database check.
+func (c InconsistencyCategory) Fixable() bool {
+	switch c {
+	case InconsistencyBundleTxOfMissingTx, InconsistencyMissingBundleTx,
+		InconsistencyApproverOfMissingTx, InconsistencyMissingApprover,
+		InconsistencyTagOfMissingTx, InconsistencyMissingTag,
+		InconsistencyAddressOfMissingTx, InconsistencyMissingAddress,
+		InconsistencyLedgerDiffAddressOfMissingDiff, InconsistencyMissingLedgerDiffAddress:
+		return true
+	default:
+		return false
+	}
+}
+
+// Suggestion returns how the inconsistency can be repaired.
+func (c InconsistencyCategory) Suggestion() string {
+	switch {
+	case c.Fixable():
+		return "run the check with fixing enabled to repair the index"
+	case c == InconsistencyBundleWithMissingTx, c == InconsistencyMilestoneBundleMissing, c == InconsistencyMilestoneBundleInvalid:
+		return "mark the database as corrupted to revalidate it at the next start, or start from a local snapshot"
+	default:
+		return "delete the database and start from a local snapshot"
+	}
+}
+
+// DatabaseCheckResult contains the amount of inconsistencies per category.
+type DatabaseCheckResult struct {
+	// Found is the amount of inconsistencies found per category.
+	Found map[InconsistencyCategory]int
+	// Fixed is the amount of inconsistencies fixed per category.
+	Fixed map[InconsistencyCategory]int
+}
+
+// Total returns the amount of inconsistencies found and not fixed.
+func (r *DatabaseCheckResult) Total() int {
+	total := 0
+	for category, count := range r.Found {
+		total += count - r.Fixed[category]
+	}
+	return total
+}
+
+// DatabaseCheckProgressFunc is called for every analyzed entry of the given storage.
+type DatabaseCheckProgressFunc func(storage string, analyzed int64)
+
+type databaseCheck struct {
+	result      *DatabaseCheckResult
+	fix         bool
+	onProgress  DatabaseCheckProgressFunc
+	abortSignal <-chan struct{}
+}
+
+// CheckDatabase walks all storages of the configured database and reports every inconsistency found.
+// If fix is set, inconsistencies which only affect an index are repaired.
+// The database must not be in use by a running node.
+func CheckDatabase(fix bool, onProgress DatabaseCheckProgressFunc, abortSignal <-chan struct{}) (*DatabaseCheckResult, error) {
+
+	c := &databaseCheck{
+		result: &DatabaseCheckResult{
+			Found: make(map[InconsistencyCategory]int),
+			Fixed: make(map[InconsistencyCategory]int),
+		},
+		fix:         fix,
+		onProgress:  onProgress,
+		abortSignal: abortSignal,
+	}
+
+	for _, check := range []func() error{
+		c.checkTransactions,
+		c.checkBundleTransactions,
+		c.checkBundles,
+		c.checkApprovers,
+		c.checkTags,
+		c.checkAddresses,
+		c.checkMilestones,
+		c.checkLedger,
+		c.checkLedgerDiffAddresses,
+	} {
+		if err := check(); err != nil {
+			return nil, err
+		}
+	}
+
+	if fix {
+		FlushStorages()
+	}
+
+	return c.result, nil
+}
+
+func (c *databaseCheck) isAborted() bool {
+	select {
+	case <-c.abortSignal:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *databaseCheck) progress(storage string, analyzed int64) {
+	if c.onProgress != nil {
+		c.onProgress(storage, analyzed)
+	}
+}
+
+// found counts the inconsistency and applies the fix function if fixing is enabled.
+func (c *databaseCheck) found(category InconsistencyCategory, fixFunc func()) {
+	c.result.Found[category]++
+	if c.fix && fixFunc != nil {
+		fixFunc()
+		c.result.Fixed[category]++
+	}
+}
+
+func copyHash(hash aingle.Hash) aingle.Hash {
+	return append(aingle.Hash{}, hash...)
+}
+
+// checkTransactions checks that the index entries of every stored transaction exist.
+func (c *databaseCheck) checkTransactions() error {
+
+	var txHashes aingle.Hashes
+	ForEachTransactionHash(func(txHash aingle.Hash) bool {
+		txHashes = append(txHashes, copyHash(txHash))
+		return !c.isAborted()
+	}, true)
+
+	for i, txHash := range txHashes {
+		if c.isAborted() {
+			return ErrOperationAborted
+		}
+		c.progress("transactions", int64(i+1))
+
+		storedTx := txStorage.LoadObjectFromStore(txHash)
+		if storedTx == nil {
+			continue
+		}
+		tx := storedTx.(*aingle.Transaction)
+
+		if !ContainsBundleTransaction(copyHash(tx.GetBundleHash()), txHash, tx.IsTail()) {
+			c.found(InconsistencyMissingBundleTx, func() {
+				StoreBundleTransaction(copyHash(tx.GetBundleHash()), txHash, tx.IsTail()).Release(true)
+			})
+		}
+
+		for _, approveeHash := range (aingle.Hashes{tx.GetTrunkHash(), tx.GetBranchHash()}) {
+			if !TransactionExistsInStore(approveeHash) {
+				// the approvee may be a solid entry point or pruned
+				continue
+			}
+
+			if !approversStorage.Contains(aingle.NewApprover(copyHash(approveeHash), txHash).ObjectStorageKey()) {
+				c.found(InconsistencyMissingApprover, func() {
+					StoreApprover(copyHash(approveeHash), txHash).Release(true)
+				})
+			}
+
+			if bytes.Equal(tx.GetTrunkHash(), tx.GetBranchHash()) {
+				break
+			}
+		}
+
+		if !tagsStorage.Contains(aingle.NewTag(copyHash(tx.GetTag()[:17]), txHash).ObjectStorageKey()) {
+			c.found(InconsistencyMissingTag, func() {
+				StoreTag(copyHash(tx.GetTag()), txHash).Release(true)
+			})
+		}
+
+		if !addressesStorage.Contains(aingle.NewAddress(copyHash(tx.GetAddress()), txHash, tx.IsValue()).ObjectStorageKey()) {
+			c.found(InconsistencyMissingAddress, func() {
+				StoreAddress(copyHash(tx.GetAddress()), txHash, tx.IsValue()).Release(true)
+			})
+		}
+	}
+
+	return nil
+}
+
+// checkBundleTransactions checks that the transaction of every bundle transaction entry exists.
+func (c *databaseCheck) checkBundleTransactions() error {
+
+	type bundleTransaction struct {
+		bundleHash aingle.Hash
+		txHash     aingle.Hash
+		isTail     bool
+	}
+
+	var bundleTxs []*bundleTransaction
+	var analyzed int64
+	ForEachBundleTransaction(func(bundleHash aingle.Hash, txHash aingle.Hash, isTail bool) bool {
+		analyzed++
+		c.progress("bundle transactions", analyzed)
+
+		if !TransactionExistsInStore(txHash) {
+			bundleTxs = append(bundleTxs, &bundleTransaction{bundleHash: copyHash(bundleHash), txHash: copyHash(txHash), isTail: isTail})
+		}
+		return !c.isAborted()
+	}, true)
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	for _, bundleTx := range bundleTxs {
+		c.found(InconsistencyBundleTxOfMissingTx, func() {
+			DeleteBundleTransaction(bundleTx.bundleHash, bundleTx.txHash, bundleTx.isTail)
+		})
+	}
+
+	return nil
+}
+
+// checkBundles checks that all transactions of every bundle exist.
+func (c *databaseCheck) checkBundles() error {
+
+	var analyzed int64
+	ForEachBundleHash(func(tailTxHash aingle.Hash) bool {
+		analyzed++
+		c.progress("bundles", analyzed)
+
+		bndl := GetStoredBundleOrNil(tailTxHash)
+		if bndl == nil {
+			return !c.isAborted()
+		}
+
+		for _, txHash := range bndl.GetTxHashes() {
+			if !TransactionExistsInStore(txHash) {
+				c.found(InconsistencyBundleWithMissingTx, nil)
+				break
+			}
+		}
+		return !c.isAborted()
+	}, true)
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	return nil
+}
+
+// checkApprovers checks that the approvee and the approver of every approver entry exist.
+func (c *databaseCheck) checkApprovers() error {
+
+	var approvers []*aingle.Approver
+	var analyzed int64
+	ForEachApprover(func(txHash aingle.Hash, approverHash aingle.Hash) bool {
+		analyzed++
+		c.progress("approvers", analyzed)
+
+		if !TransactionExistsInStore(txHash) || !TransactionExistsInStore(approverHash) {
+			approvers = append(approvers, aingle.NewApprover(copyHash(txHash), copyHash(approverHash)))
+		}
+		return !c.isAborted()
+	}, true)
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	for _, approver := range approvers {
+		c.found(InconsistencyApproverOfMissingTx, func() {
+			DeleteApprover(approver.GetTxHash(), approver.GetApproverHash())
+		})
+	}
+
+	return nil
+}
+
+// checkTags checks that the transaction of every tag entry exists.
+func (c *databaseCheck) checkTags() error {
+
+	var tags []*aingle.Tag
+	var analyzed int64
+	ForEachTag(func(txTag aingle.Hash, txHash aingle.Hash) bool {
+		analyzed++
+		c.progress("tags", analyzed)
+
+		if !TransactionExistsInStore(txHash) {
+			tags = append(tags, aingle.NewTag(copyHash(txTag), copyHash(txHash)))
+		}
+		return !c.isAborted()
+	}, true)
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	for _, tag := range tags {
+		c.found(InconsistencyTagOfMissingTx, func() {
+			DeleteTag(tag.GetTag(), tag.GetTxHash())
+		})
+	}
+
+	return nil
+}
+
+// checkAddresses checks that the transaction of every address entry exists.
+func (c *databaseCheck) checkAddresses() error {
+
+	var addresses []*aingle.Address
+	var analyzed int64
+	ForEachAddress(func(address aingle.Hash, txHash aingle.Hash, isValue bool) bool {
+		analyzed++
+		c.progress("addresses", analyzed)
+
+		if !TransactionExistsInStore(txHash) {
+			addresses = append(addresses, aingle.NewAddress(copyHash(address), copyHash(txHash), isValue))
+		}
+		return !c.isAborted()
+	}, true)
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	for _, address := range addresses {
+		c.found(InconsistencyAddressOfMissingTx, func() {
+			DeleteAddress(address.GetAddress(), address.GetTxHash())
+		})
+	}
+
+	return nil
+}
+
+// checkMilestones checks that the bundle of every milestone exists and is a milestone with the same index.
+func (c *databaseCheck) checkMilestones() error {
+
+	var msIndexes []milestone.Index
+	ForEachMilestoneIndex(func(msIndex milestone.Index) bool {
+		msIndexes = append(msIndexes, msIndex)
+		return !c.isAborted()
+	}, true)
+
+	for i, msIndex := range msIndexes {
+		if c.isAborted() {
+			return ErrOperationAborted
+		}
+		c.progress("milestones", int64(i+1))
+
+		cachedMs := GetCachedMilestoneOrNil(msIndex) // milestone +1
+		if cachedMs == nil {
+			continue
+		}
+		msHash := cachedMs.GetMilestone().Hash
+		cachedMs.Release(true) // milestone -1
+
+		bndl := GetStoredBundleOrNil(msHash)
+		if bndl == nil {
+			c.found(InconsistencyMilestoneBundleMissing, nil)
+			continue
+		}
+
+		if !bndl.IsMilestone() {
+			c.found(InconsistencyMilestoneBundleInvalid, nil)
+			continue
+		}
+
+		if !TransactionExistsInStore(bndl.GetTailHash()) || !metadataStorage.ObjectExistsInStore(bndl.GetTailHash()) {
+			c.found(InconsistencyMilestoneBundleMissing, nil)
+			continue
+		}
+
+		if bndl.GetMilestoneIndex() != msIndex {
+			c.found(InconsistencyMilestoneBundleInvalid, nil)
+		}
+	}
+
+	return nil
+}
+
+// readBalances reads all balances of the given store and returns their sum.
+func (c *databaseCheck) readBalances(store kvstore.KVStore) (map[string]uint64, uint64, error) {
+
+	balances := make(map[string]uint64)
+	var total uint64
+
+	if err := store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		balance := balanceFromBytes(value)
+		balances[string(key[:49])] = balance
+		total += balance
+		return !c.isAborted()
+	}); err != nil {
+		return nil, 0, errors.Wrap(NewDatabaseError(err), "failed to read balances")
+	}
+
+	if c.isAborted() {
+		return nil, 0, ErrOperationAborted
+	}
+
+	return balances, total, nil
+}
+
+// checkLedger checks that the snapshot balances plus the ledger diffs since the snapshot match the ledger state.
+func (c *databaseCheck) checkLedger() error {
+
+	value, err := snapshotStore.Get([]byte(snapshotMilestoneIndexKey))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			// no snapshot was loaded yet, so there is no ledger to check
+			return nil
+		}
+		return errors.Wrap(NewDatabaseError(err), "failed to retrieve snapshot milestone index")
+	}
+	snapshotIndex := milestoneIndexFromBytes(value)
+
+	balances, snapshotTotal, err := c.readBalances(snapshotLedgerStore)
+	if err != nil {
+		return err
+	}
+	if snapshotTotal != consts.TotalSupply {
+		c.found(InconsistencyLedgerSupplyMismatch, nil)
+	}
+
+	ledgerBalances, ledgerTotal, err := c.readBalances(ledgerBalanceStore)
+	if err != nil {
+		return err
+	}
+	if ledgerTotal != consts.TotalSupply {
+		c.found(InconsistencyLedgerSupplyMismatch, nil)
+	}
+
+	// apply all ledger diffs since the snapshot to the snapshot balances
+	expectedBalances := make(map[string]int64)
+	for address, balance := range balances {
+		expectedBalances[address] = int64(balance)
+	}
+
+	for msIndex := snapshotIndex + 1; msIndex <= ledgerMilestoneIndex; msIndex++ {
+		if c.isAborted() {
+			return ErrOperationAborted
+		}
+		c.progress("ledger diffs", int64(msIndex-snapshotIndex))
+
+		keyPrefix := databaseKeyForMilestoneIndex(msIndex)
+
+		var diffSum int64
+		if err := ledgerDiffStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+			change := diffFromBytes(value)
+			expectedBalances[string(key[len(keyPrefix):len(keyPrefix)+49])] += change
+			diffSum += change
+			return true
+		}); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to read ledger diff")
+		}
+
+		if diffSum != 0 {
+			c.found(InconsistencyLedgerDiffNotBalanced, nil)
+		}
+	}
+
+	for address, expected := range expectedBalances {
+		if expected != int64(ledgerBalances[address]) {
+			c.found(InconsistencyLedgerBalanceMismatch, nil)
+		}
+	}
+
+	for address, balance := range ledgerBalances {
+		if _, exists := expectedBalances[address]; !exists && balance != 0 {
+			c.found(InconsistencyLedgerBalanceMismatch, nil)
+		}
+	}
+
+	return nil
+}
+
+// checkLedgerDiffAddresses checks that the address index of the ledger diffs matches the ledger diffs.
+func (c *databaseCheck) checkLedgerDiffAddresses() error {
+
+	type ledgerDiffEntry struct {
+		msIndex milestone.Index
+		address aingle.Hash
+		value   []byte
+	}
+
+	var missingEntries []*ledgerDiffEntry
+	var analyzed int64
+	if err := ledgerDiffStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		analyzed++
+		c.progress("ledger diffs", analyzed)
+
+		entry := &ledgerDiffEntry{msIndex: milestoneIndexFromBytes(key[:4]), address: copyHash(key[4:53])}
+		if exists, err := ledgerDiffAddressStore.Has(databaseKeyForLedgerDiffAddress(entry.address, entry.msIndex)); err == nil && !exists {
+			entry.value = append([]byte{}, value...)
+			missingEntries = append(missingEntries, entry)
+		}
+		return !c.isAborted()
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to read ledger diff")
+	}
+
+	var orphanedEntries []*ledgerDiffEntry
+	analyzed = 0
+	if err := ledgerDiffAddressStore.IterateKeys(kvstore.EmptyPrefix, func(key kvstore.Key) bool {
+		analyzed++
+		c.progress("ledger diff addresses", analyzed)
+
+		entry := &ledgerDiffEntry{}
+		entry.address, entry.msIndex = ledgerDiffAddressFromDatabaseKey(key)
+		if exists, err := ledgerDiffStore.Has(databaseKeyForLedgerDiffAndAddress(entry.msIndex, entry.address)); err == nil && !exists {
+			orphanedEntries = append(orphanedEntries, entry)
+		}
+		return !c.isAborted()
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to read ledger diff address index")
+	}
+
+	if c.isAborted() {
+		return ErrOperationAborted
+	}
+
+	for _, entry := range orphanedEntries {
+		c.found(InconsistencyLedgerDiffAddressOfMissingDiff, func() {
+			if err := ledgerDiffAddressStore.Delete(databaseKeyForLedgerDiffAddress(entry.address, entry.msIndex)); err != nil {
+				panic(errors.Wrap(NewDatabaseError(err), "failed to delete ledger diff address"))
+			}
+		})
+	}
+
+	for _, entry := range missingEntries {
+		c.found(InconsistencyMissingLedgerDiffAddress, func() {
+			if err := ledgerDiffAddressStore.Set(databaseKeyForLedgerDiffAddress(entry.address, entry.msIndex), entry.value); err != nil {
+				panic(errors.Wrap(NewDatabaseError(err), "failed to store ledger diff address"))
+			}
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package tangle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+func testHash(b byte) aingle.Hash {
+	hash := make(aingle.Hash, 49)
+	hash[0] = b
+	return hash
+}
+
+func TestCheckDatabaseIndexes(t *testing.T) {
+	db := newInMemoryDatabase()
+	defer db.Close()
+
+	tangleStore, snapshotStore, spentStore := db.Stores()
+	ConfigureStorages(tangleStore, snapshotStore, spentStore, profile.Profile1GB.Caches)
+	defer ShutdownStorages()
+
+	// index entries of transactions which do not exist
+	StoreApprover(testHash(1), testHash(2)).Release(true)
+	StoreTag(testHash(3), testHash(4)).Release(true)
+	StoreAddress(testHash(5), testHash(6), true).Release(true)
+	StoreBundleTransaction(testHash(7), testHash(8), true).Release(true)
+	FlushStorages()
+
+	result, err := CheckDatabase(false, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Found[InconsistencyApproverOfMissingTx])
+	assert.Equal(t, 1, result.Found[InconsistencyTagOfMissingTx])
+	assert.Equal(t, 1, result.Found[InconsistencyAddressOfMissingTx])
+	assert.Equal(t, 1, result.Found[InconsistencyBundleTxOfMissingTx])
+	assert.Equal(t, 4, result.Total())
+
+	result, err = CheckDatabase(true, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(result.Fixed))
+	assert.Equal(t, 0, result.Total())
+
+	result, err = CheckDatabase(false, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(result.Found))
+
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+	StoreTag(testHash(3), testHash(4)).Release(true)
+	_, err = CheckDatabase(false, nil, abortSignal)
+	assert.Equal(t, ErrOperationAborted, err)
+}
@@ -0,0 +1,76 @@
+package tangle
+
+import (
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+)
+
+// inMemoryDatabase keeps all data in memory. It is meant for tests and short lived nodes.
+type inMemoryDatabase struct {
+	tangleStore   kvstore.KVStore
+	snapshotStore kvstore.KVStore
+	spentStore    kvstore.KVStore
+}
+
+func newInMemoryDatabase() *inMemoryDatabase {
+	return &inMemoryDatabase{
+		tangleStore:   mapdb.NewMapDB(),
+		snapshotStore: mapdb.NewMapDB(),
+		spentStore:    mapdb.NewMapDB(),
+	}
+}
+
+func (db *inMemoryDatabase) Stores() (kvstore.KVStore, kvstore.KVStore, kvstore.KVStore) {
+	return db.tangleStore, db.snapshotStore, db.spentStore
+}
+
+func (db *inMemoryDatabase) Close() error {
+	return nil
+}
+
+func (db *inMemoryDatabase) SupportsCleanup() bool {
+	return false
+}
+
+func (db *inMemoryDatabase) Cleanup() error {
+	return ErrNothingToCleanUp
+}
+
+func (db *inMemoryDatabase) Sizes() (tangle int64, snapshot int64, spent int64) {
+	return 0, 0, 0
+}
+
+// Snapshot copies all records of the in-memory stores, since the map based stores don't support snapshots.
+func (db *inMemoryDatabase) Snapshot() (databaseSnapshot, error) {
+	snapshot := &inMemorySnapshot{}
+	for _, store := range []kvstore.KVStore{db.tangleStore, db.snapshotStore, db.spentStore} {
+		storeCopy := mapdb.NewMapDB()
+		if _, err := copyRecords(store, storeCopy, nil, nil); err != nil {
+			return nil, err
+		}
+		snapshot.stores = append(snapshot.stores, storeCopy)
+	}
+	return snapshot, nil
+}
+
+// inMemorySnapshot holds copies of the in-memory stores.
+type inMemorySnapshot struct {
+	stores []kvstore.KVStore
+}
+
+func (s *inMemorySnapshot) Stores() (readOnlyStore, readOnlyStore, readOnlyStore) {
+	return &inMemorySnapshotStore{store: s.stores[0]}, &inMemorySnapshotStore{store: s.stores[1]}, &inMemorySnapshotStore{store: s.stores[2]}
+}
+
+func (s *inMemorySnapshot) Release() {
+	s.stores = nil
+}
+
+// inMemorySnapshotStore is a read-only store of an in-memory snapshot.
+type inMemorySnapshotStore struct {
+	store kvstore.KVStore
+}
+
+func (s *inMemorySnapshotStore) WithRealm(realm kvstore.Realm) recordIterator {
+	return s.store.WithRealm(realm)
+}
@@ -0,0 +1,227 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+const (
+	// migrationBatchSize is the amount of records written to the target database in a single batch.
+	migrationBatchSize = 10000
+)
+
+var (
+	// ErrSourceDatabaseNotFound is returned if there is no database in the source directory.
+	ErrSourceDatabaseNotFound = errors.New("no database found in source directory")
+	// ErrTargetDatabaseExists is returned if there is already a database in the target directory.
+	ErrTargetDatabaseExists = errors.New("target directory already contains a database")
+	// ErrSourceDatabaseCorrupted is returned if the source database was not shut down cleanly.
+	ErrSourceDatabaseCorrupted = errors.New("source database is marked as corrupted")
+	// ErrSourceDatabaseVersionMismatch is returned if the source database has an outdated database version.
+	ErrSourceDatabaseVersionMismatch = errors.New("source database version mismatch")
+	// ErrMigrationRecordCountMismatch is returned if the amount of records in the source and target database differ.
+	ErrMigrationRecordCountMismatch = errors.New("record count mismatch")
+)
+
+// MigrationProgressFunc is called after all records of a prefix were copied and verified.
+type MigrationProgressFunc func(prefix byte, count int)
+
+type migrationStores struct {
+	source   kvstore.KVStore
+	target   kvstore.KVStore
+	prefixes []byte
+}
+
+// MigrateDatabase copies all records of the database in the source directory to a new database
+// with the given engine in the target directory. The record count of every prefix is verified afterwards.
+//
+// The target database is marked as corrupted until all records were copied and verified,
+// so a node never starts with an incomplete target database without noticing it.
+// The health and version markers of the source database are copied at the end.
+func MigrateDatabase(sourceDirectory string, targetDirectory string, targetEngine DatabaseEngine, onProgress MigrationProgressFunc) error {
+
+	sourceEngine := DetectDatabaseEngine(sourceDirectory)
+	if sourceEngine == "" {
+		return errors.Wrapf(ErrSourceDatabaseNotFound, "%s", sourceDirectory)
+	}
+
+	if targetEngine == EngineInMemory {
+		return errors.Wrapf(ErrUnknownDatabaseEngine, "can not migrate to %s", targetEngine)
+	}
+
+	if DetectDatabaseEngine(targetDirectory) != "" {
+		return errors.Wrapf(ErrTargetDatabaseExists, "%s", targetDirectory)
+	}
+
+	sourceDb, err := openDatabase(sourceDirectory, sourceEngine)
+	if err != nil {
+		return errors.Wrap(err, "failed to open source database")
+	}
+	defer sourceDb.Close()
+
+	sourceTangleStore, sourceSnapshotStore, sourceSpentStore := sourceDb.Stores()
+	sourceHealthStore := sourceTangleStore.WithRealm([]byte{StorePrefixHealth})
+
+	if corrupted, err := sourceHealthStore.Has([]byte(healthKeyCorrupted)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to read database health status")
+	} else if corrupted {
+		return errors.Wrap(ErrSourceDatabaseCorrupted, "the node was not shut down correctly or is still running")
+	}
+
+	version, err := sourceHealthStore.Get([]byte(healthKeyVersion))
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to read database version")
+	}
+	if len(version) == 0 || version[0] != DbVersion {
+		return errors.Wrapf(ErrSourceDatabaseVersionMismatch, "start the node once to update the database")
+	}
+
+	targetDb, err := openDatabase(targetDirectory, targetEngine)
+	if err != nil {
+		return errors.Wrap(err, "failed to open target database")
+	}
+	defer targetDb.Close()
+
+	targetTangleStore, targetSnapshotStore, targetSpentStore := targetDb.Stores()
+	targetHealthStore := targetTangleStore.WithRealm([]byte{StorePrefixHealth})
+
+	if err := targetHealthStore.Set([]byte(healthKeyCorrupted), []byte{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set database health status")
+	}
+
+	for _, stores := range []*migrationStores{
+		{source: sourceTangleStore, target: targetTangleStore, prefixes: TangleDatabasePrefixes},
+		{source: sourceSnapshotStore, target: targetSnapshotStore, prefixes: SnapshotDatabasePrefixes},
+		{source: sourceSpentStore, target: targetSpentStore, prefixes: SpentAddressesDatabasePrefixes},
+	} {
+		for _, prefix := range stores.prefixes {
+			if prefix == StorePrefixHealth {
+				// the health markers are written after all records were verified
+				continue
+			}
+
+			count, err := migratePrefix(stores.source.WithRealm([]byte{prefix}), stores.target.WithRealm([]byte{prefix}))
+			if err != nil {
+				return errors.Wrapf(err, "prefix %d", prefix)
+			}
+
+			if onProgress != nil {
+				onProgress(prefix, count)
+			}
+		}
+	}
+
+	// copy the health and version markers of the source database.
+	// the source database is not marked as corrupted, so this also removes the corrupted marker of the target database.
+	if err := targetHealthStore.Delete([]byte(healthKeyCorrupted)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set database health status")
+	}
+
+	count, err := migratePrefix(sourceHealthStore, targetHealthStore)
+	if err != nil {
+		return errors.Wrapf(err, "prefix %d", StorePrefixHealth)
+	}
+
+	if onProgress != nil {
+		onProgress(StorePrefixHealth, count)
+	}
+
+	return nil
+}
+
+// migratePrefix copies all records from the source to the target store and verifies the record count.
+func migratePrefix(source kvstore.KVStore, target kvstore.KVStore) (int, error) {
+
+	count, err := copyRecords(source, target, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	targetCount, err := countRecords(target)
+	if err != nil {
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to read target database")
+	}
+
+	if targetCount != count {
+		return 0, errors.Wrapf(ErrMigrationRecordCountMismatch, "source: %d, target: %d", count, targetCount)
+	}
+
+	return count, nil
+}
+
+// copyRecords copies all records from the source to the target store in batches.
+// onBatchCommitted is called with the amount of records of every committed batch.
+func copyRecords(source recordIterator, target kvstore.KVStore, onBatchCommitted func(count int), abortSignal <-chan struct{}) (int, error) {
+
+	batch := target.Batched()
+	batchSize := 0
+	count := 0
+
+	commitBatch := func() error {
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+		if onBatchCommitted != nil {
+			onBatchCommitted(batchSize)
+		}
+		return nil
+	}
+
+	aborted := false
+	var innerErr error
+	if err := source.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		select {
+		case <-abortSignal:
+			aborted = true
+			return false
+		default:
+		}
+
+		// the key and value are only valid during the iteration
+		if err := batch.Set(append([]byte{}, key...), append([]byte{}, value...)); err != nil {
+			innerErr = err
+			return false
+		}
+		count++
+		batchSize++
+
+		if batchSize >= migrationBatchSize {
+			if err := commitBatch(); err != nil {
+				innerErr = err
+				return false
+			}
+			batch = target.Batched()
+			batchSize = 0
+		}
+		return true
+	}); err != nil {
+		batch.Cancel()
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to read source database")
+	}
+
+	if aborted {
+		batch.Cancel()
+		return 0, ErrOperationAborted
+	}
+
+	if innerErr != nil {
+		batch.Cancel()
+		return 0, errors.Wrap(NewDatabaseError(innerErr), "failed to write target database")
+	}
+
+	if err := commitBatch(); err != nil {
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to write target database")
+	}
+
+	return count, nil
+}
+
+func countRecords(store recordIterator) (int, error) {
+	count := 0
+	err := store.IterateKeys(kvstore.EmptyPrefix, func(_ kvstore.Key) bool {
+		count++
+		return true
+	})
+	return count, err
+}
@@ -18,4 +18,39 @@ const (
 	StorePrefixUnconfirmedTransactions byte = 14
 	StorePrefixSpentAddresses          byte = 15
 	StorePrefixAutopeering             byte = 16
+	StorePrefixLedgerHash              byte = 17
+	StorePrefixLedgerDiffAddress       byte = 18
+)
+
+var (
+	// TangleDatabasePrefixes are the prefixes of the stores in the tangle database.
+	TangleDatabasePrefixes = []byte{
+		StorePrefixHealth,
+		StorePrefixTransactions,
+		StorePrefixTransactionMetadata,
+		StorePrefixBundleTransactions,
+		StorePrefixBundles,
+		StorePrefixAddresses,
+		StorePrefixMilestones,
+		StorePrefixLedgerState,
+		StorePrefixLedgerBalance,
+		StorePrefixLedgerDiff,
+		StorePrefixApprovers,
+		StorePrefixTags,
+		StorePrefixUnconfirmedTransactions,
+		StorePrefixLedgerHash,
+		StorePrefixLedgerDiffAddress,
+	}
+
+	// SnapshotDatabasePrefixes are the prefixes of the stores in the snapshot database.
+	SnapshotDatabasePrefixes = []byte{
+		StorePrefixSnapshot,
+		StorePrefixSnapshotLedger,
+	}
+
+	// SpentAddressesDatabasePrefixes are the prefixes of the stores in the spent addresses database.
+	// StorePrefixAutopeering is not listed, the autopeering plugin uses its own peer database.
+	SpentAddressesDatabasePrefixes = []byte{
+		StorePrefixSpentAddresses,
+	}
 )
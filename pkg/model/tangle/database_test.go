@@ -0,0 +1,360 @@
+package tangle
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+func TestDatabaseEngines(t *testing.T) {
+	for _, engine := range []DatabaseEngine{EngineBolt, EngineBadger, EngineInMemory} {
+		dir, err := ioutil.TempDir("", "database")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		db, err := openDatabase(dir, engine)
+		assert.NoError(t, err)
+
+		// bolt stores need a realm
+		realm := []byte{StorePrefixHealth}
+
+		tangleStore, snapshotStore, spentStore := db.Stores()
+		tangleStore, snapshotStore, spentStore = tangleStore.WithRealm(realm), snapshotStore.WithRealm(realm), spentStore.WithRealm(realm)
+		assert.NoError(t, tangleStore.Set([]byte("key"), []byte("tangle")))
+		assert.NoError(t, snapshotStore.Set([]byte("key"), []byte("snapshot")))
+		assert.NoError(t, spentStore.Set([]byte("key"), []byte("spent")))
+
+		value, err := tangleStore.Get([]byte("key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("tangle"), []byte(value))
+		assert.NoError(t, db.Close())
+
+		if engine == EngineInMemory {
+			continue
+		}
+
+		// the data is persisted
+		db, err = openDatabase(dir, engine)
+		assert.NoError(t, err)
+		tangleStore, _, _ = db.Stores()
+		tangleStore = tangleStore.WithRealm(realm)
+		value, err = tangleStore.Get([]byte("key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("tangle"), []byte(value))
+		assert.NoError(t, db.Close())
+
+		// a different engine can not open the database
+		other := EngineBadger
+		if engine == EngineBadger {
+			other = EngineBolt
+		}
+		_, err = openDatabase(dir, other)
+		assert.True(t, errors.Is(err, ErrDatabaseEngineMismatch))
+	}
+
+	_, err := DatabaseEngineFromString("leveldb")
+	assert.True(t, errors.Is(err, ErrUnknownDatabaseEngine))
+}
+
+func TestOpenDatabaseClosesStoresOnError(t *testing.T) {
+	for _, engine := range []DatabaseEngine{EngineBolt, EngineBadger} {
+		dir, err := ioutil.TempDir("", "database")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		// the spent addresses database can not be created
+		switch engine {
+		case EngineBolt:
+			assert.NoError(t, os.Mkdir(path.Join(dir, SpentAddressesDbFilename), 0700))
+		case EngineBadger:
+			assert.NoError(t, ioutil.WriteFile(path.Join(dir, SpentAddressesDbDirectory), []byte{}, 0600))
+		}
+
+		_, err = openDatabase(dir, engine)
+		assert.Error(t, err)
+
+		// the already opened databases were closed and are not locked anymore
+		switch engine {
+		case EngineBolt:
+			for _, filename := range []string{TangleDbFilename, SnapshotDbFilename} {
+				boltDb, err := bbolt.Open(path.Join(dir, filename), 0666, &bbolt.Options{Timeout: time.Second})
+				if assert.NoError(t, err) {
+					assert.NoError(t, boltDb.Close())
+				}
+			}
+		case EngineBadger:
+			for _, directory := range []string{TangleDbDirectory, SnapshotDbDirectory} {
+				badgerDb, err := badgerDB(path.Join(dir, directory))
+				if assert.NoError(t, err) {
+					assert.NoError(t, badgerDb.Close())
+				}
+			}
+		}
+	}
+}
+
+func TestOpenBoltDatabaseInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := openDatabase(dir, EngineBolt)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// the files are locked by the opened database
+	_, err = openDatabase(dir, EngineBolt)
+	assert.True(t, errors.Is(err, ErrDatabaseInUse))
+}
+
+func TestMigrateDatabase(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+
+	targetDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(targetDir)
+
+	sourceDb, err := openDatabase(sourceDir, EngineBolt)
+	assert.NoError(t, err)
+
+	tangleStore, snapshotStore, spentStore := sourceDb.Stores()
+	healthStore := tangleStore.WithRealm([]byte{StorePrefixHealth})
+	assert.NoError(t, healthStore.Set([]byte(healthKeyVersion), []byte{DbVersion}))
+	assert.NoError(t, healthStore.Set([]byte(healthKeyCorrupted), []byte{}))
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, tangleStore.WithRealm([]byte{StorePrefixTransactions}).Set([]byte{byte(i)}, []byte{byte(i)}))
+	}
+	assert.NoError(t, snapshotStore.WithRealm([]byte{StorePrefixSnapshot}).Set([]byte("snapshot"), []byte{1}))
+	assert.NoError(t, spentStore.WithRealm([]byte{StorePrefixSpentAddresses}).Set([]byte("spent"), []byte{}))
+	assert.NoError(t, sourceDb.Close())
+
+	// a source database which was not shut down correctly is not migrated
+	err = MigrateDatabase(sourceDir, targetDir, EngineBadger, nil)
+	assert.True(t, errors.Is(err, ErrSourceDatabaseCorrupted))
+
+	sourceDb, err = openDatabase(sourceDir, EngineBolt)
+	assert.NoError(t, err)
+	tangleStore, _, _ = sourceDb.Stores()
+	assert.NoError(t, tangleStore.WithRealm([]byte{StorePrefixHealth}).Delete([]byte(healthKeyCorrupted)))
+	assert.NoError(t, sourceDb.Close())
+
+	migrated := make(map[byte]int)
+	assert.NoError(t, MigrateDatabase(sourceDir, targetDir, EngineBadger, func(prefix byte, count int) {
+		migrated[prefix] = count
+	}))
+	assert.Equal(t, 100, migrated[StorePrefixTransactions])
+	assert.Equal(t, 1, migrated[StorePrefixSnapshot])
+	assert.Equal(t, 1, migrated[StorePrefixSpentAddresses])
+	assert.Equal(t, 1, migrated[StorePrefixHealth])
+
+	targetDb, err := openDatabase(targetDir, EngineBadger)
+	assert.NoError(t, err)
+	defer targetDb.Close()
+
+	tangleStore, _, _ = targetDb.Stores()
+	value, err := tangleStore.WithRealm([]byte{StorePrefixTransactions}).Get([]byte{42})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{42}, []byte(value))
+
+	corrupted, err := tangleStore.WithRealm([]byte{StorePrefixHealth}).Has([]byte(healthKeyCorrupted))
+	assert.NoError(t, err)
+	assert.False(t, corrupted)
+
+	// the target already contains a database
+	err = MigrateDatabase(sourceDir, targetDir, EngineBadger, nil)
+	assert.True(t, errors.Is(err, ErrTargetDatabaseExists))
+}
+
+func TestBackupDatabase(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+
+	targetDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(targetDir)
+
+	sourceDb, err := openDatabase(sourceDir, EngineBadger)
+	assert.NoError(t, err)
+
+	tangleStore, _, spentStore := sourceDb.Stores()
+	healthStore := tangleStore.WithRealm([]byte{StorePrefixHealth})
+	assert.NoError(t, healthStore.Set([]byte(healthKeyVersion), []byte{DbVersion}))
+	assert.NoError(t, healthStore.Set([]byte(healthKeyCorrupted), []byte{}))
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, tangleStore.WithRealm([]byte{StorePrefixTransactions}).Set([]byte{byte(i)}, []byte{byte(i)}))
+	}
+	assert.NoError(t, spentStore.WithRealm([]byte{StorePrefixSpentAddresses}).Set([]byte("spent"), []byte{}))
+
+	// an aborted backup leaves the target database marked as corrupted
+	abortedDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(abortedDir)
+
+	snapshot, err := sourceDb.Snapshot()
+	assert.NoError(t, err)
+
+	// records written after the snapshot was taken are not part of the backup
+	writesDone := writeAfterSnapshot(t, func() {
+		assert.NoError(t, tangleStore.WithRealm([]byte{StorePrefixTransactions}).Set([]byte{200}, []byte{200}))
+		assert.NoError(t, tangleStore.WithRealm([]byte{StorePrefixTransactions}).Delete([]byte{42}))
+	})
+
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+	err = backupDatabase(snapshot, abortedDir, EngineBolt, true, nil, abortSignal)
+	assert.True(t, errors.Is(err, ErrOperationAborted))
+
+	var current, total int64
+	assert.NoError(t, backupDatabase(snapshot, targetDir, EngineBadger, true, func(backupCurrent int64, backupTotal int64) {
+		current, total = backupCurrent, backupTotal
+	}, nil))
+	assert.Equal(t, int64(103), total)
+	assert.Equal(t, total, current)
+
+	// the target already contains a database
+	err = backupDatabase(snapshot, targetDir, EngineBadger, true, nil, nil)
+	assert.True(t, errors.Is(err, ErrBackupTargetExists))
+	snapshot.Release()
+	<-writesDone
+	assert.NoError(t, sourceDb.Close())
+
+	for dir, corrupted := range map[string]bool{abortedDir: true, targetDir: false} {
+		engine := DetectDatabaseEngine(dir)
+		backupDb, err := openDatabase(dir, engine)
+		assert.NoError(t, err)
+
+		tangleStore, _, spentStore = backupDb.Stores()
+		contains, err := tangleStore.WithRealm([]byte{StorePrefixHealth}).Has([]byte(healthKeyCorrupted))
+		assert.NoError(t, err)
+		assert.Equal(t, corrupted, contains)
+
+		if !corrupted {
+			value, err := tangleStore.WithRealm([]byte{StorePrefixTransactions}).Get([]byte{42})
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{42}, []byte(value))
+
+			contains, err = tangleStore.WithRealm([]byte{StorePrefixTransactions}).Has([]byte{200})
+			assert.NoError(t, err)
+			assert.False(t, contains)
+
+			contains, err = spentStore.WithRealm([]byte{StorePrefixSpentAddresses}).Has([]byte("spent"))
+			assert.NoError(t, err)
+			assert.True(t, contains)
+		}
+		assert.NoError(t, backupDb.Close())
+	}
+}
+
+func TestBackupDatabasesFlushesStorages(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+
+	targetDir, err := ioutil.TempDir("", "database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(targetDir)
+
+	prevDb, prevDbEngine := db, dbEngine
+	defer func() { db, dbEngine = prevDb, prevDbEngine }()
+
+	db, err = openDatabase(sourceDir, EngineBolt)
+	assert.NoError(t, err)
+	dbEngine = EngineBolt
+	defer db.Close()
+
+	tangleStore, snapshotStore, spentStore := db.Stores()
+	ConfigureStorages(tangleStore, snapshotStore, spentStore, profile.Profile1GB.Caches)
+	defer ShutdownStorages()
+
+	// the records are only held in the caches of the storages
+	MarkAddressAsSpent(testHash(1))
+	StoreTag(testHash(2), testHash(3)).Release()
+
+	assert.NoError(t, BackupDatabases(targetDir, nil, nil))
+
+	backupDb, err := openDatabase(targetDir, EngineBolt)
+	assert.NoError(t, err)
+	defer backupDb.Close()
+
+	backupTangleStore, _, backupSpentStore := backupDb.Stores()
+	for store, prefix := range map[kvstore.KVStore]byte{backupSpentStore: StorePrefixSpentAddresses, backupTangleStore: StorePrefixTags} {
+		count, err := countRecords(store.WithRealm([]byte{prefix}))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count, "prefix: %d", prefix)
+	}
+}
+
+func TestDatabaseSnapshot(t *testing.T) {
+	for _, engine := range []DatabaseEngine{EngineBolt, EngineBadger, EngineInMemory} {
+		dir, err := ioutil.TempDir("", "database")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		db, err := openDatabase(dir, engine)
+		assert.NoError(t, err)
+
+		_, _, spentStore := db.Stores()
+		spentStore = spentStore.WithRealm([]byte{StorePrefixSpentAddresses})
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, spentStore.Set([]byte{byte(i)}, []byte{byte(i)}))
+		}
+
+		snapshot, err := db.Snapshot()
+		assert.NoError(t, err)
+
+		// changes after the snapshot was taken are not visible
+		writesDone := writeAfterSnapshot(t, func() {
+			assert.NoError(t, spentStore.Set([]byte{10}, []byte{10}))
+			assert.NoError(t, spentStore.Delete([]byte{0}))
+		})
+
+		_, _, snapshotSpentStore := snapshot.Stores()
+		records := make(map[byte]byte)
+		assert.NoError(t, snapshotSpentStore.WithRealm([]byte{StorePrefixSpentAddresses}).Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+			records[key[0]] = value[0]
+			return true
+		}))
+		assert.Len(t, records, 10, "engine: %s", engine)
+		assert.Contains(t, records, byte(0), "engine: %s", engine)
+		assert.NotContains(t, records, byte(10), "engine: %s", engine)
+
+		count, err := countRecords(snapshotSpentStore.WithRealm([]byte{StorePrefixSpentAddresses}))
+		assert.NoError(t, err)
+		assert.Equal(t, 10, count, "engine: %s", engine)
+
+		snapshot.Release()
+		<-writesDone
+		assert.NoError(t, db.Close())
+	}
+}
+
+// writeAfterSnapshot runs the given writes while a snapshot is held.
+// Bolt is not able to grow its database files while a snapshot is held,
+// so the writes are done in the background and may only finish after the snapshot was released.
+func writeAfterSnapshot(t *testing.T, writes func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writes()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Log("writes are blocked by the snapshot")
+	}
+	return done
+}
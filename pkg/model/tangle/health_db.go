@@ -7,7 +7,11 @@ import (
 )
 
 const (
-	DbVersion = 2
+	DbVersion = 3
+
+	healthKeyCorrupted = "dbCorrupted"
+	healthKeyTainted   = "dbTainted"
+	healthKeyVersion   = "dbVersion"
 )
 
 var (
@@ -21,28 +25,28 @@ func configureHealthStore(store kvstore.KVStore) {
 
 func MarkDatabaseCorrupted() {
 
-	if err := healthStore.Set([]byte("dbCorrupted"), []byte{}); err != nil {
+	if err := healthStore.Set([]byte(healthKeyCorrupted), []byte{}); err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to set database health status"))
 	}
 }
 
 func MarkDatabaseTainted() {
 
-	if err := healthStore.Set([]byte("dbTainted"), []byte{}); err != nil {
+	if err := healthStore.Set([]byte(healthKeyTainted), []byte{}); err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to set database health status"))
 	}
 }
 
 func MarkDatabaseHealthy() {
 
-	if err := healthStore.Delete([]byte("dbCorrupted")); err != nil {
+	if err := healthStore.Delete([]byte(healthKeyCorrupted)); err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to set database health status"))
 	}
 }
 
 func IsDatabaseCorrupted() bool {
 
-	contains, err := healthStore.Has([]byte("dbCorrupted"))
+	contains, err := healthStore.Has([]byte(healthKeyCorrupted))
 	if err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to read database health status"))
 	}
@@ -51,7 +55,7 @@ func IsDatabaseCorrupted() bool {
 
 func IsDatabaseTainted() bool {
 
-	contains, err := healthStore.Has([]byte("dbTainted"))
+	contains, err := healthStore.Has([]byte(healthKeyTainted))
 	if err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to read database health status"))
 	}
@@ -59,10 +63,10 @@ func IsDatabaseTainted() bool {
 }
 
 func setDatabaseVersion() {
-	_, err := healthStore.Get([]byte("dbVersion"))
+	_, err := healthStore.Get([]byte(healthKeyVersion))
 	if err == kvstore.ErrKeyNotFound {
 		// Only create the entry, if it doesn't exist already (fresh database)
-		if err := healthStore.Set([]byte("dbVersion"), []byte{DbVersion}); err != nil {
+		if err := healthStore.Set([]byte(healthKeyVersion), []byte{DbVersion}); err != nil {
 			panic(errors.Wrap(NewDatabaseError(err), "failed to set database version"))
 		}
 	}
@@ -70,7 +74,7 @@ func setDatabaseVersion() {
 
 func IsCorrectDatabaseVersion() bool {
 
-	value, err := healthStore.Get([]byte("dbVersion"))
+	value, err := healthStore.Get([]byte(healthKeyVersion))
 	if err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to read database version"))
 	}
@@ -84,7 +88,7 @@ func IsCorrectDatabaseVersion() bool {
 
 // UpdateDatabaseVersion tries to migrate the existing data to the new database version.
 func UpdateDatabaseVersion() bool {
-	value, err := healthStore.Get([]byte("dbVersion"))
+	value, err := healthStore.Get([]byte(healthKeyVersion))
 	if err != nil {
 		panic(errors.Wrap(NewDatabaseError(err), "failed to read database version"))
 	}
@@ -95,16 +99,30 @@ func UpdateDatabaseVersion() bool {
 
 	currentDbVersion := int(value[0])
 
-	if currentDbVersion == 1 && DbVersion == 2 {
+	if currentDbVersion == 1 {
 		// add information about trunk and branch to transaction metadata
 		if err := migrateVersionOneToVersionTwo(); err != nil {
 			panic(errors.Wrap(NewDatabaseError(err), "failed to migrate database to new version"))
 		}
-		setDatabaseVersion()
-		return true
+		currentDbVersion = 2
 	}
 
-	return false
+	if currentDbVersion == 2 {
+		// add the address index of the ledger diffs
+		if err := migrateVersionTwoToVersionThree(); err != nil {
+			panic(errors.Wrap(NewDatabaseError(err), "failed to migrate database to new version"))
+		}
+		currentDbVersion = 3
+	}
+
+	if currentDbVersion != DbVersion {
+		return false
+	}
+
+	if err := healthStore.Set([]byte(healthKeyVersion), []byte{DbVersion}); err != nil {
+		panic(errors.Wrap(NewDatabaseError(err), "failed to set database version"))
+	}
+	return true
 }
 
 func migrateVersionOneToVersionTwo() error {
@@ -112,3 +130,8 @@ func migrateVersionOneToVersionTwo() error {
 	// trunk an branch hashes were added to the metadata
 	return nil
 }
+
+func migrateVersionTwoToVersionThree() error {
+	// the ledger diffs are additionally stored by address to query the balance history of an address
+	return buildLedgerDiffAddressIndex()
+}
@@ -19,10 +19,12 @@ const (
 )
 
 var (
-	ledgerStore           kvstore.KVStore
-	ledgerBalanceStore    kvstore.KVStore
-	ledgerDiffStore       kvstore.KVStore
-	ledgerTransactionLock sync.RWMutex
+	ledgerStore            kvstore.KVStore
+	ledgerBalanceStore     kvstore.KVStore
+	ledgerDiffStore        kvstore.KVStore
+	ledgerHashStore        kvstore.KVStore
+	ledgerDiffAddressStore kvstore.KVStore
+	ledgerTransactionLock  sync.RWMutex
 
 	ledgerMilestoneIndex milestone.Index
 )
@@ -47,6 +49,8 @@ func configureLedgerStore(store kvstore.KVStore) {
 	ledgerStore = store.WithRealm([]byte{StorePrefixLedgerState})
 	ledgerBalanceStore = store.WithRealm([]byte{StorePrefixLedgerBalance})
 	ledgerDiffStore = store.WithRealm([]byte{StorePrefixLedgerDiff})
+	ledgerHashStore = store.WithRealm([]byte{StorePrefixLedgerHash})
+	ledgerDiffAddressStore = store.WithRealm([]byte{StorePrefixLedgerDiffAddress})
 
 	if err := readLedgerMilestoneIndexFromDatabase(); err != nil {
 		panic(err)
@@ -95,6 +99,10 @@ func readLedgerMilestoneIndexFromDatabase() error {
 	}
 	ledgerMilestoneIndex = milestoneIndexFromBytes(value)
 
+	if err := readLedgerHashFromDatabase(); err != nil {
+		return err
+	}
+
 	// set the solid milestone index based on the ledger milestone
 	SetSolidMilestoneIndex(ledgerMilestoneIndex, false)
 
@@ -127,11 +135,15 @@ func DeleteLedgerDiffForMilestone(index milestone.Index) error {
 	WriteLockLedger()
 	defer WriteUnlockLedger()
 
+	if err := deleteLedgerDiffAddressesForMilestone(index); err != nil {
+		return err
+	}
+
 	if err := ledgerDiffStore.DeletePrefix(databaseKeyForMilestoneIndex(index)); err != nil {
 		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger diff")
 	}
 
-	return nil
+	return deleteLedgerHashForMilestone(index)
 }
 
 // GetLedgerDiffForMilestoneWithoutLocking returns the ledger changes of that specific milestone.
@@ -265,8 +277,10 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 
 	balanceBatch := ledgerBalanceStore.Batched()
 	diffBatch := ledgerDiffStore.Batched()
+	diffAddressBatch := ledgerDiffAddressStore.Batched()
 
 	var diffSum int64
+	newLedgerHash := ledgerHash
 
 	for address, change := range diff {
 
@@ -287,8 +301,11 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 			balanceBatch.Delete(databaseKeyForAddress(aingle.Hash(address)))
 		}
 
+		newLedgerHash.updateBalance(aingle.Hash(address), balance, uint64(newBalance))
+
 		//Save diff
 		diffBatch.Set(databaseKeyForLedgerDiffAndAddress(index, aingle.Hash(address)), bytesFromDiff(change))
+		diffAddressBatch.Set(databaseKeyForLedgerDiffAddress(aingle.Hash(address), index), bytesFromDiff(change))
 
 		diffSum += change
 	}
@@ -301,6 +318,10 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 		return errors.Wrap(NewDatabaseError(err), "failed to store ledger diff")
 	}
 
+	if err := diffAddressBatch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store ledger diff address index")
+	}
+
 	if err := balanceBatch.Commit(); err != nil {
 		return errors.Wrap(NewDatabaseError(err), "failed to store ledger balance")
 	}
@@ -310,7 +331,7 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 	}
 
 	ledgerMilestoneIndex = index
-	return nil
+	return storeLedgerHash(newLedgerHash, index)
 }
 
 func StoreLedgerBalancesInDatabase(balances map[string]uint64, index milestone.Index) error {
@@ -342,7 +363,7 @@ func StoreLedgerBalancesInDatabase(balances map[string]uint64, index milestone.I
 	}
 
 	ledgerMilestoneIndex = index
-	return nil
+	return storeLedgerHash(ComputeLedgerHash(balances), index)
 }
 
 // GetLedgerStateForLSMIWithoutLocking returns all balances for the current solid milestone.
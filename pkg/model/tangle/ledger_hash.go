@@ -0,0 +1,178 @@
+package tangle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+)
+
+const (
+	ledgerHashKey = "ledgerHash"
+
+	// LedgerHashLength is the length of a ledger state hash.
+	LedgerHashLength = sha256.Size
+)
+
+var (
+	// the ledger hash of the current ledger milestone index
+	ledgerHash LedgerHash
+)
+
+// LedgerHash is an order independent hash over all address/balance pairs of the ledger.
+// It is the sum of the SHA-256 hashes of all (address, balance) pairs modulo 2^256,
+// which makes it possible to update it incrementally with every ledger diff.
+type LedgerHash [LedgerHashLength]byte
+
+// String returns the hex encoded ledger hash.
+func (h LedgerHash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// ledgerEntryHash returns the hash of a single address/balance pair.
+func ledgerEntryHash(address aingle.Hash, balance uint64) LedgerHash {
+	entry := make([]byte, 49+8)
+	copy(entry, address[:49])
+	binary.LittleEndian.PutUint64(entry[49:], balance)
+	return sha256.Sum256(entry)
+}
+
+// add adds the given value to the hash (big endian, modulo 2^256).
+func (h *LedgerHash) add(value LedgerHash) {
+	var carry uint64
+	for i := LedgerHashLength - 8; i >= 0; i -= 8 {
+		var sum uint64
+		sum, carry = bits.Add64(binary.BigEndian.Uint64(h[i:]), binary.BigEndian.Uint64(value[i:]), carry)
+		binary.BigEndian.PutUint64(h[i:], sum)
+	}
+}
+
+// sub subtracts the given value from the hash (big endian, modulo 2^256).
+func (h *LedgerHash) sub(value LedgerHash) {
+	var borrow uint64
+	for i := LedgerHashLength - 8; i >= 0; i -= 8 {
+		var diff uint64
+		diff, borrow = bits.Sub64(binary.BigEndian.Uint64(h[i:]), binary.BigEndian.Uint64(value[i:]), borrow)
+		binary.BigEndian.PutUint64(h[i:], diff)
+	}
+}
+
+// updateBalance replaces the old balance of the address with the new balance in the hash.
+func (h *LedgerHash) updateBalance(address aingle.Hash, oldBalance uint64, newBalance uint64) {
+	if oldBalance != 0 {
+		h.sub(ledgerEntryHash(address, oldBalance))
+	}
+	if newBalance != 0 {
+		h.add(ledgerEntryHash(address, newBalance))
+	}
+}
+
+// ComputeLedgerHash computes the ledger hash of the given balances.
+func ComputeLedgerHash(balances map[string]uint64) LedgerHash {
+	var h LedgerHash
+	for address, balance := range balances {
+		h.updateBalance(aingle.Hash(address), 0, balance)
+	}
+	return h
+}
+
+func bytesFromLedgerHash(h LedgerHash, index milestone.Index) []byte {
+	return append(h[:], bytesFromMilestoneIndex(index)...)
+}
+
+// readLedgerHashFromDatabase loads the ledger hash of the current ledger milestone index.
+// If there is no ledger hash for the current ledger milestone index (e.g. databases created by older versions),
+// it is computed from the ledger balances.
+// The ledger lock must be held while entering this function.
+func readLedgerHashFromDatabase() error {
+
+	value, err := ledgerStore.Get([]byte(ledgerHashKey))
+	if err != nil && err != kvstore.ErrKeyNotFound {
+		return errors.Wrap(NewDatabaseError(err), "failed to load ledger hash")
+	}
+
+	if err == nil && len(value) == LedgerHashLength+4 && milestoneIndexFromBytes(value[LedgerHashLength:]) == ledgerMilestoneIndex {
+		copy(ledgerHash[:], value[:LedgerHashLength])
+		return nil
+	}
+
+	if ledgerMilestoneIndex == 0 {
+		// empty ledger
+		ledgerHash = LedgerHash{}
+		return nil
+	}
+
+	var h LedgerHash
+	if err := ledgerBalanceStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		h.updateBalance(aingle.Hash(key[:49]), 0, balanceFromBytes(value))
+		return true
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to compute ledger hash")
+	}
+
+	return storeLedgerHash(h, ledgerMilestoneIndex)
+}
+
+// storeLedgerHash stores the ledger hash of the given milestone and sets it as the current ledger hash.
+// WriteLockLedger must be held while entering this function.
+func storeLedgerHash(h LedgerHash, index milestone.Index) error {
+
+	if err := ledgerHashStore.Set(databaseKeyForMilestoneIndex(index), h[:]); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store ledger hash for milestone")
+	}
+
+	if err := ledgerStore.Set([]byte(ledgerHashKey), bytesFromLedgerHash(h, index)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store ledger hash")
+	}
+
+	ledgerHash = h
+	return nil
+}
+
+// deleteLedgerHashForMilestone deletes the stored ledger hash of the given milestone.
+func deleteLedgerHashForMilestone(index milestone.Index) error {
+	if err := ledgerHashStore.Delete(databaseKeyForMilestoneIndex(index)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger hash")
+	}
+	return nil
+}
+
+// GetLedgerHashWithoutLocking returns the ledger hash of the current ledger milestone index.
+// ReadLockLedger must be held while entering this function.
+func GetLedgerHashWithoutLocking() (LedgerHash, milestone.Index) {
+	return ledgerHash, ledgerMilestoneIndex
+}
+
+// GetLedgerHash returns the ledger hash of the current ledger milestone index.
+func GetLedgerHash() (LedgerHash, milestone.Index) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	return GetLedgerHashWithoutLocking()
+}
+
+// GetLedgerHashForMilestone returns the ledger hash of the given milestone.
+// It returns false if no ledger hash is stored for that milestone (e.g. it was pruned).
+func GetLedgerHashForMilestone(index milestone.Index) (LedgerHash, bool, error) {
+
+	var h LedgerHash
+
+	value, err := ledgerHashStore.Get(databaseKeyForMilestoneIndex(index))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return h, false, nil
+		}
+		return h, false, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger hash")
+	}
+
+	copy(h[:], value)
+	return h, true, nil
+}
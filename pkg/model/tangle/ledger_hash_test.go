@@ -0,0 +1,49 @@
+package tangle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+)
+
+func testAddress(b byte) string {
+	address := make([]byte, 49)
+	address[0] = b
+	return string(address)
+}
+
+func TestLedgerHashIncremental(t *testing.T) {
+	balances := map[string]uint64{
+		testAddress(1): 100,
+		testAddress(2): 200,
+		testAddress(3): 300,
+	}
+	h := ComputeLedgerHash(balances)
+
+	// apply a diff: 3 sends everything to 1 and 4
+	diff := map[string]int64{
+		testAddress(1): 150,
+		testAddress(3): -300,
+		testAddress(4): 150,
+	}
+	for address, change := range diff {
+		newBalance := uint64(int64(balances[address]) + change)
+		h.updateBalance(aingle.Hash(address), balances[address], newBalance)
+		if newBalance == 0 {
+			delete(balances, address)
+			continue
+		}
+		balances[address] = newBalance
+	}
+
+	assert.Equal(t, ComputeLedgerHash(balances), h)
+	assert.NotEqual(t, LedgerHash{}, h)
+
+	// removing all balances results in the empty hash
+	for address, balance := range balances {
+		h.updateBalance(aingle.Hash(address), balance, 0)
+	}
+	assert.Equal(t, LedgerHash{}, h)
+}
@@ -0,0 +1,110 @@
+package tangle
+
+import (
+	"bytes"
+	"container/heap"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/objectstorage"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+)
+
+// getHashPage returns at most limit hashes in ascending order which come after the given hash,
+// and whether there are further hashes after the page.
+// The hashes are stored in the keys with the given prefixes, right after the prefix.
+// The keys are iterated through the object storage, so the page also contains the cached entries
+// which are not written to the store yet.
+func getHashPage(storage *objectstorage.ObjectStorage, after aingle.Hash, limit int, prefixes ...kvstore.KeyPrefix) (aingle.Hashes, bool) {
+
+	page := newHashPage(after, limit)
+	for _, prefix := range prefixes {
+		storage.ForEachKeyOnly(func(key []byte) bool {
+			page.add(key[len(prefix) : len(prefix)+49])
+			return true
+		}, false, prefix)
+	}
+
+	return page.result()
+}
+
+// hashPage collects the smallest hashes which are greater than a cursor hash.
+// The cached keys are not iterated in order, so all keys have to be passed to the page,
+// but only limit+1 hashes are kept in memory at the same time.
+type hashPage struct {
+	after aingle.Hash
+	limit int
+	// hashes is a max-heap of the smallest hashes found so far.
+	hashes hashMaxHeap
+}
+
+// newHashPage creates a page of at most limit hashes which come after the given hash.
+// An empty hash starts the page at the beginning of the index.
+func newHashPage(after aingle.Hash, limit int) *hashPage {
+	return &hashPage{
+		after:  after,
+		limit:  limit,
+		hashes: make(hashMaxHeap, 0, limit+1),
+	}
+}
+
+// add adds the hash to the page if it belongs to it.
+func (p *hashPage) add(hash aingle.Hash) {
+	if len(p.after) > 0 && bytes.Compare(hash, p.after) <= 0 {
+		return
+	}
+
+	if len(p.hashes) <= p.limit {
+		// the key of the hash may be reused by the iteration
+		heap.Push(&p.hashes, copyHash(hash))
+		return
+	}
+
+	if bytes.Compare(hash, p.hashes[0]) < 0 {
+		// replace the biggest hash of the page
+		p.hashes[0] = copyHash(hash)
+		heap.Fix(&p.hashes, 0)
+	}
+}
+
+// result returns the hashes of the page in ascending order and whether there are further hashes after the page.
+func (p *hashPage) result() (aingle.Hashes, bool) {
+	more := len(p.hashes) > p.limit
+	if more {
+		heap.Pop(&p.hashes)
+	}
+
+	result := make(aingle.Hashes, len(p.hashes))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(&p.hashes).(aingle.Hash)
+	}
+
+	return result, more
+}
+
+// hashMaxHeap implements heap.Interface with the biggest hash on top.
+type hashMaxHeap aingle.Hashes
+
+func (h hashMaxHeap) Len() int {
+	return len(h)
+}
+
+func (h hashMaxHeap) Less(i, j int) bool {
+	return bytes.Compare(h[i], h[j]) > 0
+}
+
+func (h hashMaxHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *hashMaxHeap) Push(x interface{}) {
+	*h = append(*h, x.(aingle.Hash))
+}
+
+func (h *hashMaxHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,124 @@
+package tangle
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/byteutils"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+func TestHashPage(t *testing.T) {
+	var hashes aingle.Hashes
+	for i := 0; i < 25; i++ {
+		hashes = append(hashes, testHash(byte(i)))
+	}
+
+	// the hashes are added in random order
+	collectPage := func(after aingle.Hash, limit int) (aingle.Hashes, bool) {
+		page := newHashPage(after, limit)
+		for _, i := range rand.Perm(len(hashes)) {
+			page.add(hashes[i])
+		}
+		return page.result()
+	}
+
+	var collected aingle.Hashes
+	var after aingle.Hash
+	for {
+		page, more := collectPage(after, 10)
+		collected = append(collected, page...)
+		if !more {
+			break
+		}
+		after = page[len(page)-1]
+	}
+	assert.Equal(t, hashes, collected)
+
+	// an exactly filled page has no further hashes
+	page, more := collectPage(hashes[14], 10)
+	assert.Equal(t, hashes[15:], page)
+	assert.False(t, more)
+
+	page, more = collectPage(hashes[24], 10)
+	assert.Empty(t, page)
+	assert.False(t, more)
+}
+
+func TestGetHashPage(t *testing.T) {
+	for _, engine := range []DatabaseEngine{EngineBolt, EngineBadger, EngineInMemory} {
+		t.Run(string(engine), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "database")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			db, err := openDatabase(dir, engine)
+			require.NoError(t, err)
+			defer db.Close()
+
+			tangleStore, snapshotStore, spentStore := db.Stores()
+			ConfigureStorages(tangleStore, snapshotStore, spentStore, profile.Profile1GB.Caches)
+			defer ShutdownStorages()
+
+			// the transactions are stored as value and non-value transactions of an address
+			address := testHash(1)
+			var hashes, valueHashes aingle.Hashes
+			for i := 0; i < 25; i++ {
+				hash := testHash(byte(i))
+				isValue := i%2 == 1
+				StoreAddress(address, hash, isValue).Release(true)
+				hashes = append(hashes, hash)
+				if isValue {
+					valueHashes = append(valueHashes, hash)
+				}
+
+				if i == 12 {
+					// the first transactions are written to the store, the others are only held in the cache
+					FlushAddressStorage()
+				}
+			}
+
+			// transactions of other addresses don't belong to the page
+			StoreAddress(testHash(0), testHash(3), false).Release(true)
+			StoreAddress(testHash(2), testHash(0), false).Release(true)
+
+			collectPages := func(limit int, valueOnly bool) aingle.Hashes {
+				var collected aingle.Hashes
+				var after aingle.Hash
+				for {
+					page, more := GetTransactionHashesForAddressPage(address, valueOnly, after, limit)
+					collected = append(collected, page...)
+					if !more {
+						return collected
+					}
+					after = page[len(page)-1]
+				}
+			}
+
+			assert.Equal(t, hashes, collectPages(10, false))
+			assert.Equal(t, hashes, collectPages(1, false))
+			assert.Equal(t, valueHashes, collectPages(5, true))
+
+			// the cursor doesn't have to be stored
+			page, more := GetTransactionHashesForAddressPage(address, false, byteutils.ConcatBytes(testHash(9), []byte{1}), 3)
+			assert.Equal(t, hashes[10:13], page)
+			assert.True(t, more)
+
+			// an exactly filled page has no further hashes
+			page, more = GetTransactionHashesForAddressPage(address, false, hashes[14], 10)
+			assert.Equal(t, hashes[15:], page)
+			assert.False(t, more)
+
+			page, more = GetTransactionHashesForAddressPage(address, false, hashes[24], 10)
+			assert.Empty(t, page)
+			assert.False(t, more)
+		})
+	}
+}
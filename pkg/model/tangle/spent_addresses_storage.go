@@ -94,12 +94,45 @@ func MarkAddressAsSpentWithoutLocking(address aingle.Hash) bool {
 	return newlyAdded
 }
 
+// CountSpentAddressesEntriesWithoutLocking returns the amount of spent addresses.
+// ReadLockSpentAddresses must be held while entering this function.
+func CountSpentAddressesEntriesWithoutLocking(abortSignal <-chan struct{}) (int32, error) {
+
+	var count int32
+
+	wasAborted := false
+	spentAddressesStorage.ForEachKeyOnly(func(key []byte) bool {
+		select {
+		case <-abortSignal:
+			wasAborted = true
+			return false
+		default:
+		}
+
+		count++
+		return true
+	}, false)
+
+	if wasAborted {
+		return 0, ErrOperationAborted
+	}
+
+	return count, nil
+}
+
 // StreamSpentAddressesToWriter streams all spent addresses directly to an io.Writer.
 func StreamSpentAddressesToWriter(buf io.Writer, abortSignal <-chan struct{}) (int32, error) {
 
 	ReadLockSpentAddresses()
 	defer ReadUnlockSpentAddresses()
 
+	return StreamSpentAddressesToWriterWithoutLocking(buf, abortSignal)
+}
+
+// StreamSpentAddressesToWriterWithoutLocking streams all spent addresses directly to an io.Writer.
+// ReadLockSpentAddresses must be held while entering this function.
+func StreamSpentAddressesToWriterWithoutLocking(buf io.Writer, abortSignal <-chan struct{}) (int32, error) {
+
 	var addressesWritten int32
 
 	wasAborted := false
@@ -74,6 +74,14 @@ func GetTagHashes(txTag aingle.Hash, forceRelease bool, maxFind ...int) aingle.H
 	return tagHashes
 }
 
+// GetTagHashesPage returns at most limit transaction hashes with the given tag in ascending order,
+// starting after the given transaction hash, and whether there are further transactions after the page.
+// tag +-0
+func GetTagHashesPage(txTag aingle.Hash, afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+
+	return getHashPage(tagsStorage, afterTxHash, limit, txTag)
+}
+
 // TagConsumer consumes the given tag during looping through all tags in the persistence layer.
 type TagConsumer func(txTag aingle.Hash, txHash aingle.Hash) bool
 
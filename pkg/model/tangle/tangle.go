@@ -2,57 +2,31 @@ package tangle
 
 import (
 	"errors"
-	"os"
-	"path"
-
-	"go.etcd.io/bbolt"
 
 	"github.com/iotaledger/hive.go/kvstore"
-	"github.com/iotaledger/hive.go/kvstore/bolt"
 
 	"github.com/Ariwonto/aingle-alpha/pkg/profile"
 )
 
-const (
-	TangleDbFilename         = "tangle.db"
-	SnapshotDbFilename       = "snapshot.db"
-	SpentAddressesDbFilename = "spent.db"
-)
-
 var (
-	dbDir      string
-	tangleDb   *bbolt.DB
-	snapshotDb *bbolt.DB
-	spentDb    *bbolt.DB
+	db       database
+	dbEngine DatabaseEngine
 
 	ErrNothingToCleanUp = errors.New("Nothing to clean up in the databases")
 )
 
-func boltDB(directory string, filename string) *bbolt.DB {
-	opts := &bbolt.Options{
-		NoSync: true,
-	}
-	db, err := bolt.CreateDB(directory, filename, opts)
-	if err != nil {
-		panic(err)
-	}
-	return db
-}
-
-func ConfigureDatabases(directory string) {
-
-	dbDir = directory
-	tangleDb = boltDB(directory, TangleDbFilename)
-
-	tangleStore := bolt.New(tangleDb)
-
-	snapshotDb = boltDB(directory, SnapshotDbFilename)
-	snapshotStore := bolt.New(snapshotDb)
+// ConfigureDatabases opens the databases of the given engine in the directory and configures the storages.
+func ConfigureDatabases(directory string, engine DatabaseEngine) error {
 
-	spentDb = boltDB(directory, SpentAddressesDbFilename)
-	spentStore := bolt.New(spentDb)
+	var err error
+	if db, err = openDatabase(directory, engine); err != nil {
+		return err
+	}
+	dbEngine = engine
 
+	tangleStore, snapshotStore, spentStore := db.Stores()
 	ConfigureStorages(tangleStore, snapshotStore, spentStore, profile.LoadProfile().Caches)
+	return nil
 }
 
 func ConfigureStorages(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore, caches profile.Caches) {
@@ -104,57 +78,18 @@ func LoadInitialValuesFromDatabase() {
 }
 
 func CloseDatabases() error {
-
-	if err := tangleDb.Sync(); err != nil {
-		return err
-	}
-
-	if err := tangleDb.Close(); err != nil {
-		return err
-	}
-
-	if err := snapshotDb.Sync(); err != nil {
-		return err
-	}
-
-	if err := snapshotDb.Close(); err != nil {
-		return err
-	}
-
-	if err := spentDb.Sync(); err != nil {
-		return err
-	}
-
-	if err := spentDb.Close(); err != nil {
-		return err
-	}
-	return nil
+	return db.Close()
 }
 
 func DatabaseSupportsCleanup() bool {
-	// Bolt does not support cleaning up anything
-	return false
+	return db.SupportsCleanup()
 }
 
 func CleanupDatabases() error {
-	// Bolt does not support cleaning up anything
-	return ErrNothingToCleanUp
+	return db.Cleanup()
 }
 
 // GetDatabaseSizes returns the size of the different databases.
 func GetDatabaseSizes() (tangle int64, snapshot int64, spent int64) {
-
-	if tangleDbFile, err := os.Stat(path.Join(dbDir, TangleDbFilename)); err == nil {
-		tangle = tangleDbFile.Size()
-	}
-
-	if snapshotDbFile, err := os.Stat(path.Join(dbDir, SnapshotDbFilename)); err == nil {
-		snapshot = snapshotDbFile.Size()
-	}
-
-	if spentDbFile, err := os.Stat(path.Join(dbDir, SpentAddressesDbFilename)); err == nil {
-		spent = spentDbFile.Size()
-	}
-
-	return
+	return db.Sizes()
 }
@@ -14,7 +14,7 @@ import (
 
 func (m *Manager) setupHandshakeEventHandlers(p *peer.Peer) {
 	// mark when a handshake was sent off
-	p.Protocol.Events.Sent[handshake.MessageTypeHandshake].Attach(events.NewClosure(func() {
+	p.Protocol.Events.Sent[handshake.MessageTypeHandshake].Attach(events.NewClosure(func(_ []byte) {
 		p.Protocol.Handshaked()
 	}))
 
@@ -112,8 +112,25 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 		}
 	}
 
+	// peers which authenticated with their pinned public key are known independent of their IP address
+	authenticatedOrigin, err := m.verifyPeerKey(p)
+	if err != nil {
+		m.Unlock()
+		return errors.Wrapf(err, p.ID)
+	}
+
+	if p.IsInbound() && authenticatedOrigin != "" {
+		// the reconnect pool entry of the static peer is replaced by this connection
+		if reconnectInfo, exists := m.reconnect[authenticatedOrigin]; exists {
+			p.InitAddress = reconnectInfo.OriginAddr
+			delete(m.reconnect, authenticatedOrigin)
+			m.Events.PeerRemovedFromReconnectPool.Trigger(authenticatedOrigin)
+		}
+	}
+
 	// check whether the peer is whitelisted
 	_, whitelisted := m.Whitelisted(p.ID)
+	whitelisted = whitelisted || authenticatedOrigin != ""
 	if !m.Opts.AcceptAnyPeer && !whitelisted {
 		m.Unlock()
 		m.Blacklist(p.PrimaryAddress.String())
@@ -132,7 +149,36 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 
 	m.Unlock()
 
-	p.Protocol.FeatureSet = byte(version)
+	p.Protocol.FeatureSet = handshakeMsg.SupportedFeatureSets(protocol.SupportedFeatureSets)
 	p.Protocol.Handshaked()
 	return nil
 }
+
+// verifyPeerKey checks the public key of the peer against the pinned public keys of the static peers.
+// it returns the origin address of the static peer if the peer authenticated with its pinned public key.
+func (m *Manager) verifyPeerKey(p *peer.Peer) (string, error) {
+
+	if p.IsSecure() {
+		if origin, pinned := m.pinnedOriginForPublicKey(p.PublicKey); pinned {
+			return origin, nil
+		}
+	}
+
+	var pinnedKey *PinnedKey
+	if p.IsInbound() {
+		_, pinnedKey = m.pinnedKeyForID(p.ID)
+	} else {
+		pinnedKey = m.pinnedKey(p.InitAddress.String())
+	}
+
+	switch {
+	case pinnedKey == nil:
+		return "", nil
+	case p.IsSecure():
+		return "", ErrNonMatchingPublicKey
+	case pinnedKey.DisablePlaintext:
+		return "", ErrPlaintextNotAllowed
+	default:
+		return "", nil
+	}
+}
@@ -1,6 +1,8 @@
 package peer
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"net"
 	"strconv"
 	"strings"
@@ -104,6 +106,8 @@ type Peer struct {
 	Addresses *iputils.IPAddresses
 	// The protocol instance under which this peer operates.
 	Protocol *protocol.Protocol
+	// The public key the peer authenticated the secure channel with, nil if the connection is plaintext.
+	PublicKey ed25519.PublicKey
 	// Metrics about the peer.
 	Metrics Metrics
 	// Whether the connection for this peer was handled inbound or was created outbound.
@@ -127,6 +131,42 @@ type Peer struct {
 	staledAutopeerCheckLastSentPackets uint32
 	// The last amount of dropped packets at the last autopeer stale check
 	staledAutopeerCheckLastDroppedPackets uint32
+	// The unix time in nanoseconds at which the latest heartbeat message was received.
+	latestHeartbeatTime atomic.Int64
+	// The latest score of the peer.
+	score atomic.Value
+}
+
+// UpdateLatestHeartbeat sets the given heartbeat as the peer's latest heartbeat message.
+func (p *Peer) UpdateLatestHeartbeat(heartbeat *sting.Heartbeat) {
+	p.LatestHeartbeat = heartbeat
+	p.latestHeartbeatTime.Store(time.Now().UnixNano())
+}
+
+// LatestHeartbeatTime returns the time at which the latest heartbeat message was received.
+// Returns the zero time if no heartbeat message was received yet.
+func (p *Peer) LatestHeartbeatTime() time.Time {
+	latestHeartbeatTime := p.latestHeartbeatTime.Load()
+	if latestHeartbeatTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, latestHeartbeatTime)
+}
+
+// Score returns the latest score of the peer or nil if the peer wasn't scored yet.
+func (p *Peer) Score() *Score {
+	score, _ := p.score.Load().(*Score)
+	return score
+}
+
+// SetScore sets the latest score of the peer.
+func (p *Peer) SetScore(score *Score) {
+	p.score.Store(score)
+}
+
+// IsSecure tells whether the connection to the peer is a secure channel.
+func (p *Peer) IsSecure() bool {
+	return p.PublicKey != nil
 }
 
 // IsInbound tells whether the peer's connection was inbound.
@@ -195,6 +235,7 @@ func (p *Peer) Info() *Info {
 		NumberOfNewTransactions:        p.Metrics.NewTransactions.Load(),
 		NumberOfKnownTransactions:      p.Metrics.KnownTransactions.Load(),
 		NumberOfStaleTransactions:      p.Metrics.StaleTransactions.Load(),
+		NumberOfInvalidTransactions:    p.Metrics.InvalidTransactions.Load(),
 		NumberOfReceivedTransactionReq: p.Metrics.ReceivedTransactionRequests.Load(),
 		NumberOfReceivedMilestoneReq:   p.Metrics.ReceivedMilestoneRequests.Load(),
 		NumberOfReceivedHeartbeats:     p.Metrics.ReceivedHeartbeats.Load(),
@@ -208,11 +249,16 @@ func (p *Peer) Info() *Info {
 		Connected:                      false,
 		Autopeered:                     false,
 		AutopeeringID:                  "",
+		Score:                          p.Score(),
 	}
 	if p.Autopeering != nil {
 		info.Autopeered = true
 		info.AutopeeringID = p.Autopeering.ID().String()
 	}
+	if p.IsSecure() {
+		info.ConnectionType = "tls"
+		info.PublicKey = base64.StdEncoding.EncodeToString(p.PublicKey)
+	}
 	return info
 }
 
@@ -255,6 +301,8 @@ type Metrics struct {
 	KnownTransactions atomic.Uint32
 	// The number of received transactions of which their timestamp is stale.
 	StaleTransactions atomic.Uint32
+	// The number of received transactions which are invalid.
+	InvalidTransactions atomic.Uint32
 	// The number of received transactions.
 	ReceivedTransactions atomic.Uint32
 	// The number of received transaction requests.
@@ -275,6 +323,10 @@ type Metrics struct {
 	SentHeartbeats atomic.Uint32
 	// The number of dropped packets.
 	DroppedPackets atomic.Uint32
+	// The number of transaction requests answered by the peer.
+	AnsweredTransactionRequests atomic.Uint32
+	// The summed up latency in milliseconds of the transaction requests answered by the peer.
+	AnsweredTransactionRequestsLatency atomic.Uint32
 }
 
 // Info acts as a static snapshot of information about a peer.
@@ -290,6 +342,7 @@ type Info struct {
 	NumberOfNewTransactions        uint32 `json:"numberOfNewTransactions"`
 	NumberOfKnownTransactions      uint32 `json:"numberOfKnownTransactions"`
 	NumberOfStaleTransactions      uint32 `json:"numberOfStaleTransactions"`
+	NumberOfInvalidTransactions    uint32 `json:"numberOfInvalidTransactions"`
 	NumberOfReceivedTransactionReq uint32 `json:"numberOfReceivedTransactionReq"`
 	NumberOfReceivedMilestoneReq   uint32 `json:"numberOfReceivedMilestoneReq"`
 	NumberOfReceivedHeartbeats     uint32 `json:"numberOfReceivedHeartbeats"`
@@ -303,4 +356,26 @@ type Info struct {
 	Connected                      bool   `json:"connected"`
 	Autopeered                     bool   `json:"autopeered"`
 	AutopeeringID                  string `json:"autopeeringId,omitempty"`
+	PublicKey                      string `json:"publicKey,omitempty"`
+	PlaintextDisabled              bool   `json:"plaintextDisabled,omitempty"`
+	Score                          *Score `json:"score,omitempty"`
+}
+
+// Score is the score of a peer computed from its observed behavior.
+// All partial scores range from 0 (worst) to 1 (best).
+type Score struct {
+	// The overall score of the peer from 0 (misbehaving) to 100 (well behaving).
+	Total float64 `json:"total"`
+	// The share of new transactions sent by the peer relative to its fair share.
+	Usefulness float64 `json:"usefulness"`
+	// The score of the latency of the transaction requests answered by the peer.
+	Latency float64 `json:"latency"`
+	// The average latency in milliseconds of the transaction requests answered by the peer.
+	AvgLatencyMs int64 `json:"avgLatencyMs"`
+	// The score of the rate of invalid transactions sent by the peer.
+	Validity float64 `json:"validity"`
+	// The score of the age of the peer's latest heartbeat.
+	Heartbeat float64 `json:"heartbeat"`
+	// The time at which the score was computed.
+	Time time.Time `json:"time"`
 }
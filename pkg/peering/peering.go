@@ -1,6 +1,7 @@
 package peering
 
 import (
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"net"
@@ -70,12 +71,14 @@ func NewManager(opts Options, peers ...*config.PeerConfig) *Manager {
 			Shutdown:                              events.NewEvent(events.CallbackCaller),
 			Error:                                 events.NewEvent(events.ErrorCaller),
 		},
-		tcpServer: tcp.NewServer(),
-		connected: map[string]*peer.Peer{},
-		reconnect: map[string]*reconnectinfo{},
-		whitelist: map[string]*autopeering.Peer{},
-		blacklist: map[string]struct{}{},
-		Opts:      opts,
+		tcpServer:  tcp.NewServer(),
+		connected:  map[string]*peer.Peer{},
+		reconnect:  map[string]*reconnectinfo{},
+		whitelist:  map[string]*autopeering.Peer{},
+		blacklist:  map[string]time.Time{},
+		pinnedKeys: map[string]*PinnedKey{},
+		pinnedIDs:  map[string]string{},
+		Opts:       opts,
 	}
 	m.moveInitialPeersToReconnectPool(peers)
 	return m
@@ -102,9 +105,15 @@ type Manager struct {
 	// defines the set of allowed peer identities.
 	whitelist   map[string]*autopeering.Peer
 	whitelistMu sync.Mutex
-	// defines a set of blacklisted IP addresses.
-	blacklist   map[string]struct{}
+	// defines a set of blacklisted IP addresses mapped to the time their blacklisting expires.
+	// a zero time means that the IP address is blacklisted permanently.
+	blacklist   map[string]time.Time
 	blacklistMu sync.Mutex
+	// holds the pinned public keys of static peers by their origin address.
+	pinnedKeys map[string]*PinnedKey
+	// maps the possible IDs of static peers with a pinned public key to their origin address.
+	pinnedIDs    map[string]string
+	pinnedKeysMu sync.Mutex
 	// used to enforce one handshake verification at a time.
 	handshakeVerifyMu sync.Mutex
 
@@ -130,6 +139,8 @@ type Options struct {
 	AcceptAnyPeer bool
 	// Inbound connection bind address.
 	BindAddress string
+	// The transport used to establish secure channels, nil if secure channels are disabled.
+	SecureTransport *SecureTransport
 }
 
 // Events defines events fired regarding peering.
@@ -208,15 +219,47 @@ func (m *Manager) IsStaticallyPeered(ips []string, port uint16) bool {
 // Blacklisted tells whether the given IP address is blacklisted.
 func (m *Manager) Blacklisted(ip string) bool {
 	m.blacklistMu.Lock()
-	_, blacklisted := m.blacklist[ip]
-	m.blacklistMu.Unlock()
-	return blacklisted
+	defer m.blacklistMu.Unlock()
+	return m.blacklistedWithoutLocking(ip)
+}
+
+// blacklistedWithoutLocking tells whether the given IP address is blacklisted and removes expired entries.
+func (m *Manager) blacklistedWithoutLocking(ip string) bool {
+	expiry, blacklisted := m.blacklist[ip]
+	if !blacklisted {
+		return false
+	}
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		delete(m.blacklist, ip)
+		return false
+	}
+	return true
+}
+
+// temporarilyBlacklisted tells whether any of the given IP addresses is blacklisted for a limited time.
+func (m *Manager) temporarilyBlacklisted(ips *iputils.IPAddresses) bool {
+	m.blacklistMu.Lock()
+	defer m.blacklistMu.Unlock()
+	for ip := range ips.IPs {
+		if m.blacklistedWithoutLocking(ip.String()) && !m.blacklist[ip.String()].IsZero() {
+			return true
+		}
+	}
+	return false
 }
 
 // Blacklist blacklists the given IP from connecting.
 func (m *Manager) Blacklist(ip string) {
 	m.blacklistMu.Lock()
-	m.blacklist[ip] = struct{}{}
+	m.blacklist[ip] = time.Time{}
+	m.blacklistMu.Unlock()
+}
+
+// BlacklistTemporarily blacklists the given IP from connecting for the given duration.
+// It replaces any existing blacklist entry of the IP.
+func (m *Manager) BlacklistTemporarily(ip string, duration time.Duration) {
+	m.blacklistMu.Lock()
+	m.blacklist[ip] = time.Now().Add(duration)
 	m.blacklistMu.Unlock()
 }
 
@@ -258,6 +301,88 @@ func (m *Manager) WhitelistRemove(id string) {
 	m.whitelistMu.Unlock()
 }
 
+// PinKey pins the public key of the static peer with the given address.
+// Connections to the peer are established over a secure channel which is authenticated by the pinned key.
+func (m *Manager) PinKey(addr string, pinnedKey *PinnedKey) error {
+	originAddr, err := iputils.ParseOriginAddress(addr)
+	if err != nil {
+		return fmt.Errorf("invalid peer address '%s': %w", addr, err)
+	}
+
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+
+	if pinnedKey == nil {
+		delete(m.pinnedKeys, originAddr.String())
+		return nil
+	}
+	m.pinnedKeys[originAddr.String()] = pinnedKey
+	return nil
+}
+
+// UnpinKey removes the pinned public key of the static peer with the given address.
+func (m *Manager) UnpinKey(addr string) {
+	originAddr, err := iputils.ParseOriginAddress(addr)
+	if err != nil {
+		return
+	}
+
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+
+	delete(m.pinnedKeys, originAddr.String())
+	for id, origin := range m.pinnedIDs {
+		if origin == originAddr.String() {
+			delete(m.pinnedIDs, id)
+		}
+	}
+}
+
+// pinnedKey returns the pinned public key of the peer with the given origin address or nil.
+func (m *Manager) pinnedKey(origin string) *PinnedKey {
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+	return m.pinnedKeys[origin]
+}
+
+// pinnedKeyForID returns the origin address and the pinned public key of the peer with the given ID.
+func (m *Manager) pinnedKeyForID(id string) (string, *PinnedKey) {
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+
+	origin, has := m.pinnedIDs[id]
+	if !has {
+		return "", nil
+	}
+	return origin, m.pinnedKeys[origin]
+}
+
+// pinnedOriginForPublicKey returns the origin address of the static peer with the given pinned public key.
+func (m *Manager) pinnedOriginForPublicKey(publicKey ed25519.PublicKey) (string, bool) {
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+
+	for origin, pinnedKey := range m.pinnedKeys {
+		if pinnedKey.PublicKey.Equal(publicKey) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// pinIDs maps the given IDs to the origin address of a static peer with a pinned public key.
+func (m *Manager) pinIDs(origin string, ids []string) {
+	m.pinnedKeysMu.Lock()
+	defer m.pinnedKeysMu.Unlock()
+
+	if _, has := m.pinnedKeys[origin]; !has {
+		return
+	}
+	for _, id := range ids {
+		m.pinnedIDs[id] = origin
+	}
+}
+
 // PeerConsumerFunc is a function which consumes a peer.
 // If it returns false, it signals that no further calls should be made to the function.
 type PeerConsumerFunc func(p *peer.Peer) bool
@@ -325,6 +450,12 @@ func (m *Manager) PeerInfos() []*peer.Info {
 	for _, p := range m.connected {
 		info := p.Info()
 		info.Connected = true
+		if p.InitAddress != nil {
+			if pinnedKey := m.pinnedKey(p.InitAddress.String()); pinnedKey != nil {
+				info.PublicKey = EncodePublicKey(pinnedKey.PublicKey)
+				info.PlaintextDisabled = pinnedKey.DisablePlaintext
+			}
+		}
 		infos = append(infos, info)
 	}
 	for _, reconnectInfo := range m.reconnect {
@@ -344,6 +475,10 @@ func (m *Manager) PeerInfos() []*peer.Info {
 			info.Autopeered = true
 			info.AutopeeringID = reconnectInfo.Autopeering.ID().String()
 		}
+		if pinnedKey := m.pinnedKey(originAddr.String()); pinnedKey != nil {
+			info.PublicKey = EncodePublicKey(pinnedKey.PublicKey)
+			info.PlaintextDisabled = pinnedKey.DisablePlaintext
+		}
 		infos = append(infos, info)
 	}
 	return infos
@@ -555,6 +690,9 @@ func (m *Manager) Remove(id string) error {
 	m.Lock()
 	defer m.Unlock()
 
+	// the pinned key is kept, static peers are also removed because of misbehavior.
+	// UnpinKey has to be called if the peer is removed from the configuration.
+
 	// make sure the peer is removed by all its possible IDs by going
 	// through each resolved IP address from the lookup
 	delete(m.reconnect, id)
@@ -602,6 +740,31 @@ func (m *Manager) Remove(id string) error {
 	return nil
 }
 
+// IsStatic tells whether the given peer is a static peer, which is kept in the reconnect pool if its connection is closed.
+func (m *Manager) IsStatic(p *peer.Peer) bool {
+	return p.Autopeering == nil && p.MoveBackToReconnectPool
+}
+
+// Evict closes the connection to the given peer and blacklists its IP addresses for the given duration.
+// Static peers are moved back into the reconnect pool and reconnected after the blacklisting expired,
+// all other peers are removed, which lets the autopeering replace them.
+func (m *Manager) Evict(p *peer.Peer, blacklistDuration time.Duration) error {
+	if m.IsStatic(p) {
+		p.Disconnected = true
+		if err := p.Conn.Close(); err != nil {
+			return err
+		}
+	} else if err := m.Remove(p.ID); err != nil {
+		return err
+	}
+
+	// replaces the permanent blacklisting of removed peers
+	for ip := range p.Addresses.IPs {
+		m.BlacklistTemporarily(ip.String(), blacklistDuration)
+	}
+	return nil
+}
+
 // Listen starts the peering server to listen for incoming connections.
 func (m *Manager) Listen() error {
 
@@ -633,14 +796,32 @@ func (m *Manager) Listen() error {
 
 		m.Events.PeerHandshakingIncoming.Trigger(conn.RemoteAddr().String())
 
-		// init peer
-		p := peer.NewInboundPeer(conn.Conn.RemoteAddr())
-		p.Conn = conn
-		p.Protocol = protocol.New(conn)
-		m.SetupEventHandlers(p)
+		if m.Opts.SecureTransport == nil {
+			m.startInbound(conn, nil)
+			return
+		}
 
-		// kick off protocol
-		go p.Protocol.Start()
+		// the transport can only be detected after the peer sent its first bytes,
+		// therefore the detection must not block the TCP server
+		go func() {
+			detectedConn, secure, err := detectSecureChannel(conn.Conn)
+			if err != nil {
+				_ = conn.Close()
+				m.Events.Error.Trigger(err)
+				return
+			}
+
+			var publicKey ed25519.PublicKey
+			if secure {
+				if detectedConn, publicKey, err = m.Opts.SecureTransport.Server(detectedConn); err != nil {
+					_ = conn.Close()
+					m.Events.Error.Trigger(fmt.Errorf("can't establish secure channel with %s: %w", conn.RemoteAddr(), err))
+					return
+				}
+			}
+
+			m.startInbound(network.NewManagedConnection(detectedConn), publicKey)
+		}()
 	}))
 
 	m.tcpServer.Events.Error.Attach(events.NewClosure(func(err error) {
@@ -651,6 +832,19 @@ func (m *Manager) Listen() error {
 	return nil
 }
 
+// startInbound inits the peer of the given inbound connection and kicks off the protocol.
+// publicKey is the public key the peer authenticated the secure channel with, nil if the connection is plaintext.
+func (m *Manager) startInbound(conn *network.ManagedConnection, publicKey ed25519.PublicKey) {
+	p := peer.NewInboundPeer(conn.Conn.RemoteAddr())
+	p.Conn = conn
+	p.PublicKey = publicKey
+	p.Protocol = protocol.New(conn)
+	m.SetupEventHandlers(p)
+
+	// kick off protocol
+	go p.Protocol.Start()
+}
+
 // Shutdown shuts down the peering server and disconnect all connected peers.
 func (m *Manager) Shutdown() {
 	m.Lock()
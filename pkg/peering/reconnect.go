@@ -55,9 +55,21 @@ next:
 			ips = append(ips, ip.String())
 		}
 
+		// peers which were evicted stay in the reconnect pool until their blacklisting expired
+		if m.temporarilyBlacklisted(peerAddrs) {
+			continue
+		}
+
 		// whitelist all possible combinations for this peer ID
 		m.Whitelist(ips, reconnectInfo.OriginAddr.Port)
 
+		// remember the possible IDs of peers with a pinned public key to verify their inbound connections
+		ids := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			ids = append(ids, peer.NewID(ip, originAddr.Port))
+		}
+		m.pinIDs(originAddr.String(), ids)
+
 		// create a new outbound peer and inject autopeering metadata if available
 		p := peer.NewOutboundPeer(originAddr, prefIP, originAddr.Port, peerAddrs)
 		if reconnectInfo.Autopeering != nil {
@@ -107,12 +119,38 @@ func (m *Manager) moveInitialPeersToReconnectPool(peers []*config.PeerConfig) {
 		originAddr.PreferIPv6 = peerConf.PreferIPv6
 		originAddr.Alias = peerConf.Alias
 
+		pinnedKey, err := PinnedKeyFromConfig(peerConf)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid peer config %s", peerConf.ID))
+		}
+		if pinnedKey != nil {
+			m.pinnedKeys[originAddr.String()] = pinnedKey
+		}
+
 		// no need to lock the manager in the configure stage
 		m.moveToReconnectPool(&reconnectinfo{OriginAddr: originAddr})
 	}
 }
 
+// PinnedKeyFromConfig returns the pinned public key of the given peer config or nil if no key is pinned.
+func PinnedKeyFromConfig(peerConf *config.PeerConfig) (*PinnedKey, error) {
+	if peerConf.PublicKey == "" {
+		if peerConf.DisablePlaintext {
+			return nil, errors.New("plaintext can only be disabled for peers with a public key")
+		}
+		return nil, nil
+	}
+
+	publicKey, err := ParsePublicKey(peerConf.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PinnedKey{PublicKey: publicKey, DisablePlaintext: peerConf.DisablePlaintext}, nil
+}
+
 // creates and initiates the connection to the given peer.
+// if the public key of the peer is pinned, a secure channel is established before the handshake.
 func (m *Manager) connect(p *peer.Peer) error {
 	addr := fmt.Sprintf("%s:%d", iputils.IPToString(p.PrimaryAddress), p.InitAddress.Port)
 	conn, err := net.DialTimeout("tcp", addr, time.Duration(2)*time.Second)
@@ -120,6 +158,36 @@ func (m *Manager) connect(p *peer.Peer) error {
 		return fmt.Errorf("can't connect to %s: %w", p.ID, err)
 	}
 
+	if pinnedKey := m.pinnedKey(p.InitAddress.String()); pinnedKey != nil {
+		if m.Opts.SecureTransport == nil {
+			if pinnedKey.DisablePlaintext {
+				_ = conn.Close()
+				return fmt.Errorf("can't connect to %s: %w", p.ID, ErrSecureChannelDisabled)
+			}
+		} else {
+			secureConn, err := m.Opts.SecureTransport.Client(conn, pinnedKey.PublicKey)
+			switch {
+			case err == nil:
+				conn = secureConn
+				p.PublicKey = pinnedKey.PublicKey
+
+			case pinnedKey.DisablePlaintext, !peerSpeaksNoTLS(err):
+				// never fall back to plaintext if the peer speaks TLS but fails to authenticate with the pinned key
+				_ = conn.Close()
+				return fmt.Errorf("can't establish secure channel to %s: %w", p.ID, err)
+
+			default:
+				// the failed TLS handshake leaves the connection in an undefined state
+				_ = conn.Close()
+				m.Events.Error.Trigger(fmt.Errorf("%s doesn't support secure channels, falling back to plaintext: %w", p.ID, err))
+
+				if conn, err = net.DialTimeout("tcp", addr, time.Duration(2)*time.Second); err != nil {
+					return fmt.Errorf("can't connect to %s: %w", p.ID, err)
+				}
+			}
+		}
+	}
+
 	p.Conn = network.NewManagedConnection(conn)
 	p.Protocol = protocol.New(p.Conn)
 	return nil
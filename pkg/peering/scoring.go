@@ -0,0 +1,257 @@
+package peering
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/peering/peer"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/utils"
+)
+
+const (
+	// the weights of the partial scores within the total score.
+	usefulnessScoreWeight = 0.3
+	latencyScoreWeight    = 0.2
+	validityScoreWeight   = 0.3
+	heartbeatScoreWeight  = 0.2
+
+	// the weight of a new observation against the previous partial score.
+	scoreSmoothingFactor = 0.5
+
+	// the factor the rate of invalid transactions is multiplied with,
+	// i.e. a rate of 10% invalid transactions results in a validity score of 0.
+	invalidRatePenalty = 10
+
+	// the amount of scoring rounds in which a newly connected peer is not evicted.
+	scoringGraceRounds = 3
+)
+
+// ScoringOptions defines options for the peer scoring.
+type ScoringOptions struct {
+	// The total score below which a peer is evicted, 0 disables the eviction.
+	MinScore float64
+	// The duration for which evicted peers are blacklisted.
+	BlacklistDuration time.Duration
+	// Whether static peers are evicted as well.
+	EvictStaticPeers bool
+	// The latency of answered requests up to which the latency gets the best score.
+	TargetLatency time.Duration
+	// The age of the latest heartbeat up to which the heartbeat gets the best score.
+	MaxHeartbeatAge time.Duration
+}
+
+// Eviction describes a peer which was evicted because of its score.
+type Eviction struct {
+	// The evicted peer.
+	Peer *peer.Peer
+	// The score of the peer at the time of the eviction.
+	Score *peer.Score
+	// Whether the peer is a static peer which is reconnected after the blacklisting expired.
+	Static bool
+}
+
+// Scorer scores the connected peers of a Manager given their observed behavior
+// and evicts peers which misbehave.
+type Scorer struct {
+	manager *Manager
+	opts    ScoringOptions
+
+	mu     sync.Mutex
+	states map[*peer.Peer]*scoringState
+}
+
+// scoringState holds the scoring state of a connected peer.
+type scoringState struct {
+	// the time at which the peer was scored for the first time.
+	firstScored time.Time
+	// the amount of scoring rounds the peer was part of.
+	rounds int
+	// the metrics of the peer at the last scoring round.
+	metrics scoringMetrics
+	// the latest score of the peer.
+	score *peer.Score
+}
+
+// scoringMetrics holds the metrics of a peer which are relevant for its score.
+type scoringMetrics struct {
+	receivedTransactions    uint32
+	newTransactions         uint32
+	invalidTransactions     uint32
+	answeredRequests        uint32
+	answeredRequestsLatency uint32
+}
+
+// newScoringMetrics returns the current metrics of the given peer.
+func newScoringMetrics(p *peer.Peer) scoringMetrics {
+	return scoringMetrics{
+		receivedTransactions:    p.Metrics.ReceivedTransactions.Load(),
+		newTransactions:         p.Metrics.NewTransactions.Load(),
+		invalidTransactions:     p.Metrics.InvalidTransactions.Load(),
+		answeredRequests:        p.Metrics.AnsweredTransactionRequests.Load(),
+		answeredRequestsLatency: p.Metrics.AnsweredTransactionRequestsLatency.Load(),
+	}
+}
+
+// since returns the difference of the metrics to the given previous metrics.
+func (m scoringMetrics) since(previous scoringMetrics) scoringMetrics {
+	return scoringMetrics{
+		receivedTransactions:    utils.GetUint32Diff(m.receivedTransactions, previous.receivedTransactions),
+		newTransactions:         utils.GetUint32Diff(m.newTransactions, previous.newTransactions),
+		invalidTransactions:     utils.GetUint32Diff(m.invalidTransactions, previous.invalidTransactions),
+		answeredRequests:        utils.GetUint32Diff(m.answeredRequests, previous.answeredRequests),
+		answeredRequestsLatency: utils.GetUint32Diff(m.answeredRequestsLatency, previous.answeredRequestsLatency),
+	}
+}
+
+// NewScorer creates a new Scorer for the connected peers of the given Manager.
+func NewScorer(manager *Manager, opts ScoringOptions) *Scorer {
+	return &Scorer{
+		manager: manager,
+		opts:    opts,
+		states:  make(map[*peer.Peer]*scoringState),
+	}
+}
+
+// Update scores all connected peers given their behavior since the last update.
+// Peers which scored below the min score are evicted and returned.
+// Updates are meant to be done in a fixed interval, as the metrics are compared between updates.
+func (s *Scorer) Update() []*Eviction {
+	s.mu.Lock()
+
+	connectedPeers := make([]*peer.Peer, 0)
+	s.manager.ForAllConnected(func(p *peer.Peer) bool {
+		if p.Protocol.Supports(sting.FeatureSet) {
+			connectedPeers = append(connectedPeers, p)
+		}
+		return true
+	})
+
+	now := time.Now()
+	states := make(map[*peer.Peer]*scoringState, len(connectedPeers))
+	var evictions []*Eviction
+
+	for _, p := range connectedPeers {
+		metrics := newScoringMetrics(p)
+
+		state, exists := s.states[p]
+		if !exists {
+			// the first round only serves as the base line of the metrics
+			state = &scoringState{firstScored: now, metrics: metrics}
+		}
+
+		heartbeatTime := p.LatestHeartbeatTime()
+		if heartbeatTime.IsZero() {
+			heartbeatTime = state.firstScored
+		}
+
+		state.score = computeScore(state.score, metrics.since(state.metrics), now.Sub(heartbeatTime), len(connectedPeers), s.opts)
+		state.score.Time = now
+		state.metrics = metrics
+		state.rounds++
+		states[p] = state
+
+		p.SetScore(state.score)
+
+		if s.misbehaves(p, state) {
+			evictions = append(evictions, &Eviction{Peer: p, Score: state.score, Static: s.manager.IsStatic(p)})
+		}
+	}
+
+	// drops the states of disconnected peers
+	s.states = states
+	s.mu.Unlock()
+
+	evicted := make([]*Eviction, 0, len(evictions))
+	for _, eviction := range evictions {
+		if err := s.manager.Evict(eviction.Peer, s.opts.BlacklistDuration); err != nil {
+			s.manager.Events.Error.Trigger(fmt.Errorf("can't evict peer %s: %w", eviction.Peer.ID, err))
+			continue
+		}
+		evicted = append(evicted, eviction)
+	}
+
+	return evicted
+}
+
+// misbehaves tells whether the given peer scored below the min score and is subject to eviction.
+func (s *Scorer) misbehaves(p *peer.Peer, state *scoringState) bool {
+	if s.opts.MinScore <= 0 {
+		// eviction disabled
+		return false
+	}
+
+	if state.rounds <= scoringGraceRounds {
+		// give newly connected peers the chance to prove themselves
+		return false
+	}
+
+	if s.manager.IsStatic(p) && !s.opts.EvictStaticPeers {
+		return false
+	}
+
+	return state.score.Total < s.opts.MinScore
+}
+
+// computeScore computes the score of a peer given its previous score and its behavior since then.
+// connectedPeers is the amount of peers which are currently connected.
+func computeScore(previous *peer.Score, delta scoringMetrics, heartbeatAge time.Duration, connectedPeers int, opts ScoringOptions) *peer.Score {
+	score := &peer.Score{Usefulness: 1, Latency: 1, Validity: 1}
+	if previous != nil {
+		score.Usefulness = previous.Usefulness
+		score.Latency = previous.Latency
+		score.AvgLatencyMs = previous.AvgLatencyMs
+		score.Validity = previous.Validity
+	}
+
+	// partial scores without new observations keep their previous value
+	if delta.receivedTransactions > 0 {
+		// a new transaction is only received first from one of the connected peers,
+		// therefore the share of new transactions is compared to the fair share of the peer.
+		newShare := float64(delta.newTransactions) / float64(delta.receivedTransactions)
+		score.Usefulness = smoothScore(score.Usefulness, math.Min(1, newShare*float64(connectedPeers)))
+
+		invalidRate := float64(delta.invalidTransactions) / float64(delta.receivedTransactions)
+		score.Validity = smoothScore(score.Validity, math.Max(0, 1-invalidRate*invalidRatePenalty))
+	}
+
+	if delta.answeredRequests > 0 {
+		avgLatency := time.Duration(delta.answeredRequestsLatency/delta.answeredRequests) * time.Millisecond
+		score.AvgLatencyMs = avgLatency.Milliseconds()
+		score.Latency = smoothScore(score.Latency, latencyScore(avgLatency, opts.TargetLatency))
+	}
+
+	score.Heartbeat = heartbeatScore(heartbeatAge, opts.MaxHeartbeatAge)
+
+	score.Total = 100 * (usefulnessScoreWeight*score.Usefulness +
+		latencyScoreWeight*score.Latency +
+		validityScoreWeight*score.Validity +
+		heartbeatScoreWeight*score.Heartbeat)
+
+	return score
+}
+
+// smoothScore weights the given observed partial score against the previous partial score.
+func smoothScore(previous float64, observed float64) float64 {
+	return previous*(1-scoreSmoothingFactor) + observed*scoreSmoothingFactor
+}
+
+// latencyScore scores the given average latency of answered requests.
+// The score declines inversely proportional once the latency exceeds the target latency.
+func latencyScore(avgLatency time.Duration, targetLatency time.Duration) float64 {
+	if targetLatency <= 0 || avgLatency <= targetLatency {
+		return 1
+	}
+	return float64(targetLatency) / float64(avgLatency)
+}
+
+// heartbeatScore scores the given age of the latest heartbeat.
+// The score declines linearly once the age exceeds the max age and reaches 0 at twice the max age.
+func heartbeatScore(age time.Duration, maxAge time.Duration) float64 {
+	if maxAge <= 0 || age <= maxAge {
+		return 1
+	}
+	return math.Max(0, 1-float64(age-maxAge)/float64(maxAge))
+}
@@ -0,0 +1,83 @@
+package peering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testScoringOptions() ScoringOptions {
+	return ScoringOptions{
+		MinScore:          50,
+		BlacklistDuration: time.Minute,
+		TargetLatency:     time.Second,
+		MaxHeartbeatAge:   time.Minute,
+	}
+}
+
+func TestComputeScore(t *testing.T) {
+	opts := testScoringOptions()
+
+	// a peer without any observations gets the best score
+	score := computeScore(nil, scoringMetrics{}, 0, 4, opts)
+	assert.InDelta(t, 100, score.Total, 0.001)
+
+	// a peer which sends its fair share of new transactions and answers requests in time stays at the best score
+	score = computeScore(score, scoringMetrics{receivedTransactions: 100, newTransactions: 25, answeredRequests: 10, answeredRequestsLatency: 5000}, 0, 4, opts)
+	assert.Equal(t, float64(1), score.Usefulness)
+	assert.Equal(t, float64(1), score.Latency)
+	assert.Equal(t, int64(500), score.AvgLatencyMs)
+	assert.InDelta(t, 100, score.Total, 0.001)
+
+	// a peer which only sends invalid and known transactions and answers late loses its score
+	for i := 0; i < 5; i++ {
+		score = computeScore(score, scoringMetrics{receivedTransactions: 100, invalidTransactions: 50, answeredRequests: 10, answeredRequestsLatency: 40000}, 3*opts.MaxHeartbeatAge, 4, opts)
+	}
+	assert.InDelta(t, 0, score.Usefulness, 0.05)
+	assert.InDelta(t, 0.25, score.Latency, 0.05)
+	assert.Equal(t, int64(4000), score.AvgLatencyMs)
+	assert.InDelta(t, 0, score.Validity, 0.05)
+	assert.Equal(t, float64(0), score.Heartbeat)
+	assert.Less(t, score.Total, opts.MinScore)
+
+	// partial scores without new observations are kept
+	previous := score
+	score = computeScore(previous, scoringMetrics{}, 0, 4, opts)
+	assert.Equal(t, previous.Usefulness, score.Usefulness)
+	assert.Equal(t, previous.Latency, score.Latency)
+	assert.Equal(t, previous.Validity, score.Validity)
+	assert.Equal(t, float64(1), score.Heartbeat)
+}
+
+func TestHeartbeatScore(t *testing.T) {
+	assert.Equal(t, float64(1), heartbeatScore(30*time.Second, time.Minute))
+	assert.Equal(t, float64(1), heartbeatScore(time.Minute, time.Minute))
+	assert.InDelta(t, 0.5, heartbeatScore(90*time.Second, time.Minute), 0.001)
+	assert.Equal(t, float64(0), heartbeatScore(3*time.Minute, time.Minute))
+	assert.Equal(t, float64(1), heartbeatScore(time.Hour, 0))
+}
+
+func TestLatencyScore(t *testing.T) {
+	assert.Equal(t, float64(1), latencyScore(500*time.Millisecond, time.Second))
+	assert.InDelta(t, 0.5, latencyScore(2*time.Second, time.Second), 0.001)
+	assert.Equal(t, float64(1), latencyScore(time.Hour, 0))
+}
+
+func TestManager_BlacklistTemporarily(t *testing.T) {
+	m := NewManager(Options{})
+
+	m.BlacklistTemporarily("127.0.0.1", time.Hour)
+	assert.True(t, m.Blacklisted("127.0.0.1"))
+
+	// expired entries are removed
+	m.BlacklistTemporarily("127.0.0.2", -time.Second)
+	assert.False(t, m.Blacklisted("127.0.0.2"))
+
+	// a permanent blacklisting replaces the temporary one
+	m.Blacklist("127.0.0.1")
+	assert.True(t, m.Blacklisted("127.0.0.1"))
+
+	m.BlacklistRemove("127.0.0.1")
+	assert.False(t, m.Blacklisted("127.0.0.1"))
+}
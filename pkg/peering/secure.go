@@ -0,0 +1,238 @@
+package peering
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"syscall"
+	"time"
+)
+
+const (
+	// secureChannelHandshakeTimeout is the time in which the secure channel must be established.
+	secureChannelHandshakeTimeout = 5 * time.Second
+
+	// tlsRecordTypeHandshake is the first byte of a TLS ClientHello.
+	// it does not collide with the first byte of the plaintext protocol, which is the handshake message type.
+	tlsRecordTypeHandshake = 0x16
+)
+
+var (
+	// ErrNonMatchingPublicKey is returned when the public key of a peer doesn't match its pinned public key.
+	ErrNonMatchingPublicKey = errors.New("public key of the peer doesn't match the pinned public key")
+	// ErrPlaintextNotAllowed is returned when a peer which must use a secure channel connects in plaintext.
+	ErrPlaintextNotAllowed = errors.New("plaintext connections are disabled for this peer")
+	// ErrSecureChannelDisabled is returned when a peer requires a secure channel but the secure channel is disabled.
+	ErrSecureChannelDisabled = errors.New("secure channel is disabled")
+	// ErrInvalidPeerCertificate is returned when the certificate of a peer does not contain an ed25519 public key.
+	ErrInvalidPeerCertificate = errors.New("invalid peer certificate")
+)
+
+// PinnedKey is the public key a static peer has to authenticate the secure channel with.
+type PinnedKey struct {
+	// The ed25519 public key of the peer.
+	PublicKey ed25519.PublicKey
+	// Whether the connection to the peer fails if no secure channel can be established.
+	DisablePlaintext bool
+}
+
+// ParsePublicKey parses a base64 encoded ed25519 public key.
+func ParsePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key '%s': %w", publicKey, err)
+	}
+	if len(keyBytes) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key '%s': wrong length %d", publicKey, len(keyBytes))
+	}
+	return ed25519.PublicKey(keyBytes), nil
+}
+
+// EncodePublicKey returns the base64 encoding of the given ed25519 public key.
+func EncodePublicKey(publicKey ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(publicKey)
+}
+
+// SecureTransport establishes TLS channels to peers, which are authenticated by their ed25519 public keys
+// instead of a certificate authority.
+type SecureTransport struct {
+	privateKey  ed25519.PrivateKey
+	certificate tls.Certificate
+}
+
+// NewSecureTransport creates a new SecureTransport which authenticates with the given private key.
+func NewSecureTransport(privateKey ed25519.PrivateKey) (*SecureTransport, error) {
+
+	// the certificate is only a container for the public key, its validity is never checked by the peers
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: EncodePublicKey(privateKey.Public().(ed25519.PublicKey))},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the certificate: %w", err)
+	}
+
+	return &SecureTransport{
+		privateKey: privateKey,
+		certificate: tls.Certificate{
+			Certificate: [][]byte{certDER},
+			PrivateKey:  privateKey,
+		},
+	}, nil
+}
+
+// PublicKey returns the public key the transport authenticates with.
+func (t *SecureTransport) PublicKey() ed25519.PublicKey {
+	return t.privateKey.Public().(ed25519.PublicKey)
+}
+
+func (t *SecureTransport) tlsConfig(verifyPublicKey func(publicKey ed25519.PublicKey) error) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{t.certificate},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// the certificates are self-signed, the peers are authenticated by their public key in VerifyPeerCertificate
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			publicKey, err := publicKeyFromCertificates(rawCerts...)
+			if err != nil {
+				return err
+			}
+			return verifyPublicKey(publicKey)
+		},
+	}
+}
+
+// Client establishes a secure channel on the given outbound connection.
+// The handshake fails if the peer doesn't authenticate with the pinned public key.
+func (t *SecureTransport) Client(conn net.Conn, pinnedKey ed25519.PublicKey) (net.Conn, error) {
+
+	tlsConn := tls.Client(conn, t.tlsConfig(func(publicKey ed25519.PublicKey) error {
+		if !publicKey.Equal(pinnedKey) {
+			return ErrNonMatchingPublicKey
+		}
+		return nil
+	}))
+
+	if err := handshakeWithTimeout(tlsConn); err != nil {
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// Server establishes a secure channel on the given inbound connection and returns the public key of the peer.
+// Every public key is accepted, the caller has to check whether the peer is allowed to connect.
+func (t *SecureTransport) Server(conn net.Conn) (net.Conn, ed25519.PublicKey, error) {
+
+	tlsConn := tls.Server(conn, t.tlsConfig(func(_ ed25519.PublicKey) error {
+		return nil
+	}))
+
+	if err := handshakeWithTimeout(tlsConn); err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := publicKeyFromCertificates(tlsConn.ConnectionState().PeerCertificates[0].Raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConn, publicKey, nil
+}
+
+// peerSpeaksNoTLS tells whether the secure channel handshake failed because the peer doesn't support secure channels.
+// Peers which only speak the plaintext protocol close the connection on the unknown message type of the ClientHello.
+// Any other failure, especially a peer which authenticates with a different public key, is not a reason to fall back to plaintext.
+func peerSpeaksNoTLS(err error) bool {
+	var recordHeaderErr tls.RecordHeaderError
+	switch {
+	case errors.As(err, &recordHeaderErr):
+		// the peer answered with something else than a TLS record
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNRESET):
+		return true
+	default:
+		return false
+	}
+}
+
+// handshakeWithTimeout runs the TLS handshake and resets the deadline of the connection afterwards.
+func handshakeWithTimeout(tlsConn *tls.Conn) error {
+
+	if err := tlsConn.SetDeadline(time.Now().Add(secureChannelHandshakeTimeout)); err != nil {
+		return err
+	}
+
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("secure channel handshake failed: %w", err)
+	}
+
+	return tlsConn.SetDeadline(time.Time{})
+}
+
+// publicKeyFromCertificates returns the ed25519 public key of the first certificate.
+func publicKeyFromCertificates(rawCerts ...[]byte) (ed25519.PublicKey, error) {
+	if len(rawCerts) == 0 {
+		return nil, ErrInvalidPeerCertificate
+	}
+
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeerCertificate, err)
+	}
+
+	publicKey, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrInvalidPeerCertificate
+	}
+
+	return publicKey, nil
+}
+
+// peekedConn is a connection of which the first bytes were already read to detect the used transport.
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// detectSecureChannel peeks the first byte of an inbound connection and returns whether the peer
+// initiates a secure channel. The returned connection has to be used instead of the given one.
+func detectSecureChannel(conn net.Conn) (net.Conn, bool, error) {
+
+	if err := conn.SetReadDeadline(time.Now().Add(secureChannelHandshakeTimeout)); err != nil {
+		return nil, false, err
+	}
+
+	reader := bufio.NewReader(conn)
+	firstByte, err := reader.Peek(1)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to detect the transport of %s: %w", conn.RemoteAddr(), err)
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, false, err
+	}
+
+	return &peekedConn{Conn: conn, reader: reader}, bytes.Equal(firstByte, []byte{tlsRecordTypeHandshake}), nil
+}
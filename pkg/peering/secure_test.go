@@ -0,0 +1,238 @@
+package peering
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/hive.go/iputils"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/peering/peer"
+)
+
+func newTestSecureTransport(t *testing.T) *SecureTransport {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	transport, err := NewSecureTransport(privateKey)
+	require.NoError(t, err)
+	return transport
+}
+
+type serverResult struct {
+	conn      net.Conn
+	publicKey ed25519.PublicKey
+	err       error
+}
+
+// runSecureChannel establishes a secure channel between the two transports over an in-memory connection.
+func runSecureChannel(t *testing.T, client *SecureTransport, server *SecureTransport, pinnedKey ed25519.PublicKey) (net.Conn, serverResult, error) {
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		_ = serverConn.Close()
+	})
+
+	serverDone := make(chan serverResult, 1)
+	go func() {
+		detectedConn, secure, err := detectSecureChannel(serverConn)
+		if err != nil || !secure {
+			_ = serverConn.Close()
+			serverDone <- serverResult{err: err}
+			return
+		}
+
+		conn, publicKey, err := server.Server(detectedConn)
+		if err != nil {
+			_ = serverConn.Close()
+		}
+		serverDone <- serverResult{conn: conn, publicKey: publicKey, err: err}
+	}()
+
+	conn, err := client.Client(clientConn, pinnedKey)
+	if err != nil {
+		_ = clientConn.Close()
+	}
+	return conn, <-serverDone, err
+}
+
+func TestSecureChannel(t *testing.T) {
+	client := newTestSecureTransport(t)
+	server := newTestSecureTransport(t)
+
+	clientConn, result, err := runSecureChannel(t, client, server, server.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, result.err)
+	assert.True(t, client.PublicKey().Equal(result.publicKey))
+
+	go func() {
+		_, _ = clientConn.Write([]byte("handshake"))
+		_ = clientConn.Close()
+	}()
+
+	data, err := ioutil.ReadAll(result.conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("handshake"), data)
+}
+
+func TestSecureChannelNonMatchingPublicKey(t *testing.T) {
+	client := newTestSecureTransport(t)
+	server := newTestSecureTransport(t)
+	other := newTestSecureTransport(t)
+
+	_, result, err := runSecureChannel(t, client, server, other.PublicKey())
+	assert.Error(t, err)
+	assert.Error(t, result.err)
+}
+
+func TestDetectPlaintext(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	// the plaintext protocol starts with the handshake message type
+	go func() {
+		_, _ = clientConn.Write([]byte{1, 0, 2})
+		_ = clientConn.Close()
+	}()
+
+	detectedConn, secure, err := detectSecureChannel(serverConn)
+	require.NoError(t, err)
+	assert.False(t, secure)
+
+	// the peeked byte is still readable
+	data, err := ioutil.ReadAll(detectedConn)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 2}, data)
+}
+
+func TestPinnedKeyFromConfig(t *testing.T) {
+	transport := newTestSecureTransport(t)
+
+	pinnedKey, err := PinnedKeyFromConfig(&config.PeerConfig{ID: "example.com:15600"})
+	assert.NoError(t, err)
+	assert.Nil(t, pinnedKey)
+
+	_, err = PinnedKeyFromConfig(&config.PeerConfig{ID: "example.com:15600", DisablePlaintext: true})
+	assert.Error(t, err)
+
+	_, err = PinnedKeyFromConfig(&config.PeerConfig{ID: "example.com:15600", PublicKey: "invalid"})
+	assert.Error(t, err)
+
+	pinnedKey, err = PinnedKeyFromConfig(&config.PeerConfig{ID: "example.com:15600", PublicKey: EncodePublicKey(transport.PublicKey()), DisablePlaintext: true})
+	assert.NoError(t, err)
+	assert.True(t, transport.PublicKey().Equal(pinnedKey.PublicKey))
+	assert.True(t, pinnedKey.DisablePlaintext)
+}
+
+func TestRemoveKeepsPinnedKey(t *testing.T) {
+	transport := newTestSecureTransport(t)
+
+	m := NewManager(Options{})
+	require.NoError(t, m.PinKey("127.0.0.1:15600", &PinnedKey{PublicKey: transport.PublicKey()}))
+
+	// a static peer which is dropped for misbehavior keeps its pinned key
+	require.NoError(t, m.Remove("127.0.0.1:15600"))
+	require.NotNil(t, m.pinnedKey("127.0.0.1:15600"))
+	assert.True(t, transport.PublicKey().Equal(m.pinnedKey("127.0.0.1:15600").PublicKey))
+
+	// the key is unpinned once the peer is removed from the configuration
+	m.UnpinKey("127.0.0.1:15600")
+	assert.Nil(t, m.pinnedKey("127.0.0.1:15600"))
+}
+
+// startTestPeer starts a TCP listener which handles every inbound connection with the given handler.
+// It returns the peer to connect to and the counter of accepted connections.
+func startTestPeer(t *testing.T, handle func(conn net.Conn)) (*peer.Peer, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	accepted := atomic.NewInt32(0)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Inc()
+			go handle(conn)
+		}
+	}()
+
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	originAddr, err := iputils.ParseOriginAddress(fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+
+	return peer.NewOutboundPeer(originAddr, net.ParseIP("127.0.0.1"), port, nil), accepted
+}
+
+func TestConnectNonMatchingPublicKey(t *testing.T) {
+	client := newTestSecureTransport(t)
+	server := newTestSecureTransport(t)
+	other := newTestSecureTransport(t)
+
+	p, accepted := startTestPeer(t, func(conn net.Conn) {
+		defer conn.Close()
+		if _, _, err := server.Server(conn); err == nil {
+			_, _ = io.Copy(ioutil.Discard, conn)
+		}
+	})
+
+	m := NewManager(Options{SecureTransport: client})
+	require.NoError(t, m.PinKey(p.InitAddress.String(), &PinnedKey{PublicKey: other.PublicKey()}))
+
+	// the peer speaks TLS but authenticates with a different key, the connection must not be downgraded
+	err := m.connect(p)
+	assert.True(t, errors.Is(err, ErrNonMatchingPublicKey))
+	assert.Nil(t, p.Conn)
+	assert.Equal(t, int32(1), accepted.Load())
+}
+
+func TestConnectPlaintextFallback(t *testing.T) {
+	client := newTestSecureTransport(t)
+	server := newTestSecureTransport(t)
+
+	// the peer only speaks the plaintext protocol and closes connections which start with a ClientHello
+	p, accepted := startTestPeer(t, func(conn net.Conn) {
+		detectedConn, secure, err := detectSecureChannel(conn)
+		if err != nil || secure {
+			_ = conn.Close()
+			return
+		}
+		defer detectedConn.Close()
+		_, _ = io.Copy(ioutil.Discard, detectedConn)
+	})
+
+	m := NewManager(Options{SecureTransport: client})
+	require.NoError(t, m.PinKey(p.InitAddress.String(), &PinnedKey{PublicKey: server.PublicKey()}))
+
+	require.NoError(t, m.connect(p))
+	assert.NotNil(t, p.Conn)
+	assert.Nil(t, p.PublicKey)
+	assert.Eventually(t, func() bool { return accepted.Load() == 2 }, time.Second, 10*time.Millisecond)
+	_ = p.Conn.Close()
+
+	// plaintext is not allowed for the peer
+	p.Conn = nil
+	require.NoError(t, m.PinKey(p.InitAddress.String(), &PinnedKey{PublicKey: server.PublicKey(), DisablePlaintext: true}))
+	assert.Error(t, m.connect(p))
+	assert.Nil(t, p.Conn)
+}
+
+func TestPeerSpeaksNoTLS(t *testing.T) {
+	assert.True(t, peerSpeaksNoTLS(fmt.Errorf("secure channel handshake failed: %w", io.EOF)))
+	assert.False(t, peerSpeaksNoTLS(fmt.Errorf("secure channel handshake failed: %w", ErrNonMatchingPublicKey)))
+	assert.False(t, peerSpeaksNoTLS(fmt.Errorf("secure channel handshake failed: %w", ErrInvalidPeerCertificate)))
+}
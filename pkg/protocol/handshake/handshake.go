@@ -58,12 +58,18 @@ type Handshake struct {
 	SupportedVersions     []byte
 }
 
-// SupportedVersion returns the highest supported protocol version.
-func (hs Handshake) SupportedVersion(ownSupportedMessagesBitset *bitset.BitSet) (version int, err error) {
+// supportedVersionsBitset returns the protocol versions the peer supports.
+func (hs Handshake) supportedVersionsBitset() *bitset.BitSet {
 	hsSupportedMessagesBitset := bitset.New(uint(len(hs.SupportedVersions) * 8))
-	hsSupportedMessagesBitset.UnmarshalBinary(hs.SupportedVersions)
+	_ = hsSupportedMessagesBitset.UnmarshalBinary(hs.SupportedVersions)
+	return hsSupportedMessagesBitset
+}
 
-	bothSupportedMessagesBitset := hsSupportedMessagesBitset.Union(ownSupportedMessagesBitset)
+// SupportedVersion returns the highest protocol version supported by both nodes.
+func (hs Handshake) SupportedVersion(ownSupportedMessagesBitset *bitset.BitSet) (version int, err error) {
+	hsSupportedMessagesBitset := hs.supportedVersionsBitset()
+
+	bothSupportedMessagesBitset := hsSupportedMessagesBitset.Intersection(ownSupportedMessagesBitset)
 
 	if !bothSupportedMessagesBitset.Any() {
 		// we don't support any protocol version the peer supports
@@ -85,6 +91,20 @@ func (hs Handshake) SupportedVersion(ownSupportedMessagesBitset *bitset.BitSet)
 	return 0, ErrVersionNotSupported
 }
 
+// SupportedFeatureSets returns the feature sets supported by both nodes as a bitmask.
+// Only the first 8 protocol versions are taken into account.
+func (hs Handshake) SupportedFeatureSets(ownSupportedMessagesBitset *bitset.BitSet) byte {
+	bothSupportedMessagesBitset := hs.supportedVersionsBitset().Intersection(ownSupportedMessagesBitset)
+
+	var featureSets byte
+	for i := uint(0); i < 8; i++ {
+		if bothSupportedMessagesBitset.Test(i) {
+			featureSets |= 1 << i
+		}
+	}
+	return featureSets
+}
+
 // NewHandshakeMessage creates a new handshake message.
 func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePort uint16, ownByteEncodedCooAddress []byte, ownUsedMWM byte) ([]byte, error) {
 
@@ -131,7 +151,6 @@ func ParseHandshake(msg []byte) (*Handshake, error) {
 	var sentTimestamp uint64
 	byteEncodedCooAddress := make([]byte, ByteEncodedCooAddressBytesLength)
 	var mwm byte
-	supportedVersions := make([]byte, 8)
 
 	r := bytes.NewReader(msg)
 
@@ -151,6 +170,8 @@ func ParseHandshake(msg []byte) (*Handshake, error) {
 		return nil, err
 	}
 
+	// the supported versions are the binary encoded bitset, which takes up the remaining bytes
+	supportedVersions := make([]byte, r.Len())
 	if _, err := r.Read(supportedVersions); err != nil {
 		return nil, err
 	}
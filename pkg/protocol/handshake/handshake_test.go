@@ -0,0 +1,136 @@
+package handshake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/willf/bitset"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/tlv"
+)
+
+// newTestBitset returns a bitset with the given length and bits set.
+func newTestBitset(length uint, bits ...uint) *bitset.BitSet {
+	b := bitset.New(length)
+	for _, bit := range bits {
+		b.Set(bit)
+	}
+	return b
+}
+
+// parseTestHandshake returns the handshake a peer with the given supported versions sends.
+func parseTestHandshake(t *testing.T, supportedVersions *bitset.BitSet) *Handshake {
+	handshakeMsg, err := NewHandshakeMessage(supportedVersions, 100, make([]byte, ByteEncodedCooAddressBytesLength), 14)
+	require.NoError(t, err)
+
+	hs, err := ParseHandshake(handshakeMsg[tlv.HeaderBytesLength:])
+	require.NoError(t, err)
+	return hs
+}
+
+func TestHandshakeSupportedFeatureSets(t *testing.T) {
+	own := newTestBitset(64, 2, 3, 4, 5)
+
+	tests := []struct {
+		name        string
+		peer        *bitset.BitSet
+		featureSets byte
+		version     int
+		err         error
+	}{
+		{
+			name:        "same feature sets",
+			peer:        newTestBitset(64, 2, 3, 4, 5),
+			featureSets: 1<<2 | 1<<3 | 1<<4 | 1<<5,
+			version:     1 << 5,
+		},
+		{
+			name:        "partial overlap",
+			peer:        newTestBitset(64, 1, 2, 4, 6),
+			featureSets: 1<<2 | 1<<4,
+			version:     1 << 4,
+		},
+		{
+			name:        "disjoint",
+			peer:        newTestBitset(64, 0, 1, 6),
+			featureSets: 0,
+			// the highest version of the peer is returned
+			version: 1 << 6,
+			err:     ErrVersionNotSupported,
+		},
+		{
+			name:        "empty",
+			peer:        newTestBitset(64),
+			featureSets: 0,
+			version:     0,
+			err:         ErrVersionNotSupported,
+		},
+		{
+			name:        "shorter bitset of an older peer",
+			peer:        newTestBitset(3, 2),
+			featureSets: 1 << 2,
+			version:     1 << 2,
+		},
+		{
+			name:        "shorter bitset of an older peer with an unknown version",
+			peer:        newTestBitset(8, 1, 2, 3),
+			featureSets: 1<<2 | 1<<3,
+			version:     1 << 3,
+		},
+		{
+			name:        "longer bitset",
+			peer:        newTestBitset(256, 2, 3, 7, 200),
+			featureSets: 1<<2 | 1<<3,
+			version:     1 << 3,
+		},
+		{
+			name:        "longer bitset without overlap",
+			peer:        newTestBitset(256, 1, 200),
+			featureSets: 0,
+			err:         ErrVersionNotSupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hs := parseTestHandshake(t, test.peer)
+
+			assert.Equal(t, test.featureSets, hs.SupportedFeatureSets(own))
+
+			version, err := hs.SupportedVersion(own)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+				if test.version != 0 {
+					assert.Equal(t, test.version, version)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.version, version)
+		})
+	}
+}
+
+func TestHandshakeSupportedFeatureSetsOnlyFirstByte(t *testing.T) {
+	// versions above the first 8 are negotiated, but are not part of the feature sets
+	own := newTestBitset(64, 2, 9)
+	hs := parseTestHandshake(t, newTestBitset(64, 2, 9))
+
+	assert.Equal(t, byte(1<<2), hs.SupportedFeatureSets(own))
+
+	version, err := hs.SupportedVersion(own)
+	assert.NoError(t, err)
+	assert.Equal(t, 1<<9, version)
+}
+
+func TestHandshakeInvalidSupportedVersions(t *testing.T) {
+	own := newTestBitset(64, 2, 3)
+
+	// the supported versions are not a binary encoded bitset
+	hs := &Handshake{SupportedVersions: []byte{1 << 2}}
+	assert.Equal(t, byte(0), hs.SupportedFeatureSets(own))
+
+	_, err := hs.SupportedVersion(own)
+	assert.Equal(t, ErrVersionNotSupported, err)
+}
@@ -17,12 +17,21 @@ func SendTransaction(p *peer.Peer, txData []byte) {
 }
 
 // SendHeartbeat sends a heartbeat message to the given peer.
-func SendHeartbeat(p *peer.Peer, solidMsIndex milestone.Index, pruningMsIndex milestone.Index, latestMsIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8) {
+// An extended heartbeat is sent if the peer supports it, otherwise the legacy heartbeat is sent.
+func SendHeartbeat(p *peer.Peer, heartbeat *sting.Heartbeat) {
 	if !p.Protocol.Supports(sting.FeatureSet) {
 		return
 	}
 
-	heartbeatData, _ := sting.NewHeartbeatMessage(solidMsIndex, pruningMsIndex, latestMsIndex, connectedNeighbors, syncedNeighbors)
+	if p.Protocol.Supports(sting.FeatureSetExtendedHeartbeat) {
+		extendedHeartbeatData, err := sting.NewExtendedHeartbeatMessage(heartbeat)
+		if err == nil {
+			p.EnqueueForSending(extendedHeartbeatData)
+			return
+		}
+	}
+
+	heartbeatData, _ := sting.NewHeartbeatMessage(heartbeat.SolidMilestoneIndex, heartbeat.PrunedMilestoneIndex, heartbeat.LatestMilestoneIndex, uint8(heartbeat.ConnectedNeighbors), uint8(heartbeat.SyncedNeighbors))
 	p.EnqueueForSending(heartbeatData)
 }
 
@@ -36,6 +45,58 @@ func SendTransactionRequest(p *peer.Peer, requestedHash aingle.Hash) {
 	p.EnqueueForSending(txReqData)
 }
 
+// SendTransactionRequests sends transaction requests for the given hashes to the given peer.
+// The hashes are sent in batches if the peer supports it, otherwise a request message is sent per hash.
+func SendTransactionRequests(p *peer.Peer, requestedHashes aingle.Hashes) {
+	if !p.Protocol.Supports(sting.FeatureSet) {
+		return
+	}
+
+	if !p.Protocol.Supports(sting.FeatureSetTransactionBatch) {
+		for _, requestedHash := range requestedHashes {
+			SendTransactionRequest(p, requestedHash)
+		}
+		return
+	}
+
+	for len(requestedHashes) > 0 {
+		batchSize := len(requestedHashes)
+		if batchSize > sting.MaxTransactionRequestBatchSize {
+			batchSize = sting.MaxTransactionRequestBatchSize
+		}
+
+		txReqBatchData, _ := sting.NewTransactionRequestBatchMessage(requestedHashes[:batchSize])
+		p.EnqueueForSending(txReqBatchData)
+		requestedHashes = requestedHashes[batchSize:]
+	}
+}
+
+// SendTransactions sends the given transactions to the given peer.
+// The transactions are sent in batches if the peer supports it, otherwise a transaction message is sent per transaction.
+func SendTransactions(p *peer.Peer, txsData [][]byte) {
+	if !p.Protocol.Supports(sting.FeatureSet) {
+		return
+	}
+
+	if !p.Protocol.Supports(sting.FeatureSetTransactionBatch) {
+		for _, txData := range txsData {
+			SendTransaction(p, txData)
+		}
+		return
+	}
+
+	for len(txsData) > 0 {
+		batchSize := len(txsData)
+		if batchSize > sting.MaxTransactionBatchSize {
+			batchSize = sting.MaxTransactionBatchSize
+		}
+
+		txBatchData, _ := sting.NewTransactionBatchMessage(txsData[:batchSize])
+		p.EnqueueForSending(txBatchData)
+		txsData = txsData[batchSize:]
+	}
+}
+
 // SendMilestoneRequest sends a milestone request to the given peer.
 func SendMilestoneRequest(p *peer.Peer, index milestone.Index) {
 	if !p.Protocol.Supports(sting.FeatureSet) {
@@ -46,6 +107,16 @@ func SendMilestoneRequest(p *peer.Peer, index milestone.Index) {
 	p.EnqueueForSending(milestoneRequestData)
 }
 
+// SendMilestoneConeRequest sends a request for all transactions confirmed by the given milestone to the given peer.
+func SendMilestoneConeRequest(p *peer.Peer, index milestone.Index) {
+	if !p.Protocol.Supports(sting.FeatureSetMilestoneCone) {
+		return
+	}
+
+	milestoneConeRequestData, _ := sting.NewMilestoneConeRequestMessage(index)
+	p.EnqueueForSending(milestoneConeRequestData)
+}
+
 // SendLatestMilestoneRequest sends a milestone request which requests the latest known milestone from the given peer.
 func SendLatestMilestoneRequest(p *peer.Peer) {
 	SendMilestoneRequest(p, sting.LatestMilestoneRequestIndex)
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/iotaledger/hive.go/batchhasher"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/Ariwonto/aingle-alpha/pkg/compressed"
 	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/dag"
 	"github.com/Ariwonto/aingle-alpha/pkg/metrics"
 	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
 	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
@@ -23,9 +25,11 @@ import (
 	"github.com/Ariwonto/aingle-alpha/pkg/peering/peer"
 	"github.com/Ariwonto/aingle-alpha/pkg/profile"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/bqueue"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/helpers"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/message"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/rqueue"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
 )
 
 const (
@@ -35,6 +39,9 @@ const (
 var (
 	workerCount         = batchhasher.CURLP81.GetBatchSize() * batchhasher.CURLP81.GetWorkerCount()
 	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errMilestoneConeLimitReached is returned by the traversal of a milestone cone if the maximum amount of transactions was collected.
+	errMilestoneConeLimitReached = errors.New("milestone cone limit reached")
 )
 
 // New creates a new processor which parses messages.
@@ -46,7 +53,18 @@ func New(requestQueue rqueue.Queue, peerManager *peering.Manager, opts *Options)
 			TransactionProcessed: events.NewEvent(TransactionProcessedCaller),
 			BroadcastTransaction: events.NewEvent(BroadcastCaller),
 		},
+		maxMilestoneConeTransactions: opts.MaxMilestoneConeTransactions,
 	}
+
+	if opts.MilestoneConeRequestsPerMinute > 0 {
+		proc.milestoneConeRequestLimiter = ratelimit.NewTokenBuckets(opts.MilestoneConeRequestsPerMinute)
+
+		// the limits of disconnected peers are not needed anymore
+		peerManager.Events.PeerDisconnected.Attach(events.NewClosure(func(p *peer.Peer) {
+			proc.milestoneConeRequestLimiter.Remove(p.ID)
+		}))
+	}
+
 	wuCacheOpts := opts.WorkUnitCacheOpts
 	proc.workUnits = objectstorage.New(
 		nil,
@@ -73,6 +91,12 @@ func New(requestQueue rqueue.Queue, peerManager *peering.Manager, opts *Options)
 			proc.processTransactionRequest(p, data)
 		case sting.MessageTypeMilestoneRequest:
 			proc.processMilestoneRequest(p, data)
+		case sting.MessageTypeTransactionBatch:
+			proc.processTransactionBatch(p, data)
+		case sting.MessageTypeTransactionRequestBatch:
+			proc.processTransactionRequestBatch(p, data)
+		case sting.MessageTypeMilestoneConeRequest:
+			proc.processMilestoneConeRequest(p, data)
 		}
 
 		task.Return(nil)
@@ -105,12 +129,20 @@ type Processor struct {
 	requestQueue rqueue.Queue
 	workUnits    *objectstorage.ObjectStorage
 	opts         Options
+	// limits the milestone cone requests per peer, nil if the requests are not limited
+	milestoneConeRequestLimiter *ratelimit.TokenBuckets
+	// the maximum amount of transactions sent in reply to a milestone cone request, 0 means unlimited
+	maxMilestoneConeTransactions int
 }
 
 // The Options for the Processor.
 type Options struct {
 	ValidMWM          uint64
 	WorkUnitCacheOpts profile.CacheOpts
+	// The maximum amount of milestone cone requests per minute a peer is answered, 0 means unlimited.
+	MilestoneConeRequestsPerMinute int
+	// The maximum amount of transactions sent in reply to a milestone cone request, 0 means unlimited.
+	MaxMilestoneConeTransactions int
 }
 
 // Run runs the processor and blocks until the shutdown signal is triggered.
@@ -220,14 +252,88 @@ func (proc *Processor) processMilestoneRequest(p *peer.Peer, data []byte) {
 	}
 
 	cachedTxs := cachedReqMs.GetBundle().GetTransactions() // txs +1
+	txsData := make([][]byte, 0, len(cachedTxs))
 	for _, cachedTxToSend := range cachedTxs {
-		transactionMsg, _ := sting.NewTransactionMessage(cachedTxToSend.GetTransaction().RawBytes)
-		p.EnqueueForSending(transactionMsg)
+		txsData = append(txsData, cachedTxToSend.GetTransaction().RawBytes)
 	}
+	helpers.SendTransactions(p, txsData)
 	cachedTxs.Release(true)   // txs -1
 	cachedReqMs.Release(true) // bundle -1
 }
 
+// processes the given milestone cone request by parsing it and then replying to the peer with all transactions
+// confirmed by the requested milestone. the transactions are sent in post-order, so approvees are sent before
+// their approvers and the peer can store and solidify the cone while receiving it.
+// requests over the rate limit of the peer are dropped, and only up to the maximum amount of transactions of
+// a cone are sent. the peer requests the rest of a truncated cone during solidification.
+func (proc *Processor) processMilestoneConeRequest(p *peer.Peer, data []byte) {
+	msIndex, err := sting.ExtractRequestedMilestoneIndex(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidRequests.Inc()
+
+		// drop the connection to the peer
+		proc.pm.Remove(p.ID)
+		return
+	}
+
+	if proc.milestoneConeRequestLimiter != nil {
+		if ok, _ := proc.milestoneConeRequestLimiter.Take(p.ID, 1); !ok {
+			return
+		}
+	}
+
+	// the cone is only complete for solid milestones which are not pruned
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil || msIndex <= snapshotInfo.PruningIndex || msIndex > tangle.GetSolidMilestoneIndex() {
+		return
+	}
+
+	cachedReqMs := tangle.GetMilestoneOrNil(msIndex) // bundle +1
+	if cachedReqMs == nil {
+		// can't reply if we don't have the wanted milestone
+		return
+	}
+	msTailTxHash := cachedReqMs.GetBundle().GetTailHash()
+	cachedReqMs.Release(true) // bundle -1
+
+	var txsData [][]byte
+	if err := dag.TraverseApprovees(msTailTxHash,
+		// traversal stops at transactions which were not confirmed by the requested milestone
+		func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+			confirmed, at := cachedTxMeta.GetMetadata().GetConfirmed()
+			return confirmed && at == msIndex, nil
+		},
+		// consumer is called in post-order
+		func(cachedTxMeta *tangle.CachedMetadata) error { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+
+			cachedTx := tangle.GetCachedTransactionOrNil(cachedTxMeta.GetMetadata().GetTxHash()) // tx +1
+			if cachedTx == nil {
+				return fmt.Errorf("%w: transaction %s", tangle.ErrTransactionNotFound, cachedTxMeta.GetMetadata().GetTxHash().Trytes())
+			}
+			txsData = append(txsData, cachedTx.GetTransaction().RawBytes)
+			cachedTx.Release(true) // tx -1
+
+			if proc.maxMilestoneConeTransactions > 0 && len(txsData) >= proc.maxMilestoneConeTransactions {
+				return errMilestoneConeLimitReached
+			}
+			return nil
+		},
+		// called on missing approvees
+		// return error on missing approvees
+		nil,
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		true, false, false, nil); err != nil && !errors.Is(err, errMilestoneConeLimitReached) {
+		// the cone could have been pruned in the meantime
+		return
+	}
+
+	helpers.SendTransactions(p, txsData)
+}
+
 // processes the given transaction request by parsing it and then replying to the peer with it.
 func (proc *Processor) processTransactionRequest(p *peer.Peer, data []byte) {
 	if len(data) != 49 {
@@ -245,6 +351,50 @@ func (proc *Processor) processTransactionRequest(p *peer.Peer, data []byte) {
 	p.EnqueueForSending(transactionMsg)
 }
 
+// processes the given transaction request batch by parsing it and then replying to the peer with the known transactions.
+func (proc *Processor) processTransactionRequestBatch(p *peer.Peer, data []byte) {
+	requestedHashes, err := sting.ExtractRequestedTransactionHashes(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidRequests.Inc()
+
+		// drop the connection to the peer
+		proc.pm.Remove(p.ID)
+		return
+	}
+
+	txsData := make([][]byte, 0, len(requestedHashes))
+	for _, requestedHash := range requestedHashes {
+		cachedTx := tangle.GetCachedTransactionOrNil(requestedHash) // tx +1
+		if cachedTx == nil {
+			// can't reply if we don't have the requested transaction
+			continue
+		}
+		txsData = append(txsData, cachedTx.GetTransaction().RawBytes)
+		cachedTx.Release() // tx -1
+	}
+
+	helpers.SendTransactions(p, txsData)
+}
+
+// processes the given transaction batch by processing each contained transaction.
+func (proc *Processor) processTransactionBatch(p *peer.Peer, data []byte) {
+	txsData, err := sting.ExtractTransactions(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidTransactions.Inc()
+
+		// drop the connection to the peer
+		proc.pm.Remove(p.ID)
+		return
+	}
+
+	for _, txData := range txsData {
+		p.Metrics.ReceivedTransactions.Inc()
+		metrics.SharedServerMetrics.Transactions.Inc()
+		proc.processTransaction(p, txData)
+	}
+}
+
 // gets or creates a new WorkUnit for the given transaction and then processes the WorkUnit.
 func (proc *Processor) processTransaction(p *peer.Peer, data []byte) {
 	cachedWorkUnit := proc.workUnitFor(data) // workUnit +1
@@ -254,6 +404,16 @@ func (proc *Processor) processTransaction(p *peer.Peer, data []byte) {
 	proc.processWorkUnit(workUnit, p)
 }
 
+// records the latency of the given request on the metrics of the peer which answered it.
+func recordAnsweredRequest(p *peer.Peer, request *rqueue.Request) {
+	if request.RequestTime.IsZero() {
+		// the request was never sent
+		return
+	}
+	p.Metrics.AnsweredTransactionRequests.Inc()
+	p.Metrics.AnsweredTransactionRequestsLatency.Add(uint32(time.Since(request.RequestTime).Milliseconds()))
+}
+
 // tries to process the WorkUnit by first checking in what state it is.
 // if the WorkUnit is invalid (because the underlying transaction is invalid), the given peer is punished.
 // if the WorkUnit is already completed, and the transaction was requested, this function emits a TransactionProcessed event.
@@ -269,6 +429,7 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		wu.processingLock.Unlock()
 
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidTransactions.Inc()
 
 		// drop the connection to the peer
 		proc.pm.Remove(p.ID)
@@ -279,6 +440,7 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 
 		// emit an event to say that a transaction was fully processed
 		if request := proc.requestQueue.Received(wu.tx.GetTxHash()); request != nil {
+			recordAnsweredRequest(p, request)
 			proc.Events.TransactionProcessed.Trigger(wu.tx, request, p)
 			wu.wasStale = false
 			return
@@ -339,6 +501,10 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		return
 	}
 
+	if request != nil {
+		recordAnsweredRequest(p, request)
+	}
+
 	// check the existence of the transaction before broadcasting it
 	containsTx := tangle.ContainsTransaction(hornetTx.GetTxHash())
 
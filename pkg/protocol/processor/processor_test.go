@@ -0,0 +1,175 @@
+package processor
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/peering"
+	"github.com/Ariwonto/aingle-alpha/pkg/peering/peer"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/message"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/rqueue"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/tlv"
+)
+
+// the solid entry points can only be loaded once
+var loadSolidEntryPointsOnce sync.Once
+
+// configureMilestoneConeTest configures in-memory storages holding milestone 5, which confirmed a chain of three transactions.
+// The solid milestone is 5, the pruning index is 2. It returns the hashes of the confirmed transactions in post-order.
+func configureMilestoneConeTest(t *testing.T) []trinary.Hash {
+	tangleStore := mapdb.NewMapDB()
+	tangle.ConfigureStorages(tangleStore, mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Profile1GB.Caches)
+	t.Cleanup(tangle.ShutdownStorages)
+	loadSolidEntryPointsOnce.Do(tangle.LoadInitialValuesFromDatabase)
+
+	tangle.ResetMilestoneIndexes()
+	t.Cleanup(tangle.ResetMilestoneIndexes)
+	tangle.SetSolidMilestoneIndex(5, false)
+
+	tangle.SetSnapshotInfo(&tangle.SnapshotInfo{CoordinatorAddress: aingle.NullHashBytes, Hash: aingle.NullHashBytes, PruningIndex: 2})
+
+	// the root was confirmed by the previous milestone, so it is not part of the cone
+	root := storeMilestoneConeTestTransaction(t, 1, consts.NullHashTrytes, 4)
+	first := storeMilestoneConeTestTransaction(t, 2, root, 5)
+	second := storeMilestoneConeTestTransaction(t, 3, first, 5)
+	msTail := storeMilestoneConeTestTransaction(t, 4, second, 5)
+	storeMilestoneConeTestMilestone(t, tangleStore, 5, msTail)
+
+	return []trinary.Hash{first, second, msTail}
+}
+
+// storeMilestoneConeTestTransaction stores a single transaction bundle approving the given trunk and returns its hash.
+// The transaction is marked as confirmed by the given milestone.
+func storeMilestoneConeTestTransaction(t *testing.T, idx int, trunk trinary.Hash, confirmedIndex milestone.Index) trinary.Hash {
+	tx := &transaction.Transaction{
+		Hash:                     trinary.IntToTrytes(int64(idx), consts.HashTrytesSize),
+		SignatureMessageFragment: trinary.IntToTrytes(0, consts.SignatureMessageFragmentSizeInTrytes),
+		Address:                  trinary.IntToTrytes(1, consts.HashTrytesSize),
+		ObsoleteTag:              trinary.IntToTrytes(0, consts.ObsoleteTagTrinarySize/3),
+		Bundle:                   trinary.IntToTrytes(int64(idx), consts.HashTrytesSize),
+		TrunkTransaction:         trunk,
+		BranchTransaction:        trunk,
+		Tag:                      trinary.IntToTrytes(0, consts.TagTrinarySize/3),
+		Nonce:                    trinary.IntToTrytes(0, consts.NonceTrinarySize/3),
+	}
+
+	cachedTx, newlyAdded := tangle.StoreTransactionIfAbsent(aingle.NewTransactionFromTx(tx, []byte(tx.Hash))) // tx +1
+	require.True(t, newlyAdded)
+	cachedTx.GetMetadata().SetSolid(true)
+	cachedTx.GetMetadata().SetConfirmed(true, confirmedIndex)
+	tangle.StoreBundleTransaction(cachedTx.GetTransaction().GetBundleHash(), cachedTx.GetTransaction().GetTxHash(), true).Release(true)
+	tangle.OnTailTransactionSolid(cachedTx) // tx -1
+
+	return tx.Hash
+}
+
+// storeMilestoneConeTestMilestone stores the given tail transaction as the milestone with the given index.
+// The milestone is written to the store directly, since valid milestone bundles need the signature of the coordinator.
+func storeMilestoneConeTestMilestone(t *testing.T, store kvstore.KVStore, index milestone.Index, tailTxHash trinary.Hash) {
+	key := make([]byte, 4)
+	binary.LittleEndian.PutUint32(key, uint32(index))
+	require.NoError(t, store.Set(append([]byte{tangle.StorePrefixMilestones}, key...), aingle.HashFromHashTrytes(tailTxHash)))
+	require.True(t, tangle.ContainsMilestone(index))
+}
+
+// newMilestoneConeTestPeer returns a peer supporting milestone cone requests and transaction batches.
+func newMilestoneConeTestPeer(id string) *peer.Peer {
+	return &peer.Peer{
+		ID:        id,
+		SendQueue: make(chan []byte, 100),
+		Protocol:  &protocol.Protocol{FeatureSet: sting.FeatureSet | sting.FeatureSetTransactionBatch | sting.FeatureSetMilestoneCone},
+	}
+}
+
+// requestMilestoneCone lets the processor answer a milestone cone request of the given peer
+// and returns the hashes of the transactions sent to the peer.
+func requestMilestoneCone(t *testing.T, proc *Processor, p *peer.Peer, index milestone.Index) []trinary.Hash {
+	msg, err := sting.NewMilestoneConeRequestMessage(index)
+	require.NoError(t, err)
+	proc.processMilestoneConeRequest(p, msg[tlv.HeaderBytesLength:])
+
+	var txHashes []trinary.Hash
+	for {
+		select {
+		case data := <-p.SendQueue:
+			require.Equal(t, message.Type(sting.MessageTypeTransactionBatch), message.Type(data[0]))
+			txsData, err := sting.ExtractTransactions(data[tlv.HeaderBytesLength:])
+			require.NoError(t, err)
+			for _, txData := range txsData {
+				txHashes = append(txHashes, trinary.Hash(txData))
+			}
+		default:
+			return txHashes
+		}
+	}
+}
+
+func TestProcessMilestoneConeRequest(t *testing.T) {
+	cone := configureMilestoneConeTest(t)
+
+	proc := New(rqueue.New(), peering.NewManager(peering.Options{}), &Options{
+		WorkUnitCacheOpts: profile.Profile1GB.Caches.IncomingTransactionFilter,
+	})
+	p := newMilestoneConeTestPeer("peer")
+
+	// the transactions confirmed by the milestone are sent in post-order
+	assert.Equal(t, cone, requestMilestoneCone(t, proc, p, 5))
+
+	// pruned milestones and milestones which are not solid are not answered
+	assert.Empty(t, requestMilestoneCone(t, proc, p, 2))
+	assert.Empty(t, requestMilestoneCone(t, proc, p, 6))
+
+	// unknown milestones are not answered
+	assert.Empty(t, requestMilestoneCone(t, proc, p, 4))
+}
+
+func TestProcessMilestoneConeRequestMaxTransactions(t *testing.T) {
+	cone := configureMilestoneConeTest(t)
+
+	proc := New(rqueue.New(), peering.NewManager(peering.Options{}), &Options{
+		WorkUnitCacheOpts:            profile.Profile1GB.Caches.IncomingTransactionFilter,
+		MaxMilestoneConeTransactions: 2,
+	})
+
+	// only the first transactions of the cone are sent, their approvees are always sent before them
+	assert.Equal(t, cone[:2], requestMilestoneCone(t, proc, newMilestoneConeTestPeer("peer"), 5))
+}
+
+func TestProcessMilestoneConeRequestRateLimit(t *testing.T) {
+	cone := configureMilestoneConeTest(t)
+
+	pm := peering.NewManager(peering.Options{})
+	proc := New(rqueue.New(), pm, &Options{
+		WorkUnitCacheOpts:              profile.Profile1GB.Caches.IncomingTransactionFilter,
+		MilestoneConeRequestsPerMinute: 2,
+	})
+	p := newMilestoneConeTestPeer("peer")
+
+	assert.Equal(t, cone, requestMilestoneCone(t, proc, p, 5))
+	assert.Equal(t, cone, requestMilestoneCone(t, proc, p, 5))
+
+	// further requests of the peer are dropped
+	assert.Empty(t, requestMilestoneCone(t, proc, p, 5))
+
+	// other peers have their own limit
+	assert.Equal(t, cone, requestMilestoneCone(t, proc, newMilestoneConeTestPeer("other"), 5))
+
+	// the limit of a peer is reset if it disconnects
+	pm.Events.PeerDisconnected.Trigger(p)
+	assert.Equal(t, cone, requestMilestoneCone(t, proc, p, 5))
+}
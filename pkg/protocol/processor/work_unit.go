@@ -111,6 +111,7 @@ func (wu *WorkUnit) punish() {
 	defer wu.receivedFromLock.Unlock()
 	for _, p := range wu.receivedFrom {
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidTransactions.Inc()
 
 		// drop the connection to the peer
 		peering.Manager().Remove(p.ID)
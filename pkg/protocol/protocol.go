@@ -34,7 +34,7 @@ var (
 	*/
 
 	// supported protocol messages/feature sets
-	SupportedFeatureSets = bitset.From([]uint64{sting.FeatureSet})
+	SupportedFeatureSets = bitset.From([]uint64{sting.FeatureSet | sting.FeatureSetTransactionBatch | sting.FeatureSetMilestoneCone | sting.FeatureSetExtendedHeartbeat})
 )
 
 var (
@@ -67,6 +67,7 @@ type Events struct {
 	// Use a message's ID to get the corresponding event.
 	Received []*events.Event
 	// Holds event instances to attach to for sent messages.
+	// The events are triggered with the sent message data without the header.
 	// Use a message's ID to get the corresponding event.
 	Sent []*events.Event
 	// Fired for generic protocol errors.
@@ -110,7 +111,7 @@ func New(conn io.ReadWriteCloser) *Protocol {
 			continue
 		}
 		receiveHandlers[i] = events.NewEvent(events.ByteSliceCaller)
-		sentHandlers[i] = events.NewEvent(events.CallbackCaller)
+		sentHandlers[i] = events.NewEvent(events.ByteSliceCaller)
 	}
 
 	protocol := &Protocol{
@@ -140,6 +141,15 @@ func (p *Protocol) SupportedFeatureSets() []string {
 	if p.Supports(sting.FeatureSet) {
 		features = append(features, sting.FeatureSetName)
 	}
+	if p.Supports(sting.FeatureSetTransactionBatch) {
+		features = append(features, sting.FeatureSetTransactionBatchName)
+	}
+	if p.Supports(sting.FeatureSetMilestoneCone) {
+		features = append(features, sting.FeatureSetMilestoneConeName)
+	}
+	if p.Supports(sting.FeatureSetExtendedHeartbeat) {
+		features = append(features, sting.FeatureSetExtendedHeartbeatName)
+	}
 	return features
 }
 
@@ -238,7 +248,7 @@ func (p *Protocol) Send(message []byte) error {
 	}
 
 	// fire event handler for sent message
-	p.Events.Sent[message[0]].Trigger()
+	p.Events.Sent[message[0]].Trigger(message[tlv.HeaderBytesLength:])
 
 	return nil
 }
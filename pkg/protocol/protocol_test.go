@@ -8,8 +8,10 @@ import (
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/handshake"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/tlv"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/stretchr/testify/assert"
+	"github.com/willf/bitset"
 )
 
 type fakeconn struct {
@@ -95,9 +97,9 @@ func TestProtocol_Send(t *testing.T) {
 	defer conn.Close()
 	p := protocol.New(conn)
 
-	var handshakeMessageSent bool
-	p.Events.Sent[handshake.HandshakeMessageDefinition.ID].Attach(events.NewClosure(func() {
-		handshakeMessageSent = true
+	var sentData []byte
+	p.Events.Sent[handshake.HandshakeMessageDefinition.ID].Attach(events.NewClosure(func(data []byte) {
+		sentData = data
 	}))
 
 	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14)
@@ -107,7 +109,8 @@ func TestProtocol_Send(t *testing.T) {
 	assert.NoError(t, p.Send(handshakeMsg))
 
 	wg.Wait()
-	assert.True(t, handshakeMessageSent)
+	// the sent event contains the message without the header
+	assert.Equal(t, handshakeMsg[tlv.HeaderBytesLength:], sentData)
 }
 
 func TestProtocol_Supports(t *testing.T) {
@@ -116,3 +119,41 @@ func TestProtocol_Supports(t *testing.T) {
 	assert.True(t, p.Supports(sting.FeatureSet))
 	assert.False(t, p.Supports(243))
 }
+
+func TestProtocol_NegotiateFeatureSets(t *testing.T) {
+	parseHandshake := func(featureSets *bitset.BitSet) *handshake.Handshake {
+		handshakeMsg, err := handshake.NewHandshakeMessage(featureSets, 100, make([]byte, 49), 14)
+		assert.NoError(t, err)
+
+		hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderBytesLength:])
+		assert.NoError(t, err)
+		return hs
+	}
+
+	// a peer which only supports STING
+	hs := parseHandshake(bitset.From([]uint64{sting.FeatureSet}))
+	version, err := hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.FeatureSet, version)
+	assert.Equal(t, byte(sting.FeatureSet), hs.SupportedFeatureSets(protocol.SupportedFeatureSets))
+
+	// a peer which also supports transaction batches
+	hs = parseHandshake(bitset.From([]uint64{sting.FeatureSet | sting.FeatureSetTransactionBatch}))
+	version, err = hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.FeatureSetTransactionBatch, version)
+	assert.Equal(t, byte(sting.FeatureSet|sting.FeatureSetTransactionBatch), hs.SupportedFeatureSets(protocol.SupportedFeatureSets))
+
+	// a peer which supports all feature sets
+	hs = parseHandshake(protocol.SupportedFeatureSets)
+	version, err = hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.FeatureSetExtendedHeartbeat, version)
+	assert.Equal(t, byte(sting.FeatureSet|sting.FeatureSetTransactionBatch|sting.FeatureSetMilestoneCone|sting.FeatureSetExtendedHeartbeat), hs.SupportedFeatureSets(protocol.SupportedFeatureSets))
+
+	// a peer without any common feature set
+	hs = parseHandshake(bitset.From([]uint64{1 << 1}))
+	_, err = hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.Error(t, err)
+	assert.Zero(t, hs.SupportedFeatureSets(protocol.SupportedFeatureSets))
+}
@@ -15,6 +15,8 @@ import (
 type Queue interface {
 	// Next returns the next request to send, pops it from the queue and marks it as pending.
 	Next() *Request
+	// NextBatch returns up to max requests to send, pops them from the queue and marks them as pending.
+	NextBatch(max int) []*Request
 	// Peek returns the next request to send without popping it from the queue.
 	Peek() *Request
 	// Enqueue enqueues the given request if it isn't already queued or pending.
@@ -84,6 +86,9 @@ type Request struct {
 	// the time at which this request was first enqueued.
 	// do not modify this time
 	EnqueueTime time.Time
+	// the time at which this request was last popped from the queue to be sent.
+	// do not modify this time
+	RequestTime time.Time
 }
 
 // implements a priority queue where requests with the lowest milestone index are popped first.
@@ -113,6 +118,22 @@ func (pq *priorityqueue) Next() (r *Request) {
 	return heap.Pop(pq).(*Request)
 }
 
+func (pq *priorityqueue) NextBatch(max int) []*Request {
+	pq.Lock()
+	defer pq.Unlock()
+
+	count := len(pq.queued)
+	if count > max {
+		count = max
+	}
+
+	requests := make([]*Request, 0, count)
+	for i := 0; i < count; i++ {
+		requests = append(requests, heap.Pop(pq).(*Request))
+	}
+	return requests
+}
+
 func (pq *priorityqueue) Enqueue(r *Request) bool {
 	pq.Lock()
 	defer pq.Unlock()
@@ -317,6 +338,7 @@ func (pq *priorityqueue) Pop() interface{} {
 	// mark as pending and remove from queued
 	delete(pq.queued, string(r.Hash))
 	pq.pending[string(r.Hash)] = r
+	r.RequestTime = time.Now()
 	return r
 }
 
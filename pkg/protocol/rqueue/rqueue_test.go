@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/rqueue"
 	"github.com/iotaledger/iota.go/trinary"
 	"github.com/stretchr/testify/assert"
@@ -125,3 +126,36 @@ func TestRequestQueue(t *testing.T) {
 	assert.Zero(t, len(pendingReqs))
 	assert.Zero(t, len(processingReq))
 }
+
+func TestRequestQueue_NextBatch(t *testing.T) {
+	q := rqueue.New()
+
+	for i, trytes := range []string{"A", "B", "C", "D", "E"} {
+		assert.True(t, q.Enqueue(&rqueue.Request{
+			Hash:           aingle.Hash(trinary.MustTrytesToBytes(trytes)),
+			MilestoneIndex: milestone.Index(10 - i),
+		}))
+	}
+
+	// requests with the highest priority are popped first
+	batch := q.NextBatch(3)
+	assert.Len(t, batch, 3)
+	for i, r := range batch {
+		assert.Equal(t, milestone.Index(6+i), r.MilestoneIndex)
+		assert.True(t, q.IsPending(r.Hash))
+	}
+
+	queued, pending, _ := q.Size()
+	assert.Equal(t, 2, queued)
+	assert.Equal(t, 3, pending)
+
+	// the batch is limited to the queued requests
+	batch = q.NextBatch(3)
+	assert.Len(t, batch, 2)
+
+	assert.Empty(t, q.NextBatch(3))
+
+	queued, pending, _ = q.Size()
+	assert.Zero(t, queued)
+	assert.Equal(t, 5, pending)
+}
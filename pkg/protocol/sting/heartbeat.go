@@ -2,17 +2,31 @@ package sting
 
 import (
 	"encoding/binary"
+	"math"
 
 	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
 )
 
 // Heartbeat contains information about a nodes current solid and pruned milestone index.
+// The fields after SyncedNeighbors are only set if the heartbeat was received as an extended heartbeat.
 type Heartbeat struct {
 	SolidMilestoneIndex  milestone.Index `json:"solid_milestone_index"`
 	PrunedMilestoneIndex milestone.Index `json:"pruned_milestone_index"`
 	LatestMilestoneIndex milestone.Index `json:"latest_milestone_index"`
 	ConnectedNeighbors   int             `json:"connected_neighbors"`
 	SyncedNeighbors      int             `json:"synced_neighbors"`
+	// Version is the version of the extended heartbeat, 0 for legacy heartbeats.
+	Version uint8 `json:"version"`
+	// SnapshotMilestoneIndex is the milestone index of the snapshot the node's database is based on.
+	SnapshotMilestoneIndex milestone.Index `json:"snapshot_milestone_index"`
+	// FeatureSets is the bitmask of the feature sets the node supports.
+	FeatureSets byte `json:"feature_sets"`
+	// TipPoolSize is the amount of tips in the node's tip pool.
+	TipPoolSize int `json:"tip_pool_size"`
+	// RequestQueueSize is the amount of queued and pending requests of the node.
+	RequestQueueSize int `json:"request_queue_size"`
+	// Alias is the optional alias of the node.
+	Alias string `json:"alias,omitempty"`
 }
 
 /// ParseHeartbeat parses the given message into a heartbeat.
@@ -26,6 +40,65 @@ func ParseHeartbeat(data []byte) *Heartbeat {
 	}
 }
 
+// ParseExtendedHeartbeat parses the given message into a heartbeat.
+// Fields appended by newer versions of the extended heartbeat are ignored.
+func ParseExtendedHeartbeat(data []byte) (*Heartbeat, error) {
+	if len(data) < ExtendedHeartbeatMinBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	heartbeat := ParseHeartbeat(data)
+
+	offset := HeartbeatMessageDefinition.MaxBytesLength
+	heartbeat.Version = data[offset]
+	if heartbeat.Version < ExtendedHeartbeatVersion {
+		return nil, ErrInvalidHeartbeatVersion
+	}
+	offset++
+
+	heartbeat.SnapshotMilestoneIndex = milestone.Index(binary.BigEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+
+	heartbeat.FeatureSets = data[offset]
+	offset++
+
+	heartbeat.TipPoolSize = int(binary.BigEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+
+	heartbeat.RequestQueueSize = int(binary.BigEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+
+	aliasLength := uint16(data[offset])
+	offset++
+
+	if aliasLength > ExtendedHeartbeatAliasMaxBytesLength || int(offset+aliasLength) > len(data) {
+		return nil, ErrInvalidSourceLength
+	}
+	heartbeat.Alias = string(data[offset : offset+aliasLength])
+
+	return heartbeat, nil
+}
+
+func clampUint8(value int) uint8 {
+	switch {
+	case value < 0:
+		return 0
+	case value > math.MaxUint8:
+		return math.MaxUint8
+	}
+	return uint8(value)
+}
+
+func clampUint32(value int) uint32 {
+	switch {
+	case value < 0:
+		return 0
+	case uint64(value) > math.MaxUint32:
+		return math.MaxUint32
+	}
+	return uint32(value)
+}
+
 func HeartbeatCaller(handler interface{}, params ...interface{}) {
 	handler.(func(heartbeat *Heartbeat))(params[0].(*Heartbeat))
 }
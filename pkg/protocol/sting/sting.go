@@ -15,6 +15,14 @@ import (
 var (
 	// ErrInvalidSourceLength is returned when an invalid source byte slice for extraction of certain data is passed.
 	ErrInvalidSourceLength = errors.New("invalid source byte slice")
+	// ErrTooManyTransactions is returned when more transactions are passed than fit into a transaction batch message.
+	ErrTooManyTransactions = errors.New("too many transactions for a batch")
+	// ErrTooManyRequestedTransactions is returned when more hashes are passed than fit into a transaction request batch message.
+	ErrTooManyRequestedTransactions = errors.New("too many requested transactions for a batch")
+	// ErrInvalidHeartbeatVersion is returned when an extended heartbeat with an invalid version is parsed.
+	ErrInvalidHeartbeatVersion = errors.New("invalid extended heartbeat version")
+	// ErrHeartbeatAliasTooLong is returned when the alias doesn't fit into an extended heartbeat message.
+	ErrHeartbeatAliasTooLong = errors.New("alias too long for an extended heartbeat")
 )
 
 // FeatureSet denotes the version bit for Chrysalis-Pt1 support.
@@ -23,6 +31,27 @@ const FeatureSet = 1 << 2
 // FeatureSetName is the name of the feature set.
 const FeatureSetName = "Chrysalis-Pt1"
 
+// FeatureSetTransactionBatch denotes the version bit for batched transaction requests and responses.
+// It extends the Chrysalis-Pt1 feature set, peers supporting it also support FeatureSet.
+const FeatureSetTransactionBatch = 1 << 3
+
+// FeatureSetTransactionBatchName is the name of the transaction batch feature set.
+const FeatureSetTransactionBatchName = "TransactionBatch"
+
+// FeatureSetMilestoneCone denotes the version bit for milestone cone requests.
+// It extends the Chrysalis-Pt1 feature set, peers supporting it also support FeatureSet.
+const FeatureSetMilestoneCone = 1 << 4
+
+// FeatureSetMilestoneConeName is the name of the milestone cone feature set.
+const FeatureSetMilestoneConeName = "MilestoneCone"
+
+// FeatureSetExtendedHeartbeat denotes the version bit for extended heartbeats.
+// It extends the Chrysalis-Pt1 feature set, peers supporting it also support FeatureSet.
+const FeatureSetExtendedHeartbeat = 1 << 5
+
+// FeatureSetExtendedHeartbeatName is the name of the extended heartbeat feature set.
+const FeatureSetExtendedHeartbeatName = "ExtendedHeartbeat"
+
 func init() {
 	if err := message.RegisterType(MessageTypeMilestoneRequest, MilestoneRequestMessageDefinition); err != nil {
 		panic(err)
@@ -36,6 +65,18 @@ func init() {
 	if err := message.RegisterType(MessageTypeHeartbeat, HeartbeatMessageDefinition); err != nil {
 		panic(err)
 	}
+	if err := message.RegisterType(MessageTypeTransactionRequestBatch, TransactionRequestBatchMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeTransactionBatch, TransactionBatchMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeMilestoneConeRequest, MilestoneConeRequestMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeExtendedHeartbeat, ExtendedHeartbeatMessageDefinition); err != nil {
+		panic(err)
+	}
 }
 
 const (
@@ -43,6 +84,16 @@ const (
 	MessageTypeTransaction        message.Type = 4
 	MessageTypeTransactionRequest message.Type = 5
 	MessageTypeHeartbeat          message.Type = 6
+
+	// only available with FeatureSetTransactionBatch
+	MessageTypeTransactionRequestBatch message.Type = 7
+	MessageTypeTransactionBatch        message.Type = 8
+
+	// only available with FeatureSetMilestoneCone
+	MessageTypeMilestoneConeRequest message.Type = 9
+
+	// only available with FeatureSetExtendedHeartbeat
+	MessageTypeExtendedHeartbeat message.Type = 10
 )
 
 const (
@@ -57,6 +108,34 @@ const (
 
 	// The index to use to request the latest milestone via a milestone request message.
 	LatestMilestoneRequestIndex = 0
+
+	// The max amount of transaction hashes within a transaction request batch packet.
+	MaxTransactionRequestBatchSize = 64
+
+	// The max amount of transactions within a transaction batch packet.
+	MaxTransactionBatchSize = 32
+
+	// The amount of bytes used to denote the length of a transaction within a transaction batch packet.
+	TransactionBatchTxLengthBytesLength = 2
+
+	// The max amount of bytes of a transaction within a transaction batch packet.
+	TransactionBatchTxMaxBytesLength = consts.NonSigTxPartBytesLength + consts.SigDataMaxBytesLength
+
+	// The current version of the extended heartbeat packet.
+	// Newer versions only append fields, so older nodes are able to parse the known part of the packet.
+	ExtendedHeartbeatVersion = 1
+
+	// The min amount of bytes of an extended heartbeat packet of version 1:
+	// the legacy heartbeat, the version, the snapshot index, the feature sets,
+	// the tip pool size, the request queue size and the length of the alias.
+	ExtendedHeartbeatMinBytesLength = HeartbeatMilestoneIndexBytesLength*3 + 2 + 1 + HeartbeatMilestoneIndexBytesLength + 1 + 4 + 4 + 1
+
+	// The max amount of bytes used for the alias within an extended heartbeat packet.
+	ExtendedHeartbeatAliasMaxBytesLength = 64
+
+	// The max amount of bytes of an extended heartbeat packet.
+	// It leaves room for fields added by newer versions.
+	ExtendedHeartbeatMaxBytesLength = 512
 )
 
 var (
@@ -83,12 +162,44 @@ var (
 		VariableLength: false,
 	}
 
+	// The requested transaction hashes packet.
+	// Contains up to MaxTransactionRequestBatchSize hashes of requested transaction payloads.
+	TransactionRequestBatchMessageDefinition = &message.Definition{
+		ID:             MessageTypeTransactionRequestBatch,
+		MaxBytesLength: RequestedTransactionHashMsgBytesLength * MaxTransactionRequestBatchSize,
+		VariableLength: true,
+	}
+
+	// The transaction batch packet.
+	// Contains up to MaxTransactionBatchSize transactions, each prefixed by its length.
+	TransactionBatchMessageDefinition = &message.Definition{
+		ID:             MessageTypeTransactionBatch,
+		MaxBytesLength: (TransactionBatchTxLengthBytesLength + TransactionBatchTxMaxBytesLength) * MaxTransactionBatchSize,
+		VariableLength: true,
+	}
+
 	// The requested milestone index packet.
 	MilestoneRequestMessageDefinition = &message.Definition{
 		ID:             MessageTypeMilestoneRequest,
 		MaxBytesLength: RequestedMilestoneIndexMsgBytesLength,
 		VariableLength: false,
 	}
+
+	// The requested milestone cone packet.
+	// Contains the index of the milestone of which all confirmed transactions are requested.
+	MilestoneConeRequestMessageDefinition = &message.Definition{
+		ID:             MessageTypeMilestoneConeRequest,
+		MaxBytesLength: RequestedMilestoneIndexMsgBytesLength,
+		VariableLength: false,
+	}
+
+	// The extended heartbeat packet.
+	// Contains the legacy heartbeat followed by a version and the fields of that version.
+	ExtendedHeartbeatMessageDefinition = &message.Definition{
+		ID:             MessageTypeExtendedHeartbeat,
+		MaxBytesLength: ExtendedHeartbeatMaxBytesLength,
+		VariableLength: true,
+	}
 )
 
 // NewTransactionMessage creates a new transaction message.
@@ -121,6 +232,101 @@ func NewTransactionRequestMessage(requestedHash aingle.Hash) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// NewTransactionRequestBatchMessage creates a transaction request batch message.
+func NewTransactionRequestBatchMessage(requestedHashes aingle.Hashes) ([]byte, error) {
+	if len(requestedHashes) > MaxTransactionRequestBatchSize {
+		return nil, ErrTooManyRequestedTransactions
+	}
+
+	msgBytesLength := uint16(len(requestedHashes) * RequestedTransactionHashMsgBytesLength)
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeTransactionRequestBatch, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	for _, requestedHash := range requestedHashes {
+		if err := binary.Write(buf, binary.BigEndian, requestedHash[:RequestedTransactionHashMsgBytesLength]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExtractRequestedTransactionHashes extracts the requested transaction hashes from the given source.
+func ExtractRequestedTransactionHashes(source []byte) (aingle.Hashes, error) {
+	if len(source) == 0 || len(source)%RequestedTransactionHashMsgBytesLength != 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	requestedHashes := make(aingle.Hashes, 0, len(source)/RequestedTransactionHashMsgBytesLength)
+	for offset := 0; offset < len(source); offset += RequestedTransactionHashMsgBytesLength {
+		requestedHashes = append(requestedHashes, aingle.Hash(source[offset:offset+RequestedTransactionHashMsgBytesLength]))
+	}
+
+	return requestedHashes, nil
+}
+
+// NewTransactionBatchMessage creates a new transaction batch message.
+func NewTransactionBatchMessage(txsData [][]byte) ([]byte, error) {
+	if len(txsData) > MaxTransactionBatchSize {
+		return nil, ErrTooManyTransactions
+	}
+
+	msgBytesLength := 0
+	for _, txData := range txsData {
+		if len(txData) > TransactionBatchTxMaxBytesLength {
+			return nil, ErrInvalidSourceLength
+		}
+		msgBytesLength += TransactionBatchTxLengthBytesLength + len(txData)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, int(tlv.HeaderMessageDefinition.MaxBytesLength)+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeTransactionBatch, uint16(msgBytesLength)); err != nil {
+		return nil, err
+	}
+
+	for _, txData := range txsData {
+		if err := binary.Write(buf, binary.BigEndian, uint16(len(txData))); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Write(buf, binary.BigEndian, txData); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExtractTransactions extracts the transactions from the given transaction batch source.
+func ExtractTransactions(source []byte) ([][]byte, error) {
+	var txsData [][]byte
+
+	for offset := 0; offset < len(source); {
+		if len(txsData) == MaxTransactionBatchSize || len(source)-offset < TransactionBatchTxLengthBytesLength {
+			return nil, ErrInvalidSourceLength
+		}
+
+		txBytesLength := int(binary.BigEndian.Uint16(source[offset : offset+TransactionBatchTxLengthBytesLength]))
+		offset += TransactionBatchTxLengthBytesLength
+
+		if txBytesLength == 0 || txBytesLength > TransactionBatchTxMaxBytesLength || len(source)-offset < txBytesLength {
+			return nil, ErrInvalidSourceLength
+		}
+
+		// copy the transaction, so the source buffer is not referenced by the single transactions
+		txsData = append(txsData, append([]byte{}, source[offset:offset+txBytesLength]...))
+		offset += txBytesLength
+	}
+
+	if len(txsData) == 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return txsData, nil
+}
+
 // NewHeartbeatMessage creates a new heartbeat message.
 func NewHeartbeatMessage(solidMilestoneIndex milestone.Index, prunedMilestoneIndex milestone.Index, latestMilestoneIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8) ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+HeartbeatMessageDefinition.MaxBytesLength))
@@ -151,6 +357,45 @@ func NewHeartbeatMessage(solidMilestoneIndex milestone.Index, prunedMilestoneInd
 	return buf.Bytes(), nil
 }
 
+// NewExtendedHeartbeatMessage creates a new extended heartbeat message of the current version.
+func NewExtendedHeartbeatMessage(heartbeat *Heartbeat) ([]byte, error) {
+	if len(heartbeat.Alias) > ExtendedHeartbeatAliasMaxBytesLength {
+		return nil, ErrHeartbeatAliasTooLong
+	}
+
+	msgBytesLength := uint16(ExtendedHeartbeatMinBytesLength + len(heartbeat.Alias))
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeExtendedHeartbeat, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	fields := []interface{}{
+		heartbeat.SolidMilestoneIndex,
+		heartbeat.PrunedMilestoneIndex,
+		heartbeat.LatestMilestoneIndex,
+		clampUint8(heartbeat.ConnectedNeighbors),
+		clampUint8(heartbeat.SyncedNeighbors),
+		uint8(ExtendedHeartbeatVersion),
+		heartbeat.SnapshotMilestoneIndex,
+		heartbeat.FeatureSets,
+		clampUint32(heartbeat.TipPoolSize),
+		clampUint32(heartbeat.RequestQueueSize),
+		uint8(len(heartbeat.Alias)),
+	}
+
+	for _, field := range fields {
+		if err := binary.Write(buf, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := buf.WriteString(heartbeat.Alias); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // NewMilestoneRequestMessage creates a new milestone request message.
 func NewMilestoneRequestMessage(requestedMilestoneIndex milestone.Index) ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+MilestoneRequestMessageDefinition.MaxBytesLength))
@@ -165,6 +410,20 @@ func NewMilestoneRequestMessage(requestedMilestoneIndex milestone.Index) ([]byte
 	return buf.Bytes(), nil
 }
 
+// NewMilestoneConeRequestMessage creates a new milestone cone request message.
+func NewMilestoneConeRequestMessage(requestedMilestoneIndex milestone.Index) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+MilestoneConeRequestMessageDefinition.MaxBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMilestoneConeRequest, MilestoneConeRequestMessageDefinition.MaxBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, requestedMilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // ExtractRequestedMilestoneIndex extracts the requested milestone index from the given source.
 func ExtractRequestedMilestoneIndex(source []byte) (milestone.Index, error) {
 	if len(source) != 4 {
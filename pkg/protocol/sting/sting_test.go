@@ -0,0 +1,130 @@
+package sting_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/tlv"
+)
+
+func TestTransactionRequestBatchMessage(t *testing.T) {
+	hashes := aingle.Hashes{
+		aingle.Hash(bytes.Repeat([]byte{1}, sting.RequestedTransactionHashMsgBytesLength)),
+		aingle.Hash(bytes.Repeat([]byte{2}, sting.RequestedTransactionHashMsgBytesLength)),
+	}
+
+	msg, err := sting.NewTransactionRequestBatchMessage(hashes)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.MessageTypeTransactionRequestBatch, header.Definition.ID)
+	assert.Equal(t, uint16(2*sting.RequestedTransactionHashMsgBytesLength), header.MessageBytesLength)
+
+	requestedHashes, err := sting.ExtractRequestedTransactionHashes(msg[tlv.HeaderBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, hashes, requestedHashes)
+
+	_, err = sting.ExtractRequestedTransactionHashes(msg[tlv.HeaderBytesLength+1:])
+	assert.Error(t, err)
+
+	_, err = sting.NewTransactionRequestBatchMessage(make(aingle.Hashes, sting.MaxTransactionRequestBatchSize+1))
+	assert.Error(t, err)
+}
+
+func TestTransactionBatchMessage(t *testing.T) {
+	txsData := [][]byte{
+		bytes.Repeat([]byte{1}, 300),
+		bytes.Repeat([]byte{2}, sting.TransactionBatchTxMaxBytesLength),
+	}
+
+	msg, err := sting.NewTransactionBatchMessage(txsData)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.MessageTypeTransactionBatch, header.Definition.ID)
+	assert.Equal(t, uint16(len(msg)-tlv.HeaderBytesLength), header.MessageBytesLength)
+
+	extractedTxsData, err := sting.ExtractTransactions(msg[tlv.HeaderBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, txsData, extractedTxsData)
+
+	// truncated transaction
+	_, err = sting.ExtractTransactions(msg[tlv.HeaderBytesLength : len(msg)-1])
+	assert.Error(t, err)
+
+	// empty batch
+	_, err = sting.ExtractTransactions([]byte{})
+	assert.Error(t, err)
+
+	_, err = sting.NewTransactionBatchMessage(make([][]byte, sting.MaxTransactionBatchSize+1))
+	assert.Error(t, err)
+}
+
+func TestMilestoneConeRequestMessage(t *testing.T) {
+	msg, err := sting.NewMilestoneConeRequestMessage(1337)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.MessageTypeMilestoneConeRequest, header.Definition.ID)
+
+	msIndex, err := sting.ExtractRequestedMilestoneIndex(msg[tlv.HeaderBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(1337), msIndex)
+}
+
+func TestExtendedHeartbeatMessage(t *testing.T) {
+	heartbeat := &sting.Heartbeat{
+		SolidMilestoneIndex:    100,
+		PrunedMilestoneIndex:   10,
+		LatestMilestoneIndex:   101,
+		ConnectedNeighbors:     5,
+		SyncedNeighbors:        4,
+		Version:                sting.ExtendedHeartbeatVersion,
+		SnapshotMilestoneIndex: 20,
+		FeatureSets:            sting.FeatureSet | sting.FeatureSetExtendedHeartbeat,
+		TipPoolSize:            42,
+		RequestQueueSize:       7,
+		Alias:                  "node-1",
+	}
+
+	msg, err := sting.NewExtendedHeartbeatMessage(heartbeat)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.MessageTypeExtendedHeartbeat, header.Definition.ID)
+	assert.Equal(t, uint16(sting.ExtendedHeartbeatMinBytesLength+len(heartbeat.Alias)), header.MessageBytesLength)
+
+	parsed, err := sting.ParseExtendedHeartbeat(msg[tlv.HeaderBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, heartbeat, parsed)
+
+	// the legacy part is still readable by the legacy parser
+	legacy := sting.ParseHeartbeat(msg[tlv.HeaderBytesLength:])
+	assert.Equal(t, heartbeat.SolidMilestoneIndex, legacy.SolidMilestoneIndex)
+	assert.Equal(t, heartbeat.SyncedNeighbors, legacy.SyncedNeighbors)
+
+	// fields appended by newer versions are ignored
+	newerVersion := append([]byte{}, msg[tlv.HeaderBytesLength:]...)
+	newerVersion[sting.HeartbeatMessageDefinition.MaxBytesLength] = sting.ExtendedHeartbeatVersion + 1
+	newerVersion = append(newerVersion, 1, 2, 3)
+	parsed, err = sting.ParseExtendedHeartbeat(newerVersion)
+	assert.NoError(t, err)
+	assert.Equal(t, heartbeat.Alias, parsed.Alias)
+	assert.Equal(t, uint8(sting.ExtendedHeartbeatVersion+1), parsed.Version)
+
+	_, err = sting.ParseExtendedHeartbeat(msg[tlv.HeaderBytesLength : len(msg)-1])
+	assert.Error(t, err)
+
+	heartbeat.Alias = string(bytes.Repeat([]byte{'a'}, sting.ExtendedHeartbeatAliasMaxBytesLength+1))
+	_, err = sting.NewExtendedHeartbeatMessage(heartbeat)
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket limits the rate of events to a given amount per minute.
+// The bucket holds at most one minute worth of tokens, so bursts up to the per minute limit are allowed.
+type TokenBucket struct {
+	mu sync.Mutex
+	// perMinute is the amount of tokens which are refilled every minute.
+	perMinute int
+	// tokens is the amount of tokens currently in the bucket.
+	tokens float64
+	// lastRefill is the time the tokens were refilled the last time.
+	lastRefill time.Time
+}
+
+// NewTokenBucket creates a full bucket which refills the given amount of tokens per minute.
+func NewTokenBucket(perMinute int) *TokenBucket {
+	return &TokenBucket{
+		perMinute:  perMinute,
+		tokens:     float64(perMinute),
+		lastRefill: time.Now(),
+	}
+}
+
+// PerMinute returns the amount of tokens which are refilled every minute.
+func (b *TokenBucket) PerMinute() int {
+	return b.perMinute
+}
+
+// Take takes the given amount of tokens out of the bucket.
+// If there are not enough tokens, nothing is taken and the duration until
+// enough tokens are available is returned.
+func (b *TokenBucket) Take(count int) (bool, time.Duration) {
+	return b.takeAt(count, time.Now())
+}
+
+func (b *TokenBucket) takeAt(count int, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	perSecond := float64(b.perMinute) / 60
+
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens = math.Min(float64(b.perMinute), b.tokens+elapsed.Seconds()*perSecond)
+		b.lastRefill = now
+	}
+
+	if float64(count) <= b.tokens {
+		b.tokens -= float64(count)
+		return true, 0
+	}
+
+	if count > b.perMinute || perSecond == 0 {
+		// the bucket never holds enough tokens
+		return false, time.Minute
+	}
+
+	return false, time.Duration((float64(count) - b.tokens) / perSecond * float64(time.Second))
+}
+
+// Refund puts the given amount of tokens back into the bucket, e.g. if the event they were taken for failed.
+// The bucket does not hold more than the per minute limit afterwards.
+func (b *TokenBucket) Refund(count int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(float64(b.perMinute), b.tokens+float64(count))
+}
+
+// TokenBuckets holds a token bucket per key, e.g. to limit the rate of events per peer.
+type TokenBuckets struct {
+	mu sync.Mutex
+	// perMinute is the amount of tokens which are refilled every minute in every bucket.
+	perMinute int
+	buckets   map[string]*TokenBucket
+}
+
+// NewTokenBuckets creates token buckets which refill the given amount of tokens per minute.
+func NewTokenBuckets(perMinute int) *TokenBuckets {
+	return &TokenBuckets{
+		perMinute: perMinute,
+		buckets:   make(map[string]*TokenBucket),
+	}
+}
+
+// Take takes the given amount of tokens out of the bucket of the given key.
+// A full bucket is created for unknown keys.
+func (b *TokenBuckets) Take(key string, count int) (bool, time.Duration) {
+	return b.bucket(key).Take(count)
+}
+
+// Remove removes the bucket of the given key.
+func (b *TokenBuckets) Remove(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.buckets, key)
+}
+
+func (b *TokenBuckets) bucket(key string) *TokenBucket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket, exists := b.buckets[key]
+	if !exists {
+		bucket = NewTokenBucket(b.perMinute)
+		b.buckets[key] = bucket
+	}
+	return bucket
+}
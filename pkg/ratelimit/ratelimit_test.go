@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(60)
+	now := b.lastRefill
+
+	// a full bucket allows a burst of the per minute limit
+	ok, _ := b.takeAt(50, now)
+	assert.True(t, ok)
+	ok, _ = b.takeAt(10, now)
+	assert.True(t, ok)
+
+	ok, retryAfter := b.takeAt(2, now)
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// one token is refilled every second
+	ok, _ = b.takeAt(2, now.Add(2*time.Second))
+	assert.True(t, ok)
+
+	// the bucket does not hold more than the per minute limit
+	ok, _ = b.takeAt(61, now.Add(time.Hour))
+	assert.False(t, ok)
+	ok, _ = b.takeAt(60, now.Add(time.Hour))
+	assert.True(t, ok)
+
+	// refunded tokens can be taken again
+	b.Refund(10)
+	ok, _ = b.takeAt(10, now.Add(time.Hour))
+	assert.True(t, ok)
+	ok, _ = b.takeAt(1, now.Add(time.Hour))
+	assert.False(t, ok)
+
+	// refunds do not exceed the per minute limit
+	b.Refund(100)
+	ok, _ = b.takeAt(61, now.Add(time.Hour))
+	assert.False(t, ok)
+	ok, _ = b.takeAt(60, now.Add(time.Hour))
+	assert.True(t, ok)
+}
+
+func TestTokenBuckets(t *testing.T) {
+	b := NewTokenBuckets(2)
+
+	// every key has its own bucket
+	for _, key := range []string{"a", "b"} {
+		ok, _ := b.Take(key, 2)
+		assert.True(t, ok, key)
+		ok, _ = b.Take(key, 1)
+		assert.False(t, ok, key)
+	}
+
+	// removed keys start with a full bucket again
+	b.Remove("a")
+	ok, _ := b.Take("a", 2)
+	assert.True(t, ok)
+	ok, _ = b.Take("b", 1)
+	assert.False(t, ok)
+}
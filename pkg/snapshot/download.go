@@ -0,0 +1,464 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+const (
+	// sourceFileSuffix is appended to the path of a partial download to store the URL it was downloaded from
+	// and the validator (ETag or Last-Modified) of the downloaded file.
+	sourceFileSuffix = ".source"
+
+	// infoRangeBytesLength is the amount of bytes of the compressed snapshot file which are requested to read the snapshot info.
+	infoRangeBytesLength = 64 * 1024
+)
+
+var (
+	// ErrNoValidSource is returned if no source could provide a valid snapshot file.
+	ErrNoValidSource = errors.New("no valid source found")
+	// ErrNotEnoughSources is returned if less sources than required could be queried.
+	ErrNotEnoughSources = errors.New("not enough sources available")
+	// ErrSourcesMismatch is returned if the sources do not serve the same snapshot.
+	ErrSourcesMismatch = errors.New("sources serve different snapshots")
+	// ErrPinnedHashMismatch is returned if a source does not serve the snapshot with the pinned hash.
+	ErrPinnedHashMismatch = errors.New("snapshot does not match the pinned hash")
+	// ErrPinnedFileHashMismatch is returned if the downloaded file does not match the pinned file hash.
+	ErrPinnedFileHashMismatch = errors.New("snapshot file does not match the pinned file hash")
+)
+
+// DownloadOptions define how a snapshot file is downloaded.
+type DownloadOptions struct {
+	// PinnedHash is the expected snapshot milestone hash.
+	// If it is set, only the sources serving the snapshot with this hash are used.
+	PinnedHash aingle.Hash
+	// MinSources is the minimum amount of sources which must serve the same snapshot if no hash is pinned.
+	// If it is not set, 2 sources are required if more than one URL is given.
+	MinSources int
+	// PinnedFileHash is the expected SHA-256 digest of the snapshot file.
+	// If it is set, the downloaded file is checked against it instead of the files served by the other sources.
+	PinnedFileHash []byte
+	// Client is the HTTP client used for the requests. http.DefaultClient is used if it is nil.
+	Client *http.Client
+	// OnProgress is called while a file is downloaded.
+	OnProgress func(url string, written uint64, total uint64)
+	// OnSourceError is called for every source which could not be used.
+	OnSourceError func(url string, err error)
+	// AbortSignal aborts the download.
+	AbortSignal <-chan struct{}
+}
+
+type snapshotSource struct {
+	url  string
+	info *tangle.SnapshotInfo
+}
+
+// progressWriter reports the progress of a download and aborts it if the abort signal is triggered.
+type progressWriter struct {
+	url         string
+	written     uint64
+	total       uint64
+	onProgress  func(url string, written uint64, total uint64)
+	abortSignal <-chan struct{}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	if isAborted(w.abortSignal) {
+		return 0, ErrOperationAborted
+	}
+
+	w.written += uint64(len(p))
+	if w.onProgress != nil {
+		w.onProgress(w.url, w.written, w.total)
+	}
+	return len(p), nil
+}
+
+func sameSnapshot(a *tangle.SnapshotInfo, b *tangle.SnapshotInfo) bool {
+	return a.SnapshotIndex == b.SnapshotIndex && bytes.Equal(a.Hash, b.Hash)
+}
+
+// Download downloads a gzip compressed snapshot file to the given path.
+//
+// The snapshot info of all sources is queried first. If a hash is pinned, only the sources serving that snapshot
+// are used. Otherwise at least MinSources sources must be reachable and all of them must serve the same snapshot.
+// The file is downloaded from the first matching source which provides a valid file.
+// If no file hash is pinned, the SHA-256 digest of the downloaded file is compared to the files
+// served by the other matching sources, which are streamed but not stored.
+// Partial downloads are resumed with HTTP range requests if the same source is used again.
+func Download(filePath string, urls []string, opts *DownloadOptions) (*tangle.SnapshotInfo, error) {
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	onSourceError := func(url string, err error) {
+		if opts.OnSourceError != nil {
+			opts.OnSourceError(url, err)
+		}
+	}
+
+	var sources []*snapshotSource
+	for _, url := range urls {
+		if isAborted(opts.AbortSignal) {
+			return nil, ErrOperationAborted
+		}
+
+		info, err := fetchInfo(client, url)
+		if err != nil {
+			onSourceError(url, err)
+			continue
+		}
+		sources = append(sources, &snapshotSource{url: url, info: info})
+	}
+
+	// the min amount of sources which must serve the identical file
+	minSources := 1
+
+	var candidates []*snapshotSource
+	if len(opts.PinnedHash) > 0 {
+		for _, source := range sources {
+			if !bytes.Equal(source.info.Hash, opts.PinnedHash) {
+				onSourceError(source.url, errors.Wrapf(ErrPinnedHashMismatch, "snapshot index %d (%v)", source.info.SnapshotIndex, source.info.Hash.Trytes()))
+				continue
+			}
+			candidates = append(candidates, source)
+		}
+
+		if len(candidates) == 0 {
+			return nil, errors.Wrapf(ErrNoValidSource, "no source serves the pinned snapshot %v", opts.PinnedHash.Trytes())
+		}
+	} else {
+		switch {
+		case opts.MinSources > 0:
+			minSources = opts.MinSources
+		case len(urls) > 1:
+			// a single source must not decide about the snapshot if there are others to compare it with
+			minSources = 2
+		}
+
+		if len(sources) < minSources {
+			return nil, errors.Wrapf(ErrNotEnoughSources, "available: %d, required: %d", len(sources), minSources)
+		}
+
+		for _, source := range sources[1:] {
+			if !sameSnapshot(sources[0].info, source.info) {
+				return nil, errors.Wrapf(ErrSourcesMismatch, "%s: %d (%v), %s: %d (%v)",
+					sources[0].url, sources[0].info.SnapshotIndex, sources[0].info.Hash.Trytes(),
+					source.url, source.info.SnapshotIndex, source.info.Hash.Trytes())
+			}
+		}
+		candidates = sources
+	}
+
+	for _, source := range candidates {
+		if err := downloadFile(client, filePath, source.url, opts); err != nil {
+			if err == ErrOperationAborted {
+				return nil, err
+			}
+			// the partial file is kept to resume the download from the same source later
+			onSourceError(source.url, err)
+			continue
+		}
+
+		info, err := verifyFile(filePath, opts.AbortSignal)
+		if err == nil && !sameSnapshot(info, source.info) {
+			err = errors.Wrapf(ErrSourcesMismatch, "announced: %d (%v), downloaded: %d (%v)", source.info.SnapshotIndex, source.info.Hash.Trytes(), info.SnapshotIndex, info.Hash.Trytes())
+		}
+
+		var digest []byte
+		if err == nil {
+			digest, err = fileDigest(filePath)
+		}
+
+		if err == nil && len(opts.PinnedFileHash) > 0 && !bytes.Equal(digest, opts.PinnedFileHash) {
+			err = errors.Wrapf(ErrPinnedFileHashMismatch, "expected: %x, downloaded: %x", opts.PinnedFileHash, digest)
+		}
+
+		if err != nil {
+			if err == ErrOperationAborted {
+				return nil, err
+			}
+			os.Remove(filePath)
+			os.Remove(filePath + sourceFileSuffix)
+			onSourceError(source.url, err)
+			continue
+		}
+
+		if len(opts.PinnedFileHash) == 0 {
+			others := make([]*snapshotSource, 0, len(candidates)-1)
+			for _, other := range candidates {
+				if other != source {
+					others = append(others, other)
+				}
+			}
+
+			if err := checkFileDigests(client, digest, source.url, others, minSources, onSourceError, opts); err != nil {
+				if err != ErrOperationAborted {
+					// the file is not trusted
+					os.Remove(filePath)
+				}
+				os.Remove(filePath + sourceFileSuffix)
+				return nil, err
+			}
+		}
+
+		os.Remove(filePath + sourceFileSuffix)
+		return info, nil
+	}
+
+	return nil, ErrNoValidSource
+}
+
+// fetchInfo reads the snapshot info of the snapshot file served at the given URL.
+// Only the beginning of the file is requested. If the source doesn't support range requests,
+// the transfer is stopped once the snapshot info was read.
+func fetchInfo(client *http.Client, url string) (*tangle.SnapshotInfo, error) {
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", infoRangeBytesLength-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+
+	gzipReader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	return ReadInfo(gzipReader)
+}
+
+// checkFileDigests checks that the other sources serve a file with the given SHA-256 digest.
+// Sources which can't be reached during the check don't count towards the min amount of sources.
+func checkFileDigests(client *http.Client, digest []byte, url string, others []*snapshotSource, minSources int, onSourceError func(url string, err error), opts *DownloadOptions) error {
+
+	identical := 1
+	for _, other := range others {
+		otherDigest, err := fetchFileDigest(client, other.url, opts)
+		if err != nil {
+			if err == ErrOperationAborted {
+				return err
+			}
+			onSourceError(other.url, err)
+			continue
+		}
+
+		if !bytes.Equal(digest, otherDigest) {
+			return errors.Wrapf(ErrSourcesMismatch, "file digest %s: %x, %s: %x", url, digest, other.url, otherDigest)
+		}
+		identical++
+	}
+
+	if identical < minSources {
+		return errors.Wrapf(ErrNotEnoughSources, "sources serving the identical file: %d, required: %d", identical, minSources)
+	}
+	return nil
+}
+
+// fetchFileDigest streams the file served at the given URL and returns its SHA-256 digest.
+func fetchFileDigest(client *http.Client, url string, opts *DownloadOptions) ([]byte, error) {
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+
+	progress := &progressWriter{
+		url:         url,
+		onProgress:  opts.OnProgress,
+		abortSignal: opts.AbortSignal,
+	}
+	if resp.ContentLength > 0 {
+		progress.total = uint64(resp.ContentLength)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.TeeReader(resp.Body, progress)); err != nil {
+		if errors.Is(err, ErrOperationAborted) {
+			return nil, ErrOperationAborted
+		}
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// fileDigest returns the SHA-256 digest of the given file.
+func fileDigest(filePath string) ([]byte, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// verifyFile checks the structure and the checksum of a gzip compressed snapshot file and returns its snapshot info.
+func verifyFile(filePath string, abortSignal <-chan struct{}) (*tangle.SnapshotInfo, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	var info *tangle.SnapshotInfo
+	if err := Read(gzipReader, &Consumers{
+		Info: func(i *tangle.SnapshotInfo) error {
+			info = i
+			return nil
+		},
+	}, abortSignal); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// readPartialDownloadSource returns the URL and the validator of the file a partial download was downloaded from.
+func readPartialDownloadSource(filePath string) (url string, validator string) {
+	source, err := ioutil.ReadFile(filePath + sourceFileSuffix)
+	if err != nil {
+		return "", ""
+	}
+
+	lines := strings.SplitN(string(source), "\n", 2)
+	if len(lines) < 2 {
+		return lines[0], ""
+	}
+	return lines[0], lines[1]
+}
+
+// rangeValidator returns the validator of the served file which is sent within If-Range headers.
+// Weak ETags can't be used in If-Range headers, Last-Modified is used instead.
+func rangeValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// downloadFile downloads the file at the given URL.
+// If a partial file of the same source exists, the download is resumed with a range request.
+// The range is only served if the file wasn't changed since the partial download, otherwise the whole file is downloaded.
+func downloadFile(client *http.Client, filePath string, url string, opts *DownloadOptions) error {
+
+	var offset int64
+	sourceURL, validator := readPartialDownloadSource(filePath)
+	if sourceURL == url && validator != "" {
+		if fileInfo, err := os.Stat(filePath); err == nil {
+			offset = fileInfo.Size()
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	fileFlags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != offset {
+			return fmt.Errorf("invalid content range: %s", resp.Header.Get("Content-Range"))
+		}
+		fileFlags |= os.O_APPEND
+
+	case http.StatusOK:
+		// the source does not support range requests or the file changed, start from the beginning
+		offset = 0
+		fileFlags |= os.O_TRUNC
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is already complete
+		return nil
+
+	default:
+		return fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+
+	// a partial download is only resumed if the source provides a validator of the file
+	if err := ioutil.WriteFile(filePath+sourceFileSuffix, []byte(url+"\n"+rangeValidator(resp)), 0660); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(filePath, fileFlags, 0660)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	progress := &progressWriter{
+		url:         url,
+		written:     uint64(offset),
+		onProgress:  opts.OnProgress,
+		abortSignal: opts.AbortSignal,
+	}
+	if resp.ContentLength > 0 {
+		progress.total = uint64(offset + resp.ContentLength)
+	}
+
+	if _, err := io.Copy(out, io.TeeReader(resp.Body, progress)); err != nil {
+		if errors.Is(err, ErrOperationAborted) {
+			return ErrOperationAborted
+		}
+		return err
+	}
+
+	return out.Close()
+}
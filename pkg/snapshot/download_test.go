@@ -0,0 +1,272 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+)
+
+// gzipSnapshot creates a gzip compressed snapshot file for the given snapshot milestone.
+func gzipSnapshot(t *testing.T, msHash byte, msIndex milestone.Index) []byte {
+	return gzipSnapshotWithBalanceOffset(t, msHash, msIndex, 0)
+}
+
+// gzipSnapshotWithBalanceOffset creates a gzip compressed snapshot file for the given snapshot milestone,
+// in which the balance of the first address is raised by the given offset.
+func gzipSnapshotWithBalanceOffset(t *testing.T, msHash byte, msIndex milestone.Index, balanceOffset uint64) []byte {
+	info := &tangle.SnapshotInfo{
+		CoordinatorAddress: aingle.Hash(testHash(1)),
+		Hash:               aingle.Hash(testHash(msHash)),
+		SnapshotIndex:      msIndex,
+		EntryPointIndex:    msIndex,
+		PruningIndex:       msIndex,
+	}
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+
+	w, err := snapshot.NewWriter(gzipWriter, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteInfo(info))
+	assert.NoError(t, w.WriteSolidEntryPoints(map[string]milestone.Index{}))
+	assert.NoError(t, w.WriteSeenMilestones(map[string]milestone.Index{}))
+
+	// enough balances to make a partial download worthwhile
+	balances := make(map[string]uint64)
+	for i := 0; i < 1000; i++ {
+		address := make([]byte, snapshot.HashLength)
+		address[0], address[1] = byte(i), byte(i>>8)
+		balances[string(address)] = uint64(i)
+	}
+	balances[string(make([]byte, snapshot.HashLength))] += balanceOffset
+	assert.NoError(t, w.WriteBalances(balances))
+	assert.NoError(t, w.WriteLedgerDiffs(map[milestone.Index]map[string]int64{}))
+	assert.NoError(t, w.WriteSpentAddresses(0, nil))
+	assert.NoError(t, w.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
+
+type testSource struct {
+	*httptest.Server
+	sync.Mutex
+	data           []byte
+	etag           string
+	infoRequests   int
+	resumeRequests int
+}
+
+func newTestSource(data []byte) *testSource {
+	source := &testSource{data: data, etag: `"1"`}
+	source.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source.Lock()
+		if strings.HasPrefix(r.Header.Get("Range"), "bytes=0-") {
+			source.infoRequests++
+		}
+		if r.Header.Get("If-Range") != "" {
+			source.resumeRequests++
+		}
+		data, etag := source.data, source.etag
+		source.Unlock()
+
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "export.bin", time.Time{}, bytes.NewReader(data))
+	}))
+	return source
+}
+
+// update changes the file served by the source.
+func (s *testSource) update(data []byte, etag string) {
+	s.Lock()
+	defer s.Unlock()
+	s.data, s.etag = data, etag
+}
+
+func tempFilePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	return filepath.Join(dir, "export.bin.tmp"), func() { os.RemoveAll(dir) }
+}
+
+func TestDownloadMultipleSources(t *testing.T) {
+	data := gzipSnapshot(t, 2, 100)
+
+	source1 := newTestSource(data)
+	defer source1.Close()
+	source2 := newTestSource(data)
+	defer source2.Close()
+
+	filePath, cleanup := tempFilePath(t)
+	defer cleanup()
+
+	info, err := snapshot.Download(filePath, []string{source1.URL, source2.URL}, &snapshot.DownloadOptions{MinSources: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(100), info.SnapshotIndex)
+
+	downloaded, err := ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+
+	// only the beginning of the file is requested to read the snapshot info
+	assert.Equal(t, 1, source1.infoRequests)
+	assert.Equal(t, 1, source2.infoRequests)
+
+	// not enough sources
+	_, err = snapshot.Download(filePath, []string{source1.URL, "http://127.0.0.1:1/export.bin"}, &snapshot.DownloadOptions{MinSources: 2})
+	assert.True(t, errors.Is(err, snapshot.ErrNotEnoughSources))
+
+	// 2 sources are required by default if multiple sources are given
+	_, err = snapshot.Download(filePath, []string{source1.URL, "http://127.0.0.1:1/export.bin"}, &snapshot.DownloadOptions{})
+	assert.True(t, errors.Is(err, snapshot.ErrNotEnoughSources))
+
+	_, err = snapshot.Download(filePath, []string{source1.URL}, &snapshot.DownloadOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDownloadSourcesMismatch(t *testing.T) {
+	honest := newTestSource(gzipSnapshot(t, 2, 100))
+	defer honest.Close()
+	stale := newTestSource(gzipSnapshot(t, 3, 90))
+	defer stale.Close()
+
+	filePath, cleanup := tempFilePath(t)
+	defer cleanup()
+
+	_, err := snapshot.Download(filePath, []string{honest.URL, stale.URL}, &snapshot.DownloadOptions{})
+	assert.True(t, errors.Is(err, snapshot.ErrSourcesMismatch))
+
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	// the pinned hash selects the matching source
+	var sourceErrors []string
+	info, err := snapshot.Download(filePath, []string{stale.URL, honest.URL}, &snapshot.DownloadOptions{
+		PinnedHash: aingle.Hash(testHash(2)),
+		OnSourceError: func(url string, err error) {
+			assert.True(t, errors.Is(err, snapshot.ErrPinnedHashMismatch))
+			sourceErrors = append(sourceErrors, url)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(100), info.SnapshotIndex)
+	assert.Equal(t, []string{stale.URL}, sourceErrors)
+
+	// no source serves the pinned hash
+	_, err = snapshot.Download(filePath, []string{stale.URL}, &snapshot.DownloadOptions{PinnedHash: aingle.Hash(testHash(2))})
+	assert.True(t, errors.Is(err, snapshot.ErrNoValidSource))
+}
+
+func TestDownloadResume(t *testing.T) {
+	data := gzipSnapshot(t, 2, 100)
+
+	source := newTestSource(data)
+	defer source.Close()
+
+	filePath, cleanup := tempFilePath(t)
+	defer cleanup()
+
+	// simulate an interrupted download of the same source
+	assert.NoError(t, ioutil.WriteFile(filePath, data[:len(data)/2], 0660))
+	assert.NoError(t, ioutil.WriteFile(filePath+".source", []byte(source.URL+"\n"+source.etag), 0660))
+
+	_, err := snapshot.Download(filePath, []string{source.URL}, &snapshot.DownloadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.resumeRequests)
+
+	downloaded, err := ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+
+	_, err = os.Stat(filePath + ".source")
+	assert.True(t, os.IsNotExist(err))
+
+	// the file changed since the partial download, the whole file is downloaded
+	updated := gzipSnapshot(t, 3, 110)
+	source.update(updated, `"2"`)
+	assert.NoError(t, ioutil.WriteFile(filePath, data[:len(data)/2], 0660))
+	assert.NoError(t, ioutil.WriteFile(filePath+".source", []byte(source.URL+"\n"+`"1"`), 0660))
+
+	info, err := snapshot.Download(filePath, []string{source.URL}, &snapshot.DownloadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(110), info.SnapshotIndex)
+	assert.Equal(t, 2, source.resumeRequests)
+
+	downloaded, err = ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, downloaded)
+
+	// a partial download without a validator of the file is not resumed
+	assert.NoError(t, ioutil.WriteFile(filePath, updated[:len(updated)/2], 0660))
+	assert.NoError(t, ioutil.WriteFile(filePath+".source", []byte(source.URL), 0660))
+
+	_, err = snapshot.Download(filePath, []string{source.URL}, &snapshot.DownloadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, source.resumeRequests)
+
+	// a corrupted partial download is detected and removed
+	corrupted := append([]byte{}, updated[:len(updated)/2]...)
+	corrupted[len(corrupted)-1] ^= 0xFF
+	assert.NoError(t, ioutil.WriteFile(filePath, corrupted, 0660))
+	assert.NoError(t, ioutil.WriteFile(filePath+".source", []byte(source.URL+"\n"+`"2"`), 0660))
+
+	_, err = snapshot.Download(filePath, []string{source.URL}, &snapshot.DownloadOptions{})
+	assert.True(t, errors.Is(err, snapshot.ErrNoValidSource))
+
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadFileDigestMismatch(t *testing.T) {
+	data := gzipSnapshot(t, 2, 100)
+
+	honest := newTestSource(data)
+	defer honest.Close()
+	// announces the same snapshot milestone, but serves a different ledger state
+	tampered := newTestSource(gzipSnapshotWithBalanceOffset(t, 2, 100, 1000))
+	defer tampered.Close()
+
+	filePath, cleanup := tempFilePath(t)
+	defer cleanup()
+
+	_, err := snapshot.Download(filePath, []string{tampered.URL, honest.URL}, &snapshot.DownloadOptions{MinSources: 2})
+	assert.True(t, errors.Is(err, snapshot.ErrSourcesMismatch))
+
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	// the pinned file hash selects the honest source
+	digest := sha256.Sum256(data)
+	var sourceErrors []string
+	info, err := snapshot.Download(filePath, []string{tampered.URL, honest.URL}, &snapshot.DownloadOptions{
+		PinnedFileHash: digest[:],
+		OnSourceError: func(url string, err error) {
+			assert.True(t, errors.Is(err, snapshot.ErrPinnedFileHashMismatch))
+			sourceErrors = append(sourceErrors, url)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(100), info.SnapshotIndex)
+	assert.Equal(t, []string{tampered.URL}, sourceErrors)
+
+	downloaded, err := ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+}
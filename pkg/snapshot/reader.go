@@ -0,0 +1,407 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+// Consumers are called for the content of a snapshot file while it is read.
+// A nil consumer skips the corresponding entries.
+//
+// Caution: the checksum can only be checked after the whole file was read,
+// so the consumers may be called with content of a corrupted file.
+// Use Verify before applying the content of a snapshot file.
+type Consumers struct {
+	Info            func(info *tangle.SnapshotInfo) error
+	DeltaBase       func(index milestone.Index, hash aingle.Hash) error
+	SolidEntryPoint func(hash aingle.Hash, index milestone.Index) error
+	SeenMilestone   func(hash aingle.Hash, index milestone.Index) error
+	Balance         func(address aingle.Hash, balance uint64) error
+	LedgerDiff      func(index milestone.Index, diff map[string]int64) error
+	SpentAddress    func(address aingle.Hash) error
+}
+
+// consumerError wraps errors returned by the consumers, so they are not mistaken for malformed sections.
+type consumerError struct {
+	err error
+}
+
+func (e *consumerError) Error() string {
+	return e.err.Error()
+}
+
+type sectionReaderFunc func(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error
+
+var sectionReaders = map[SectionType]sectionReaderFunc{
+	SectionInfo:             readInfoSection,
+	SectionSolidEntryPoints: readSolidEntryPointsSection,
+	SectionSeenMilestones:   readSeenMilestonesSection,
+	SectionBalances:         readBalancesSection,
+	SectionLedgerDiffs:      readLedgerDiffsSection,
+	SectionSpentAddresses:   readSpentAddressesSection,
+	SectionDeltaBase:        readDeltaBaseSection,
+}
+
+// Verify reads the whole snapshot file and checks its structure and checksum without consuming the content.
+func Verify(reader io.Reader, abortSignal <-chan struct{}) error {
+	return Read(reader, nil, abortSignal)
+}
+
+// Read streams the content of a snapshot file to the given consumers.
+// It returns an error if the file is malformed or the checksum does not match the content.
+// Errors returned by the consumers are passed through unchanged.
+func Read(reader io.Reader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	return read(reader, FileMagic, sectionOrder, consumers, abortSignal)
+}
+
+// VerifyDelta reads the whole delta snapshot file and checks its structure and checksum without consuming the content.
+func VerifyDelta(reader io.Reader, abortSignal <-chan struct{}) error {
+	return ReadDelta(reader, nil, abortSignal)
+}
+
+// ReadDelta streams the content of a delta snapshot file to the given consumers.
+// It returns an error if the file is malformed or the checksum does not match the content.
+// Errors returned by the consumers are passed through unchanged.
+func ReadDelta(reader io.Reader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	return read(reader, DeltaFileMagic, deltaSectionOrder, consumers, abortSignal)
+}
+
+func read(reader io.Reader, fileMagic [4]byte, order []SectionType, consumers *Consumers, abortSignal <-chan struct{}) error {
+
+	if consumers == nil {
+		consumers = &Consumers{}
+	}
+
+	bufReader := bufio.NewReader(reader)
+	hasher := sha256.New()
+
+	// everything except the checksum is part of the hash
+	body := io.TeeReader(bufReader, hasher)
+
+	if err := readFileHeader(body, fileMagic); err != nil {
+		return err
+	}
+
+	for _, expectedSectionType := range order {
+		var sectionType SectionType
+		var sectionLength uint64
+
+		if err := binary.Read(body, binary.LittleEndian, &sectionType); err != nil {
+			return errors.Wrapf(ErrInvalidSection, "reading section '%v' failed: %v", expectedSectionType, err)
+		}
+
+		if sectionType != expectedSectionType {
+			return errors.Wrapf(ErrInvalidSection, "expected section '%v', got '%v'", expectedSectionType, sectionType)
+		}
+
+		if err := binary.Read(body, binary.LittleEndian, &sectionLength); err != nil {
+			return errors.Wrapf(ErrInvalidSection, "reading length of section '%v' failed: %v", sectionType, err)
+		}
+
+		sectionReader := &io.LimitedReader{R: body, N: int64(sectionLength)}
+		if err := sectionReaders[sectionType](sectionReader, consumers, abortSignal); err != nil {
+			if err == ErrOperationAborted {
+				return err
+			}
+			if cErr, ok := err.(*consumerError); ok {
+				return cErr.err
+			}
+			return errors.Wrapf(ErrInvalidSection, "section '%v': %v", sectionType, err)
+		}
+
+		if sectionReader.N != 0 {
+			return errors.Wrapf(ErrInvalidSection, "section '%v' has %d unread bytes", sectionType, sectionReader.N)
+		}
+	}
+
+	checksum := make([]byte, ChecksumLength)
+	if _, err := io.ReadFull(bufReader, checksum); err != nil {
+		return errors.Wrapf(ErrChecksumMismatch, "reading checksum failed: %v", err)
+	}
+
+	if !bytes.Equal(checksum, hasher.Sum(nil)) {
+		return ErrChecksumMismatch
+	}
+
+	if _, err := bufReader.ReadByte(); err != io.EOF {
+		return ErrTrailingData
+	}
+
+	return nil
+}
+
+// readFileHeader reads the magic and the version of a snapshot file.
+func readFileHeader(reader io.Reader, fileMagic [4]byte) error {
+	var magic [4]byte
+	if _, err := io.ReadFull(reader, magic[:]); err != nil {
+		return errors.Wrapf(ErrInvalidMagic, "reading magic failed: %v", err)
+	}
+
+	if magic != fileMagic {
+		return ErrInvalidMagic
+	}
+
+	var version byte
+	if err := binary.Read(reader, binary.LittleEndian, &version); err != nil {
+		return errors.Wrapf(ErrUnsupportedVersion, "reading version failed: %v", err)
+	}
+
+	if version != FileVersion {
+		return errors.Wrapf(ErrUnsupportedVersion, "file version is %d, supported version is %d", version, FileVersion)
+	}
+
+	return nil
+}
+
+// ReadInfo reads only the header and the snapshot info of a snapshot file.
+// The checksum of the file is not checked.
+func ReadInfo(reader io.Reader) (*tangle.SnapshotInfo, error) {
+
+	if err := readFileHeader(reader, FileMagic); err != nil {
+		return nil, err
+	}
+
+	var sectionType SectionType
+	var sectionLength uint64
+
+	if err := binary.Read(reader, binary.LittleEndian, &sectionType); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSection, "reading section '%v' failed: %v", SectionInfo, err)
+	}
+
+	if sectionType != SectionInfo {
+		return nil, errors.Wrapf(ErrInvalidSection, "expected section '%v', got '%v'", SectionInfo, sectionType)
+	}
+
+	if err := binary.Read(reader, binary.LittleEndian, &sectionLength); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSection, "reading length of section '%v' failed: %v", sectionType, err)
+	}
+
+	var info *tangle.SnapshotInfo
+	if err := readInfoSection(&io.LimitedReader{R: reader, N: int64(sectionLength)}, &Consumers{
+		Info: func(i *tangle.SnapshotInfo) error {
+			info = i
+			return nil
+		},
+	}, nil); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSection, "section '%v': %v", sectionType, err)
+	}
+
+	return info, nil
+}
+
+// checkEntryLength checks that the remaining section length is a multiple of the entry length.
+func checkEntryLength(reader *io.LimitedReader, entryLength int64) error {
+	if reader.N%entryLength != 0 {
+		return errors.Errorf("length %d is not a multiple of the entry length %d", reader.N, entryLength)
+	}
+	return nil
+}
+
+func readInfoSection(reader *io.LimitedReader, consumers *Consumers, _ <-chan struct{}) error {
+	if reader.N != infoLength {
+		return errors.Errorf("invalid length %d != %d", reader.N, infoLength)
+	}
+
+	infoBytes := make([]byte, infoLength)
+	if _, err := io.ReadFull(reader, infoBytes); err != nil {
+		return err
+	}
+
+	info, err := tangle.SnapshotInfoFromBytes(infoBytes)
+	if err != nil {
+		return err
+	}
+
+	if consumers.Info == nil {
+		return nil
+	}
+	if err := consumers.Info(info); err != nil {
+		return &consumerError{err: err}
+	}
+	return nil
+}
+
+func readDeltaBaseSection(reader *io.LimitedReader, consumers *Consumers, _ <-chan struct{}) error {
+	if reader.N != deltaBaseLength {
+		return errors.Errorf("invalid length %d != %d", reader.N, deltaBaseLength)
+	}
+
+	var index uint32
+	if err := binary.Read(reader, binary.LittleEndian, &index); err != nil {
+		return err
+	}
+
+	hash := make([]byte, HashLength)
+	if _, err := io.ReadFull(reader, hash); err != nil {
+		return err
+	}
+
+	if consumers.DeltaBase == nil {
+		return nil
+	}
+
+	if err := consumers.DeltaBase(milestone.Index(index), aingle.Hash(hash)); err != nil {
+		return &consumerError{err: err}
+	}
+	return nil
+}
+
+func readHashIndexSection(reader *io.LimitedReader, consumer func(hash aingle.Hash, index milestone.Index) error, abortSignal <-chan struct{}) error {
+	if err := checkEntryLength(reader, hashIndexEntryLength); err != nil {
+		return err
+	}
+
+	for reader.N > 0 {
+		if isAborted(abortSignal) {
+			return ErrOperationAborted
+		}
+
+		hash := make([]byte, HashLength)
+		var index uint32
+
+		if _, err := io.ReadFull(reader, hash); err != nil {
+			return err
+		}
+
+		if err := binary.Read(reader, binary.LittleEndian, &index); err != nil {
+			return err
+		}
+
+		if consumer == nil {
+			continue
+		}
+
+		if err := consumer(aingle.Hash(hash), milestone.Index(index)); err != nil {
+			return &consumerError{err: err}
+		}
+	}
+
+	return nil
+}
+
+func readSolidEntryPointsSection(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	return readHashIndexSection(reader, consumers.SolidEntryPoint, abortSignal)
+}
+
+func readSeenMilestonesSection(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	return readHashIndexSection(reader, consumers.SeenMilestone, abortSignal)
+}
+
+func readBalancesSection(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	if err := checkEntryLength(reader, balanceEntryLength); err != nil {
+		return err
+	}
+
+	for reader.N > 0 {
+		if isAborted(abortSignal) {
+			return ErrOperationAborted
+		}
+
+		address := make([]byte, HashLength)
+		var balance uint64
+
+		if _, err := io.ReadFull(reader, address); err != nil {
+			return err
+		}
+
+		if err := binary.Read(reader, binary.LittleEndian, &balance); err != nil {
+			return err
+		}
+
+		if consumers.Balance == nil {
+			continue
+		}
+
+		if err := consumers.Balance(aingle.Hash(address), balance); err != nil {
+			return &consumerError{err: err}
+		}
+	}
+
+	return nil
+}
+
+func readLedgerDiffsSection(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error {
+
+	for reader.N > 0 {
+		var index uint32
+		var count uint32
+
+		if err := binary.Read(reader, binary.LittleEndian, &index); err != nil {
+			return err
+		}
+
+		if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+			return err
+		}
+
+		if int64(count)*ledgerDiffEntryLength > reader.N {
+			return errors.Errorf("ledger diff for milestone %d exceeds the section length", index)
+		}
+
+		diff := make(map[string]int64, count)
+		for i := uint32(0); i < count; i++ {
+			if isAborted(abortSignal) {
+				return ErrOperationAborted
+			}
+
+			address := make([]byte, HashLength)
+			var change int64
+
+			if _, err := io.ReadFull(reader, address); err != nil {
+				return err
+			}
+
+			if err := binary.Read(reader, binary.LittleEndian, &change); err != nil {
+				return err
+			}
+
+			diff[string(address)] = change
+		}
+
+		if consumers.LedgerDiff == nil {
+			continue
+		}
+
+		if err := consumers.LedgerDiff(milestone.Index(index), diff); err != nil {
+			return &consumerError{err: err}
+		}
+	}
+
+	return nil
+}
+
+func readSpentAddressesSection(reader *io.LimitedReader, consumers *Consumers, abortSignal <-chan struct{}) error {
+	if err := checkEntryLength(reader, spentAddressEntryLength); err != nil {
+		return err
+	}
+
+	for reader.N > 0 {
+		if isAborted(abortSignal) {
+			return ErrOperationAborted
+		}
+
+		address := make([]byte, HashLength)
+		if _, err := io.ReadFull(reader, address); err != nil {
+			return err
+		}
+
+		if consumers.SpentAddress == nil {
+			continue
+		}
+
+		if err := consumers.SpentAddress(aingle.Hash(address)); err != nil {
+			return &consumerError{err: err}
+		}
+	}
+
+	return nil
+}
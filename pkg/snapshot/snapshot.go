@@ -0,0 +1,122 @@
+// Package snapshot implements the encoding of local snapshot files.
+//
+// A local snapshot file is made up of the following parts (all integers are little endian):
+//
+//	magic        [4]byte  "AILS"
+//	version      byte     the version of the file format (see FileVersion)
+//	sections     ...      the sections of the file in the order given below
+//	checksum     [32]byte SHA-256 hash over all preceding bytes
+//
+// Every section starts with its type (byte) and the length of its payload in bytes (uint64):
+//
+//	SectionInfo              the bytes of a tangle.SnapshotInfo
+//	SectionSolidEntryPoints  (hash [49]byte, milestone index uint32) per entry
+//	SectionSeenMilestones    (hash [49]byte, milestone index uint32) per entry
+//	SectionBalances          (address [49]byte, balance uint64) per entry
+//	SectionLedgerDiffs       (milestone index uint32, count uint32, (address [49]byte, diff int64) * count) per milestone
+//	SectionSpentAddresses    (address [49]byte) per entry
+//
+// A delta snapshot file contains the changes between a base milestone A and a target milestone B.
+// It starts with DeltaFileMagic and contains the following sections:
+//
+//	SectionInfo              the bytes of a tangle.SnapshotInfo at the target milestone
+//	SectionDeltaBase         (milestone index uint32, milestone hash [49]byte) of the base milestone
+//	SectionSolidEntryPoints  the solid entry points at the target milestone, replacing the ones at the base milestone
+//	SectionLedgerDiffs       the ledger diffs of all milestones in (A, B]
+//	SectionSpentAddresses    the addresses which were spent in (A, B]
+package snapshot
+
+import (
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FileVersion is the version of the local snapshot file format written by this package.
+	FileVersion byte = 1
+
+	// HashLength is the length of the hashes and addresses in a snapshot file.
+	HashLength = 49
+
+	// ChecksumLength is the length of the trailing checksum of a snapshot file.
+	ChecksumLength = sha256.Size
+
+	hashIndexEntryLength    = HashLength + 4
+	balanceEntryLength      = HashLength + 8
+	ledgerDiffHeaderLength  = 4 + 4
+	ledgerDiffEntryLength   = HashLength + 8
+	spentAddressEntryLength = HashLength
+	deltaBaseLength         = 4 + HashLength
+	infoLength              = 119
+)
+
+// SectionType defines the type of a section in a snapshot file.
+type SectionType byte
+
+const (
+	SectionInfo SectionType = iota + 1
+	SectionSolidEntryPoints
+	SectionSeenMilestones
+	SectionBalances
+	SectionLedgerDiffs
+	SectionSpentAddresses
+	SectionDeltaBase
+)
+
+var (
+	// FileMagic is written at the beginning of every snapshot file.
+	FileMagic = [4]byte{'A', 'I', 'L', 'S'}
+
+	// DeltaFileMagic is written at the beginning of every delta snapshot file.
+	DeltaFileMagic = [4]byte{'A', 'I', 'L', 'D'}
+
+	// sectionOrder is the order in which the sections are written to a snapshot file.
+	sectionOrder = []SectionType{SectionInfo, SectionSolidEntryPoints, SectionSeenMilestones, SectionBalances, SectionLedgerDiffs, SectionSpentAddresses}
+
+	// deltaSectionOrder is the order in which the sections are written to a delta snapshot file.
+	deltaSectionOrder = []SectionType{SectionInfo, SectionDeltaBase, SectionSolidEntryPoints, SectionLedgerDiffs, SectionSpentAddresses}
+
+	// ErrInvalidMagic is returned if the file does not start with the snapshot file magic.
+	ErrInvalidMagic = errors.New("invalid snapshot file magic")
+	// ErrUnsupportedVersion is returned if the version of the snapshot file is not supported.
+	ErrUnsupportedVersion = errors.New("unsupported snapshot file version")
+	// ErrInvalidSection is returned if a section is malformed or not in the expected order.
+	ErrInvalidSection = errors.New("invalid snapshot file section")
+	// ErrChecksumMismatch is returned if the checksum of the snapshot file does not match its content.
+	ErrChecksumMismatch = errors.New("snapshot file checksum mismatch")
+	// ErrTrailingData is returned if there is data after the checksum of the snapshot file.
+	ErrTrailingData = errors.New("trailing data after snapshot file checksum")
+	// ErrOperationAborted is returned when the operation was aborted e.g. by a shutdown signal.
+	ErrOperationAborted = errors.New("operation was aborted")
+)
+
+func (t SectionType) String() string {
+	switch t {
+	case SectionInfo:
+		return "info"
+	case SectionSolidEntryPoints:
+		return "solid entry points"
+	case SectionSeenMilestones:
+		return "seen milestones"
+	case SectionBalances:
+		return "balances"
+	case SectionLedgerDiffs:
+		return "ledger diffs"
+	case SectionSpentAddresses:
+		return "spent addresses"
+	case SectionDeltaBase:
+		return "delta base"
+	default:
+		return "unknown"
+	}
+}
+
+func isAborted(abortSignal <-chan struct{}) bool {
+	select {
+	case <-abortSignal:
+		return true
+	default:
+		return false
+	}
+}
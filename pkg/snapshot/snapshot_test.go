@@ -0,0 +1,169 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+)
+
+func testHash(b byte) string {
+	return string(bytes.Repeat([]byte{b}, snapshot.HashLength))
+}
+
+func writeTestSnapshot(t *testing.T) ([]byte, *tangle.SnapshotInfo) {
+	info := &tangle.SnapshotInfo{
+		CoordinatorAddress: aingle.Hash(testHash(1)),
+		Hash:               aingle.Hash(testHash(2)),
+		SnapshotIndex:      100,
+		EntryPointIndex:    90,
+		PruningIndex:       80,
+		Timestamp:          1600000000,
+	}
+
+	spentAddresses := []string{testHash(7), testHash(8)}
+
+	var buf bytes.Buffer
+	w, err := snapshot.NewWriter(&buf, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteInfo(info))
+	assert.NoError(t, w.WriteSolidEntryPoints(map[string]milestone.Index{testHash(3): 99}))
+	assert.NoError(t, w.WriteSeenMilestones(map[string]milestone.Index{testHash(4): 101, testHash(5): 102}))
+	assert.NoError(t, w.WriteBalances(map[string]uint64{testHash(6): 2779530283277761}))
+	assert.NoError(t, w.WriteLedgerDiffs(map[milestone.Index]map[string]int64{101: {testHash(6): -5, testHash(9): 5}}))
+	assert.NoError(t, w.WriteSpentAddresses(int32(len(spentAddresses)), func(buf io.Writer, _ <-chan struct{}) (int32, error) {
+		for _, addr := range spentAddresses {
+			if err := binary.Write(buf, binary.LittleEndian, []byte(addr)); err != nil {
+				return 0, err
+			}
+		}
+		return int32(len(spentAddresses)), nil
+	}))
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes(), info
+}
+
+func TestRoundTrip(t *testing.T) {
+	data, info := writeTestSnapshot(t)
+
+	var readInfo *tangle.SnapshotInfo
+	solidEntryPoints := make(map[string]milestone.Index)
+	seenMilestones := make(map[string]milestone.Index)
+	balances := make(map[string]uint64)
+	ledgerDiffs := make(map[milestone.Index]map[string]int64)
+	var spentAddresses []string
+
+	err := snapshot.Read(bytes.NewReader(data), &snapshot.Consumers{
+		Info: func(i *tangle.SnapshotInfo) error {
+			readInfo = i
+			return nil
+		},
+		SolidEntryPoint: func(hash aingle.Hash, index milestone.Index) error {
+			solidEntryPoints[string(hash)] = index
+			return nil
+		},
+		SeenMilestone: func(hash aingle.Hash, index milestone.Index) error {
+			seenMilestones[string(hash)] = index
+			return nil
+		},
+		Balance: func(address aingle.Hash, balance uint64) error {
+			balances[string(address)] = balance
+			return nil
+		},
+		LedgerDiff: func(index milestone.Index, diff map[string]int64) error {
+			ledgerDiffs[index] = diff
+			return nil
+		},
+		SpentAddress: func(address aingle.Hash) error {
+			spentAddresses = append(spentAddresses, string(address))
+			return nil
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, info.GetBytes(), readInfo.GetBytes())
+	assert.Equal(t, map[string]milestone.Index{testHash(3): 99}, solidEntryPoints)
+	assert.Equal(t, map[string]milestone.Index{testHash(4): 101, testHash(5): 102}, seenMilestones)
+	assert.Equal(t, map[string]uint64{testHash(6): 2779530283277761}, balances)
+	assert.Equal(t, map[milestone.Index]map[string]int64{101: {testHash(6): -5, testHash(9): 5}}, ledgerDiffs)
+	assert.Equal(t, []string{testHash(7), testHash(8)}, spentAddresses)
+}
+
+func TestVerifyCorruptedFile(t *testing.T) {
+	data, _ := writeTestSnapshot(t)
+	assert.NoError(t, snapshot.Verify(bytes.NewReader(data), nil))
+
+	// flip a bit in the ledger diffs
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)-snapshot.ChecksumLength-3*snapshot.HashLength] ^= 1
+	assert.True(t, errors.Is(snapshot.Verify(bytes.NewReader(corrupted), nil), snapshot.ErrChecksumMismatch))
+
+	// truncated download
+	assert.Error(t, snapshot.Verify(bytes.NewReader(data[:len(data)-10]), nil))
+
+	// trailing data
+	assert.True(t, errors.Is(snapshot.Verify(bytes.NewReader(append(append([]byte{}, data...), 0)), nil), snapshot.ErrTrailingData))
+
+	// wrong magic
+	corrupted = append([]byte{}, data...)
+	corrupted[0] = 'X'
+	assert.True(t, errors.Is(snapshot.Verify(bytes.NewReader(corrupted), nil), snapshot.ErrInvalidMagic))
+
+	// unsupported version
+	corrupted = append([]byte{}, data...)
+	corrupted[4] = snapshot.FileVersion + 1
+	assert.True(t, errors.Is(snapshot.Verify(bytes.NewReader(corrupted), nil), snapshot.ErrUnsupportedVersion))
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	info := &tangle.SnapshotInfo{
+		CoordinatorAddress: aingle.Hash(testHash(1)),
+		Hash:               aingle.Hash(testHash(2)),
+		SnapshotIndex:      110,
+		EntryPointIndex:    110,
+		PruningIndex:       110,
+		Timestamp:          1600000600,
+	}
+
+	var buf bytes.Buffer
+	w, err := snapshot.NewDeltaWriter(&buf, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteInfo(info))
+	assert.NoError(t, w.WriteDeltaBase(100, aingle.Hash(testHash(3))))
+	assert.NoError(t, w.WriteSolidEntryPoints(map[string]milestone.Index{testHash(4): 109}))
+	assert.NoError(t, w.WriteLedgerDiffs(map[milestone.Index]map[string]int64{105: {testHash(5): -1, testHash(6): 1}}))
+	assert.NoError(t, w.WriteSpentAddresses(0, nil))
+	assert.NoError(t, w.Close())
+
+	// a delta snapshot is not a full snapshot
+	assert.True(t, errors.Is(snapshot.Verify(bytes.NewReader(buf.Bytes()), nil), snapshot.ErrInvalidMagic))
+
+	var baseIndex milestone.Index
+	var baseHash aingle.Hash
+	err = snapshot.ReadDelta(bytes.NewReader(buf.Bytes()), &snapshot.Consumers{
+		DeltaBase: func(index milestone.Index, hash aingle.Hash) error {
+			baseIndex = index
+			baseHash = hash
+			return nil
+		},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.Index(100), baseIndex)
+	assert.Equal(t, aingle.Hash(testHash(3)), baseHash)
+
+	// consumer errors are passed through
+	errTest := errors.New("test")
+	err = snapshot.ReadDelta(bytes.NewReader(buf.Bytes()), &snapshot.Consumers{
+		DeltaBase: func(milestone.Index, aingle.Hash) error { return errTest },
+	}, nil)
+	assert.Equal(t, errTest, err)
+}
@@ -0,0 +1,239 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+// SpentAddressesStreamFunc streams all spent addresses to the given writer and returns the amount of written addresses.
+// tangle.StreamSpentAddressesToWriter satisfies this signature.
+type SpentAddressesStreamFunc func(buf io.Writer, abortSignal <-chan struct{}) (int32, error)
+
+// Writer writes a snapshot file section by section and appends the checksum on Close.
+// The sections have to be written in the order defined by the file format.
+type Writer struct {
+	raw         io.Writer
+	writer      io.Writer
+	hasher      hash.Hash
+	order       []SectionType
+	nextSection int
+	abortSignal <-chan struct{}
+}
+
+// NewWriter creates a new Writer for a snapshot file and writes the file header to the given io.Writer.
+func NewWriter(writer io.Writer, abortSignal <-chan struct{}) (*Writer, error) {
+	return newWriter(writer, FileMagic, sectionOrder, abortSignal)
+}
+
+// NewDeltaWriter creates a new Writer for a delta snapshot file and writes the file header to the given io.Writer.
+func NewDeltaWriter(writer io.Writer, abortSignal <-chan struct{}) (*Writer, error) {
+	return newWriter(writer, DeltaFileMagic, deltaSectionOrder, abortSignal)
+}
+
+func newWriter(writer io.Writer, magic [4]byte, order []SectionType, abortSignal <-chan struct{}) (*Writer, error) {
+	hasher := sha256.New()
+
+	w := &Writer{
+		raw:         writer,
+		writer:      io.MultiWriter(writer, hasher),
+		hasher:      hasher,
+		order:       order,
+		abortSignal: abortSignal,
+	}
+
+	if _, err := w.writer.Write(magic[:]); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(w.writer, binary.LittleEndian, FileVersion); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// beginSection writes the header of the next section and checks that the sections are written in order.
+func (w *Writer) beginSection(sectionType SectionType, length uint64) error {
+	if w.nextSection >= len(w.order) || w.order[w.nextSection] != sectionType {
+		return errors.Wrapf(ErrInvalidSection, "section '%v' written out of order", sectionType)
+	}
+	w.nextSection++
+
+	if err := binary.Write(w.writer, binary.LittleEndian, sectionType); err != nil {
+		return err
+	}
+
+	return binary.Write(w.writer, binary.LittleEndian, length)
+}
+
+// WriteInfo writes the snapshot info section.
+func (w *Writer) WriteInfo(info *tangle.SnapshotInfo) error {
+	infoBytes := info.GetBytes()
+
+	if err := w.beginSection(SectionInfo, uint64(len(infoBytes))); err != nil {
+		return err
+	}
+
+	_, err := w.writer.Write(infoBytes)
+	return err
+}
+
+// WriteDeltaBase writes the base milestone section of a delta snapshot file.
+func (w *Writer) WriteDeltaBase(index milestone.Index, hash aingle.Hash) error {
+	if err := w.beginSection(SectionDeltaBase, deltaBaseLength); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w.writer, binary.LittleEndian, uint32(index)); err != nil {
+		return err
+	}
+
+	return binary.Write(w.writer, binary.LittleEndian, hash[:HashLength])
+}
+
+// writeHashIndexSection writes a section of hashes and their milestone indexes.
+func (w *Writer) writeHashIndexSection(sectionType SectionType, entries map[string]milestone.Index) error {
+	if err := w.beginSection(sectionType, uint64(len(entries))*hashIndexEntryLength); err != nil {
+		return err
+	}
+
+	for hash, index := range entries {
+		if isAborted(w.abortSignal) {
+			return ErrOperationAborted
+		}
+
+		if err := binary.Write(w.writer, binary.LittleEndian, []byte(hash)[:HashLength]); err != nil {
+			return err
+		}
+
+		if err := binary.Write(w.writer, binary.LittleEndian, uint32(index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteSolidEntryPoints writes the solid entry points section.
+func (w *Writer) WriteSolidEntryPoints(solidEntryPoints map[string]milestone.Index) error {
+	return w.writeHashIndexSection(SectionSolidEntryPoints, solidEntryPoints)
+}
+
+// WriteSeenMilestones writes the seen milestones section.
+func (w *Writer) WriteSeenMilestones(seenMilestones map[string]milestone.Index) error {
+	return w.writeHashIndexSection(SectionSeenMilestones, seenMilestones)
+}
+
+// WriteBalances writes the ledger balances section.
+func (w *Writer) WriteBalances(balances map[string]uint64) error {
+	if err := w.beginSection(SectionBalances, uint64(len(balances))*balanceEntryLength); err != nil {
+		return err
+	}
+
+	for address, balance := range balances {
+		if isAborted(w.abortSignal) {
+			return ErrOperationAborted
+		}
+
+		if err := binary.Write(w.writer, binary.LittleEndian, []byte(address)[:HashLength]); err != nil {
+			return err
+		}
+
+		if err := binary.Write(w.writer, binary.LittleEndian, balance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteLedgerDiffs writes the ledger diffs section. The diffs are written in ascending milestone order.
+func (w *Writer) WriteLedgerDiffs(ledgerDiffs map[milestone.Index]map[string]int64) error {
+
+	var length uint64
+	indexes := make([]milestone.Index, 0, len(ledgerDiffs))
+	for index, diff := range ledgerDiffs {
+		indexes = append(indexes, index)
+		length += ledgerDiffHeaderLength + uint64(len(diff))*ledgerDiffEntryLength
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	if err := w.beginSection(SectionLedgerDiffs, length); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		diff := ledgerDiffs[index]
+
+		if err := binary.Write(w.writer, binary.LittleEndian, uint32(index)); err != nil {
+			return err
+		}
+
+		if err := binary.Write(w.writer, binary.LittleEndian, uint32(len(diff))); err != nil {
+			return err
+		}
+
+		for address, change := range diff {
+			if isAborted(w.abortSignal) {
+				return ErrOperationAborted
+			}
+
+			if err := binary.Write(w.writer, binary.LittleEndian, []byte(address)[:HashLength]); err != nil {
+				return err
+			}
+
+			if err := binary.Write(w.writer, binary.LittleEndian, change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteSpentAddresses writes the spent addresses section.
+// The amount of spent addresses must be known in advance and must not change while streaming them.
+func (w *Writer) WriteSpentAddresses(spentAddressesCount int32, streamFunc SpentAddressesStreamFunc) error {
+	if err := w.beginSection(SectionSpentAddresses, uint64(spentAddressesCount)*spentAddressEntryLength); err != nil {
+		return err
+	}
+
+	if spentAddressesCount == 0 {
+		return nil
+	}
+
+	spentAddressesWritten, err := streamFunc(w.writer, w.abortSignal)
+	if err != nil {
+		if err == tangle.ErrOperationAborted {
+			return ErrOperationAborted
+		}
+		return err
+	}
+
+	if spentAddressesWritten != spentAddressesCount {
+		return errors.Wrapf(ErrInvalidSection, "amount of spent addresses changed during writing: %d != %d", spentAddressesWritten, spentAddressesCount)
+	}
+
+	return nil
+}
+
+// Close writes the checksum of the snapshot file. All sections must have been written before.
+// The underlying io.Writer is not closed.
+func (w *Writer) Close() error {
+	if w.nextSection != len(w.order) {
+		return errors.Wrapf(ErrInvalidSection, "section '%v' is missing", w.order[w.nextSection])
+	}
+
+	// the checksum itself is not part of the hash
+	_, err := w.raw.Write(w.hasher.Sum(nil))
+	return err
+}
@@ -51,6 +51,20 @@ const (
 	ScoreNonLazy
 )
 
+// String returns the name of the score.
+func (s Score) String() string {
+	switch s {
+	case ScoreLazy:
+		return "lazy"
+	case ScoreSemiLazy:
+		return "semiLazy"
+	case ScoreNonLazy:
+		return "nonLazy"
+	default:
+		return "unknown"
+	}
+}
+
 var (
 	// ErrNoTipsAvailable is returned when no tips are available in the node.
 	ErrNoTipsAvailable = errors.New("no tips available")
@@ -348,6 +362,52 @@ func (ts *TipSelector) SelectNonLazyTips() (aingle.Hashes, error) {
 	return ts.selectTips(ts.nonLazyTipsMap)
 }
 
+// GetTipHashes returns the tail transaction hashes of all tips in the non-lazy and semi-lazy tip pools.
+func (ts *TipSelector) GetTipHashes() (nonLazy aingle.Hashes, semiLazy aingle.Hashes) {
+
+	ts.tipsLock.Lock()
+	defer ts.tipsLock.Unlock()
+
+	nonLazy = make(aingle.Hashes, 0, len(ts.nonLazyTipsMap))
+	for _, tip := range ts.nonLazyTipsMap {
+		nonLazy = append(nonLazy, tip.Hash)
+	}
+
+	semiLazy = make(aingle.Hashes, 0, len(ts.semiLazyTipsMap))
+	for _, tip := range ts.semiLazyTipsMap {
+		semiLazy = append(semiLazy, tip.Hash)
+	}
+
+	return nonLazy, semiLazy
+}
+
+// TipCount returns the amount of tips in the non-lazy and semi-lazy tip pools.
+func (ts *TipSelector) TipCount() int {
+
+	ts.tipsLock.Lock()
+	defer ts.tipsLock.Unlock()
+
+	return len(ts.nonLazyTipsMap) + len(ts.semiLazyTipsMap)
+}
+
+// GetTipScore returns the score of the given tail transaction and whether it is in one of the tip pools.
+// If the transaction is not in a tip pool, the score is calculated in relation to the given LSMI.
+func (ts *TipSelector) GetTipScore(tailTxHash aingle.Hash, lsmi milestone.Index) (score Score, inTipPool bool) {
+
+	ts.tipsLock.Lock()
+	if tip, exists := ts.nonLazyTipsMap[string(tailTxHash)]; exists {
+		ts.tipsLock.Unlock()
+		return tip.Score, true
+	}
+	if tip, exists := ts.semiLazyTipsMap[string(tailTxHash)]; exists {
+		ts.tipsLock.Unlock()
+		return tip.Score, true
+	}
+	ts.tipsLock.Unlock()
+
+	return ts.calculateScore(tailTxHash, lsmi), false
+}
+
 // CleanUpReferencedTips checks if tips were referenced before
 // and removes them if they reached their maximum age.
 func (ts *TipSelector) CleanUpReferencedTips() int {
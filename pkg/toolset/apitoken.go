@@ -0,0 +1,35 @@
+package toolset
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+func apiTokenGen(args []string) error {
+
+	if len(args) > 0 {
+		return errors.New("too many arguments for 'apitoken'")
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	tokenHex := hex.EncodeToString(token)
+	saltHex := hex.EncodeToString(salt)
+	hash := sha256.Sum256(append([]byte(tokenHex), []byte(saltHex)...))
+
+	fmt.Printf("Your API token: %s\n", tokenHex)
+	fmt.Printf("Add it to the API tokens config with\n  \"tokenHash\": \"%x\",\n  \"tokenSalt\": \"%s\"\n", hash, saltHex)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/utils"
+)
+
+func databaseBackup(args []string) error {
+
+	if len(args) != 2 {
+		return errors.New("wrong arguments for 'dbbackup', usage: dbbackup [SOURCE_PATH] [TARGET_PATH]")
+	}
+
+	sourcePath := args[0]
+	targetPath := args[1]
+
+	abortSignal := make(chan struct{})
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+	go func() {
+		if _, ok := <-signalChan; ok {
+			close(abortSignal)
+		}
+	}()
+
+	ts := time.Now()
+	fmt.Printf("backing up database %s to %s...\n", sourcePath, targetPath)
+
+	lastStatusTime := time.Now()
+	if err := tangle.BackupDatabase(sourcePath, targetPath, func(current int64, total int64) {
+		if time.Since(lastStatusTime) < printStatusInterval {
+			return
+		}
+		lastStatusTime = time.Now()
+
+		percentage, remaining := utils.EstimateRemainingTime(ts, current, total)
+		fmt.Printf("backing up database...%d/%d (%0.2f%%). %v left...\n", current, total, percentage, remaining.Truncate(time.Second))
+	}, abortSignal); err != nil {
+		return fmt.Errorf("database backup failed: %v", err)
+	}
+
+	fmt.Printf("database backup finished (took %v).\n", time.Since(ts).Truncate(time.Second))
+
+	return nil
+}
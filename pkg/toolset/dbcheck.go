@@ -0,0 +1,92 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+func databaseCheck(args []string) error {
+
+	if len(args) < 1 || len(args) > 2 || (len(args) == 2 && strings.ToLower(args[1]) != "fix") {
+		return errors.New("wrong arguments for 'dbcheck', usage: dbcheck [DATABASE_PATH] (fix)")
+	}
+
+	databasePath := args[0]
+	fix := len(args) == 2
+
+	// the engine of the database is detected, the configured engine may not match the checked database
+	engine := tangle.DetectDatabaseEngine(databasePath)
+	if engine == "" {
+		return fmt.Errorf("no database found in %s", databasePath)
+	}
+
+	if err := tangle.ConfigureDatabases(databasePath, engine); err != nil {
+		if errors.Is(err, tangle.ErrDatabaseInUse) {
+			return fmt.Errorf("database %s is in use, stop the node before checking the database", databasePath)
+		}
+		return fmt.Errorf("opening %s database failed: %v", engine, err)
+	}
+	defer func() {
+		tangle.ShutdownStorages()
+		tangle.CloseDatabases()
+	}()
+
+	if tangle.IsDatabaseCorrupted() {
+		fmt.Println("the database is marked as corrupted, the node was not shut down correctly or is still running")
+	}
+
+	abortSignal := make(chan struct{})
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+	go func() {
+		if _, ok := <-signalChan; ok {
+			close(abortSignal)
+		}
+	}()
+
+	ts := time.Now()
+	fmt.Printf("checking database %s...\n", databasePath)
+
+	lastStatusTime := time.Now()
+	result, err := tangle.CheckDatabase(fix, func(storage string, analyzed int64) {
+		if time.Since(lastStatusTime) < printStatusInterval {
+			return
+		}
+		lastStatusTime = time.Now()
+
+		fmt.Printf("analyzed %d %s\n", analyzed, storage)
+	}, abortSignal)
+	if err != nil {
+		return fmt.Errorf("database check failed: %v", err)
+	}
+
+	fmt.Printf("database check finished (took %v).\n\n", time.Since(ts).Truncate(time.Second))
+
+	for _, category := range tangle.InconsistencyCategories {
+		found := result.Found[category]
+		if found == 0 {
+			continue
+		}
+
+		if fixed := result.Fixed[category]; fixed > 0 {
+			fmt.Printf("%s: %d (fixed: %d)\n", category, found, fixed)
+			continue
+		}
+		fmt.Printf("%s: %d\n\t=> %s\n", category, found, category.Suggestion())
+	}
+
+	if result.Total() == 0 {
+		fmt.Println("no inconsistencies found")
+		return nil
+	}
+
+	return fmt.Errorf("%d inconsistencies found", result.Total())
+}
@@ -0,0 +1,37 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+func databaseMigrate(args []string) error {
+
+	if len(args) != 3 {
+		return errors.New("wrong arguments for 'dbmigrate', usage: dbmigrate [SOURCE_PATH] [TARGET_PATH] [TARGET_ENGINE]")
+	}
+
+	sourcePath := args[0]
+	targetPath := args[1]
+
+	targetEngine, err := tangle.DatabaseEngineFromString(args[2])
+	if err != nil {
+		return err
+	}
+
+	ts := time.Now()
+	fmt.Printf("migrating database %s to %s (%s)...\n", sourcePath, targetPath, targetEngine)
+
+	if err := tangle.MigrateDatabase(sourcePath, targetPath, targetEngine, func(prefix byte, count int) {
+		fmt.Printf("migrated prefix %d: %d records\n", prefix, count)
+	}); err != nil {
+		return fmt.Errorf("database migration failed: %v", err)
+	}
+
+	fmt.Printf("database migration finished (took %v). set \"db.path\" to %s and \"db.engine\" to %s to use the new database.\n", time.Since(ts).Truncate(time.Second), targetPath, targetEngine)
+
+	return nil
+}
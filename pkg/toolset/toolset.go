@@ -8,10 +8,14 @@ import (
 
 var (
 	tools = map[string]func([]string) error{
-		"pwdhash": hashPasswordAndSalt,
-		"seedgen": seedGen,
-		"list":    listTools,
-		"merkle":  merkleTreeCreate,
+		"pwdhash":   hashPasswordAndSalt,
+		"seedgen":   seedGen,
+		"list":      listTools,
+		"merkle":    merkleTreeCreate,
+		"dbmigrate": databaseMigrate,
+		"dbbackup":  databaseBackup,
+		"dbcheck":   databaseCheck,
+		"apitoken":  apiTokenGen,
 	}
 )
 
@@ -58,6 +62,10 @@ func listTools(args []string) error {
 	fmt.Println("pwdhash: generates a sha265 sum from your password and salt")
 	fmt.Println("seedgen: generates an autopeering seed")
 	fmt.Println("merkle: generates a Merkle tree for coordinator plugin")
+	fmt.Println("dbmigrate: copies the database to another directory using a different database engine")
+	fmt.Println("dbbackup: copies the database of a stopped node to another directory")
+	fmt.Println("dbcheck: checks the database for inconsistencies and optionally fixes the indexes")
+	fmt.Println("apitoken: generates a bearer token for the HTTP API and its hash for the API tokens config")
 
 	return nil
 }
@@ -166,7 +166,7 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 	// confirm all conflicting txs of the conflicting tails
 	for _, txHash := range mutations.TailsExcludedConflicting {
 		if err := forEachBundleTxMetaWithTailTxHash(txHash, func(txMeta *tangle.CachedMetadata) {
-			txMeta.GetMetadata().SetConflicting(true)
+			txMeta.GetMetadata().SetConflicting(mutations.Conflicts[string(txHash)])
 			if !txMeta.GetMetadata().IsConfirmed() {
 				txMeta.GetMetadata().SetConfirmed(true, milestoneIndex)
 				txMeta.GetMetadata().SetRootSnapshotIndexes(milestoneIndex, milestoneIndex, milestoneIndex)
@@ -593,6 +593,10 @@ func TestWhiteFlagWithMultipleConflicting(t *testing.T) {
 	require.Equal(t, 4, conf.TxsConflicting)
 	require.Equal(t, 3, conf.TxsZeroValue) // The milestone
 
+	cachedTailC := bundleC.GetBundle().GetTail() // tx +1
+	require.Equal(t, aingle.ConflictInsufficientBalance, cachedTailC.GetMetadata().GetConflict())
+	cachedTailC.Release(true) // tx -1
+
 	// Verify balances (seed, index, balance)
 	assertAddressBalance(t, seed1, 0, 0)
 	assertAddressBalance(t, seed1, 1, 0)
@@ -611,6 +615,10 @@ func TestWhiteFlagWithMultipleConflicting(t *testing.T) {
 	require.Equal(t, 4, conf.TxsConflicting)
 	require.Equal(t, 3, conf.TxsZeroValue) // The milestone
 
+	cachedTailD := bundleD.GetBundle().GetTail() // tx +1
+	require.Equal(t, aingle.ConflictInsufficientBalance, cachedTailD.GetMetadata().GetConflict())
+	cachedTailD.Release(true) // tx -1
+
 	// Verify balances (seed, index, balance)
 	assertAddressBalance(t, seed1, 0, 0)
 	assertAddressBalance(t, seed1, 1, 0)
@@ -630,6 +638,47 @@ func TestWhiteFlagWithMultipleConflicting(t *testing.T) {
 	tangle.ShutdownStorages()
 }
 
+func TestWhiteFlagWithDoubleSpendInCone(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(generateAddress(t, seed1, 0))] = 1000
+
+	milestones := setupCoordinatorAndIssueInitialMilestones(t, balances, 3)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := storeBundle(t, attachTo(t, milestones[0].GetBundle().GetTailHash(), milestones[1].GetBundle().GetTailHash(), sendFrom(t, "A", seed1, 0, 1000, seed2, 0, 100)), false)
+	// Double spend of seed1[0], valid in the previous ledger state but conflicting with bundle A
+	bundleB := storeBundle(t, attachTo(t, bundleA.GetBundle().GetTailHash(), milestones[2].GetBundle().GetTailHash(), sendFrom(t, "B", seed1, 0, 1000, seed3, 0, 200)), false)
+
+	// Confirming milestone at bundle B, bundle A is applied first
+	_, conf := issueAndConfirmMilestoneOnTip(t, bundleB.GetBundle().GetTailHash(), true)
+	require.Equal(t, 4+4+3, conf.TxsConfirmed) // 3 are for the milestone itself
+	require.Equal(t, 4, conf.TxsValue)
+	require.Equal(t, 4, conf.TxsConflicting)
+
+	cachedTailA := bundleA.GetBundle().GetTail() // tx +1
+	require.Equal(t, aingle.ConflictNone, cachedTailA.GetMetadata().GetConflict())
+	cachedTailA.Release(true) // tx -1
+
+	cachedTailB := bundleB.GetBundle().GetTail() // tx +1
+	require.Equal(t, aingle.ConflictInconsistentCone, cachedTailB.GetMetadata().GetConflict())
+	cachedTailB.Release(true) // tx -1
+
+	// Verify balances (seed, index, balance)
+	assertAddressBalance(t, seed1, 0, 0)
+	assertAddressBalance(t, seed1, 1, 900)
+	assertAddressBalance(t, seed2, 0, 100)
+	assertAddressBalance(t, seed3, 0, 0)
+
+	// Clean up all the bundles we created
+	cachedBundles.Release()
+	cachedBundles = nil
+
+	// This should not hang, i.e. all objects should be released
+	tangle.ShutdownStorages()
+}
+
 func TestWhiteFlagWithOnlyZeroTx(t *testing.T) {
 
 	// Fill up the balances
@@ -38,6 +38,8 @@ type WhiteFlagMutations struct {
 	TailsIncluded aingle.Hashes
 	// The tails of bundles which were excluded as they were conflicting with the mutations.
 	TailsExcludedConflicting aingle.Hashes
+	// The reasons why the tails in TailsExcludedConflicting were excluded, keyed by tail hash.
+	Conflicts map[string]aingle.Conflict
 	// The tails which were excluded because they were part of a zero or spam value transfer.
 	TailsExcludedZeroValue aingle.Hashes
 	// The tails which were referenced by the milestone (should be the sum of TailsIncluded + TailsExcludedConflicting + TailsExcludedZeroValue).
@@ -63,12 +65,17 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 	wfConf := &WhiteFlagMutations{
 		TailsIncluded:            make(aingle.Hashes, 0),
 		TailsExcludedConflicting: make(aingle.Hashes, 0),
+		Conflicts:                make(map[string]aingle.Conflict),
 		TailsExcludedZeroValue:   make(aingle.Hashes, 0),
 		TailsReferenced:          make(aingle.Hashes, 0),
 		NewAddressState:          make(map[string]int64),
 		AddressMutations:         make(map[string]int64),
 	}
 
+	// the balances of the previous ledger state of all addresses which were loaded during the walk,
+	// they are used to determine the reason of a conflict without additional lookups.
+	ledgerBalances := make(map[string]int64)
+
 	// traversal stops if no more transactions pass the given condition
 	// Caution: condition func is not in DFS order
 	condition := func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
@@ -123,7 +130,7 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			return nil
 		}
 
-		var conflicting bool
+		conflict := aingle.ConflictNone
 
 		// contains the updated mutations from this bundle against the
 		// current mutations of the milestone's confirming cone (or previous ledger state).
@@ -135,13 +142,14 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 		for addr, change := range mutations {
 
 			// load state from milestone cone mutation or previous milestone
-			balance, has := wfConf.NewAddressState[addr]
-			if !has {
+			balance, mutatedInCone := wfConf.NewAddressState[addr]
+			if !mutatedInCone {
 				balanceStateFromPreviousMilestone, _, err := tangle.GetBalanceForAddressWithoutLocking(aingle.Hash(addr))
 				if err != nil {
 					return fmt.Errorf("%w: unable to retrieve balance of address %s", err, addr)
 				}
 				balance = int64(balanceStateFromPreviousMilestone)
+				ledgerBalances[addr] = balance
 			}
 
 			// note that there's no overflow of int64 values here
@@ -150,8 +158,13 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			newBalance := balance + change
 
 			// on below zero or above total supply the mutation is invalid
-			if newBalance < 0 || math.AbsInt64(newBalance) > consts.TotalSupply {
-				conflicting = true
+			if math.AbsInt64(newBalance) > consts.TotalSupply {
+				conflict = aingle.ConflictInvalidBundle
+				break
+			}
+
+			if newBalance < 0 {
+				conflict = conflictReasonForInput(ledgerBalances[addr], change, mutatedInCone)
 				break
 			}
 
@@ -161,8 +174,9 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 
 		wfConf.TailsReferenced = append(wfConf.TailsReferenced, cachedTxMeta.GetMetadata().GetTxHash())
 
-		if conflicting {
+		if conflict != aingle.ConflictNone {
 			wfConf.TailsExcludedConflicting = append(wfConf.TailsExcludedConflicting, cachedTxMeta.GetMetadata().GetTxHash())
+			wfConf.Conflicts[string(cachedTxMeta.GetMetadata().GetTxHash())] = conflict
 			return nil
 		}
 
@@ -226,3 +240,14 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 
 	return wfConf, nil
 }
+
+// conflictReasonForInput returns why spending the given change from an input address creates a negative balance.
+// ledgerBalance is the balance of the address in the previous ledger state, mutatedInCone tells
+// whether bundles applied before in the same cone already mutated the address.
+func conflictReasonForInput(ledgerBalance int64, change int64, mutatedInCone bool) aingle.Conflict {
+	if mutatedInCone && ledgerBalance+change >= 0 {
+		// the input would have been valid without the mutations applied before in the same cone
+		return aingle.ConflictInconsistentCone
+	}
+	return aingle.ConflictInsufficientBalance
+}
@@ -49,7 +49,7 @@ func ParseConfig() {
 }
 
 func PrintConfig() {
-	config.PrintConfig([]string{config.CfgWebAPIBasicAuthPasswordHash, config.CfgWebAPIBasicAuthPasswordSalt, config.CfgDashboardBasicAuthPasswordHash, config.CfgDashboardBasicAuthPasswordSalt})
+	config.PrintConfig([]string{config.CfgWebAPIBasicAuthPasswordHash, config.CfgWebAPIBasicAuthPasswordSalt, config.CfgDashboardBasicAuthPasswordHash, config.CfgDashboardBasicAuthPasswordSalt, config.CfgNetGossipSecureChannelPrivateKeySeed})
 }
 
 // HideConfigFlags hides all non essential flags from the help/usage text.
@@ -306,12 +306,8 @@ func sendBundle(b coordinator.Bundle, isMilestone bool) error {
 func isBelowMaxDepth(cachedTailTxMeta *tangle.CachedMetadata) bool {
 	defer cachedTailTxMeta.Release(true)
 
-	lsmi := tangle.GetSolidMilestoneIndex()
-
-	_, ortsi := dag.GetTransactionRootSnapshotIndexes(cachedTailTxMeta.Retain(), lsmi) // meta +1
-
 	// if the OTRSI to LSMI delta is over belowMaxDepth, then the tip is invalid.
-	return (lsmi - ortsi) > belowMaxDepth
+	return dag.IsBelowMaxDepth(cachedTailTxMeta.Retain(), tangle.GetSolidMilestoneIndex(), belowMaxDepth) // meta +1
 }
 
 // GetEvents returns the events of the coordinator
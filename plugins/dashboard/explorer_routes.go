@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"encoding/base64"
 	"net/http"
 	"strconv"
 	"strings"
@@ -43,9 +44,10 @@ type ExplorerTx struct {
 	AttachmentTimestampLowerBound int64          `json:"attachment_timestamp_lower_bound"`
 	AttachmentTimestampUpperBound int64          `json:"attachment_timestamp_upper_bound"`
 	Confirmed                     struct {
-		State       bool            `json:"state"`
-		Conflicting bool            `json:"conflicting"`
-		Milestone   milestone.Index `json:"milestone_index"`
+		State          bool            `json:"state"`
+		Conflicting    bool            `json:"conflicting"`
+		ConflictReason string          `json:"conflict_reason"`
+		Milestone      milestone.Index `json:"milestone_index"`
 	} `json:"confirmed"`
 	Approvers      []string        `json:"approvers"`
 	Solid          bool            `json:"solid"`
@@ -64,6 +66,10 @@ func createExplorerTx(cachedTx *tangle.CachedTransaction) (*ExplorerTx, error) {
 	originTx := cachedTx.GetTransaction().Tx
 	confirmed, by := cachedTx.GetMetadata().GetConfirmed()
 	conflicting := cachedTx.GetMetadata().IsConflicting()
+	var conflictReason string
+	if conflicting {
+		conflictReason = cachedTx.GetMetadata().GetConflict().String()
+	}
 	t := &ExplorerTx{
 		Hash:                          originTx.Hash,
 		SignatureMessageFragment:      originTx.SignatureMessageFragment,
@@ -82,10 +88,11 @@ func createExplorerTx(cachedTx *tangle.CachedTransaction) (*ExplorerTx, error) {
 		AttachmentTimestampLowerBound: originTx.AttachmentTimestampLowerBound,
 		AttachmentTimestampUpperBound: originTx.AttachmentTimestampUpperBound,
 		Confirmed: struct {
-			State       bool            `json:"state"`
-			Conflicting bool            `json:"conflicting"`
-			Milestone   milestone.Index `json:"milestone_index"`
-		}{confirmed, conflicting, by},
+			State          bool            `json:"state"`
+			Conflicting    bool            `json:"conflicting"`
+			ConflictReason string          `json:"conflict_reason"`
+			Milestone      milestone.Index `json:"milestone_index"`
+		}{confirmed, conflicting, conflictReason, by},
 		Solid: cachedTx.GetMetadata().IsSolid(),
 	}
 
@@ -147,7 +154,8 @@ func createExplorerTx(cachedTx *tangle.CachedTransaction) (*ExplorerTx, error) {
 }
 
 type ExplorerTag struct {
-	Txs []*ExplorerTx `json:"txs"`
+	Txs    []*ExplorerTx `json:"txs"`
+	Cursor string        `json:"cursor,omitempty"`
 }
 
 type ExplorerAddress struct {
@@ -155,6 +163,7 @@ type ExplorerAddress struct {
 	Txs          []*ExplorerTx `json:"txs"`
 	Spent        bool          `json:"spent"`
 	SpentEnabled bool          `json:"spent_enabled"`
+	Cursor       string        `json:"cursor,omitempty"`
 }
 
 type SearchResult struct {
@@ -187,7 +196,7 @@ func setupExplorerRoutes(routeGroup *echo.Group) {
 
 	routeGroup.GET("/tag/:tag", func(c echo.Context) error {
 		tag := strings.ToUpper(c.Param("tag"))
-		txs, err := findTag(strings.ToUpper(tag))
+		txs, err := findTag(strings.ToUpper(tag), c.QueryParam("cursor"))
 		if err != nil {
 			return err
 		}
@@ -196,7 +205,7 @@ func setupExplorerRoutes(routeGroup *echo.Group) {
 
 	routeGroup.GET("/addr/:hash/value", func(c echo.Context) error {
 		hash := strings.ToUpper(c.Param("hash"))
-		addr, err := findAddress(hash, true)
+		addr, err := findAddress(hash, true, c.QueryParam("cursor"))
 		if err != nil {
 			return err
 		}
@@ -205,7 +214,7 @@ func setupExplorerRoutes(routeGroup *echo.Group) {
 
 	routeGroup.GET("/addr/:hash", func(c echo.Context) error {
 		hash := strings.ToUpper(c.Param("hash"))
-		addr, err := findAddress(hash, false)
+		addr, err := findAddress(hash, false, c.QueryParam("cursor"))
 		if err != nil {
 			return err
 		}
@@ -246,7 +255,7 @@ func setupExplorerRoutes(routeGroup *echo.Group) {
 
 		// tag query
 		if len(search) == 27 {
-			txs, err := findTag(search)
+			txs, err := findTag(search, "")
 			if err == nil && len(txs.Txs) > 0 {
 				result.Tag = txs
 				return c.JSON(http.StatusOK, result)
@@ -272,7 +281,7 @@ func setupExplorerRoutes(routeGroup *echo.Group) {
 
 		go func() {
 			defer wg.Done()
-			addr, err := findAddress(search, false)
+			addr, err := findAddress(search, false, "")
 			if err == nil && (len(addr.Txs) > 0 || addr.Balance > 0) {
 				result.Address = addr
 			}
@@ -318,7 +327,7 @@ func findTransaction(hash trinary.Hash) (*ExplorerTx, error) {
 	return t, err
 }
 
-func findTag(tag trinary.Trytes) (*ExplorerTag, error) {
+func findTag(tag trinary.Trytes, cursor string) (*ExplorerTag, error) {
 	if err := trinary.ValidTrytes(tag); err != nil {
 		return nil, errors.Wrapf(ErrInvalidParameter, "tag invalid: %s", tag)
 	}
@@ -327,8 +336,13 @@ func findTag(tag trinary.Trytes) (*ExplorerTag, error) {
 		return nil, errors.Wrapf(ErrInvalidParameter, "tag invalid length: %s", tag)
 	}
 
-	txHashes := tangle.GetTagHashes(aingle.HashFromTagTrytes(tag), true, MaxTagResults)
-	if len(txHashes) == 0 {
+	afterTxHash, err := decodeExplorerCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes, more := tangle.GetTagHashesPage(aingle.HashFromTagTrytes(tag), afterTxHash, MaxTagResults)
+	if len(txHashes) == 0 && len(afterTxHash) == 0 {
 		return nil, errors.Wrapf(ErrNotFound, "tag %s unknown", tag)
 	}
 
@@ -349,7 +363,12 @@ func findTag(tag trinary.Trytes) (*ExplorerTag, error) {
 		}
 	}
 
-	return &ExplorerTag{Txs: txs}, nil
+	result := &ExplorerTag{Txs: txs}
+	if more {
+		result.Cursor = encodeExplorerCursor(txHashes[len(txHashes)-1])
+	}
+
+	return result, nil
 }
 
 func findBundles(hash trinary.Hash) ([][]*ExplorerTx, error) {
@@ -382,7 +401,7 @@ func findBundles(hash trinary.Hash) ([][]*ExplorerTx, error) {
 	return expBndls, nil
 }
 
-func findAddress(hash trinary.Hash, valueOnly bool) (*ExplorerAddress, error) {
+func findAddress(hash trinary.Hash, valueOnly bool, cursor string) (*ExplorerAddress, error) {
 	if len(hash) > 81 {
 		hash = hash[:81]
 	}
@@ -390,9 +409,14 @@ func findAddress(hash trinary.Hash, valueOnly bool) (*ExplorerAddress, error) {
 		return nil, errors.Wrapf(ErrInvalidParameter, "hash invalid: %s", hash)
 	}
 
+	afterTxHash, err := decodeExplorerCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	addr := aingle.HashFromAddressTrytes(hash)
 
-	txHashes := tangle.GetTransactionHashesForAddress(addr, valueOnly, true, MaxTransactionsForAddressResults)
+	txHashes, more := tangle.GetTransactionHashesForAddressPage(addr, valueOnly, afterTxHash, MaxTransactionsForAddressResults)
 
 	txs := make([]*ExplorerTx, 0, len(txHashes))
 	if len(txHashes) != 0 {
@@ -416,10 +440,35 @@ func findAddress(hash trinary.Hash, valueOnly bool) (*ExplorerAddress, error) {
 		return nil, err
 	}
 
-	return &ExplorerAddress{
+	result := &ExplorerAddress{
 		Balance:      balance,
 		Txs:          txs,
 		Spent:        tangle.WasAddressSpentFrom(addr),
 		SpentEnabled: tangle.GetSnapshotInfo().IsSpentAddressesEnabled(),
-	}, nil
+	}
+	if more {
+		result.Cursor = encodeExplorerCursor(txHashes[len(txHashes)-1])
+	}
+
+	return result, nil
+}
+
+// encodeExplorerCursor encodes the last returned transaction hash of a page into an opaque cursor.
+func encodeExplorerCursor(lastTxHash aingle.Hash) string {
+	return base64.RawURLEncoding.EncodeToString(lastTxHash)
+}
+
+// decodeExplorerCursor decodes the transaction hash after which the next page starts.
+// An empty cursor starts at the first page.
+func decodeExplorerCursor(cursor string) (aingle.Hash, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	lastTxHash, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(lastTxHash) != 49 {
+		return nil, errors.Wrapf(ErrInvalidParameter, "cursor invalid: %s", cursor)
+	}
+
+	return lastTxHash, nil
 }
@@ -9,7 +9,7 @@ import Spinner from "react-bootstrap/Spinner";
 import ListGroup from "react-bootstrap/ListGroup";
 import {Link} from 'react-router-dom';
 import * as dateformat from 'dateformat';
-import Alert from "react-bootstrap/Alert";
+import Button from "react-bootstrap/Button";
 import Badge, {BadgeProps} from "react-bootstrap/Badge";
 import {IOTAValue} from "app/components/IOTAValue";
 import FormCheck from "react-bootstrap/FormCheck";
@@ -121,10 +121,11 @@ export class ExplorerAddressQueryResult extends React.Component<Props, State> {
                                 Balance: <IOTAValue>{addr.balance}</IOTAValue>
                             </p>
                             {
-                                addr.txs !== null && addr.txs.length === 100 &&
-                                <Alert variant={"warning"}>
-                                    Max. {addr.txs.length} transactions are shown.
-                                </Alert>
+                                addr.cursor &&
+                                <Button variant="outline-secondary" size="sm" className={"mb-3"}
+                                        onClick={() => this.props.explorerStore.loadMoreAddressTxs(this.props.match.params.hash)}>
+                                    {addr.txs.length} transactions are shown, load more
+                                </Button>
                             }
                             <Row className={"mb-3"}>
                                 <Col>
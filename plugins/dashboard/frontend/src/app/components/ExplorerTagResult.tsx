@@ -9,7 +9,7 @@ import Spinner from "react-bootstrap/Spinner";
 import ListGroup from "react-bootstrap/ListGroup";
 import {Link} from 'react-router-dom';
 import * as dateformat from 'dateformat';
-import Alert from "react-bootstrap/Alert";
+import Button from "react-bootstrap/Button";
 import * as style from '../../assets/main.css';
 
 interface Props {
@@ -62,10 +62,11 @@ export class ExplorerTagQueryResult extends React.Component<Props, any> {
                     tag !== null ?
                         <React.Fragment>
                             {
-                                tag.txs !== null && tag.txs.length === 100 &&
-                                <Alert variant={"warning"}>
-                                    Max. 100 transactions are shown.
-                                </Alert>
+                                tag.cursor &&
+                                <Button variant="outline-secondary" size="sm" className={"mb-3"}
+                                        onClick={() => this.props.explorerStore.loadMoreTagTxs(this.props.match.params.hash)}>
+                                    {tag.txs.length} transactions are shown, load more
+                                </Button>
                             }
                             <Row className={"mb-3"}>
                                 <Col>
@@ -168,7 +168,7 @@ export class ExplorerTransactionQueryResult extends React.Component<Props, any>
                                             tx.confirmed.state ?
                                                 tx.confirmed.conflicting ?
                                                     <Badge variant="danger">
-                                                        Conflicting at Milestone {tx.confirmed.milestone_index}
+                                                        Conflicting at Milestone {tx.confirmed.milestone_index}: {tx.confirmed.conflict_reason}
                                                     </Badge>
                                                     :
                                                     <Badge variant="success">
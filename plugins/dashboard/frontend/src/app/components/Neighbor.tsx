@@ -74,6 +74,20 @@ export class Neighbor extends React.Component<Props, any> {
                                         <ListGroup.Item>
                                             Identity: {last.identity}
                                         </ListGroup.Item>
+                                        <If condition={!!last.info.score}>
+                                            <ListGroup.Item>
+                                                Score: {' '}
+                                                {last.info.score.total.toFixed(2)}
+                                                {' '}
+                                                (Usefulness: {last.info.score.usefulness.toFixed(2)},
+                                                {' '}
+                                                Latency: {last.info.score.latency.toFixed(2)} / {last.info.score.avgLatencyMs} ms,
+                                                {' '}
+                                                Validity: {last.info.score.validity.toFixed(2)},
+                                                {' '}
+                                                Heartbeat: {last.info.score.heartbeat.toFixed(2)})
+                                            </ListGroup.Item>
+                                        </If>
                                     </ListGroup>
                                 </Col>
                             </Row>
@@ -143,7 +157,12 @@ export class Neighbor extends React.Component<Props, any> {
                                                 Pruned Milestone Index: {' '}
                                                 {last.heartbeat.pruned_milestone_index}
                                             </ListGroup.Item>
-
+                                            <If condition={last.heartbeat.version > 0}>
+                                                <ListGroup.Item>
+                                                    Snapshot Milestone Index: {' '}
+                                                    {last.heartbeat.snapshot_milestone_index}
+                                                </ListGroup.Item>
+                                            </If>
                                         </If>
                                     </ListGroup>
                                 </Col>
@@ -152,6 +171,20 @@ export class Neighbor extends React.Component<Props, any> {
                                         <ListGroup.Item>
                                             Identity: {last.identity}
                                         </ListGroup.Item>
+                                        <If condition={!!last.info.score}>
+                                            <ListGroup.Item>
+                                                Score: {' '}
+                                                {last.info.score.total.toFixed(2)}
+                                                {' '}
+                                                (Usefulness: {last.info.score.usefulness.toFixed(2)},
+                                                {' '}
+                                                Latency: {last.info.score.latency.toFixed(2)} / {last.info.score.avgLatencyMs} ms,
+                                                {' '}
+                                                Validity: {last.info.score.validity.toFixed(2)},
+                                                {' '}
+                                                Heartbeat: {last.info.score.heartbeat.toFixed(2)})
+                                            </ListGroup.Item>
+                                        </If>
                                         <If condition={!!last.heartbeat}>
                                             <ListGroup.Item>
                                                 Neighbors: {' '}
@@ -161,6 +194,22 @@ export class Neighbor extends React.Component<Props, any> {
                                                 Synced Neighbors: {' '}
                                                 {last.heartbeat.synced_neighbors}
                                             </ListGroup.Item>
+                                            <If condition={last.heartbeat.version > 0}>
+                                                <If condition={!!last.heartbeat.alias}>
+                                                    <ListGroup.Item>
+                                                        Node Alias: {' '}
+                                                        {last.heartbeat.alias}
+                                                    </ListGroup.Item>
+                                                </If>
+                                                <ListGroup.Item>
+                                                    Tip Pool Size: {' '}
+                                                    {last.heartbeat.tip_pool_size}
+                                                </ListGroup.Item>
+                                                <ListGroup.Item>
+                                                    Request Queue Size: {' '}
+                                                    {last.heartbeat.request_queue_size}
+                                                </ListGroup.Item>
+                                            </If>
                                         </If>
                                     </ListGroup>
                                 </Col>
@@ -44,15 +44,18 @@ class AddressResult {
     txs: Array<Transaction>;
     spent: boolean;
     spent_enabled: boolean;
+    cursor: string;
 }
 
 class TagResult {
     txs: Array<Transaction>;
+    cursor: string;
 }
 
 class ConfirmedState {
     state: boolean;
     conflicting: boolean;
+    conflict_reason: string;
     milestone_index: number;
 }
 
@@ -265,6 +268,20 @@ export class ExplorerStore {
         }
     };
 
+    loadMoreAddressTxs = async (hash: string) => {
+        if (!this.addr || !this.addr.cursor) {
+            return;
+        }
+        try {
+            let res = await fetch(`/api/addr/${hash}${this.valueOnly ? "/value" : ""}?cursor=${this.addr.cursor}`);
+            let addr: AddressResult = await res.json();
+            addr.txs = this.addr.txs.concat(addr.txs);
+            this.updateAddress(addr);
+        } catch (err) {
+            this.updateQueryError(err);
+        }
+    };
+
     searchTag = async (hash: string) => {
         this.updateQueryLoading(true);
         try {
@@ -280,6 +297,20 @@ export class ExplorerStore {
         }
     }
 
+    loadMoreTagTxs = async (hash: string) => {
+        if (!this.tag || !this.tag.cursor) {
+            return;
+        }
+        try {
+            let res = await fetch(`/api/tag/${hash}?cursor=${this.tag.cursor}`);
+            let tag: TagResult = await res.json();
+            tag.txs = this.tag.txs.concat(tag.txs);
+            this.updateTag(tag);
+        } catch (error) {
+            this.updateQueryError(error);
+        }
+    };
+
     @action
     reset = () => {
         this.tx = null;
@@ -234,6 +234,12 @@ class Heartbeat {
     latest_milestone_index: number;
     connected_neighbors: number;
     synced_neighbors: number;
+    version: number;
+    snapshot_milestone_index: number;
+    feature_sets: number;
+    tip_pool_size: number;
+    request_queue_size: number;
+    alias: string;
 }
 
 class NeighborInfo {
@@ -244,6 +250,7 @@ class NeighborInfo {
     numberOfNewTransactions: number;
     numberOfKnownTransactions: number;
     numberOfStaleTransactions: number;
+    numberOfInvalidTransactions: number;
     numberOfReceivedTransactionReq: number;
     numberOfReceivedMilestoneReq: number;
     numberOfReceivedHeartbeats: number;
@@ -255,6 +262,16 @@ class NeighborInfo {
     connectionType: string;
     autopeeringId: string;
     connected: boolean;
+    score: NeighborScore;
+}
+
+class NeighborScore {
+    total: number;
+    usefulness: number;
+    latency: number;
+    avgLatencyMs: number;
+    validity: number;
+    heartbeat: number;
 }
 
 const chartSeriesOpts = {
@@ -35,7 +35,14 @@ func configure(plugin *node.Plugin) {
 		runtime.GOMAXPROCS(128)
 	}
 
-	tangle.ConfigureDatabases(config.NodeConfig.GetString(config.CfgDatabasePath))
+	engine, err := tangle.DatabaseEngineFromString(config.NodeConfig.GetString(config.CfgDatabaseEngine))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if err := tangle.ConfigureDatabases(config.NodeConfig.GetString(config.CfgDatabasePath), engine); err != nil {
+		log.Panicf("opening %s database failed: %v", engine, err)
+	}
 
 	if !tangle.IsCorrectDatabaseVersion() {
 		if !tangle.UpdateDatabaseVersion() {
@@ -9,19 +9,27 @@ import (
 )
 
 // BroadcastHeartbeat broadcasts a heartbeat message to every connected peer who supports STING.
+// Peers which support extended heartbeats receive an extended heartbeat instead.
 func BroadcastHeartbeat() {
-	snapshotInfo := tangle.GetSnapshotInfo()
-	if snapshotInfo == nil {
+	heartbeat := newHeartbeat()
+	if heartbeat == nil {
 		return
 	}
 
-	connected, synced := manager.ConnectedAndSyncedPeerCount()
+	heartbeatMsg, _ := sting.NewHeartbeatMessage(heartbeat.SolidMilestoneIndex, heartbeat.PrunedMilestoneIndex, heartbeat.LatestMilestoneIndex, uint8(heartbeat.ConnectedNeighbors), uint8(heartbeat.SyncedNeighbors))
+	extendedHeartbeatMsg, err := sting.NewExtendedHeartbeatMessage(heartbeat)
+	if err != nil {
+		extendedHeartbeatMsg = heartbeatMsg
+	}
 
-	heartbeatMsg, _ := sting.NewHeartbeatMessage(tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced)
 	manager.ForAllConnected(func(p *peer.Peer) bool {
 		if !p.Protocol.Supports(sting.FeatureSet) {
 			return true
 		}
+		if p.Protocol.Supports(sting.FeatureSetExtendedHeartbeat) {
+			p.EnqueueForSending(extendedHeartbeatMsg)
+			return true
+		}
 		p.EnqueueForSending(heartbeatMsg)
 		return true
 	})
@@ -40,6 +48,10 @@ func BroadcastLatestMilestoneRequest() {
 
 // BroadcastMilestoneRequests broadcasts up to N requests for milestones nearest to the current solid milestone index
 // to every connected peer who supports STING. Returns the number of milestones requested.
+// Peers which support milestone cone requests are asked for all transactions confirmed by the milestone instead,
+// which saves the round trips of requesting the missing approvees one by one.
+// The milestone cone requests to a peer are limited to the rate the peer answers, a milestone request is sent instead
+// if the limit of all peers is reached.
 func BroadcastMilestoneRequests(rangeToRequest int, onExistingMilestoneInRange func(index milestone.Index), from ...milestone.Index) int {
 	var requested int
 
@@ -68,6 +80,9 @@ func BroadcastMilestoneRequests(rangeToRequest int, onExistingMilestoneInRange f
 
 	// send each ms request to a random peer who supports the message
 	for _, msIndex := range msIndexes {
+		var fallbackPeer *peer.Peer
+		requested := false
+
 		manager.ForAllConnected(func(p *peer.Peer) bool {
 			if !p.Protocol.Supports(sting.FeatureSet) {
 				return true
@@ -75,9 +90,29 @@ func BroadcastMilestoneRequests(rangeToRequest int, onExistingMilestoneInRange f
 			if !p.HasDataFor(msIndex) {
 				return true
 			}
-			helpers.SendMilestoneRequest(p, msIndex)
+			if !p.Protocol.Supports(sting.FeatureSetMilestoneCone) || !takeMilestoneConeRequest(p) {
+				if fallbackPeer == nil {
+					fallbackPeer = p
+				}
+				return true
+			}
+			helpers.SendMilestoneConeRequest(p, msIndex)
+			requested = true
 			return false
 		})
+
+		if !requested && fallbackPeer != nil {
+			helpers.SendMilestoneRequest(fallbackPeer, msIndex)
+		}
 	}
 	return requested
 }
+
+// takeMilestoneConeRequest returns whether a milestone cone request can be sent to the given peer without exceeding its limit.
+func takeMilestoneConeRequest(p *peer.Peer) bool {
+	if milestoneConeRequestLimiter == nil {
+		return true
+	}
+	ok, _ := milestoneConeRequestLimiter.Take(p.ID, 1)
+	return ok
+}
@@ -0,0 +1,66 @@
+package gossip
+
+import (
+	"strings"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol"
+	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+)
+
+var (
+	tipPoolSizeFunc func() int
+)
+
+// SetTipPoolSizeFunc sets the function which returns the amount of tips in the tip pool.
+// The tip pool size is advertised to peers within extended heartbeats.
+func SetTipPoolSizeFunc(sizeFunc func() int) {
+	tipPoolSizeFunc = sizeFunc
+}
+
+// newHeartbeat creates a heartbeat containing the current state of the node.
+// Returns nil if no snapshot info is available yet.
+func newHeartbeat() *sting.Heartbeat {
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return nil
+	}
+
+	connected, synced := manager.ConnectedAndSyncedPeerCount()
+	queued, pending, _ := RequestQueue().Size()
+
+	var tipPoolSize int
+	if tipPoolSizeFunc != nil {
+		tipPoolSize = tipPoolSizeFunc()
+	}
+
+	var featureSets byte
+	if words := protocol.SupportedFeatureSets.Bytes(); len(words) > 0 {
+		featureSets = byte(words[0])
+	}
+
+	return &sting.Heartbeat{
+		SolidMilestoneIndex:    tangle.GetSolidMilestoneIndex(),
+		PrunedMilestoneIndex:   snapshotInfo.PruningIndex,
+		LatestMilestoneIndex:   tangle.GetLatestMilestoneIndex(),
+		ConnectedNeighbors:     int(connected),
+		SyncedNeighbors:        int(synced),
+		Version:                sting.ExtendedHeartbeatVersion,
+		SnapshotMilestoneIndex: snapshotInfo.SnapshotIndex,
+		FeatureSets:            featureSets,
+		TipPoolSize:            tipPoolSize,
+		RequestQueueSize:       queued + pending,
+		Alias:                  heartbeatAlias(),
+	}
+}
+
+// heartbeatAlias returns the configured node alias, cut to the max length allowed within a heartbeat.
+func heartbeatAlias() string {
+	alias := config.NodeConfig.GetString(config.CfgNodeAlias)
+	if len(alias) <= sting.ExtendedHeartbeatAliasMaxBytesLength {
+		return alias
+	}
+	// don't cut a multi-byte character in half
+	return strings.ToValidUTF8(alias[:sting.ExtendedHeartbeatAliasMaxBytesLength], "")
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/helpers"
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/events"
@@ -19,6 +18,7 @@ import (
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/processor"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/rqueue"
 	"github.com/Ariwonto/aingle-alpha/pkg/protocol/sting"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
 	"github.com/Ariwonto/aingle-alpha/pkg/shutdown"
 	peeringplugin "github.com/Ariwonto/aingle-alpha/plugins/peering"
 )
@@ -34,6 +34,9 @@ var (
 	broadcastQueue         bqueue.Queue
 	broadcastQueueOnce     sync.Once
 	onBroadcastTransaction *events.Closure
+
+	// limits the milestone cone requests sent to a peer to the requests the peer answers, nil if the requests are not limited
+	milestoneConeRequestLimiter *ratelimit.TokenBuckets
 )
 
 // RequestQueue returns the request queue instance of the gossip plugin.
@@ -56,8 +59,10 @@ func BroadcastQueue() bqueue.Queue {
 func Processor() *processor.Processor {
 	msgProcessorOnce.Do(func() {
 		msgProcessor = processor.New(requestQueue, peeringplugin.Manager(), &processor.Options{
-			ValidMWM:          config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
-			WorkUnitCacheOpts: profile.LoadProfile().Caches.IncomingTransactionFilter,
+			ValidMWM:                       config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
+			WorkUnitCacheOpts:              profile.LoadProfile().Caches.IncomingTransactionFilter,
+			MilestoneConeRequestsPerMinute: config.NodeConfig.GetInt(config.CfgNetGossipMilestoneConeRequestsPerMinute),
+			MaxMilestoneConeTransactions:   config.NodeConfig.GetInt(config.CfgNetGossipMilestoneConeMaxTransactions),
 		})
 	})
 	return msgProcessor
@@ -75,6 +80,10 @@ func configure(plugin *node.Plugin) {
 	// create new message processor
 	Processor()
 
+	if perMinute := config.NodeConfig.GetInt(config.CfgNetGossipMilestoneConeRequestsPerMinute); perMinute > 0 {
+		milestoneConeRequestLimiter = ratelimit.NewTokenBuckets(perMinute)
+	}
+
 	// handle broadcasts emitted by the message processor
 	onBroadcastTransaction = events.NewClosure(broadcastQueue.EnqueueForBroadcast)
 
@@ -85,9 +94,8 @@ func configure(plugin *node.Plugin) {
 			addSTINGMessageEventHandlers(p)
 
 			// send heartbeat and latest milestone request
-			if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
-				connected, synced := manager.ConnectedAndSyncedPeerCount()
-				helpers.SendHeartbeat(p, tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced)
+			if heartbeat := newHeartbeat(); heartbeat != nil {
+				helpers.SendHeartbeat(p, heartbeat)
 				helpers.SendLatestMilestoneRequest(p)
 			}
 		}
@@ -95,6 +103,9 @@ func configure(plugin *node.Plugin) {
 		disconnectSignal := make(chan struct{})
 		p.Conn.Events.Close.Attach(events.NewClosure(func() {
 			removeMessageEventHandlers(p)
+			if milestoneConeRequestLimiter != nil {
+				milestoneConeRequestLimiter.Remove(p.ID)
+			}
 			close(disconnectSignal)
 		}))
 
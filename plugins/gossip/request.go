@@ -70,40 +70,53 @@ func runRequestWorkers() {
 				}
 
 				// drain request queue
-				for r := RequestQueue().Next(); r != nil; r = RequestQueue().Next() {
-					requested := false
-					manager.ForAllConnected(func(p *peer.Peer) bool {
-						if !p.Protocol.Supports(sting.FeatureSet) {
-							return true
-						}
-						// we only send a request message if the peer actually has the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
-						if !p.HasDataFor(r.MilestoneIndex) {
-							return true
-						}
-
-						helpers.SendTransactionRequest(p, r.Hash)
-						requested = true
-						return false
-					})
+				for requests := RequestQueue().NextBatch(sting.MaxTransactionRequestBatchSize); len(requests) > 0; requests = RequestQueue().NextBatch(sting.MaxTransactionRequestBatchSize) {
+					// collect the requested hashes per peer, so they can be sent in batches
+					requestedHashes := make(map[*peer.Peer]aingle.Hashes)
 
-					if !requested {
-						// We have no neighbor that has the data for sure,
-						// so we ask all neighbors that could have the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
+					for _, r := range requests {
+						// prefer the peer with the smallest request backlog, since it is most likely to answer in time
+						var target *peer.Peer
 						manager.ForAllConnected(func(p *peer.Peer) bool {
 							if !p.Protocol.Supports(sting.FeatureSet) {
 								return true
 							}
-
-							// we only send a request message if the peer could have the data
-							if !p.CouldHaveDataFor(r.MilestoneIndex) {
+							// we only send a request message if the peer actually has the data
+							// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
+							if !p.HasDataFor(r.MilestoneIndex) {
 								return true
 							}
 
-							helpers.SendTransactionRequest(p, r.Hash)
+							if target == nil || p.LatestHeartbeat.RequestQueueSize < target.LatestHeartbeat.RequestQueueSize {
+								target = p
+							}
 							return true
 						})
+
+						if target != nil {
+							requestedHashes[target] = append(requestedHashes[target], r.Hash)
+						} else {
+							// We have no neighbor that has the data for sure,
+							// so we ask all neighbors that could have the data
+							// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
+							manager.ForAllConnected(func(p *peer.Peer) bool {
+								if !p.Protocol.Supports(sting.FeatureSet) {
+									return true
+								}
+
+								// we only send a request message if the peer could have the data
+								if !p.CouldHaveDataFor(r.MilestoneIndex) {
+									return true
+								}
+
+								requestedHashes[p] = append(requestedHashes[p], r.Hash)
+								return true
+							})
+						}
+					}
+
+					for p, hashes := range requestedHashes {
+						helpers.SendTransactionRequests(p, hashes)
 					}
 				}
 			}
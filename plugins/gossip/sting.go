@@ -19,7 +19,7 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		msgProcessor.Process(p, sting.MessageTypeTransaction, data)
 	}))
 
-	p.Protocol.Events.Sent[sting.MessageTypeTransaction].Attach(events.NewClosure(func() {
+	p.Protocol.Events.Sent[sting.MessageTypeTransaction].Attach(events.NewClosure(func(_ []byte) {
 		p.Metrics.SentPackets.Inc()
 		p.Metrics.SentTransactions.Inc()
 		metrics.SharedServerMetrics.SentTransactions.Inc()
@@ -31,7 +31,7 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		msgProcessor.Process(p, sting.MessageTypeTransactionRequest, data)
 	}))
 
-	p.Protocol.Events.Sent[sting.MessageTypeTransactionRequest].Attach(events.NewClosure(func() {
+	p.Protocol.Events.Sent[sting.MessageTypeTransactionRequest].Attach(events.NewClosure(func(_ []byte) {
 		p.Metrics.SentPackets.Inc()
 		p.Metrics.SentTransactionRequests.Inc()
 		metrics.SharedServerMetrics.SentTransactionRequests.Inc()
@@ -43,7 +43,7 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		msgProcessor.Process(p, sting.MessageTypeMilestoneRequest, data)
 	}))
 
-	p.Protocol.Events.Sent[sting.MessageTypeMilestoneRequest].Attach(events.NewClosure(func() {
+	p.Protocol.Events.Sent[sting.MessageTypeMilestoneRequest].Attach(events.NewClosure(func(_ []byte) {
 		p.Metrics.SentPackets.Inc()
 		p.Metrics.SentMilestoneRequests.Inc()
 		metrics.SharedServerMetrics.SentMilestoneRequests.Inc()
@@ -53,26 +53,114 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		p.Metrics.ReceivedHeartbeats.Inc()
 		metrics.SharedServerMetrics.ReceivedHeartbeats.Inc()
 
-		p.LatestHeartbeat = sting.ParseHeartbeat(data)
+		processHeartbeat(p, sting.ParseHeartbeat(data))
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(_ []byte) {
+		p.Metrics.SentPackets.Inc()
+		p.Metrics.SentHeartbeats.Inc()
+		metrics.SharedServerMetrics.SentHeartbeats.Inc()
+	}))
+
+	if p.Protocol.Supports(sting.FeatureSetTransactionBatch) {
+		addTransactionBatchMessageEventHandlers(p)
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetMilestoneCone) {
+		addMilestoneConeMessageEventHandlers(p)
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetExtendedHeartbeat) {
+		addExtendedHeartbeatMessageEventHandlers(p)
+	}
+}
+
+// processHeartbeat stores the received heartbeat of the given peer.
+func processHeartbeat(p *peer.Peer, heartbeat *sting.Heartbeat) {
+	p.UpdateLatestHeartbeat(heartbeat)
+
+	if p.Autopeering != nil && p.LatestHeartbeat.SolidMilestoneIndex < tangle.GetSnapshotInfo().PruningIndex {
+		// peer is connected via autopeering and its latest solid milestone index is below our pruning index.
+		// we can't help this neighbor to become sync, so it's better to drop the connection and free the slots for other peers.
+		log.Infof("dropping autopeered neighbor %s / %s because LSMI (%d) is below our pruning index (%d)", p.Autopeering.Address(), p.Autopeering.ID(), p.LatestHeartbeat.SolidMilestoneIndex, tangle.GetSnapshotInfo().PruningIndex)
+		peering.Manager().Remove(p.ID)
+		return
+	}
+
+	p.Events.HeartbeatUpdated.Trigger(p.LatestHeartbeat)
+}
+
+// addExtendedHeartbeatMessageEventHandlers adds the event handlers for extended heartbeats.
+func addExtendedHeartbeatMessageEventHandlers(p *peer.Peer) {
+
+	p.Protocol.Events.Received[sting.MessageTypeExtendedHeartbeat].Attach(events.NewClosure(func(data []byte) {
+		p.Metrics.ReceivedHeartbeats.Inc()
+		metrics.SharedServerMetrics.ReceivedHeartbeats.Inc()
 
-		if p.Autopeering != nil && p.LatestHeartbeat.SolidMilestoneIndex < tangle.GetSnapshotInfo().PruningIndex {
-			// peer is connected via autopeering and its latest solid milestone index is below our pruning index.
-			// we can't help this neighbor to become sync, so it's better to drop the connection and free the slots for other peers.
-			log.Infof("dropping autopeered neighbor %s / %s because LSMI (%d) is below our pruning index (%d)", p.Autopeering.Address(), p.Autopeering.ID(), p.LatestHeartbeat.SolidMilestoneIndex, tangle.GetSnapshotInfo().PruningIndex)
-			peering.Manager().Remove(p.ID)
+		heartbeat, err := sting.ParseExtendedHeartbeat(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
 			return
 		}
 
-		p.Events.HeartbeatUpdated.Trigger(p.LatestHeartbeat)
+		processHeartbeat(p, heartbeat)
 	}))
 
-	p.Protocol.Events.Sent[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func() {
+	p.Protocol.Events.Sent[sting.MessageTypeExtendedHeartbeat].Attach(events.NewClosure(func(_ []byte) {
 		p.Metrics.SentPackets.Inc()
 		p.Metrics.SentHeartbeats.Inc()
 		metrics.SharedServerMetrics.SentHeartbeats.Inc()
 	}))
 }
 
+// addMilestoneConeMessageEventHandlers adds the event handlers for milestone cone requests.
+func addMilestoneConeMessageEventHandlers(p *peer.Peer) {
+
+	p.Protocol.Events.Received[sting.MessageTypeMilestoneConeRequest].Attach(events.NewClosure(func(data []byte) {
+		p.Metrics.ReceivedMilestoneRequests.Inc()
+		metrics.SharedServerMetrics.ReceivedMilestoneRequests.Inc()
+		msgProcessor.Process(p, sting.MessageTypeMilestoneConeRequest, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeMilestoneConeRequest].Attach(events.NewClosure(func(_ []byte) {
+		p.Metrics.SentPackets.Inc()
+		p.Metrics.SentMilestoneRequests.Inc()
+		metrics.SharedServerMetrics.SentMilestoneRequests.Inc()
+	}))
+}
+
+// addTransactionBatchMessageEventHandlers adds the event handlers for batched transactions and transaction requests.
+func addTransactionBatchMessageEventHandlers(p *peer.Peer) {
+
+	// the contained transactions are counted while processing the batch
+	p.Protocol.Events.Received[sting.MessageTypeTransactionBatch].Attach(events.NewClosure(func(data []byte) {
+		msgProcessor.Process(p, sting.MessageTypeTransactionBatch, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeTransactionBatch].Attach(events.NewClosure(func(data []byte) {
+		// the sent batch was created by this node, so it can always be extracted
+		txsData, _ := sting.ExtractTransactions(data)
+		txsCount := uint32(len(txsData))
+		p.Metrics.SentPackets.Inc()
+		p.Metrics.SentTransactions.Add(txsCount)
+		metrics.SharedServerMetrics.SentTransactions.Add(txsCount)
+	}))
+
+	p.Protocol.Events.Received[sting.MessageTypeTransactionRequestBatch].Attach(events.NewClosure(func(data []byte) {
+		requestsCount := uint32(len(data) / sting.RequestedTransactionHashMsgBytesLength)
+		p.Metrics.ReceivedTransactionRequests.Add(requestsCount)
+		metrics.SharedServerMetrics.ReceivedTransactionRequests.Add(requestsCount)
+		msgProcessor.Process(p, sting.MessageTypeTransactionRequestBatch, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeTransactionRequestBatch].Attach(events.NewClosure(func(data []byte) {
+		requestsCount := uint32(len(data) / sting.RequestedTransactionHashMsgBytesLength)
+		p.Metrics.SentPackets.Inc()
+		p.Metrics.SentTransactionRequests.Add(requestsCount)
+		metrics.SharedServerMetrics.SentTransactionRequests.Add(requestsCount)
+	}))
+}
+
 // removeMessageEventHandlers removes all the event handlers for sent and received messages.
 func removeMessageEventHandlers(p *peer.Peer) {
 
@@ -22,6 +22,9 @@ func NewBroker() (*Broker, error) {
 		log.Fatal("configure broker config error: ", err)
 	}
 
+	// keep track of the subscribed address topics
+	registerAddressTopicsProvider()
+
 	b, err := broker.NewBroker(c)
 	if err != nil {
 		log.Fatal("New Broker error: ", err)
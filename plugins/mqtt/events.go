@@ -46,6 +46,14 @@ func onConfirmedTx(cachedMeta *tangle.CachedMetadata, msIndex milestone.Index, _
 		}
 
 		cachedTx.ConsumeTransaction(func(tx *aingle.Transaction) {
+			if metadata.IsConflicting() {
+				// conflicting topic
+				if err := publishConflictingTx(tx.Tx, msIndex, metadata.GetConflict()); err != nil {
+					log.Warn(err.Error())
+				}
+				return
+			}
+
 			// conf_trytes topic
 			if err := publishConfTrytes(tx.Tx, msIndex); err != nil {
 				log.Warn(err.Error())
@@ -145,15 +153,37 @@ func publishLSM(bndl *tangle.Bundle) error {
 }
 
 // Publish confirmed transaction
+// The transaction is also published to the address topic if the address has subscribers.
 func publishConfTx(iotaTx *transaction.Transaction, msIndex milestone.Index) error {
 
-	return mqttBroker.Send(topicSN, fmt.Sprintf(`{"msIndex":%d,"txHash":"%v","address":"%v","trunk":"%v","branch":"%v","bundle":"%v","timestamp":"%s"}`,
+	message := fmt.Sprintf(`{"msIndex":%d,"txHash":"%v","address":"%v","trunk":"%v","branch":"%v","bundle":"%v","timestamp":"%s"}`,
 		msIndex,                  // Index of the milestone that confirmed the transaction
 		iotaTx.Hash,              // Transaction hash
 		iotaTx.Address,           // Address
 		iotaTx.TrunkTransaction,  // Trunk transaction hash
 		iotaTx.BranchTransaction, // Branch transaction hash
 		iotaTx.Bundle,            // Bundle hash
+		time.Now().UTC().Format(time.RFC3339))
+
+	if err := mqttBroker.Send(topicSN, message); err != nil {
+		return err
+	}
+
+	if !addressTopics.IsSubscribed(iotaTx.Address) {
+		return nil
+	}
+	return mqttBroker.Send(addressTopicSN(iotaTx.Address), message)
+}
+
+// Publish transaction of a bundle which was excluded from the ledger by a milestone
+func publishConflictingTx(iotaTx *transaction.Transaction, msIndex milestone.Index, conflict aingle.Conflict) error {
+
+	return mqttBroker.Send(topicConflicting, fmt.Sprintf(`{"msIndex":%d,"txHash":"%v","address":"%v","bundle":"%v","reason":"%v","timestamp":"%s"}`,
+		msIndex,           // Index of the milestone that referenced the transaction
+		iotaTx.Hash,       // Transaction hash
+		iotaTx.Address,    // Address
+		iotaTx.Bundle,     // Bundle hash
+		conflict.String(), // Reason why the bundle was excluded from the ledger
 		time.Now().UTC().Format(time.RFC3339)))
 }
 
@@ -189,9 +219,10 @@ func publishTxTrytes(iotaTx *transaction.Transaction) error {
 }
 
 // Publish a transaction that has recently been added to the ledger
+// The transaction is also published to the address topic if the address has subscribers.
 func publishTx(iotaTx *transaction.Transaction) error {
 
-	return mqttBroker.Send(topicTX, fmt.Sprintf(`{"txHash":"%v","address":"%v","value":%d,"obsoleteTag":"%v","txTimestamp":%d,"currentIndex":%d,"lastIndex":%d,"bundle":"%v","trunk":"%v","branch":"%v","recTimestamp":%d,"tag":"%v","timestamp":"%s"}`,
+	message := fmt.Sprintf(`{"txHash":"%v","address":"%v","value":%d,"obsoleteTag":"%v","txTimestamp":%d,"currentIndex":%d,"lastIndex":%d,"bundle":"%v","trunk":"%v","branch":"%v","recTimestamp":%d,"tag":"%v","timestamp":"%s"}`,
 		iotaTx.Hash,              // Transaction hash
 		iotaTx.Address,           // Address
 		iotaTx.Value,             // Value
@@ -204,7 +235,16 @@ func publishTx(iotaTx *transaction.Transaction) error {
 		iotaTx.BranchTransaction, // Branch transaction hash
 		time.Now().Unix(),        // Unix timestamp for when the transaction was received
 		iotaTx.Tag,               // Tag
-		time.Now().UTC().Format(time.RFC3339)))
+		time.Now().UTC().Format(time.RFC3339))
+
+	if err := mqttBroker.Send(topicTX, message); err != nil {
+		return err
+	}
+
+	if !addressTopics.IsSubscribed(iotaTx.Address) {
+		return nil
+	}
+	return mqttBroker.Send(addressTopicTX(iotaTx.Address), message)
 }
 
 func publishSpentAddress(addr trinary.Hash) error {
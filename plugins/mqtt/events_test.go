@@ -0,0 +1,80 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/fhmq/hmq/broker"
+	"github.com/fhmq/hmq/broker/lib/topics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/transaction"
+)
+
+// publishedTopicsProvider records the topics of the messages published by the broker.
+type publishedTopicsProvider struct {
+	topics.TopicsProvider
+	published []string
+}
+
+func (p *publishedTopicsProvider) Subscribers(topic []byte, qos byte, subs *[]interface{}, qoss *[]byte) error {
+	p.published = append(p.published, string(topic))
+	return p.TopicsProvider.Subscribers(topic, qos, subs, qoss)
+}
+
+// newEventsTestBroker replaces the broker with one whose published topics are recorded.
+func newEventsTestBroker(t *testing.T) *publishedTopicsProvider {
+	resetAddressTopics(t)
+
+	provider := &publishedTopicsProvider{TopicsProvider: &addressTopicsProvider{TopicsProvider: topics.NewMemProvider()}}
+	topics.Unregister(brokerTopicsProvider)
+	topics.Register(brokerTopicsProvider, provider)
+	t.Cleanup(func() {
+		topics.Unregister(brokerTopicsProvider)
+		topics.Register(brokerTopicsProvider, topics.NewMemProvider())
+	})
+
+	b, err := broker.NewBroker(nil)
+	require.NoError(t, err)
+
+	prevBroker := mqttBroker
+	t.Cleanup(func() { mqttBroker = prevBroker })
+	mqttBroker = &Broker{broker: b}
+
+	return provider
+}
+
+func TestPublishAddressTopics(t *testing.T) {
+	provider := newEventsTestBroker(t)
+
+	txA := &transaction.Transaction{Hash: topicsTestAddressB, Address: topicsTestAddressA}
+	txB := &transaction.Transaction{Hash: topicsTestAddressA, Address: topicsTestAddressB}
+
+	// no address has subscribers
+	require.NoError(t, publishTx(txA))
+	require.NoError(t, publishConfTx(txA, 1))
+	assert.Equal(t, []string{topicTX, topicSN}, provider.published)
+
+	subscriber := &struct{ id int }{1}
+	_, err := provider.Subscribe([]byte(addressTopicTX(topicsTestAddressA)), 0, subscriber)
+	require.NoError(t, err)
+
+	// only the subscribed address is published to its address topics
+	provider.published = nil
+	require.NoError(t, publishTx(txA))
+	require.NoError(t, publishConfTx(txA, 1))
+	require.NoError(t, publishTx(txB))
+	require.NoError(t, publishConfTx(txB, 1))
+	assert.Equal(t, []string{
+		topicTX, addressTopicTX(topicsTestAddressA),
+		topicSN, addressTopicSN(topicsTestAddressA),
+		topicTX,
+		topicSN,
+	}, provider.published)
+
+	require.NoError(t, provider.Unsubscribe([]byte(addressTopicTX(topicsTestAddressA)), subscriber))
+
+	provider.published = nil
+	require.NoError(t, publishTx(txA))
+	assert.Equal(t, []string{topicTX}, provider.published)
+}
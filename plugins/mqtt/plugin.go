@@ -107,11 +107,9 @@ func run(plugin *node.Plugin) {
 			cachedMeta.Release(true) // meta -1
 			return
 		}
-		// Avoid notifying for conflicting txs
-		if !cachedMeta.GetMetadata().IsConflicting() {
-			if _, added := confirmedTxWorkerPool.TrySubmit(cachedMeta, msIndex, confTime); added { // meta pass +1
-				return // Avoid meta -1 (done inside workerpool task)
-			}
+		// conflicting txs are only published on the conflicting topic
+		if _, added := confirmedTxWorkerPool.TrySubmit(cachedMeta, msIndex, confTime); added { // meta pass +1
+			return // Avoid meta -1 (done inside workerpool task)
 		}
 		cachedMeta.Release(true) // meta -1
 	})
@@ -174,12 +172,6 @@ func run(plugin *node.Plugin) {
 		}
 	}, shutdown.PriorityMetricsPublishers)
 
-	/*
-		daemon.BackgroundWorker("MQTT address topic updater", func(shutdownSignal <-chan struct{}) {
-			timeutil.Ticker(updateAddressTopics, 5*time.Second, shutdownSignal)
-		}, shutdown.PriorityMetricsPublishers)
-	*/
-
 	daemon.BackgroundWorker("MQTT[NewTxWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[NewTxWorker] ... done")
 		tangle.Events.ReceivedNewTransaction.Attach(onReceivedNewTransaction)
@@ -1,70 +1,145 @@
 package mqtt
 
+import (
+	"strings"
+
+	"github.com/fhmq/hmq/broker/lib/topics"
+
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
 // Topic names
 const (
-	topicLMI          = "lmi"
-	topicLMSI         = "lmsi"
-	topicLMHS         = "lmhs"
-	topicLM           = "lm"
-	topicLSM          = "lsm"
-	topicSN           = "sn"
-	topicConfTrytes   = "conf_trytes"
-	topicTxTrytes     = "trytes"
-	topicTX           = "tx"
-	topicSpentAddress = "spent_address"
-	//topicPrefixAddress = "addr/"
+	topicLMI           = "lmi"
+	topicLMSI          = "lmsi"
+	topicLMHS          = "lmhs"
+	topicLM            = "lm"
+	topicLSM           = "lsm"
+	topicSN            = "sn"
+	topicConfTrytes    = "conf_trytes"
+	topicTxTrytes      = "trytes"
+	topicTX            = "tx"
+	topicSpentAddress  = "spent_address"
+	topicConflicting   = "conflicting"
+	topicPrefixAddress = "addr/"
+)
+
+const (
+	// the name of the topics provider used by the broker
+	brokerTopicsProvider = "mem"
 )
 
-/*
 var (
-	addressTopics AddressTopics
+	addressTopics = &AddressTopics{subscribers: make(map[trinary.Hash]map[addressSubscriber]struct{})}
 )
 
-// AddressTopics stuct
+// addressTopicTX returns the topic for new transactions of the given address.
+func addressTopicTX(addr trinary.Hash) string {
+	return topicPrefixAddress + addr + "/" + topicTX
+}
+
+// addressTopicSN returns the topic for confirmed transactions of the given address.
+func addressTopicSN(addr trinary.Hash) string {
+	return topicPrefixAddress + addr + "/" + topicSN
+}
+
+// addressFromTopic returns the address of an "addr/<address>/..." topic.
+// Only 81-tryte addresses without checksum in uppercase characters are valid.
+func addressFromTopic(topic string) (trinary.Hash, bool) {
+	if !strings.HasPrefix(topic, topicPrefixAddress) {
+		return "", false
+	}
+
+	addr := strings.SplitN(strings.TrimPrefix(topic, topicPrefixAddress), "/", 2)[0]
+	if !guards.IsTrytesOfExactLength(addr, consts.HashTrytesSize) {
+		return "", false
+	}
+	return addr, true
+}
+
+// addressSubscriber is a subscription of a client to an address topic.
+type addressSubscriber struct {
+	topic      string
+	subscriber interface{}
+}
+
+// AddressTopics keeps track of the addresses which have subscribers on the broker,
+// so only transactions of subscribed addresses are published to address topics.
 type AddressTopics struct {
-	mu         sync.Mutex
-	Addressses []string
+	mu          syncutils.RWMutex
+	subscribers map[trinary.Hash]map[addressSubscriber]struct{}
+}
+
+// IsSubscribed returns whether the given address has subscribers.
+func (a *AddressTopics) IsSubscribed(addr trinary.Hash) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, subscribed := a.subscribers[addr]
+	return subscribed
 }
 
-// GetAddressTopics returns subscribed addresses
-func GetAddressTopics() []string {
-	addressTopics.mu.Lock()
-	defer addressTopics.mu.Unlock()
-	return addressTopics.Addressses
+func (a *AddressTopics) add(topic string, subscriber interface{}) {
+	addr, ok := addressFromTopic(topic)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, exists := a.subscribers[addr]; !exists {
+		a.subscribers[addr] = make(map[addressSubscriber]struct{})
+	}
+	a.subscribers[addr][addressSubscriber{topic: topic, subscriber: subscriber}] = struct{}{}
 }
 
-// updateAddressTopics filters Subscribers for address topics
-func updateAddressTopics() {
-
-	addrTopic := []string{}
-	if mqttBroker != nil {
-
-		var subs []interface{}
-		var qoss []byte
-
-		err := mqttBroker.Subscribers([]byte(topicPrefixAddress), packet.Qos, &subs, &qoss)
-		if err != nil {
-			log.Errorf("search sub client error, %v", err)
-		}
-
-		for _, sub := range subs {
-			if s, ok := sub.(*subscription); ok {
-				err := address.ValidAddress(s)
-				if err == nil {
-					if len(s) == 90 {
-						addrTopic = append(addrTopic, s[:81])
-					} else if len(s) == 81 {
-						addrTopic = append(addrTopic, s)
-					}
-				}
-			}
-		}
+func (a *AddressTopics) remove(topic string, subscriber interface{}) {
+	addr, ok := addressFromTopic(topic)
+	if !ok {
+		return
 	}
 
-	sort.Strings(addrTopic)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	subscribers, exists := a.subscribers[addr]
+	if !exists {
+		return
+	}
+
+	delete(subscribers, addressSubscriber{topic: topic, subscriber: subscriber})
+	if len(subscribers) == 0 {
+		delete(a.subscribers, addr)
+	}
+}
+
+// addressTopicsProvider wraps the topics provider of the broker to keep track of the live subscriptions to address topics.
+type addressTopicsProvider struct {
+	topics.TopicsProvider
+}
+
+func (p *addressTopicsProvider) Subscribe(topic []byte, qos byte, subscriber interface{}) (byte, error) {
+	qos, err := p.TopicsProvider.Subscribe(topic, qos, subscriber)
+	if err != nil {
+		return qos, err
+	}
+
+	addressTopics.add(string(topic), subscriber)
+	return qos, nil
+}
+
+func (p *addressTopicsProvider) Unsubscribe(topic []byte, subscriber interface{}) error {
+	addressTopics.remove(string(topic), subscriber)
+	return p.TopicsProvider.Unsubscribe(topic, subscriber)
+}
 
-	addressTopics.mu.Lock()
-	addressTopics.Addressses = addrTopic
-	addressTopics.mu.Unlock()
+// registerAddressTopicsProvider replaces the topics provider of the broker with one which keeps track of the address topics.
+// It must be called before the broker is created.
+func registerAddressTopicsProvider() {
+	topics.Unregister(brokerTopicsProvider)
+	topics.Register(brokerTopicsProvider, &addressTopicsProvider{TopicsProvider: topics.NewMemProvider()})
 }
-*/
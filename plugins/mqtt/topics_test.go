@@ -0,0 +1,133 @@
+package mqtt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fhmq/hmq/broker/lib/topics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+var (
+	topicsTestAddressA = strings.Repeat("A", consts.HashTrytesSize)
+	topicsTestAddressB = strings.Repeat("B", consts.HashTrytesSize)
+)
+
+// resetAddressTopics replaces the address topics with empty ones for the duration of the test.
+func resetAddressTopics(t *testing.T) {
+	prevAddressTopics := addressTopics
+	t.Cleanup(func() { addressTopics = prevAddressTopics })
+	addressTopics = &AddressTopics{subscribers: make(map[trinary.Hash]map[addressSubscriber]struct{})}
+}
+
+func TestAddressFromTopic(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic string
+		addr  trinary.Hash
+		valid bool
+	}{
+		{"tx topic", addressTopicTX(topicsTestAddressA), topicsTestAddressA, true},
+		{"sn topic", addressTopicSN(topicsTestAddressA), topicsTestAddressA, true},
+		{"wildcard", topicPrefixAddress + topicsTestAddressA + "/#", topicsTestAddressA, true},
+		{"no sub topic", topicPrefixAddress + topicsTestAddressA, topicsTestAddressA, true},
+		{"lowercase", addressTopicTX(strings.ToLower(topicsTestAddressA)), "", false},
+		{"checksum", addressTopicTX(topicsTestAddressA + strings.Repeat("9", 9)), "", false},
+		{"too short", addressTopicTX(topicsTestAddressA[1:]), "", false},
+		{"wildcard address", topicPrefixAddress + "+/" + topicTX, "", false},
+		{"wrong prefix", "address/" + topicsTestAddressA + "/" + topicTX, "", false},
+		{"plain topic", topicTX, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr, valid := addressFromTopic(test.topic)
+			assert.Equal(t, test.valid, valid)
+			assert.Equal(t, test.addr, addr)
+		})
+	}
+}
+
+func TestAddressTopicsBookkeeping(t *testing.T) {
+	resetAddressTopics(t)
+
+	subscriberA := &struct{ id int }{1}
+	subscriberB := &struct{ id int }{2}
+
+	assert.False(t, addressTopics.IsSubscribed(topicsTestAddressA))
+
+	addressTopics.add(addressTopicTX(topicsTestAddressA), subscriberA)
+	addressTopics.add(addressTopicSN(topicsTestAddressA), subscriberA)
+	addressTopics.add(addressTopicTX(topicsTestAddressA), subscriberB)
+	addressTopics.add(topicTX, subscriberA)
+	addressTopics.add(addressTopicTX(strings.ToLower(topicsTestAddressB)), subscriberA)
+
+	assert.True(t, addressTopics.IsSubscribed(topicsTestAddressA))
+	assert.False(t, addressTopics.IsSubscribed(topicsTestAddressB))
+	assert.Len(t, addressTopics.subscribers, 1)
+	assert.Len(t, addressTopics.subscribers[topicsTestAddressA], 3)
+
+	// subscribing twice to the same topic is tracked once
+	addressTopics.add(addressTopicTX(topicsTestAddressA), subscriberB)
+	assert.Len(t, addressTopics.subscribers[topicsTestAddressA], 3)
+
+	// unknown subscriptions are ignored
+	addressTopics.remove(addressTopicSN(topicsTestAddressA), subscriberB)
+	addressTopics.remove(addressTopicTX(topicsTestAddressB), subscriberA)
+	addressTopics.remove(topicTX, subscriberA)
+	assert.Len(t, addressTopics.subscribers[topicsTestAddressA], 3)
+
+	addressTopics.remove(addressTopicTX(topicsTestAddressA), subscriberA)
+	addressTopics.remove(addressTopicSN(topicsTestAddressA), subscriberA)
+	assert.True(t, addressTopics.IsSubscribed(topicsTestAddressA))
+
+	// the address is removed with its last subscription
+	addressTopics.remove(addressTopicTX(topicsTestAddressA), subscriberB)
+	assert.False(t, addressTopics.IsSubscribed(topicsTestAddressA))
+	assert.Empty(t, addressTopics.subscribers)
+}
+
+func TestAddressTopicsProvider(t *testing.T) {
+	resetAddressTopics(t)
+
+	provider := &addressTopicsProvider{TopicsProvider: topics.NewMemProvider()}
+	subscriberA := &struct{ id int }{1}
+	subscriberB := &struct{ id int }{2}
+
+	_, err := provider.Subscribe([]byte(addressTopicTX(topicsTestAddressA)), 0, subscriberA)
+	require.NoError(t, err)
+	_, err = provider.Subscribe([]byte(addressTopicSN(topicsTestAddressA)), 0, subscriberB)
+	require.NoError(t, err)
+	_, err = provider.Subscribe([]byte(topicTX), 0, subscriberB)
+	require.NoError(t, err)
+	assert.True(t, addressTopics.IsSubscribed(topicsTestAddressA))
+
+	// subscriptions rejected by the wrapped provider are not tracked
+	_, err = provider.Subscribe([]byte(addressTopicTX(topicsTestAddressB)), 3, subscriberA)
+	require.Error(t, err)
+	_, err = provider.Subscribe([]byte(addressTopicTX(topicsTestAddressB)), 0, nil)
+	require.Error(t, err)
+	assert.False(t, addressTopics.IsSubscribed(topicsTestAddressB))
+
+	// the subscriptions are still passed to the wrapped provider
+	var subs []interface{}
+	var qoss []byte
+	require.NoError(t, provider.Subscribers([]byte(addressTopicTX(topicsTestAddressA)), 0, &subs, &qoss))
+	assert.Equal(t, []interface{}{subscriberA}, subs)
+
+	require.NoError(t, provider.Unsubscribe([]byte(addressTopicTX(topicsTestAddressA)), subscriberA))
+	assert.True(t, addressTopics.IsSubscribed(topicsTestAddressA))
+
+	require.NoError(t, provider.Subscribers([]byte(addressTopicTX(topicsTestAddressA)), 0, &subs, &qoss))
+	assert.Empty(t, subs)
+
+	require.NoError(t, provider.Unsubscribe([]byte(addressTopicSN(topicsTestAddressA)), subscriberB))
+	assert.False(t, addressTopics.IsSubscribed(topicsTestAddressA))
+
+	require.NoError(t, provider.Unsubscribe([]byte(topicTX), subscriberB))
+	assert.Empty(t, addressTopics.subscribers)
+}
@@ -1,10 +1,13 @@
 package peering
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/fsnotify/fsnotify"
+
 	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/peering"
 )
 
 func configurePeerConfigWatcher() {
@@ -26,6 +29,11 @@ func runConfigWatcher() {
 
 		modified, added, removed := getPeerConfigDiff()
 
+		// peers which are not part of the config anymore lose their pinned keys
+		for _, p := range removed {
+			Manager().UnpinKey(p.ID)
+		}
+
 		// remove peers if we do not accept connections from unknown peers
 		if !acceptAnyPeer && len(removed) > 0 {
 			for _, p := range removed {
@@ -46,7 +54,7 @@ func runConfigWatcher() {
 					log.Warn(err)
 				}
 				// and re-add it with the updated info
-				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias); err != nil {
+				if err := addPeer(&p); err != nil {
 					log.Warn("was unable to re-add modified peer %s", p.ID)
 				}
 			}
@@ -56,7 +64,7 @@ func runConfigWatcher() {
 		if len(added) > 0 {
 			log.Infof("adding peers due to config change")
 			for _, p := range added {
-				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias); err != nil {
+				if err := addPeer(&p); err != nil {
 					log.Warn("was unable to re-add modified peer %s", p.ID)
 				}
 			}
@@ -64,6 +72,20 @@ func runConfigWatcher() {
 	})
 }
 
+// pins the public key of the given peer and adds it to the manager.
+func addPeer(p *config.PeerConfig) error {
+	pinnedKey, err := peering.PinnedKeyFromConfig(p)
+	if err != nil {
+		return fmt.Errorf("invalid peer config %s: %w", p.ID, err)
+	}
+
+	if err := Manager().PinKey(p.ID, pinnedKey); err != nil {
+		return err
+	}
+
+	return Manager().Add(p.ID, p.PreferIPv6, p.Alias)
+}
+
 // calculates the diffs between the loaded peers and the modified config.
 func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 	currentPeers := Manager().PeerInfos()
@@ -83,7 +105,8 @@ func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 		for _, configPeer := range configPeers {
 			if strings.EqualFold(currentPeer.Address, configPeer.ID) || strings.EqualFold(currentPeer.DomainWithPort, configPeer.ID) {
 				found = true
-				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) {
+				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) ||
+					(currentPeer.PublicKey != configPeer.PublicKey) || (currentPeer.PlaintextDisabled != configPeer.DisablePlaintext) {
 					modified = append(modified, configPeer)
 				}
 				break
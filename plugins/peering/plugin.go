@@ -1,6 +1,9 @@
 package peering
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"sync"
@@ -66,13 +69,44 @@ func Manager() *peering.Manager {
 				ByteEncodedCooAddress: cooAddrBytes,
 				MWM:                   byte(mwm),
 			},
-			MaxConnected:  config.PeeringConfig.GetInt(config.CfgPeeringMaxPeers),
-			AcceptAnyPeer: config.PeeringConfig.GetBool(config.CfgPeeringAcceptAnyConnection),
+			MaxConnected:    config.PeeringConfig.GetInt(config.CfgPeeringMaxPeers),
+			AcceptAnyPeer:   config.PeeringConfig.GetBool(config.CfgPeeringAcceptAnyConnection),
+			SecureTransport: secureTransport(),
 		}, peers...)
 	})
 	return manager
 }
 
+// secureTransport creates the transport for secure channels to static peers or returns nil if secure channels are disabled.
+func secureTransport() *peering.SecureTransport {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipSecureChannelEnabled) {
+		return nil
+	}
+
+	var privateKey ed25519.PrivateKey
+	if seed := config.NodeConfig.GetString(config.CfgNetGossipSecureChannelPrivateKeySeed); seed != "" {
+		seedBytes, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil || len(seedBytes) != ed25519.SeedSize {
+			log.Fatalf("invalid '%s': must be a base64 encoded %d bytes seed", config.CfgNetGossipSecureChannelPrivateKeySeed, ed25519.SeedSize)
+		}
+		privateKey = ed25519.NewKeyFromSeed(seedBytes)
+	} else {
+		var err error
+		if _, privateKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			log.Fatalf("couldn't generate the secure channel key: %s", err)
+		}
+		log.Warnf("no '%s' configured, using a random key. the public key changes with every restart", config.CfgNetGossipSecureChannelPrivateKeySeed)
+	}
+
+	transport, err := peering.NewSecureTransport(privateKey)
+	if err != nil {
+		log.Fatalf("couldn't initialize the secure channel: %s", err)
+	}
+
+	log.Infof("secure channel public key: %s", peering.EncodePublicKey(transport.PublicKey()))
+	return transport
+}
+
 func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 
@@ -208,4 +242,29 @@ func run(_ *node.Plugin) {
 			timeutil.Ticker(checkStaledPeers, 60*time.Second, shutdownSignal)
 		}, shutdown.PriorityPeerReconnecter)
 	}
+
+	scorer := peering.NewScorer(Manager(), peering.ScoringOptions{
+		MinScore:          config.NodeConfig.GetFloat64(config.CfgNetPeerScoringMinScore),
+		BlacklistDuration: time.Duration(config.NodeConfig.GetInt(config.CfgNetPeerScoringBlacklistDurationMinutes)) * time.Minute,
+		EvictStaticPeers:  config.NodeConfig.GetBool(config.CfgNetPeerScoringEvictStaticPeers),
+		TargetLatency:     time.Duration(config.NodeConfig.GetInt(config.CfgNetPeerScoringTargetLatencyMilliseconds)) * time.Millisecond,
+		MaxHeartbeatAge:   time.Duration(config.NodeConfig.GetInt(config.CfgNetPeerScoringMaxHeartbeatAgeSeconds)) * time.Second,
+	})
+
+	// create a background worker that scores the connected peers and evicts misbehaving ones
+	daemon.BackgroundWorker("Peering Scoring", func(shutdownSignal <-chan struct{}) {
+
+		scorePeers := func() {
+			for _, eviction := range scorer.Update() {
+				if eviction.Static {
+					log.Infof("evicting static neighbor %s because of its score %0.2f, reconnecting after the blacklisting expired", eviction.Peer.ID, eviction.Score.Total)
+					continue
+				}
+				log.Infof("evicting neighbor %s because of its score %0.2f", eviction.Peer.ID, eviction.Score.Total)
+			}
+		}
+
+		intervalSec := config.NodeConfig.GetInt(config.CfgNetPeerScoringIntervalSeconds)
+		timeutil.Ticker(scorePeers, time.Duration(intervalSec)*time.Second, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
 }
@@ -0,0 +1,41 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/metrics"
+)
+
+var (
+	webAPIRateLimitedRequests        *prometheus.GaugeVec
+	webAPIConcurrencyLimitedRequests *prometheus.GaugeVec
+)
+
+func init() {
+	webAPIRateLimitedRequests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_webapi_rate_limited_requests",
+			Help: "Number of web API requests rejected because the client exceeded its rate limit.",
+		},
+		[]string{"class"},
+	)
+	webAPIConcurrencyLimitedRequests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_webapi_concurrency_limited_requests",
+			Help: "Number of web API requests rejected because too many requests were processed concurrently.",
+		},
+		[]string{"class"},
+	)
+
+	registry.MustRegister(webAPIRateLimitedRequests)
+	registry.MustRegister(webAPIConcurrencyLimitedRequests)
+
+	addCollect(collectWebAPI)
+}
+
+func collectWebAPI() {
+	webAPIRateLimitedRequests.WithLabelValues("normal").Set(float64(metrics.SharedWebAPIMetrics.RateLimitedRequests.Load()))
+	webAPIRateLimitedRequests.WithLabelValues("expensive").Set(float64(metrics.SharedWebAPIMetrics.RateLimitedExpensiveRequests.Load()))
+	webAPIConcurrencyLimitedRequests.WithLabelValues("normal").Set(float64(metrics.SharedWebAPIMetrics.ConcurrencyLimitedRequests.Load()))
+	webAPIConcurrencyLimitedRequests.WithLabelValues("expensive").Set(float64(metrics.SharedWebAPIMetrics.ConcurrencyLimitedExpensiveRequests.Load()))
+}
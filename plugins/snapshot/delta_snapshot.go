@@ -0,0 +1,429 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+type deltaSnapshotHeader struct {
+	info             *tangle.SnapshotInfo
+	baseIndex        milestone.Index
+	baseHash         aingle.Hash
+	solidEntryPoints map[string]milestone.Index
+	ledgerDiffs      map[milestone.Index]map[string]int64
+	spentAddresses   map[string]struct{}
+}
+
+// getLedgerDiffsForRange returns the ledger diffs of all milestones in (baseIndex, targetIndex]
+// and the addresses that were spent in these milestones.
+func getLedgerDiffsForRange(baseIndex milestone.Index, targetIndex milestone.Index, abortSignal <-chan struct{}) (map[milestone.Index]map[string]int64, map[string]struct{}, error) {
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	ledgerDiffs := make(map[milestone.Index]map[string]int64)
+	spentAddresses := make(map[string]struct{})
+
+	for milestoneIndex := baseIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		diff, err := tangle.GetLedgerDiffForMilestoneWithoutLocking(milestoneIndex, abortSignal)
+		if err != nil {
+			if err == tangle.ErrOperationAborted {
+				return nil, nil, ErrSnapshotCreationWasAborted
+			}
+			return nil, nil, err
+		}
+		ledgerDiffs[milestoneIndex] = diff
+
+		// addresses are marked as spent if their balance decreased, the same way it is done for confirmed bundles
+		for address, change := range diff {
+			if change < 0 {
+				spentAddresses[address] = struct{}{}
+			}
+		}
+	}
+
+	return ledgerDiffs, spentAddresses, nil
+}
+
+// getMilestoneHash returns the hash of the given milestone.
+// The snapshot milestone may already be pruned, so its hash is taken from the snapshot info.
+func getMilestoneHash(msIndex milestone.Index, snapshotInfo *tangle.SnapshotInfo) (aingle.Hash, error) {
+	if msIndex == snapshotInfo.SnapshotIndex {
+		return snapshotInfo.Hash, nil
+	}
+
+	cachedMs := tangle.GetMilestoneOrNil(msIndex) // bundle +1
+	if cachedMs == nil {
+		return nil, errors.Wrapf(ErrNotEnoughHistory, "milestone (%d) not found", msIndex)
+	}
+	defer cachedMs.Release(true) // bundle -1
+
+	return cachedMs.GetBundle().GetMilestoneHash(), nil
+}
+
+func writeDeltaSnapshot(writer io.Writer, dsh *deltaSnapshotHeader, abortSignal <-chan struct{}) error {
+
+	snapshotWriter, err := snapshot.NewDeltaWriter(writer, abortSignal)
+	if err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteInfo(dsh.info); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteDeltaBase(dsh.baseIndex, dsh.baseHash); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteSolidEntryPoints(dsh.solidEntryPoints); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteLedgerDiffs(dsh.ledgerDiffs); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteSpentAddresses(int32(len(dsh.spentAddresses)), func(buf io.Writer, abortSignal <-chan struct{}) (int32, error) {
+		var written int32
+		for address := range dsh.spentAddresses {
+			select {
+			case <-abortSignal:
+				return written, tangle.ErrOperationAborted
+			default:
+			}
+
+			if err := binary.Write(buf, binary.LittleEndian, []byte(address)[:49]); err != nil {
+				return written, err
+			}
+			written++
+		}
+		return written, nil
+	}); err != nil {
+		return err
+	}
+
+	return snapshotWriter.Close()
+}
+
+func createDeltaSnapshotWithoutLocking(baseIndex milestone.Index, targetIndex milestone.Index, filePath string, abortSignal <-chan struct{}) error {
+
+	log.Infof("creating delta snapshot from index %d to %d", baseIndex, targetIndex)
+	ts := time.Now()
+
+	setIsSnapshotting(true)
+	defer setIsSnapshotting(false)
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.Wrap(ErrCritical, "no snapshot info found")
+	}
+
+	if baseIndex >= targetIndex {
+		return errors.Wrapf(ErrTargetIndexTooOld, "base index: %d, target index: %d", baseIndex, targetIndex)
+	}
+
+	if baseIndex < snapshotInfo.PruningIndex {
+		// the ledger diffs of pruned milestones are not available anymore
+		return errors.Wrapf(ErrNotEnoughHistory, "minimum base index: %d, actual base index: %d", snapshotInfo.PruningIndex, baseIndex)
+	}
+
+	if err := checkSnapshotLimits(targetIndex, snapshotInfo, false); err != nil {
+		return err
+	}
+
+	baseHash, err := getMilestoneHash(baseIndex, snapshotInfo)
+	if err != nil {
+		return err
+	}
+
+	cachedTargetMs := tangle.GetMilestoneOrNil(targetIndex) // bundle +1
+	if cachedTargetMs == nil {
+		return errors.Wrapf(ErrCritical, "target milestone (%d) not found", targetIndex)
+	}
+	defer cachedTargetMs.Release(true) // bundle -1
+
+	cachedTargetMsTail := cachedTargetMs.GetBundle().GetTail() // tx +1
+	defer cachedTargetMsTail.Release(true)                     // tx -1
+
+	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
+	if err != nil {
+		return err
+	}
+
+	ledgerDiffs, spentAddresses, err := getLedgerDiffsForRange(baseIndex, targetIndex, abortSignal)
+	if err != nil {
+		if err == ErrSnapshotCreationWasAborted {
+			return err
+		}
+		return errors.Wrap(ErrCritical, err.Error())
+	}
+
+	targetInfo := &tangle.SnapshotInfo{
+		CoordinatorAddress: snapshotInfo.CoordinatorAddress,
+		Hash:               cachedTargetMs.GetBundle().GetMilestoneHash(),
+		SnapshotIndex:      targetIndex,
+		EntryPointIndex:    targetIndex,
+		PruningIndex:       targetIndex,
+		Timestamp:          cachedTargetMsTail.GetTransaction().GetTimestamp(),
+	}
+	targetInfo.SetSpentAddressesEnabled(snapshotInfo.IsSpentAddressesEnabled())
+
+	if !snapshotInfo.IsSpentAddressesEnabled() {
+		spentAddresses = make(map[string]struct{})
+	}
+
+	dsh := &deltaSnapshotHeader{
+		info:             targetInfo,
+		baseIndex:        baseIndex,
+		baseHash:         baseHash,
+		solidEntryPoints: newSolidEntryPoints,
+		ledgerDiffs:      ledgerDiffs,
+		spentAddresses:   spentAddresses,
+	}
+
+	if err := createSnapshotFile(filePath, func(writer io.Writer) error {
+		return writeDeltaSnapshot(writer, dsh, abortSignal)
+	}); err != nil {
+		return err
+	}
+
+	log.Infof("created delta snapshot from index %d to %d (%v), took %v", baseIndex, targetIndex, targetInfo.Hash.Trytes(), time.Since(ts))
+
+	return nil
+}
+
+// CreateDeltaSnapshot creates a delta snapshot file containing the changes between the base and the target milestone index.
+func CreateDeltaSnapshot(baseIndex milestone.Index, targetIndex milestone.Index, filePath string, abortSignal <-chan struct{}) error {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	return createDeltaSnapshotWithoutLocking(baseIndex, targetIndex, filePath, abortSignal)
+}
+
+// VerifyDeltaSnapshotFile checks the structure and the checksum of a delta snapshot file without loading it.
+func VerifyDeltaSnapshotFile(filePath string) error {
+	return readSnapshotFile(filePath, snapshot.ReadDelta, nil)
+}
+
+// readDeltaSnapshotFile reads the whole content of a delta snapshot file.
+func readDeltaSnapshotFile(filePath string) (*deltaSnapshotHeader, error) {
+
+	dsh := &deltaSnapshotHeader{
+		solidEntryPoints: make(map[string]milestone.Index),
+		ledgerDiffs:      make(map[milestone.Index]map[string]int64),
+		spentAddresses:   make(map[string]struct{}),
+	}
+
+	if err := readSnapshotFile(filePath, snapshot.ReadDelta, &snapshot.Consumers{
+		Info: func(info *tangle.SnapshotInfo) error {
+			dsh.info = info
+			return nil
+		},
+		DeltaBase: func(index milestone.Index, hash aingle.Hash) error {
+			dsh.baseIndex = index
+			dsh.baseHash = hash
+			return nil
+		},
+		SolidEntryPoint: func(hash aingle.Hash, index milestone.Index) error {
+			dsh.solidEntryPoints[string(hash)] = index
+			return nil
+		},
+		LedgerDiff: func(index milestone.Index, diff map[string]int64) error {
+			if daemon.IsStopped() {
+				return ErrSnapshotImportWasAborted
+			}
+			dsh.ledgerDiffs[index] = diff
+			return nil
+		},
+		SpentAddress: func(address aingle.Hash) error {
+			if daemon.IsStopped() {
+				return ErrSnapshotImportWasAborted
+			}
+			dsh.spentAddresses[string(address)] = struct{}{}
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return dsh, nil
+}
+
+// checkDeltaLedgerDiffs applies the ledger diffs of the delta snapshot to the given balances.
+// It returns an error instead of panicking if the diffs do not fit the ledger state.
+func checkDeltaLedgerDiffs(balances map[string]uint64, dsh *deltaSnapshotHeader) error {
+
+	for milestoneIndex := dsh.baseIndex + 1; milestoneIndex <= dsh.info.SnapshotIndex; milestoneIndex++ {
+		diff, exists := dsh.ledgerDiffs[milestoneIndex]
+		if !exists {
+			return fmt.Errorf("ledger diff for milestone %d is missing", milestoneIndex)
+		}
+
+		var diffSum int64
+		for address, change := range diff {
+			newBalance := int64(balances[address]) + change
+
+			if newBalance < 0 {
+				return fmt.Errorf("ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, aingle.Hash(address).Trytes(), balances[address], change)
+			} else if newBalance == 0 {
+				delete(balances, address)
+			} else {
+				balances[address] = uint64(newBalance)
+			}
+
+			diffSum += change
+		}
+
+		if diffSum != 0 {
+			return fmt.Errorf("ledger diff for milestone %d does not sum up to zero", milestoneIndex)
+		}
+	}
+
+	if len(dsh.ledgerDiffs) != int(dsh.info.SnapshotIndex-dsh.baseIndex) {
+		return fmt.Errorf("delta snapshot contains ledger diffs outside of the range (%d, %d]", dsh.baseIndex, dsh.info.SnapshotIndex)
+	}
+
+	return nil
+}
+
+// applyDeltaLedgerDiffs checks and applies the ledger diffs of the delta snapshot to the ledger.
+// It returns the balances at the target index of the delta snapshot.
+func applyDeltaLedgerDiffs(dsh *deltaSnapshotHeader) (map[string]uint64, error) {
+
+	tangle.WriteLockLedger()
+	defer tangle.WriteUnlockLedger()
+
+	balances, ledgerIndex, err := tangle.GetLedgerStateForLSMIWithoutLocking(nil)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSnapshotImportFailed, "ledger state: %v", err)
+	}
+
+	if ledgerIndex != dsh.baseIndex {
+		return nil, errors.Wrapf(ErrDeltaBaseMismatch, "ledger index: %d, delta base index: %d", ledgerIndex, dsh.baseIndex)
+	}
+
+	// check all diffs before the ledger is modified
+	if err := checkDeltaLedgerDiffs(balances, dsh); err != nil {
+		return nil, errors.Wrap(ErrSnapshotImportFailed, err.Error())
+	}
+
+	for milestoneIndex := dsh.baseIndex + 1; milestoneIndex <= dsh.info.SnapshotIndex; milestoneIndex++ {
+		if err := tangle.ApplyLedgerDiffWithoutLocking(dsh.ledgerDiffs[milestoneIndex], milestoneIndex); err != nil {
+			return nil, errors.Wrapf(ErrCritical, "applying ledger diff for milestone %d failed: %v", milestoneIndex, err)
+		}
+	}
+
+	return balances, nil
+}
+
+// LoadDeltaSnapshotFromFile applies a delta snapshot file on top of the current snapshot of the node.
+// The base of the delta snapshot must be the current snapshot milestone and the node must not have solidified
+// any milestone above it. Delta snapshots which target an index lower or equal to the current snapshot index are skipped.
+func LoadDeltaSnapshotFromFile(filePath string) error {
+
+	log.Infof("Verifying delta snapshot file %s...", filePath)
+
+	if err := VerifyDeltaSnapshotFile(filePath); err != nil {
+		return err
+	}
+
+	dsh, err := readDeltaSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.Wrap(ErrDeltaBaseMismatch, "no snapshot info found")
+	}
+
+	if dsh.info.SnapshotIndex <= snapshotInfo.SnapshotIndex {
+		log.Infof("Skipping delta snapshot to index %d, snapshot index is already %d", dsh.info.SnapshotIndex, snapshotInfo.SnapshotIndex)
+		return nil
+	}
+
+	if dsh.baseIndex != snapshotInfo.SnapshotIndex || !bytes.Equal(dsh.baseHash, snapshotInfo.Hash) {
+		return errors.Wrapf(ErrDeltaBaseMismatch, "snapshot index: %d (%v), delta base index: %d (%v)", snapshotInfo.SnapshotIndex, snapshotInfo.Hash.Trytes(), dsh.baseIndex, dsh.baseHash.Trytes())
+	}
+
+	if solidMilestoneIndex := tangle.GetSolidMilestoneIndex(); solidMilestoneIndex != snapshotInfo.SnapshotIndex {
+		return errors.Wrapf(ErrDeltaBaseMismatch, "solid milestone index %d is above the snapshot index %d", solidMilestoneIndex, snapshotInfo.SnapshotIndex)
+	}
+
+	log.Infof("Applying delta snapshot from index %d to %d...", dsh.baseIndex, dsh.info.SnapshotIndex)
+
+	targetIndex := dsh.info.SnapshotIndex
+
+	balances, err := applyDeltaLedgerDiffs(dsh)
+	if err != nil {
+		return err
+	}
+
+	// the ledger was already modified, every error from now on leaves the database in an inconsistent state
+	if err := tangle.StoreSnapshotBalancesInDatabase(balances, targetIndex); err != nil {
+		return errors.Wrapf(ErrCritical, "snapshot ledger state: %v", err)
+	}
+
+	tangle.WriteLockSolidEntryPoints()
+	tangle.ResetSolidEntryPoints()
+
+	// Genesis transaction
+	tangle.SolidEntryPointsAdd(aingle.NullHashBytes, targetIndex)
+
+	for hash, index := range dsh.solidEntryPoints {
+		tangle.SolidEntryPointsAdd(aingle.Hash(hash), index)
+	}
+
+	tangle.StoreSolidEntryPoints()
+	tangle.WriteUnlockSolidEntryPoints()
+
+	// the spent addresses are only complete if the base snapshot and the delta snapshot contain them
+	spentAddressesEnabled := snapshotInfo.IsSpentAddressesEnabled() && dsh.info.IsSpentAddressesEnabled()
+	if spentAddressesEnabled {
+		tangle.WriteLockSpentAddresses()
+		for address := range dsh.spentAddresses {
+			tangle.MarkAddressAsSpentWithoutLocking(aingle.Hash(address))
+		}
+		tangle.WriteUnlockSpentAddresses()
+	}
+
+	tangle.SetSnapshotMilestone(snapshotInfo.CoordinatorAddress, dsh.info.Hash, targetIndex, targetIndex, targetIndex, dsh.info.Timestamp, spentAddressesEnabled)
+	tangle.SetSolidMilestoneIndex(targetIndex)
+	tangle.SetLatestSeenMilestoneIndexFromSnapshot(targetIndex)
+
+	tangleplugin.Events.SnapshotMilestoneIndexChanged.Trigger(targetIndex)
+
+	log.Infof("Finished applying delta snapshot to milestone %d", targetIndex)
+
+	return nil
+}
+
+// loadDeltaSnapshotsFromConfig applies the configured delta snapshot files in the given order.
+// Delta snapshots which can't be applied are skipped, the node continues from the last snapshot that was applied.
+// Only critical errors, which leave the database in an inconsistent state, are returned.
+func loadDeltaSnapshotsFromConfig() error {
+	for _, path := range config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDeltaPaths) {
+		if err := LoadDeltaSnapshotFromFile(path); err != nil {
+			if errors.Is(err, ErrCritical) {
+				return errors.Wrapf(err, "loading delta snapshot %s failed", path)
+			}
+			log.Warnf("Skipping delta snapshot %s, it can't be applied: %v", path, err)
+		}
+	}
+	return nil
+}
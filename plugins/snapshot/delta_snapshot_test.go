@@ -0,0 +1,233 @@
+package snapshot
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+)
+
+func testHash(b byte) aingle.Hash {
+	hash := make(aingle.Hash, 49)
+	hash[0] = b
+	return hash
+}
+
+var (
+	addrA = testHash(1)
+	addrB = testHash(2)
+	addrC = testHash(3)
+
+	// the solid entry points can only be initialized once, they are reset by loading a snapshot
+	loadInitialValuesOnce sync.Once
+)
+
+// loadTestSnapshot loads a full snapshot at the given index into empty in-memory storages.
+// The whole supply is stored on addrA.
+func loadTestSnapshot(t *testing.T, dir string, index milestone.Index) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Profile1GB.Caches)
+	t.Cleanup(tangle.ShutdownStorages)
+	loadInitialValuesOnce.Do(tangle.LoadInitialValuesFromDatabase)
+	tangle.OverwriteSolidMilestoneIndex(0)
+
+	info := &tangle.SnapshotInfo{
+		CoordinatorAddress: aingle.NullHashBytes,
+		Hash:               testHash(byte(index)),
+		SnapshotIndex:      index,
+		EntryPointIndex:    index,
+		PruningIndex:       index,
+		Timestamp:          1600000000,
+	}
+
+	filePath := filepath.Join(dir, "full.bin")
+	require.NoError(t, createSnapshotFile(filePath, func(writer io.Writer) error {
+		snapshotWriter, err := snapshot.NewWriter(writer, nil)
+		if err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteInfo(info); err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteSolidEntryPoints(map[string]milestone.Index{string(testHash(50)): index}); err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteSeenMilestones(map[string]milestone.Index{}); err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteBalances(map[string]uint64{string(addrA): consts.TotalSupply}); err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteLedgerDiffs(map[milestone.Index]map[string]int64{}); err != nil {
+			return err
+		}
+		if err := snapshotWriter.WriteSpentAddresses(0, nil); err != nil {
+			return err
+		}
+		return snapshotWriter.Close()
+	}))
+
+	require.NoError(t, LoadSnapshotFromFile(filePath))
+}
+
+// newTestDeltaSnapshot returns a delta snapshot from baseIndex to baseIndex+2 which moves funds from addrA to addrB and addrC.
+func newTestDeltaSnapshot(baseIndex milestone.Index) *deltaSnapshotHeader {
+	targetIndex := baseIndex + 2
+
+	return &deltaSnapshotHeader{
+		info: &tangle.SnapshotInfo{
+			CoordinatorAddress: aingle.NullHashBytes,
+			Hash:               testHash(byte(targetIndex)),
+			SnapshotIndex:      targetIndex,
+			EntryPointIndex:    targetIndex,
+			PruningIndex:       targetIndex,
+			Timestamp:          1600000100,
+		},
+		baseIndex:        baseIndex,
+		baseHash:         testHash(byte(baseIndex)),
+		solidEntryPoints: map[string]milestone.Index{string(testHash(60)): targetIndex},
+		ledgerDiffs: map[milestone.Index]map[string]int64{
+			baseIndex + 1: {string(addrA): -100, string(addrB): 100},
+			baseIndex + 2: {string(addrB): -40, string(addrC): 40},
+		},
+		spentAddresses: map[string]struct{}{string(addrA): {}, string(addrB): {}},
+	}
+}
+
+func writeTestDeltaSnapshot(t *testing.T, dir string, name string, dsh *deltaSnapshotHeader) string {
+	filePath := filepath.Join(dir, name)
+	require.NoError(t, createSnapshotFile(filePath, func(writer io.Writer) error {
+		return writeDeltaSnapshot(writer, dsh, nil)
+	}))
+	return filePath
+}
+
+// assertLedger checks the ledger index and the balances of the test addresses.
+func assertLedger(t *testing.T, ledgerIndex milestone.Index, balanceA uint64, balanceB uint64, balanceC uint64) {
+	for address, expected := range map[string]uint64{string(addrA): balanceA, string(addrB): balanceB, string(addrC): balanceC} {
+		balance, index, err := tangle.GetBalanceForAddress(aingle.Hash(address))
+		require.NoError(t, err)
+		assert.Equal(t, expected, balance)
+		assert.Equal(t, ledgerIndex, index)
+	}
+}
+
+func TestLoadDeltaSnapshotFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	loadTestSnapshot(t, dir, 10)
+
+	dsh := newTestDeltaSnapshot(10)
+	filePath := writeTestDeltaSnapshot(t, dir, "delta.bin", dsh)
+
+	// the written file contains the same data
+	readDsh, err := readDeltaSnapshotFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, dsh, readDsh)
+
+	require.NoError(t, LoadDeltaSnapshotFromFile(filePath))
+	assertLedger(t, 12, consts.TotalSupply-100, 60, 40)
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	assert.Equal(t, milestone.Index(12), snapshotInfo.SnapshotIndex)
+	assert.Equal(t, testHash(12), snapshotInfo.Hash)
+	assert.Equal(t, milestone.Index(12), tangle.GetSolidMilestoneIndex())
+	assert.True(t, tangle.SolidEntryPointsContain(testHash(60)))
+	assert.False(t, tangle.SolidEntryPointsContain(testHash(50)))
+
+	// the ledger diffs of the delta are available for the balance history
+	changes, startBalance, err := tangle.GetBalanceHistory(addrB, 11, 12)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), startBalance)
+	assert.Equal(t, []*tangle.BalanceChange{
+		{MilestoneIndex: 11, Delta: 100, Balance: 100},
+		{MilestoneIndex: 12, Delta: -40, Balance: 60},
+	}, changes)
+
+	// a delta which was already applied is skipped
+	require.NoError(t, LoadDeltaSnapshotFromFile(filePath))
+	assertLedger(t, 12, consts.TotalSupply-100, 60, 40)
+}
+
+func TestLoadDeltaSnapshotFromFileBaseMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	loadTestSnapshot(t, dir, 10)
+
+	// the base index does not match the snapshot index
+	err = LoadDeltaSnapshotFromFile(writeTestDeltaSnapshot(t, dir, "delta.bin", newTestDeltaSnapshot(9)))
+	assert.True(t, errors.Is(err, ErrDeltaBaseMismatch))
+
+	// the base hash does not match the snapshot milestone
+	dsh := newTestDeltaSnapshot(10)
+	dsh.baseHash = testHash(99)
+	err = LoadDeltaSnapshotFromFile(writeTestDeltaSnapshot(t, dir, "delta.bin", dsh))
+	assert.True(t, errors.Is(err, ErrDeltaBaseMismatch))
+
+	// the ledger is checked as well
+	_, err = applyDeltaLedgerDiffs(newTestDeltaSnapshot(9))
+	assert.True(t, errors.Is(err, ErrDeltaBaseMismatch))
+
+	assertLedger(t, 10, consts.TotalSupply, 0, 0)
+	assert.Equal(t, milestone.Index(10), tangle.GetSnapshotInfo().SnapshotIndex)
+}
+
+func TestLoadDeltaSnapshotFromFileNegativeBalance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	loadTestSnapshot(t, dir, 10)
+
+	// the first diff is valid, the second one spends more than addrB owns
+	dsh := newTestDeltaSnapshot(10)
+	dsh.ledgerDiffs[12] = map[string]int64{string(addrB): -200, string(addrC): 200}
+
+	err = LoadDeltaSnapshotFromFile(writeTestDeltaSnapshot(t, dir, "delta.bin", dsh))
+	assert.True(t, errors.Is(err, ErrSnapshotImportFailed))
+
+	// the ledger is not modified
+	assertLedger(t, 10, consts.TotalSupply, 0, 0)
+	assert.Equal(t, milestone.Index(10), tangle.GetSnapshotInfo().SnapshotIndex)
+	assert.Equal(t, milestone.Index(10), tangle.GetSolidMilestoneIndex())
+}
+
+func TestLoadDeltaSnapshotsFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	loadTestSnapshot(t, dir, 10)
+
+	// the deltas which can't be applied are skipped, the node continues with the last applied snapshot
+	config.NodeConfig.Set(config.CfgLocalSnapshotsDeltaPaths, []string{
+		filepath.Join(dir, "missing.bin"),
+		writeTestDeltaSnapshot(t, dir, "mismatch.bin", newTestDeltaSnapshot(9)),
+		writeTestDeltaSnapshot(t, dir, "delta.bin", newTestDeltaSnapshot(10)),
+		writeTestDeltaSnapshot(t, dir, "gap.bin", newTestDeltaSnapshot(13)),
+	})
+	defer config.NodeConfig.Set(config.CfgLocalSnapshotsDeltaPaths, []string{})
+
+	require.NoError(t, loadDeltaSnapshotsFromConfig())
+	assertLedger(t, 12, consts.TotalSupply-100, 60, 40)
+	assert.Equal(t, milestone.Index(12), tangle.GetSnapshotInfo().SnapshotIndex)
+}
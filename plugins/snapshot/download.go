@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/guards"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+)
+
+// downloadProgressLogger logs the progress of a download at most once a second.
+type downloadProgressLogger struct {
+	last time.Time
+}
+
+func (l *downloadProgressLogger) onProgress(url string, written uint64, total uint64) {
+	if time.Since(l.last) < time.Second {
+		return
+	}
+	l.last = time.Now()
+
+	if total == 0 {
+		log.Infof("Downloading from %s... %s", url, humanize.Bytes(written))
+		return
+	}
+
+	log.Infof("Downloading from %s... %s/%s (%0.2f%%)", url, humanize.Bytes(written), humanize.Bytes(total), float64(written)*100.0/float64(total))
+}
+
+// downloadSnapshotFile downloads the snapshot file from the given URLs.
+// The snapshot info of all sources is compared (or checked against the pinned hash) before the file is downloaded.
+// The downloaded file is compared to the files of the other sources, or checked against the pinned file hash.
+// The file is written to a temporary file first and renamed once the download succeeded
+// and the downloaded file was verified. Interrupted downloads are resumed on the next start.
+func downloadSnapshotFile(filePath string, urls []string) error {
+	filePathTmp := filePath + ".tmp"
+
+	progressLogger := &downloadProgressLogger{}
+	opts := &snapshot.DownloadOptions{
+		MinSources: config.NodeConfig.GetInt(config.CfgLocalSnapshotsDownloadMinSources),
+		OnProgress: progressLogger.onProgress,
+		OnSourceError: func(url string, err error) {
+			log.Warnf("Snapshot source %s can not be used: %v", url, err)
+		},
+	}
+
+	if pinnedHash := config.NodeConfig.GetString(config.CfgLocalSnapshotsPinnedHash); pinnedHash != "" {
+		if !guards.IsTransactionHash(pinnedHash) {
+			return errors.Wrapf(ErrSnapshotDownloadNoValidSource, "invalid pinned hash: %s", pinnedHash)
+		}
+		opts.PinnedHash = aingle.HashFromHashTrytes(pinnedHash)
+	}
+
+	if pinnedFileHash := config.NodeConfig.GetString(config.CfgLocalSnapshotsPinnedFileHash); pinnedFileHash != "" {
+		fileHash, err := hex.DecodeString(pinnedFileHash)
+		if err != nil || len(fileHash) != sha256.Size {
+			return errors.Wrapf(ErrSnapshotDownloadNoValidSource, "invalid pinned file hash: %s", pinnedFileHash)
+		}
+		opts.PinnedFileHash = fileHash
+	}
+
+	info, err := snapshot.Download(filePathTmp, urls, opts)
+	if err != nil {
+		if err == snapshot.ErrOperationAborted {
+			return ErrSnapshotDownloadWasAborted
+		}
+		return errors.Wrap(ErrSnapshotDownloadNoValidSource, err.Error())
+	}
+
+	log.Infof("Downloaded snapshot for milestone %d (%v)", info.SnapshotIndex, info.Hash.Trytes())
+
+	return os.Rename(filePathTmp, filePath)
+}
@@ -0,0 +1,159 @@
+package snapshot
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+// loadGlobalSnapshotLedger reads the ledger state of a global snapshot file ("address;balance" per line).
+func loadGlobalSnapshotLedger(filePathLedger string) (map[string]uint64, error) {
+
+	ledgerFile, err := os.Open(filePathLedger)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSnapshotImportFailed, "opening ledger file failed: %v", err)
+	}
+	defer ledgerFile.Close()
+
+	ledgerState := make(map[string]uint64)
+	var total uint64
+
+	scanner := bufio.NewScanner(ledgerFile)
+	for scanner.Scan() {
+		if daemon.IsStopped() {
+			return nil, ErrSnapshotImportWasAborted
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		lineSplitted := strings.Split(line, ";")
+		if len(lineSplitted) != 2 {
+			return nil, errors.Wrapf(ErrSnapshotImportFailed, "wrong format in %v", filePathLedger)
+		}
+
+		if err := address.ValidAddress(lineSplitted[0]); err != nil {
+			return nil, errors.Wrapf(ErrSnapshotImportFailed, "invalid address %v: %v", lineSplitted[0], err)
+		}
+
+		balance, err := strconv.ParseUint(lineSplitted[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(ErrSnapshotImportFailed, "parse balance of %v failed: %v", lineSplitted[0], err)
+		}
+
+		ledgerState[string(aingle.HashFromAddressTrytes(lineSplitted[0]))] = balance
+		total += balance
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(ErrSnapshotImportFailed, "reading ledger file failed: %v", err)
+	}
+
+	if total != consts.TotalSupply {
+		return nil, errors.Wrapf(ErrSnapshotImportFailed, "ledger state does not match the total supply: %d != %d", total, consts.TotalSupply)
+	}
+
+	return ledgerState, nil
+}
+
+// loadSpentAddresses marks all addresses of a spent addresses file (one address per line) as spent.
+func loadSpentAddresses(filePathSpent string) (int, error) {
+
+	spentAddressesFile, err := os.Open(filePathSpent)
+	if err != nil {
+		return 0, errors.Wrapf(ErrSnapshotImportFailed, "opening spent addresses file failed: %v", err)
+	}
+	defer spentAddressesFile.Close()
+
+	tangle.WriteLockSpentAddresses()
+	defer tangle.WriteUnlockSpentAddresses()
+
+	var spentAddressesCount int
+
+	scanner := bufio.NewScanner(spentAddressesFile)
+	for scanner.Scan() {
+		if daemon.IsStopped() {
+			return 0, ErrSnapshotImportWasAborted
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err := address.ValidAddress(line); err != nil {
+			return 0, errors.Wrapf(ErrSnapshotImportFailed, "invalid spent address %v: %v", line, err)
+		}
+
+		tangle.MarkAddressAsSpentWithoutLocking(aingle.HashFromAddressTrytes(line))
+		spentAddressesCount++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Wrapf(ErrSnapshotImportFailed, "reading spent addresses file failed: %v", err)
+	}
+
+	return spentAddressesCount, nil
+}
+
+// LoadGlobalSnapshot loads the ledger state and the spent addresses of a global snapshot into the database.
+func LoadGlobalSnapshot(filePathLedger string, filePathsSpent []string, snapshotIndex milestone.Index) error {
+
+	log.Infof("Loading global snapshot with index %v...", snapshotIndex)
+
+	ledgerState, err := loadGlobalSnapshotLedger(filePathLedger)
+	if err != nil {
+		return err
+	}
+
+	spentAddressesEnabled := config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled)
+	if spentAddressesEnabled {
+		for _, filePathSpent := range filePathsSpent {
+			count, err := loadSpentAddresses(filePathSpent)
+			if err != nil {
+				return err
+			}
+			log.Infof("Imported %d spent addresses from %v", count, filePathSpent)
+		}
+	}
+
+	tangle.WriteLockSolidEntryPoints()
+	tangle.ResetSolidEntryPoints()
+	tangle.SolidEntryPointsAdd(aingle.NullHashBytes, snapshotIndex)
+	tangle.StoreSolidEntryPoints()
+	tangle.WriteUnlockSolidEntryPoints()
+
+	if err := tangle.StoreSnapshotBalancesInDatabase(ledgerState, snapshotIndex); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "snapshot ledger state: %v", err)
+	}
+
+	if err := tangle.StoreLedgerBalancesInDatabase(ledgerState, snapshotIndex); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "ledger state: %v", err)
+	}
+
+	cooAddress := aingle.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
+	tangle.SetSnapshotMilestone(cooAddress, aingle.NullHashBytes, snapshotIndex, snapshotIndex, snapshotIndex, time.Now().Unix(), spentAddressesEnabled)
+	tangle.SetSolidMilestoneIndex(snapshotIndex)
+
+	tangleplugin.Events.SnapshotMilestoneIndexChanged.Trigger(snapshotIndex)
+
+	log.Infof("Finished loading global snapshot with index %v", snapshotIndex)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+var (
+	globalAddrA = strings.Repeat("A", 81)
+	globalAddrB = strings.Repeat("B", 81)
+)
+
+func writeTestFile(t *testing.T, dir string, name string, content string) string {
+	filePath := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0600))
+	return filePath
+}
+
+func TestLoadGlobalSnapshotLedger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// empty lines are ignored
+	filePath := writeTestFile(t, dir, "valid.txt", fmt.Sprintf("%s;%d\n\n%s;100\n", globalAddrA, consts.TotalSupply-100, globalAddrB))
+	ledgerState, err := loadGlobalSnapshotLedger(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{
+		string(aingle.HashFromAddressTrytes(globalAddrA)): consts.TotalSupply - 100,
+		string(aingle.HashFromAddressTrytes(globalAddrB)): 100,
+	}, ledgerState)
+
+	for name, content := range map[string]string{
+		"format":  fmt.Sprintf("%s;%d;0\n", globalAddrA, consts.TotalSupply),
+		"address": fmt.Sprintf("INVALID;%d\n", consts.TotalSupply),
+		"balance": fmt.Sprintf("%s;-%d\n", globalAddrA, consts.TotalSupply),
+		"supply":  fmt.Sprintf("%s;%d\n", globalAddrA, consts.TotalSupply-1),
+	} {
+		_, err := loadGlobalSnapshotLedger(writeTestFile(t, dir, name+".txt", content))
+		assert.True(t, errors.Is(err, ErrSnapshotImportFailed), name)
+	}
+
+	_, err = loadGlobalSnapshotLedger(filepath.Join(dir, "missing.txt"))
+	assert.True(t, errors.Is(err, ErrSnapshotImportFailed))
+}
+
+func TestLoadSpentAddresses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Profile1GB.Caches)
+	defer tangle.ShutdownStorages()
+
+	count, err := loadSpentAddresses(writeTestFile(t, dir, "spent.txt", globalAddrA+"\n\n"+globalAddrB+"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, tangle.WasAddressSpentFrom(aingle.HashFromAddressTrytes(globalAddrA)))
+	assert.True(t, tangle.WasAddressSpentFrom(aingle.HashFromAddressTrytes(globalAddrB)))
+	assert.False(t, tangle.WasAddressSpentFrom(aingle.HashFromAddressTrytes(strings.Repeat("C", 81))))
+
+	_, err = loadSpentAddresses(writeTestFile(t, dir, "invalid.txt", globalAddrA+"\nINVALID\n"))
+	assert.True(t, errors.Is(err, ErrSnapshotImportFailed))
+}
@@ -0,0 +1,661 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/dag"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/snapshot"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+const (
+	// SolidEntryPointCheckThresholdPast is the amount of milestones before the target index
+	// that are walked to search for solid entry points.
+	SolidEntryPointCheckThresholdPast = 50
+	// SolidEntryPointCheckThresholdFuture is the amount of milestones after the target index
+	// that must be solid before a local snapshot can be taken.
+	SolidEntryPointCheckThresholdFuture = 50
+)
+
+type localSnapshotHeader struct {
+	msHash           aingle.Hash
+	msIndex          milestone.Index
+	msTimestamp      int64
+	solidEntryPoints map[string]milestone.Index
+	seenMilestones   map[string]milestone.Index
+	balances         map[string]uint64
+	ledgerDiffs      map[milestone.Index]map[string]int64
+}
+
+// isSolidEntryPoint checks whether any direct approver of the given transaction was confirmed by a milestone which is above the target milestone.
+func isSolidEntryPoint(txHash aingle.Hash, targetIndex milestone.Index) bool {
+
+	for _, approverHash := range tangle.GetApproverHashes(txHash) {
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(approverHash) // meta +1
+		if cachedTxMeta == nil {
+			// Ignore this transaction since it doesn't exist
+			continue
+		}
+
+		// HINT: Check for orphaned Tx as solid entry points is skipped, since this operation is heavy and not necessary, and
+		//		 since they should all be found by iterating the milestones to a certain depth under targetIndex, because the tipselection for COO was changed.
+		confirmed, at := cachedTxMeta.GetMetadata().GetConfirmed()
+		cachedTxMeta.Release(true) // meta -1
+
+		if confirmed && (at > targetIndex) {
+			// confirmed by a later milestone than targetIndex => solidEntryPoint
+			return true
+		}
+	}
+
+	return false
+}
+
+// getMilestoneApprovees traverses a milestone and collects all tx that were confirmed by that milestone or higher
+func getMilestoneApprovees(milestoneIndex milestone.Index, cachedMsTailTx *tangle.CachedTransaction, panicOnMissingTx bool, abortSignal <-chan struct{}) (aingle.Hashes, error) {
+
+	defer cachedMsTailTx.Release(true) // tx -1
+
+	var approvees aingle.Hashes
+	err := dag.TraverseApprovees(cachedMsTailTx.GetTransaction().GetTxHash(),
+		// traversal stops if no more transactions pass the given condition
+		// Caution: condition func is not in DFS order
+		func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+
+			// collect all tx that were confirmed by that milestone or higher
+			_, at := cachedTxMeta.GetMetadata().GetConfirmed()
+			return at >= milestoneIndex, nil
+		},
+		// consumer
+		func(cachedTxMeta *tangle.CachedMetadata) error { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+			approvees = append(approvees, cachedTxMeta.GetMetadata().GetTxHash())
+			return nil
+		},
+		// called on missing approvees
+		func(approveeHash aingle.Hash) error {
+			if panicOnMissingTx {
+				return errors.Wrapf(ErrCritical, "transaction not found: %v", approveeHash.Trytes())
+			}
+			return nil
+		},
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		true, false, false, abortSignal)
+
+	if err == tangle.ErrOperationAborted {
+		return nil, ErrSnapshotCreationWasAborted
+	}
+
+	return approvees, err
+}
+
+func shouldTakeSnapshot(solidMilestoneIndex milestone.Index) bool {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		log.Panic("No snapshotInfo found!")
+	}
+
+	var snapshotInterval milestone.Index
+	if tangle.IsNodeSynced() {
+		snapshotInterval = snapshotIntervalSynced
+	} else {
+		snapshotInterval = snapshotIntervalUnsynced
+	}
+
+	if (solidMilestoneIndex < snapshotDepth+snapshotInterval) || (solidMilestoneIndex-snapshotDepth) < snapshotInfo.PruningIndex+1+SolidEntryPointCheckThresholdPast {
+		// Not enough history to calculate solid entry points
+		return false
+	}
+
+	return solidMilestoneIndex-(snapshotDepth+snapshotInterval) >= snapshotInfo.SnapshotIndex
+}
+
+func getSolidEntryPoints(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]milestone.Index, error) {
+
+	solidEntryPoints := make(map[string]milestone.Index)
+	solidEntryPoints[string(aingle.NullHashBytes)] = targetIndex
+
+	// Iterate from a reasonable old milestone to the target index to check for solid entry points
+	for milestoneIndex := targetIndex - SolidEntryPointCheckThresholdPast; milestoneIndex <= targetIndex; milestoneIndex++ {
+		select {
+		case <-abortSignal:
+			return nil, ErrSnapshotCreationWasAborted
+		default:
+		}
+
+		cachedMs := tangle.GetMilestoneOrNil(milestoneIndex) // bundle +1
+		if cachedMs == nil {
+			return nil, errors.Wrapf(ErrCritical, "milestone (%d) not found!", milestoneIndex)
+		}
+
+		// Get all approvees of that milestone
+		cachedMsTailTx := cachedMs.GetBundle().GetTail() // tx +1
+		cachedMs.Release(true)                           // bundle -1
+
+		approvees, err := getMilestoneApprovees(milestoneIndex, cachedMsTailTx, true, abortSignal) // tx pass +1
+		if err != nil {
+			return nil, err
+		}
+
+		for _, approvee := range approvees {
+			select {
+			case <-abortSignal:
+				return nil, ErrSnapshotCreationWasAborted
+			default:
+			}
+
+			if isEntryPoint := isSolidEntryPoint(approvee, targetIndex); isEntryPoint {
+				// A solid entry point should only be a tail transaction, otherwise the whole bundle can't be reproduced with a snapshot file
+				tails, err := dag.FindAllTails(approvee, false, true)
+				if err != nil {
+					return nil, errors.Wrap(ErrCritical, err.Error())
+				}
+
+				for tailHash := range tails {
+					solidEntryPoints[tailHash] = milestoneIndex
+				}
+			}
+		}
+	}
+
+	return solidEntryPoints, nil
+}
+
+func getSeenMilestones(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]milestone.Index, error) {
+
+	// Fill the list with seen milestones
+	seenMilestones := make(map[string]milestone.Index)
+	lastMilestone := tangle.SearchLatestMilestoneIndexInStore()
+	for milestoneIndex := targetIndex + 1; milestoneIndex <= lastMilestone; milestoneIndex++ {
+		select {
+		case <-abortSignal:
+			return nil, ErrSnapshotCreationWasAborted
+		default:
+		}
+
+		cachedMs := tangle.GetMilestoneOrNil(milestoneIndex) // bundle +1
+		if cachedMs == nil {
+			continue
+		}
+		seenMilestones[string(cachedMs.GetBundle().GetTailHash())] = milestoneIndex
+		cachedMs.Release(true) // bundle -1
+	}
+
+	return seenMilestones, nil
+}
+
+// getLedgerStateAtMilestone walks back the ledger diffs from the solid milestone to the target index.
+// It returns the balances at the target index and the ledger diffs that were walked back.
+// ReadLockLedger must be held while entering this function.
+func getLedgerStateAtMilestone(balances map[string]uint64, targetIndex milestone.Index, solidMilestoneIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, map[milestone.Index]map[string]int64, error) {
+
+	ledgerDiffs := make(map[milestone.Index]map[string]int64)
+
+	// Calculate balances for targetIndex
+	for milestoneIndex := solidMilestoneIndex; milestoneIndex > targetIndex; milestoneIndex-- {
+		diff, err := tangle.GetLedgerDiffForMilestoneWithoutLocking(milestoneIndex, abortSignal)
+		if err != nil {
+			if err == tangle.ErrOperationAborted {
+				return nil, nil, ErrSnapshotCreationWasAborted
+			}
+			return nil, nil, err
+		}
+		ledgerDiffs[milestoneIndex] = diff
+
+		for address, change := range diff {
+			select {
+			case <-abortSignal:
+				return nil, nil, ErrSnapshotCreationWasAborted
+			default:
+			}
+
+			newBalance := int64(balances[address]) - change
+
+			if newBalance < 0 {
+				return nil, nil, fmt.Errorf("ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, aingle.Hash(address).Trytes(), balances[address], change)
+			} else if newBalance == 0 {
+				delete(balances, address)
+			} else {
+				balances[address] = uint64(newBalance)
+			}
+		}
+	}
+
+	return balances, ledgerDiffs, nil
+}
+
+// getLedgerStateForTargetIndex returns the balances of all addresses at the given target index
+// and the ledger diffs of all milestones between the target index and the solid milestone.
+func getLedgerStateForTargetIndex(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, map[milestone.Index]map[string]int64, error) {
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	balances, ledgerIndex, err := tangle.GetLedgerStateForLSMIWithoutLocking(abortSignal)
+	if err != nil {
+		if err == tangle.ErrOperationAborted {
+			return nil, nil, ErrSnapshotCreationWasAborted
+		}
+		return nil, nil, err
+	}
+
+	if ledgerIndex != tangle.GetSolidMilestoneIndex() {
+		return nil, nil, fmt.Errorf("ledger index wrong! %d/%d", ledgerIndex, tangle.GetSolidMilestoneIndex())
+	}
+
+	return getLedgerStateAtMilestone(balances, targetIndex, ledgerIndex, abortSignal)
+}
+
+func checkSnapshotLimits(targetIndex milestone.Index, snapshotInfo *tangle.SnapshotInfo, checkIncreasingSnapshots bool) error {
+
+	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
+
+	if solidMilestoneIndex < SolidEntryPointCheckThresholdFuture {
+		return errors.Wrapf(ErrNotEnoughHistory, "minimum solid index: %d, actual solid index: %d", SolidEntryPointCheckThresholdFuture+1, solidMilestoneIndex)
+	}
+
+	minimumIndex := milestone.Index(SolidEntryPointCheckThresholdPast + 1)
+	maximumIndex := solidMilestoneIndex - SolidEntryPointCheckThresholdFuture
+
+	if checkIncreasingSnapshots && (minimumIndex < snapshotInfo.SnapshotIndex+1) {
+		minimumIndex = snapshotInfo.SnapshotIndex + 1
+	}
+
+	if minimumIndex < snapshotInfo.PruningIndex+1+SolidEntryPointCheckThresholdPast {
+		// since we always generate new solid entry points, we need enough history
+		minimumIndex = snapshotInfo.PruningIndex + 1 + SolidEntryPointCheckThresholdPast
+	}
+
+	switch {
+	case minimumIndex > maximumIndex:
+		return errors.Wrapf(ErrNotEnoughHistory, "minimum index (%d) exceeds maximum index (%d)", minimumIndex, maximumIndex)
+	case targetIndex > maximumIndex:
+		return errors.Wrapf(ErrTargetIndexTooNew, "maximum: %d, actual: %d", maximumIndex, targetIndex)
+	case targetIndex < minimumIndex:
+		return errors.Wrapf(ErrTargetIndexTooOld, "minimum: %d, actual: %d", minimumIndex, targetIndex)
+	}
+
+	return nil
+}
+
+// createSnapshotFile writes a gzip compressed snapshot file to a temporary file and renames it afterwards.
+func createSnapshotFile(filePath string, writeFunc func(writer io.Writer) error) error {
+
+	filePathTmp := filePath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(filePathTmp)
+
+	exportFile, err := os.OpenFile(filePathTmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+	defer exportFile.Close()
+
+	gzipWriter := gzip.NewWriter(exportFile)
+	defer gzipWriter.Close()
+
+	if err := writeFunc(gzipWriter); err != nil {
+		if err == snapshot.ErrOperationAborted {
+			return ErrSnapshotCreationWasAborted
+		}
+		return err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+
+	if err := exportFile.Close(); err != nil {
+		return err
+	}
+
+	// Rename tmp file to final file name
+	if err := os.Rename(filePathTmp, filePath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func writeLocalSnapshot(writer io.Writer, lsh *localSnapshotHeader, abortSignal <-chan struct{}) error {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.Wrap(ErrCritical, "no snapshot info found")
+	}
+	spentAddressesEnabled := snapshotInfo.IsSpentAddressesEnabled()
+
+	// the snapshot info in the file describes the state at the target index
+	fileInfo := &tangle.SnapshotInfo{
+		CoordinatorAddress: snapshotInfo.CoordinatorAddress,
+		Hash:               lsh.msHash,
+		SnapshotIndex:      lsh.msIndex,
+		EntryPointIndex:    lsh.msIndex,
+		PruningIndex:       lsh.msIndex,
+		Timestamp:          lsh.msTimestamp,
+	}
+	fileInfo.SetSpentAddressesEnabled(spentAddressesEnabled)
+
+	// spent addresses must not change between counting and writing them
+	tangle.ReadLockSpentAddresses()
+	defer tangle.ReadUnlockSpentAddresses()
+
+	var spentAddressesCount int32
+	if spentAddressesEnabled {
+		var err error
+		if spentAddressesCount, err = tangle.CountSpentAddressesEntriesWithoutLocking(abortSignal); err != nil {
+			if err == tangle.ErrOperationAborted {
+				return ErrSnapshotCreationWasAborted
+			}
+			return err
+		}
+	}
+
+	snapshotWriter, err := snapshot.NewWriter(writer, abortSignal)
+	if err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteInfo(fileInfo); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteSolidEntryPoints(lsh.solidEntryPoints); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteSeenMilestones(lsh.seenMilestones); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteBalances(lsh.balances); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteLedgerDiffs(lsh.ledgerDiffs); err != nil {
+		return err
+	}
+
+	if err := snapshotWriter.WriteSpentAddresses(spentAddressesCount, tangle.StreamSpentAddressesToWriterWithoutLocking); err != nil {
+		return err
+	}
+
+	return snapshotWriter.Close()
+}
+
+func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath string, writeToDatabase bool, abortSignal <-chan struct{}) error {
+
+	log.Infof("creating local snapshot for targetIndex %d", targetIndex)
+	ts := time.Now()
+
+	setIsSnapshotting(true)
+	defer setIsSnapshotting(false)
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.Wrap(ErrCritical, "no snapshot info found")
+	}
+
+	if err := checkSnapshotLimits(targetIndex, snapshotInfo, writeToDatabase); err != nil {
+		return err
+	}
+
+	cachedTargetMs := tangle.GetMilestoneOrNil(targetIndex) // bundle +1
+	if cachedTargetMs == nil {
+		return errors.Wrapf(ErrCritical, "target milestone (%d) not found", targetIndex)
+	}
+	defer cachedTargetMs.Release(true) // bundle -1
+
+	cachedTargetMsTail := cachedTargetMs.GetBundle().GetTail() // tx +1
+	defer cachedTargetMsTail.Release(true)                     // tx -1
+
+	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
+	if err != nil {
+		return err
+	}
+
+	seenMilestones, err := getSeenMilestones(targetIndex, abortSignal)
+	if err != nil {
+		return err
+	}
+
+	newBalances, ledgerDiffs, err := getLedgerStateForTargetIndex(targetIndex, abortSignal)
+	if err != nil {
+		if err == ErrSnapshotCreationWasAborted {
+			return err
+		}
+		return errors.Wrap(ErrCritical, err.Error())
+	}
+
+	lsh := &localSnapshotHeader{
+		msHash:           cachedTargetMs.GetBundle().GetMilestoneHash(),
+		msIndex:          targetIndex,
+		msTimestamp:      cachedTargetMsTail.GetTransaction().GetTimestamp(),
+		solidEntryPoints: newSolidEntryPoints,
+		seenMilestones:   seenMilestones,
+		balances:         newBalances,
+		ledgerDiffs:      ledgerDiffs,
+	}
+
+	if err := createSnapshotFile(filePath, func(writer io.Writer) error {
+		return writeLocalSnapshot(writer, lsh, abortSignal)
+	}); err != nil {
+		return err
+	}
+
+	if writeToDatabase {
+		// This has to be done before acquiring the SolidEntryPoints Lock, otherwise there is a race condition with "solidifyMilestone"
+		// In "solidifyMilestone" the LedgerLock is acquired, but by traversing the tangle, the SolidEntryPoint Lock is also acquired.
+		// ToDo: we should flush the caches here, just to be sure that all information before this local snapshot we stored in the persistence layer.
+		if err := tangle.StoreSnapshotBalancesInDatabase(newBalances, targetIndex); err != nil {
+			return errors.Wrap(ErrCritical, err.Error())
+		}
+
+		snapshotInfo.Hash = lsh.msHash
+		snapshotInfo.SnapshotIndex = targetIndex
+		snapshotInfo.Timestamp = lsh.msTimestamp
+		tangle.SetSnapshotInfo(snapshotInfo)
+
+		tangleplugin.Events.SnapshotMilestoneIndexChanged.Trigger(targetIndex)
+	}
+
+	log.Infof("created local snapshot for target index %d (%v), took %v", targetIndex, lsh.msHash.Trytes(), time.Since(ts))
+
+	return nil
+}
+
+// CreateLocalSnapshot creates a local snapshot file for the given target milestone index.
+// If writeToDatabase is set, the snapshot milestone of the node is moved to the target index.
+func CreateLocalSnapshot(targetIndex milestone.Index, filePath string, writeToDatabase bool, abortSignal <-chan struct{}) error {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	return createLocalSnapshotWithoutLocking(targetIndex, filePath, writeToDatabase, abortSignal)
+}
+
+// openSnapshotFile opens a gzip compressed local snapshot file.
+func openSnapshotFile(filePath string) (*os.File, *gzip.Reader, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrSnapshotImportFailed, "opening file failed: %v", err)
+	}
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, errors.Wrapf(ErrSnapshotImportFailed, "reading gzip failed: %v", err)
+	}
+
+	return file, gzipReader, nil
+}
+
+// snapshotReadFunc reads a snapshot file and streams its content to the given consumers.
+type snapshotReadFunc func(reader io.Reader, consumers *snapshot.Consumers, abortSignal <-chan struct{}) error
+
+// readSnapshotFile streams the content of a gzip compressed snapshot file to the given consumers.
+func readSnapshotFile(filePath string, readFunc snapshotReadFunc, consumers *snapshot.Consumers) error {
+
+	file, gzipReader, err := openSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	defer gzipReader.Close()
+
+	if err := readFunc(gzipReader, consumers, nil); err != nil {
+		if err == ErrSnapshotImportWasAborted {
+			return err
+		}
+		if errors.Is(err, snapshot.ErrUnsupportedVersion) {
+			return errors.Wrap(ErrUnsupportedLSFileVersion, err.Error())
+		}
+		return errors.Wrap(ErrSnapshotImportFailed, err.Error())
+	}
+
+	return nil
+}
+
+// VerifySnapshotFile checks the structure and the checksum of a local snapshot file without loading it.
+func VerifySnapshotFile(filePath string) error {
+	return readSnapshotFile(filePath, snapshot.Read, nil)
+}
+
+// LoadSnapshotFromFile loads a local snapshot file into the database.
+// The file is verified before anything is written to the database.
+func LoadSnapshotFromFile(filePath string) error {
+	log.Info("Verifying snapshot file...")
+
+	if err := VerifySnapshotFile(filePath); err != nil {
+		return err
+	}
+
+	log.Info("Loading snapshot file...")
+
+	var fileInfo *tangle.SnapshotInfo
+	solidEntryPoints := make(map[string]milestone.Index)
+	ledgerState := make(map[string]uint64)
+	var seenMilestonesCount, ledgerDiffsCount, spentAddressesCount int
+
+	spentAddressesEnabled := config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled)
+
+	tangle.WriteLockSpentAddresses()
+	err := readSnapshotFile(filePath, snapshot.Read, &snapshot.Consumers{
+		Info: func(info *tangle.SnapshotInfo) error {
+			fileInfo = info
+			return nil
+		},
+		SolidEntryPoint: func(hash aingle.Hash, index milestone.Index) error {
+			solidEntryPoints[string(hash)] = index
+			return nil
+		},
+		SeenMilestone: func(_ aingle.Hash, index milestone.Index) error {
+			if daemon.IsStopped() {
+				return ErrSnapshotImportWasAborted
+			}
+			tangle.SetLatestSeenMilestoneIndexFromSnapshot(index)
+			seenMilestonesCount++
+			return nil
+		},
+		Balance: func(address aingle.Hash, balance uint64) error {
+			if daemon.IsStopped() {
+				return ErrSnapshotImportWasAborted
+			}
+			ledgerState[string(address)] = balance
+			return nil
+		},
+		LedgerDiff: func(_ milestone.Index, _ map[string]int64) error {
+			// the ledger diffs are not needed to load the ledger state at the snapshot index
+			ledgerDiffsCount++
+			return nil
+		},
+		SpentAddress: func(address aingle.Hash) error {
+			if daemon.IsStopped() {
+				return ErrSnapshotImportWasAborted
+			}
+			if spentAddressesEnabled {
+				tangle.MarkAddressAsSpentWithoutLocking(address)
+				spentAddressesCount++
+			}
+			return nil
+		},
+	})
+	tangle.WriteUnlockSpentAddresses()
+
+	if err != nil {
+		return err
+	}
+
+	var total uint64
+	for _, balance := range ledgerState {
+		total += balance
+	}
+
+	if total != consts.TotalSupply {
+		return errors.Wrapf(ErrSnapshotImportFailed, "ledger state does not match the total supply: %d != %d", total, consts.TotalSupply)
+	}
+
+	snapshotIndex := fileInfo.SnapshotIndex
+
+	log.Infof("importing %d solid entry points", len(solidEntryPoints))
+
+	tangle.WriteLockSolidEntryPoints()
+	tangle.ResetSolidEntryPoints()
+
+	// Genesis transaction
+	tangle.SolidEntryPointsAdd(aingle.NullHashBytes, snapshotIndex)
+
+	for hash, index := range solidEntryPoints {
+		tangle.SolidEntryPointsAdd(aingle.Hash(hash), index)
+	}
+
+	tangle.StoreSolidEntryPoints()
+	tangle.WriteUnlockSolidEntryPoints()
+
+	log.Infof("imported %d seen milestones, skipped %d ledger diffs", seenMilestonesCount, ledgerDiffsCount)
+
+	log.Infof("importing %d ledger entries", len(ledgerState))
+
+	if err := tangle.StoreSnapshotBalancesInDatabase(ledgerState, snapshotIndex); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "snapshot ledger state: %v", err)
+	}
+
+	if err := tangle.StoreLedgerBalancesInDatabase(ledgerState, snapshotIndex); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "ledger state: %v", err)
+	}
+
+	// the spent addresses are only complete if the snapshot file contains them as well
+	spentAddressesEnabled = spentAddressesEnabled && fileInfo.IsSpentAddressesEnabled()
+	if spentAddressesEnabled {
+		log.Infof("imported %d spent addresses", spentAddressesCount)
+	}
+
+	cooAddress := aingle.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
+	tangle.SetSnapshotMilestone(cooAddress, fileInfo.Hash, snapshotIndex, snapshotIndex, snapshotIndex, fileInfo.Timestamp, spentAddressesEnabled)
+	tangle.SetSolidMilestoneIndex(snapshotIndex)
+
+	tangleplugin.Events.SnapshotMilestoneIndexChanged.Trigger(snapshotIndex)
+
+	log.Infof("Finished loading snapshot for milestone %d", snapshotIndex)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+func TestCheckSnapshotLimits(t *testing.T) {
+	defer tangle.OverwriteSolidMilestoneIndex(tangle.GetSolidMilestoneIndex())
+
+	tests := []struct {
+		name                     string
+		solidMilestoneIndex      milestone.Index
+		targetIndex              milestone.Index
+		snapshotIndex            milestone.Index
+		pruningIndex             milestone.Index
+		checkIncreasingSnapshots bool
+		err                      error
+	}{
+		{"not enough solid milestones", SolidEntryPointCheckThresholdFuture - 1, 1, 0, 0, false, ErrNotEnoughHistory},
+		{"no room between the thresholds", 100, 50, 0, 0, false, ErrNotEnoughHistory},
+		{"lowest target", 200, SolidEntryPointCheckThresholdPast + 1, 0, 0, false, nil},
+		{"highest target", 200, 200 - SolidEntryPointCheckThresholdFuture, 0, 0, false, nil},
+		{"target too new", 200, 200 - SolidEntryPointCheckThresholdFuture + 1, 0, 0, false, ErrTargetIndexTooNew},
+		{"target too old", 200, SolidEntryPointCheckThresholdPast, 0, 0, false, ErrTargetIndexTooOld},
+		{"target below the last snapshot", 200, 100, 120, 0, true, ErrTargetIndexTooOld},
+		{"target below the last snapshot allowed", 200, 100, 120, 0, false, nil},
+		{"target above the last snapshot", 200, 121, 120, 0, true, nil},
+		{"not enough history after pruning", 300, 120, 0, 100, false, ErrTargetIndexTooOld},
+		{"no room after pruning", 200, 120, 0, 100, false, ErrNotEnoughHistory},
+		{"enough history after pruning", 300, 100 + 1 + SolidEntryPointCheckThresholdPast, 0, 100, false, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tangle.OverwriteSolidMilestoneIndex(test.solidMilestoneIndex)
+
+			err := checkSnapshotLimits(test.targetIndex, &tangle.SnapshotInfo{SnapshotIndex: test.snapshotIndex, PruningIndex: test.pruningIndex}, test.checkIncreasingSnapshots)
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, test.err), "unexpected error: %v", err)
+		})
+	}
+}
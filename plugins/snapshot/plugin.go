@@ -0,0 +1,209 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/shutdown"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+var (
+	PLUGIN = node.NewPlugin("Snapshot", node.Enabled, configure, run)
+	log    *logger.Logger
+
+	ErrNoSnapshotSpecified           = errors.New("no snapshot file was specified in the config")
+	ErrNoSnapshotDownloadURL         = errors.New("no download URL given for local snapshot")
+	ErrSnapshotDownloadWasAborted    = errors.New("snapshot download was aborted")
+	ErrSnapshotDownloadNoValidSource = errors.New("no valid source found, snapshot download not possible")
+	ErrSnapshotImportWasAborted      = errors.New("snapshot import was aborted")
+	ErrSnapshotImportFailed          = errors.New("snapshot import failed")
+	ErrSnapshotCreationWasAborted    = errors.New("operation was aborted")
+	ErrSnapshotCreationFailed        = errors.New("creating snapshot failed")
+	ErrTargetIndexTooNew             = errors.New("snapshot target is too new")
+	ErrTargetIndexTooOld             = errors.New("snapshot target is too old")
+	ErrNotEnoughHistory              = errors.New("not enough history")
+	ErrNoPruningNeeded               = errors.New("no pruning needed")
+	ErrPruningAborted                = errors.New("pruning was aborted")
+	ErrUnsupportedLSFileVersion      = errors.New("unsupported local snapshot file version")
+	ErrDeltaBaseMismatch             = errors.New("delta snapshot does not match the current snapshot")
+	ErrCritical                      = errors.New("critical error")
+
+	localSnapshotLock       = syncutils.Mutex{}
+	newSolidMilestoneSignal = make(chan milestone.Index)
+
+	snapshotDepth            milestone.Index
+	snapshotIntervalSynced   milestone.Index
+	snapshotIntervalUnsynced milestone.Index
+
+	pruningEnabled bool
+	pruningDelay   milestone.Index
+
+	statusLock     syncutils.RWMutex
+	isSnapshotting bool
+	isPruning      bool
+
+	onSolidMilestoneIndexChanged *events.Closure
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger(plugin.Name)
+
+	snapshotDepth = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsDepth))
+	if snapshotDepth < SolidEntryPointCheckThresholdFuture {
+		log.Warnf("Parameter '%s' is too small (%d). Value was changed to %d", config.CfgLocalSnapshotsDepth, snapshotDepth, SolidEntryPointCheckThresholdFuture)
+		snapshotDepth = SolidEntryPointCheckThresholdFuture
+	}
+	snapshotIntervalSynced = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsIntervalSynced))
+	snapshotIntervalUnsynced = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsIntervalUnsynced))
+
+	pruningEnabled = config.NodeConfig.GetBool(config.CfgPruningEnabled)
+	pruningDelay = milestone.Index(config.NodeConfig.GetInt(config.CfgPruningDelay))
+	pruningDelayMin := snapshotDepth + SolidEntryPointCheckThresholdPast + AdditionalPruningThreshold + 1
+	if pruningDelay < pruningDelayMin {
+		log.Warnf("Parameter '%s' is too small (%d). Value was changed to %d", config.CfgPruningDelay, pruningDelay, pruningDelayMin)
+		pruningDelay = pruningDelayMin
+	}
+
+	onSolidMilestoneIndexChanged = events.NewClosure(func(msIndex milestone.Index) {
+		select {
+		case newSolidMilestoneSignal <- msIndex:
+		default:
+		}
+	})
+
+	if tangle.GetSnapshotInfo() == nil {
+		// the database does not contain a snapshot yet
+		var err error
+		switch loadType := strings.ToLower(config.NodeConfig.GetString(config.CfgSnapshotLoadType)); loadType {
+		case "global":
+			err = loadGlobalSnapshotFromConfig()
+		case "local":
+			err = loadLocalSnapshotFromConfig()
+		default:
+			log.Fatalf("invalid snapshot load type: '%s', allowed types: 'local' or 'global'", loadType)
+		}
+
+		if err != nil {
+			tangle.MarkDatabaseCorrupted()
+			log.Panic(err.Error())
+		}
+	}
+
+	if err := loadDeltaSnapshotsFromConfig(); err != nil {
+		tangle.MarkDatabaseCorrupted()
+		log.Panic(err.Error())
+	}
+}
+
+func run(_ *node.Plugin) {
+
+	daemon.BackgroundWorker("LocalSnapshots", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting LocalSnapshots ... done")
+
+		tangleplugin.Events.SolidMilestoneIndexChanged.Attach(onSolidMilestoneIndexChanged)
+		defer tangleplugin.Events.SolidMilestoneIndexChanged.Detach(onSolidMilestoneIndexChanged)
+
+		for {
+			select {
+			case <-shutdownSignal:
+				log.Info("Stopping LocalSnapshots...")
+				log.Info("Stopping LocalSnapshots... done")
+				return
+
+			case solidMilestoneIndex := <-newSolidMilestoneSignal:
+				localSnapshotLock.Lock()
+
+				if shouldTakeSnapshot(solidMilestoneIndex) {
+					localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+					if err := createLocalSnapshotWithoutLocking(solidMilestoneIndex-snapshotDepth, localSnapshotPath, true, shutdownSignal); err != nil {
+						if errors.Is(err, ErrCritical) {
+							log.Panic(errors.Wrap(ErrSnapshotCreationFailed, err.Error()))
+						}
+						log.Warn(errors.Wrap(ErrSnapshotCreationFailed, err.Error()))
+					}
+				}
+
+				if pruningEnabled {
+					if solidMilestoneIndex > pruningDelay {
+						if _, err := pruneDatabase(solidMilestoneIndex-pruningDelay, shutdownSignal); err != nil {
+							log.Debugf("pruning aborted: %v", err.Error())
+						}
+					}
+				}
+
+				localSnapshotLock.Unlock()
+			}
+		}
+	}, shutdown.PriorityLocalSnapshots)
+}
+
+// IsSnapshottingOrPruning returns whether a local snapshot is currently being created or the database is pruned.
+func IsSnapshottingOrPruning() bool {
+	statusLock.RLock()
+	defer statusLock.RUnlock()
+	return isSnapshotting || isPruning
+}
+
+func setIsSnapshotting(value bool) {
+	statusLock.Lock()
+	isSnapshotting = value
+	statusLock.Unlock()
+}
+
+func setIsPruning(value bool) {
+	statusLock.Lock()
+	isPruning = value
+	statusLock.Unlock()
+}
+
+func loadGlobalSnapshotFromConfig() error {
+	path := config.NodeConfig.GetString(config.CfgGlobalSnapshotPath)
+	if path == "" {
+		return ErrNoSnapshotSpecified
+	}
+
+	return LoadGlobalSnapshot(path,
+		config.NodeConfig.GetStringSlice(config.CfgGlobalSnapshotSpentAddressesPaths),
+		milestone.Index(config.NodeConfig.GetInt(config.CfgGlobalSnapshotIndex)))
+}
+
+func loadLocalSnapshotFromConfig() error {
+	path := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+	if path == "" {
+		return ErrNoSnapshotSpecified
+	}
+
+	if _, fileErr := os.Stat(path); os.IsNotExist(fileErr) {
+		// create dir if it not exists
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return errors.Wrapf(err, "could not create snapshot dir '%s'", path)
+		}
+
+		urls := config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDownloadURLs)
+		if len(urls) == 0 {
+			return ErrNoSnapshotDownloadURL
+		}
+
+		log.Infof("Downloading snapshot from one of the provided sources %v", urls)
+		if err := downloadSnapshotFile(path, urls); err != nil {
+			return errors.Wrap(err, "error downloading snapshot file")
+		}
+
+		log.Info("Snapshot download finished")
+	}
+
+	return LoadSnapshotFromFile(path)
+}
@@ -0,0 +1,257 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/dag"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/plugins/database"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+const (
+	// AdditionalPruningThreshold is needed, because the transactions in the getMilestoneApprovees call in getSolidEntryPoints
+	// can reference older transactions as well
+	AdditionalPruningThreshold = 50
+)
+
+// pruneUnconfirmedTransactions prunes all unconfirmed tx from the database for the given milestone
+func pruneUnconfirmedTransactions(targetIndex milestone.Index) int {
+
+	txsToCheckMap := make(map[string]struct{})
+
+	// Check if tx is still unconfirmed
+	for _, txHash := range tangle.GetUnconfirmedTxHashes(targetIndex, true) {
+		if _, exists := txsToCheckMap[string(txHash)]; exists {
+			continue
+		}
+
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHash) // meta +1
+		if cachedTxMeta == nil {
+			continue
+		}
+
+		if cachedTxMeta.GetMetadata().IsConfirmed() {
+			// transaction was confirmed => skip
+			cachedTxMeta.Release(true) // meta -1
+			continue
+		}
+
+		// transaction is still unconfirmed => check if it can be removed
+		txsToCheckMap[string(txHash)] = struct{}{}
+		cachedTxMeta.Release(true) // meta -1
+	}
+
+	txCount := pruneTransactions(txsToCheckMap)
+	tangle.DeleteUnconfirmedTxs(targetIndex)
+
+	return txCount
+}
+
+// pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone
+func pruneMilestone(milestoneIndex milestone.Index) {
+
+	// state diffs
+	if err := tangle.DeleteLedgerDiffForMilestone(milestoneIndex); err != nil {
+		log.Warn(err)
+	}
+
+	tangle.DeleteMilestone(milestoneIndex)
+}
+
+// pruneTransactions prunes the approvers, bundles, bundle txs, addresses, tags and transaction metadata from the database
+func pruneTransactions(txsToCheckMap map[string]struct{}) int {
+
+	txsToDeleteMap := make(map[string]struct{})
+
+	for txHashToCheck := range txsToCheckMap {
+
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(aingle.Hash(txHashToCheck)) // meta +1
+		if cachedTxMeta == nil {
+			log.Warnf("pruneTransactions: Transaction not found: %s", aingle.Hash(txHashToCheck).Trytes())
+			continue
+		}
+
+		for txToRemove := range tangle.RemoveTransactionFromBundle(cachedTxMeta.GetMetadata()) {
+			txsToDeleteMap[txToRemove] = struct{}{}
+		}
+
+		// since it's only loaded from the database and the object is not modified, it is safe to release it again
+		cachedTxMeta.Release(true) // meta -1
+	}
+
+	for txHashToDelete := range txsToDeleteMap {
+
+		cachedTx := tangle.GetCachedTransactionOrNil(aingle.Hash(txHashToDelete)) // tx +1
+		if cachedTx == nil {
+			continue
+		}
+
+		cachedTx.ConsumeTransaction(func(tx *aingle.Transaction) { // tx -1
+			// Delete the reference in the approvees
+			tangle.DeleteApprover(tx.GetTrunkHash(), tx.GetTxHash())
+			tangle.DeleteApprover(tx.GetBranchHash(), tx.GetTxHash())
+
+			tangle.DeleteTag(tx.GetTag(), tx.GetTxHash())
+			tangle.DeleteAddress(tx.GetAddress(), tx.GetTxHash())
+			tangle.DeleteApprovers(tx.GetTxHash())
+			tangle.DeleteTransaction(tx.GetTxHash())
+		})
+	}
+
+	return len(txsToDeleteMap)
+}
+
+func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) (milestone.Index, error) {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		log.Panic("No snapshotInfo found!")
+	}
+
+	if snapshotInfo.SnapshotIndex < SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1 {
+		// Not enough history
+		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1, targetIndex)
+	}
+
+	targetIndexMax := snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1
+	if targetIndex > targetIndexMax {
+		targetIndex = targetIndexMax
+	}
+
+	if snapshotInfo.PruningIndex >= targetIndex {
+		// no pruning needed
+		return 0, errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex, targetIndex)
+	}
+
+	if snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1 > targetIndex {
+		// we prune in "AdditionalPruningThreshold" steps to recalculate the solidEntryPoints
+		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1, targetIndex)
+	}
+
+	setIsPruning(true)
+	defer setIsPruning(false)
+
+	// calculate solid entry points for the new end of the tangle history
+	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
+	if err != nil {
+		return 0, err
+	}
+
+	tangle.WriteLockSolidEntryPoints()
+	tangle.ResetSolidEntryPoints()
+	for solidEntryPoint, index := range newSolidEntryPoints {
+		tangle.SolidEntryPointsAdd(aingle.Hash(solidEntryPoint), index)
+	}
+	tangle.StoreSolidEntryPoints()
+	tangle.WriteUnlockSolidEntryPoints()
+
+	// we have to set the new solid entry point index.
+	// this way we can cleanly prune even if the pruning was aborted last time
+	snapshotInfo.EntryPointIndex = targetIndex
+	tangle.SetSnapshotInfo(snapshotInfo)
+
+	// unconfirmed txs have to be pruned for PruningIndex as well, since this could be LSI at startup of the node
+	pruneUnconfirmedTransactions(snapshotInfo.PruningIndex)
+
+	// Iterate through all milestones that have to be pruned
+	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		select {
+		case <-abortSignal:
+			// Stop pruning the next milestone
+			return 0, ErrPruningAborted
+		default:
+		}
+
+		log.Infof("Pruning milestone (%d)...", milestoneIndex)
+
+		ts := time.Now()
+		txCount := pruneUnconfirmedTransactions(milestoneIndex)
+
+		cachedMs := tangle.GetMilestoneOrNil(milestoneIndex) // bundle +1
+		if cachedMs == nil {
+			// Milestone not found, pruning impossible
+			log.Warnf("Pruning milestone (%d) failed! Milestone not found!", milestoneIndex)
+			continue
+		}
+
+		var approvees aingle.Hashes
+		err := dag.TraverseApprovees(cachedMs.GetBundle().GetTailHash(),
+			// traversal stops if no more transactions pass the given condition
+			// Caution: condition func is not in DFS order
+			func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
+				defer cachedTxMeta.Release(true) // meta -1
+				// everything that was referenced by that milestone can be pruned (even transactions of older milestones)
+				return true, nil
+			},
+			// consumer
+			func(cachedTxMeta *tangle.CachedMetadata) error { // meta +1
+				defer cachedTxMeta.Release(true) // meta -1
+				approvees = append(approvees, cachedTxMeta.GetMetadata().GetTxHash())
+				return nil
+			},
+			// called on missing approvees
+			func(approveeHash aingle.Hash) error { return nil },
+			// called on solid entry points
+			// Ignore solid entry points (snapshot milestone included)
+			nil,
+			true, false, false, nil)
+
+		cachedMs.Release(true) // bundle -1
+
+		if err != nil {
+			log.Warnf("Pruning milestone (%d) failed! %v", milestoneIndex, err)
+			continue
+		}
+
+		// Use a map to speed up lookup
+		txsToCheckMap := make(map[string]struct{}, len(approvees))
+		for _, approvee := range approvees {
+			txsToCheckMap[string(approvee)] = struct{}{}
+		}
+
+		txCount += pruneTransactions(txsToCheckMap)
+
+		pruneMilestone(milestoneIndex)
+
+		snapshotInfo.PruningIndex = milestoneIndex
+		tangle.SetSnapshotInfo(snapshotInfo)
+
+		log.Infof("Pruning milestone (%d) took %v. Pruned %d transactions. ", milestoneIndex, time.Since(ts).Truncate(time.Millisecond), txCount)
+
+		tangleplugin.Events.PruningMilestoneIndexChanged.Trigger(milestoneIndex)
+	}
+
+	database.RunGarbageCollection()
+
+	return targetIndex, nil
+}
+
+// PruneDatabaseByDepth prunes the database, keeping the given amount of milestones below the solid milestone.
+func PruneDatabaseByDepth(depth milestone.Index) error {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
+
+	if solidMilestoneIndex <= depth {
+		// Not enough history
+		return errors.Wrapf(ErrNotEnoughHistory, "solid milestone index: %d, depth: %d", solidMilestoneIndex, depth)
+	}
+
+	_, err := pruneDatabase(solidMilestoneIndex-depth, nil)
+	return err
+}
+
+// PruneDatabaseByTargetIndex prunes the database up to the given target milestone index.
+func PruneDatabaseByTargetIndex(targetIndex milestone.Index) error {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	_, err := pruneDatabase(targetIndex, nil)
+	return err
+}
@@ -0,0 +1,19 @@
+package snapshot
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+)
+
+func TestMain(m *testing.M) {
+	// the plugin is not configured in the tests
+	log = logger.NewNopLogger()
+	config.NodeConfig.Set(config.CfgCoordinatorAddress, strings.Repeat("9", 81))
+
+	os.Exit(m.Run())
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/Ariwonto/aingle-alpha/pkg/shutdown"
 	"github.com/Ariwonto/aingle-alpha/pkg/tipselect"
 	"github.com/Ariwonto/aingle-alpha/pkg/whiteflag"
+	"github.com/Ariwonto/aingle-alpha/plugins/gossip"
 	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
 )
 
@@ -45,6 +46,9 @@ func configure(plugin *node.Plugin) {
 		config.NodeConfig.GetUint32(config.CfgTipSelSemiLazy+config.CfgTipSelMaxApprovers),
 	)
 
+	// advertise the tip pool size in heartbeats
+	gossip.SetTipPoolSizeFunc(TipSelector.TipCount)
+
 	configureEvents()
 }
 
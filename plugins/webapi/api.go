@@ -40,17 +40,24 @@ func webAPIRoute() {
 
 		implementation, apiCallExists := implementedAPIcalls[cmd]
 
-		whitelisted := false
-		remoteHost, _, _ := net.SplitHostPort(c.Request.RemoteAddr)
-		remoteAddress := net.ParseIP(remoteHost)
-		for _, whitelistedNet := range whitelistedNetworks {
-			if whitelistedNet.Contains(remoteAddress) {
-				whitelisted = true
-				break
+		if token, authenticated := apiTokenFromContext(c); authenticated {
+			// API tokens only grant access to the commands in their permissions
+			if apiCallExists && !token.isPermitted(cmd) {
+				e := ErrorReturn{
+					Error: fmt.Sprintf("Command [%v] is not permitted for API token '%s'", originCommand, token.name),
+				}
+				c.JSON(http.StatusForbidden, e)
+				return
 			}
-		}
 
-		if !whitelisted {
+			if cmd == "attachtotangle" {
+				txCount := requestedPoWCount(request)
+				if !token.takePoWQuota(c, txCount) {
+					return
+				}
+				defer token.refundPoWQuotaOnError(c, txCount)
+			}
+		} else if !isWhitelisted(c) {
 			// Check if command is permitted. If it's not permited and the request does not come from localhost, deny it.
 			_, permited := permitedEndpoints[cmd]
 			if apiCallExists && !permited {
@@ -70,10 +77,36 @@ func webAPIRoute() {
 			return
 		}
 
+		release, ok := acquireRequestLimits(c, cmd)
+		if !ok {
+			return
+		}
+		defer release()
+
 		implementation(&request, c, serverShutdownSignal)
 	})
 }
 
+// requestedPoWCount returns the amount of transactions an attachToTangle request does PoW for.
+func requestedPoWCount(request map[string]interface{}) int {
+	if trytes, ok := request["trytes"].([]interface{}); ok && len(trytes) > 0 {
+		return len(trytes)
+	}
+	return 1
+}
+
+// isWhitelisted returns whether the request comes from a whitelisted network.
+func isWhitelisted(c *gin.Context) bool {
+	remoteHost, _, _ := net.SplitHostPort(c.Request.RemoteAddr)
+	remoteAddress := net.ParseIP(remoteHost)
+	for _, whitelistedNet := range whitelistedNetworks {
+		if whitelistedNet.Contains(remoteAddress) {
+			return true
+		}
+	}
+	return false
+}
+
 // health check
 func restAPIRoute() {
 
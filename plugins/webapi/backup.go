@@ -0,0 +1,113 @@
+package webapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/utils"
+)
+
+const (
+	backupStatusInterval = 2 * time.Second
+)
+
+func init() {
+	addEndpoint("createDatabaseBackup", createDatabaseBackup, implementedAPIcalls)
+}
+
+func createDatabaseBackup(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &CreateDatabaseBackup{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	backupPath, err := validateBackupTargetPath(query.TargetPath, config.NodeConfig.GetString(config.CfgDatabasePath))
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	log.Infof("creating database backup in %s...", backupPath)
+
+	ts := time.Now()
+	lastStatusTime := time.Now()
+	if err := tangle.BackupDatabases(backupPath, func(current int64, total int64) {
+		if time.Since(lastStatusTime) < backupStatusInterval {
+			return
+		}
+		lastStatusTime = time.Now()
+
+		percentage, remaining := utils.EstimateRemainingTime(ts, current, total)
+		log.Infof("creating database backup...%d/%d (%0.2f%%). %v left...", current, total, percentage, remaining.Truncate(time.Second))
+	}, abortSignal); err != nil {
+		log.Warnf("creating database backup failed: %v", err)
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	duration := time.Since(ts)
+	log.Infof("creating database backup in %s... done (took %v)", backupPath, duration.Truncate(time.Millisecond))
+
+	c.JSON(http.StatusOK, CreateDatabaseBackupReturn{Path: backupPath, Duration: int(duration.Milliseconds())})
+}
+
+// validateBackupTargetPath checks that the target path of a backup is an empty or not existing directory
+// outside of the database directory and returns the cleaned path.
+func validateBackupTargetPath(targetPath string, databasePath string) (string, error) {
+	if targetPath == "" {
+		return "", errors.New("no targetPath given")
+	}
+
+	targetPath = filepath.Clean(targetPath)
+
+	absTargetPath, err := filepath.Abs(targetPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid targetPath: %v", err)
+	}
+
+	absDatabasePath, err := filepath.Abs(databasePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid database path: %v", err)
+	}
+
+	if rel, err := filepath.Rel(absDatabasePath, absTargetPath); err == nil && (rel == "." || !strings.HasPrefix(rel, "..")) {
+		return "", errors.New("targetPath must not be inside the database directory")
+	}
+
+	info, err := os.Stat(targetPath)
+	switch {
+	case os.IsNotExist(err):
+		return targetPath, nil
+	case err != nil:
+		return "", fmt.Errorf("invalid targetPath: %v", err)
+	case !info.IsDir():
+		return "", errors.New("targetPath is not a directory")
+	}
+
+	entries, err := ioutil.ReadDir(targetPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid targetPath: %v", err)
+	}
+	if len(entries) > 0 {
+		return "", errors.New("targetPath is not empty")
+	}
+
+	return targetPath, nil
+}
@@ -0,0 +1,59 @@
+package webapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBackupTargetPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	databasePath := filepath.Join(dir, "mainnetdb")
+	require.NoError(t, os.Mkdir(databasePath, 0700))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "empty"), 0700))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nonempty"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "nonempty", "file"), nil, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0600))
+
+	tests := []struct {
+		name       string
+		targetPath string
+		valid      bool
+	}{
+		{"empty", "", false},
+		{"database path", databasePath, false},
+		{"inside database path", filepath.Join(databasePath, "backup"), false},
+		{"file", filepath.Join(dir, "file"), false},
+		{"non-empty directory", filepath.Join(dir, "nonempty"), false},
+		{"empty directory", filepath.Join(dir, "empty"), true},
+		{"not existing", filepath.Join(dir, "backup", ".."+string(filepath.Separator), "mainnetdb_backup"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backupPath, err := validateBackupTargetPath(tt.targetPath, databasePath)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Clean(tt.targetPath), backupPath)
+		})
+	}
+}
+
+func TestCreateDatabaseBackupWithoutTargetPath(t *testing.T) {
+	router := newWebAPITestRouter(t, &apiToken{name: "test", admin: true})
+
+	resp := serveWebAPITestRequest(router, `{"command": "createDatabaseBackup"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "no targetPath given")
+}
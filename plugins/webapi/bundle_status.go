@@ -0,0 +1,168 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/iota.go/guards"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/dag"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/tipselect"
+	"github.com/Ariwonto/aingle-alpha/plugins/urts"
+)
+
+func init() {
+	addEndpoint("getBundleStatus", getBundleStatus, implementedAPIcalls)
+}
+
+func getBundleStatus(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	// do not reply if URTS is disabled
+	if node.IsSkipped(urts.PLUGIN) {
+		e.Error = "tipselection plugin disabled in this node"
+		c.JSON(http.StatusServiceUnavailable, e)
+		return
+	}
+
+	if !tangle.IsNodeSyncedWithThreshold() {
+		e.Error = ErrNodeNotSync.Error()
+		c.JSON(http.StatusServiceUnavailable, e)
+		return
+	}
+
+	query := &GetBundleStatus{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !guards.IsHash(query.Bundle) {
+		e.Error = "invalid bundle hash supplied"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	maxResults := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxFindTransactions)
+	tailTxHashes := tangle.GetBundleTailTransactionHashes(aingle.HashFromHashTrytes(query.Bundle), true, maxResults)
+	if len(tailTxHashes) == 0 {
+		e.Error = "bundle not found"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	lsmi := tangle.GetSolidMilestoneIndex()
+	belowMaxDepth := milestone.Index(config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth))
+
+	result := GetBundleStatusReturn{
+		Bundle: query.Bundle,
+		Tails:  make([]*BundleTailStatus, 0, len(tailTxHashes)),
+	}
+
+	for _, tailTxHash := range tailTxHashes {
+		tailStatus := getBundleTailStatus(tailTxHash, lsmi, belowMaxDepth)
+		if tailStatus == nil {
+			// the tail transaction was pruned in the meantime
+			continue
+		}
+
+		if tailStatus.Confirmed {
+			result.Confirmed = true
+		}
+		result.Tails = append(result.Tails, tailStatus)
+	}
+
+	// reattach the bundle if none of the tails can still be confirmed, but at least one of them would help if reattached.
+	// reattaching conflicting or invalid bundle instances would not help.
+	canBeConfirmed := false
+	for _, tailStatus := range result.Tails {
+		if result.Confirmed {
+			// the bundle is already confirmed, there is no need to promote or reattach any of its tails
+			tailStatus.ShouldPromote = false
+			tailStatus.ShouldReattach = false
+			continue
+		}
+
+		if tailStatus.ShouldReattach {
+			result.ShouldReattach = true
+			continue
+		}
+
+		if !tailStatus.Conflicting && (!tailStatus.Complete || tailStatus.Valid) {
+			canBeConfirmed = true
+		}
+	}
+	result.ShouldReattach = result.ShouldReattach && !canBeConfirmed
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getBundleTailStatus returns the status of the bundle instance of the given tail transaction
+// or nil if the tail transaction is unknown.
+func getBundleTailStatus(tailTxHash aingle.Hash, lsmi milestone.Index, belowMaxDepth milestone.Index) *BundleTailStatus {
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tailTxHash) // meta +1
+	if cachedTxMeta == nil {
+		return nil
+	}
+	defer cachedTxMeta.Release(true) // meta -1
+
+	metadata := cachedTxMeta.GetMetadata()
+
+	// avoid passing true for conflicting tx to be consistent with getTipInfo
+	confirmed, milestoneIndex := metadata.GetConfirmed()
+	conflicting := metadata.IsConflicting()
+
+	tailStatus := &BundleTailStatus{
+		TailTransaction: tailTxHash.Trytes(),
+		Solid:           metadata.IsSolid(),
+		Confirmed:       confirmed && !conflicting,
+		Conflicting:     conflicting,
+		TipScore:        tipselect.ScoreLazy.String(),
+	}
+
+	if confirmed {
+		tailStatus.MilestoneIndex = milestoneIndex
+	}
+
+	if conflicting {
+		tailStatus.ConflictReason = metadata.GetConflict().String()
+	}
+
+	if cachedBndl := tangle.GetCachedBundleOrNil(tailTxHash); cachedBndl != nil { // bundle +1
+		tailStatus.Complete = true
+		tailStatus.Valid = cachedBndl.GetBundle().IsValid()
+		cachedBndl.Release(true) // bundle -1
+	}
+
+	if !tailStatus.Solid || confirmed {
+		// the root snapshot indexes are only known for solid transactions
+		// and the tip score is meaningless for confirmed transactions
+		return tailStatus
+	}
+
+	score, inTipPool := urts.TipSelector.GetTipScore(tailTxHash, lsmi)
+	tailStatus.TipScore = score.String()
+	tailStatus.InTipPool = inTipPool
+	tailStatus.BelowMaxDepth = dag.IsBelowMaxDepth(cachedTxMeta.Retain(), lsmi, belowMaxDepth) // meta +1
+
+	if conflicting || (tailStatus.Complete && !tailStatus.Valid) {
+		// promoting or reattaching this bundle instance would not help
+		return tailStatus
+	}
+
+	tailStatus.ShouldReattach = tailStatus.BelowMaxDepth
+	tailStatus.ShouldPromote = !tailStatus.BelowMaxDepth && score != tipselect.ScoreNonLazy
+
+	return tailStatus
+}
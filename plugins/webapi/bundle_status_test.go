@@ -0,0 +1,233 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+// bundleStatusTestTail describes a tail transaction of a bundle instance.
+type bundleStatusTestTail struct {
+	// whether the bundle instance is constructed, the constructed bundles are invalid
+	construct      bool
+	modifyMetadata func(metadata *aingle.TransactionMetadata)
+	shouldPromote  bool
+	shouldReattach bool
+}
+
+// storeBundleStatusTestTail stores the tail transaction of a bundle instance with a single transaction.
+func storeBundleStatusTestTail(t *testing.T, idx int, bundleHash trinary.Hash, tail bundleStatusTestTail) trinary.Hash {
+	// the construction of bundles checks the snapshot info whether spent addresses are enabled
+	tangle.SetSnapshotInfo(&tangle.SnapshotInfo{CoordinatorAddress: aingle.NullHashBytes, Hash: aingle.NullHashBytes})
+
+	tx, err := transaction.AsTransactionObject(strings.Repeat("9", consts.TransactionTrytesSize), trinary.IntToTrytes(int64(idx), consts.HashTrytesSize))
+	require.NoError(t, err)
+	tx.Bundle = bundleHash
+
+	cachedTx, alreadyAdded := tangle.AddTransactionToStorage(aingle.NewTransactionFromTx(tx, nil), tangle.GetLatestMilestoneIndex(), false, true, true) // tx +1
+	require.False(t, alreadyAdded)
+
+	tail.modifyMetadata(cachedTx.GetMetadata())
+	if tail.construct {
+		tangle.OnTailTransactionSolid(cachedTx.Retain()) // tx pass +1
+	}
+	cachedTx.Release(true) // tx -1
+
+	return tx.Hash
+}
+
+func TestGetBundleStatus(t *testing.T) {
+	prevBelowMaxDepth := config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth)
+	t.Cleanup(func() { config.NodeConfig.Set(config.CfgTipSelBelowMaxDepth, prevBelowMaxDepth) })
+	config.NodeConfig.Set(config.CfgTipSelBelowMaxDepth, 5)
+
+	solid := func(metadata *aingle.TransactionMetadata) {
+		metadata.SetSolid(true)
+		// the cone of the transaction is within max depth of the solid milestone
+		metadata.SetRootSnapshotIndexes(10, 10, 10)
+	}
+
+	belowMaxDepth := func(metadata *aingle.TransactionMetadata) {
+		metadata.SetSolid(true)
+		// the oldest root of the cone is below max depth
+		metadata.SetRootSnapshotIndexes(10, 1, 10)
+	}
+
+	lazy := func(metadata *aingle.TransactionMetadata) {
+		metadata.SetSolid(true)
+		// the cone of the transaction is lazy, but within max depth of the solid milestone
+		metadata.SetRootSnapshotIndexes(9, 9, 10)
+	}
+
+	conflicting := func(metadata *aingle.TransactionMetadata) {
+		metadata.SetConflicting(aingle.ConflictInsufficientBalance)
+	}
+
+	confirmed := func(metadata *aingle.TransactionMetadata) {
+		solid(metadata)
+		metadata.SetConfirmed(true, 10)
+	}
+
+	tests := []struct {
+		name           string
+		tails          []bundleStatusTestTail
+		confirmed      bool
+		shouldReattach bool
+	}{
+		{
+			name: "non-lazy tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: solid},
+			},
+		},
+		{
+			name: "unsolid tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: func(_ *aingle.TransactionMetadata) {}},
+			},
+		},
+		{
+			name: "tail below max depth",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+			},
+			shouldReattach: true,
+		},
+		{
+			name: "lazy tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: lazy, shouldPromote: true},
+			},
+		},
+		{
+			name: "tail below max depth and lazy tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+				{modifyMetadata: lazy, shouldPromote: true},
+			},
+		},
+		{
+			name: "tail below max depth and non-lazy tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+				{modifyMetadata: solid},
+			},
+		},
+		{
+			name: "tail below max depth and unsolid tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+				{modifyMetadata: func(_ *aingle.TransactionMetadata) {}},
+			},
+		},
+		{
+			name: "confirmed tail",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: confirmed},
+				{modifyMetadata: belowMaxDepth},
+				{modifyMetadata: lazy},
+			},
+			confirmed: true,
+		},
+		{
+			name: "conflicting tails",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: func(metadata *aingle.TransactionMetadata) {
+					lazy(metadata)
+					conflicting(metadata)
+				}},
+				{modifyMetadata: func(metadata *aingle.TransactionMetadata) {
+					belowMaxDepth(metadata)
+					conflicting(metadata)
+				}},
+				{modifyMetadata: func(metadata *aingle.TransactionMetadata) {
+					confirmed(metadata)
+					conflicting(metadata)
+				}},
+			},
+		},
+		{
+			name: "invalid tails",
+			tails: []bundleStatusTestTail{
+				{construct: true, modifyMetadata: lazy},
+				{construct: true, modifyMetadata: belowMaxDepth},
+			},
+		},
+		{
+			name: "conflicting tail and tail below max depth",
+			tails: []bundleStatusTestTail{
+				{modifyMetadata: func(metadata *aingle.TransactionMetadata) {
+					solid(metadata)
+					conflicting(metadata)
+				}},
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+			},
+			shouldReattach: true,
+		},
+		{
+			name: "invalid tail and tail below max depth",
+			tails: []bundleStatusTestTail{
+				{construct: true, modifyMetadata: solid},
+				{modifyMetadata: belowMaxDepth, shouldReattach: true},
+			},
+			shouldReattach: true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configureReissueTest(t)
+			tangle.SetSolidMilestoneIndex(10)
+			router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+			bundleHash := trinary.IntToTrytes(int64(i+1), consts.HashTrytesSize)
+
+			tailStatuses := make(map[trinary.Hash]bundleStatusTestTail)
+			for j, tail := range test.tails {
+				tailStatuses[storeBundleStatusTestTail(t, j+1, bundleHash, tail)] = tail
+			}
+
+			resp := serveWebAPITestRequest(router, fmt.Sprintf(`{"command": "getBundleStatus", "bundle": %q}`, bundleHash))
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+			result := &GetBundleStatusReturn{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+			assert.Equal(t, bundleHash, result.Bundle)
+			assert.Equal(t, test.confirmed, result.Confirmed)
+			assert.Equal(t, test.shouldReattach, result.ShouldReattach)
+
+			require.Len(t, result.Tails, len(test.tails))
+			for _, tailStatus := range result.Tails {
+				tail, exists := tailStatuses[tailStatus.TailTransaction]
+				require.True(t, exists)
+				assert.Equal(t, tail.construct, tailStatus.Complete, tailStatus.TailTransaction)
+				assert.False(t, tailStatus.Valid, tailStatus.TailTransaction)
+				assert.Equal(t, tail.shouldPromote, tailStatus.ShouldPromote, tailStatus.TailTransaction)
+				assert.Equal(t, tail.shouldReattach, tailStatus.ShouldReattach, tailStatus.TailTransaction)
+			}
+		})
+	}
+
+	t.Run("bundle not found", func(t *testing.T) {
+		configureReissueTest(t)
+		tangle.SetSolidMilestoneIndex(10)
+		router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+		resp := serveWebAPITestRequest(router, fmt.Sprintf(`{"command": "getBundleStatus", "bundle": %q}`, consts.NullHashTrytes))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "bundle not found")
+	})
+}
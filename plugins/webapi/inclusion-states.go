@@ -45,6 +45,7 @@ func getInclusionStates(i interface{}, c *gin.Context, _ <-chan struct{}) {
 	}
 
 	inclusionStates := []bool{}
+	conflicts := []string{}
 
 	for _, tx := range query.Transactions {
 		// get tx data
@@ -53,14 +54,22 @@ func getInclusionStates(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		if cachedTxMeta == nil {
 			// if tx is unknown, return false
 			inclusionStates = append(inclusionStates, false)
+			conflicts = append(conflicts, "")
 			continue
 		}
 		// check if tx is set as confirmed. Avoid passing true for conflicting tx to be backwards compatible
 		confirmed := cachedTxMeta.GetMetadata().IsConfirmed() && !cachedTxMeta.GetMetadata().IsConflicting()
 
+		// the reason why the tx was excluded from the ledger, empty if it is not conflicting
+		var conflict string
+		if cachedTxMeta.GetMetadata().IsConflicting() {
+			conflict = cachedTxMeta.GetMetadata().GetConflict().String()
+		}
+
 		cachedTxMeta.Release(true) // meta -1
 		inclusionStates = append(inclusionStates, confirmed)
+		conflicts = append(conflicts, conflict)
 	}
 
-	c.JSON(http.StatusOK, GetInclusionStatesReturn{States: inclusionStates})
+	c.JSON(http.StatusOK, GetInclusionStatesReturn{States: inclusionStates, Conflicts: conflicts})
 }
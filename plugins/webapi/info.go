@@ -91,6 +91,11 @@ func getNodeInfo(_ interface{}, c *gin.Context, _ <-chan struct{}) {
 	// Coo addr
 	result.CoordinatorAddress = config.NodeConfig.GetString(config.CfgCoordinatorAddress)
 
+	// Ledger hash
+	ledgerHash, ledgerHashIndex := tangle.GetLedgerHash()
+	result.LedgerHash = ledgerHash.String()
+	result.LedgerHashMilestoneIndex = ledgerHashIndex
+
 	// Return node info
 	c.JSON(http.StatusOK, result)
 }
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/trinary"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
@@ -18,6 +20,8 @@ func init() {
 	addEndpoint("getLedgerDiff", getLedgerDiff, implementedAPIcalls)
 	addEndpoint("getLedgerDiffExt", getLedgerDiffExt, implementedAPIcalls)
 	addEndpoint("getLedgerState", getLedgerState, implementedAPIcalls)
+	addEndpoint("getLedgerHash", getLedgerHash, implementedAPIcalls)
+	addEndpoint("getBalanceHistory", getBalanceHistory, implementedAPIcalls)
 }
 
 func getLedgerDiff(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -226,3 +230,112 @@ func getLedgerState(i interface{}, c *gin.Context, abortSignal <-chan struct{})
 
 	c.JSON(http.StatusOK, GetLedgerStateReturn{Balances: balancesTrytes, MilestoneIndex: index})
 }
+
+func getLedgerHash(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetLedgerHash{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	ledgerHash, ledgerIndex := tangle.GetLedgerHash()
+	if query.MilestoneIndex == 0 || query.MilestoneIndex == ledgerIndex {
+		c.JSON(http.StatusOK, GetLedgerHashReturn{LedgerHash: ledgerHash.String(), MilestoneIndex: ledgerIndex})
+		return
+	}
+
+	if query.MilestoneIndex > ledgerIndex {
+		e.Error = fmt.Sprintf("Invalid milestone index supplied, ledger index is %d", ledgerIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	ledgerHash, exists, err := tangle.GetLedgerHashForMilestone(query.MilestoneIndex)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !exists {
+		e.Error = fmt.Sprintf("Ledger hash for milestone %d not found", query.MilestoneIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetLedgerHashReturn{LedgerHash: ledgerHash.String(), MilestoneIndex: query.MilestoneIndex})
+}
+
+func getBalanceHistory(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetBalanceHistory{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := address.ValidAddress(query.Address); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", err, query.Address)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	// ledger diffs are only kept for the milestones after the pruning index
+	minIndex := tangle.GetSnapshotInfo().PruningIndex + 1
+	ledgerIndex := tangle.GetSolidMilestoneIndex()
+
+	fromIndex := query.FromIndex
+	if fromIndex == 0 {
+		fromIndex = minIndex
+	}
+
+	toIndex := query.ToIndex
+	if toIndex == 0 {
+		toIndex = ledgerIndex
+	}
+
+	if fromIndex < minIndex {
+		e.Error = fmt.Sprintf("Invalid from index supplied, the ledger diffs are pruned up to index %d", minIndex-1)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if toIndex > ledgerIndex || fromIndex > toIndex {
+		e.Error = fmt.Sprintf("Invalid milestone range supplied, lsmi is %d", ledgerIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	changes, startBalance, err := tangle.GetBalanceHistoryWithoutLocking(aingle.HashFromAddressTrytes(query.Address), fromIndex, toIndex)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result := GetBalanceHistoryReturn{
+		Address:      query.Address[:consts.HashTrytesSize],
+		FromIndex:    fromIndex,
+		ToIndex:      toIndex,
+		StartBalance: startBalance,
+		Changes:      make([]*BalanceChange, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		result.Changes = append(result.Changes, &BalanceChange{
+			MilestoneIndex: change.MilestoneIndex,
+			Delta:          change.Delta,
+			Balance:        change.Balance,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,172 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+const (
+	// defaultRichListAddresses is the amount of addresses returned by getLedgerStats if no amount was requested
+	defaultRichListAddresses = 100
+
+	// balanceBucketCount is the amount of buckets of the balance histogram.
+	// the buckets are decades of the balance, the last bucket contains the total supply.
+	balanceBucketCount = 16
+)
+
+var (
+	ledgerStatsCache     *ledgerStats
+	ledgerStatsCacheLock sync.Mutex
+)
+
+// ledgerStats are the statistics of the ledger state at a solid milestone.
+type ledgerStats struct {
+	milestoneIndex milestone.Index
+	// richList contains the addresses with the highest balances, limited to the configured maximum
+	richList         []*LedgerStatsAddressBalance
+	nonZeroAddresses int
+	totalBalance     uint64
+	histogram        []*BalanceBucket
+}
+
+func init() {
+	addEndpoint("getLedgerStats", getLedgerStats, implementedAPIcalls)
+}
+
+func getLedgerStats(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetLedgerStats{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	maxRichListAddresses := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxRichListAddresses)
+
+	addresses := query.Addresses
+	if addresses == 0 {
+		addresses = defaultRichListAddresses
+	}
+
+	if addresses < 0 || addresses > maxRichListAddresses {
+		e.Error = "Invalid amount of addresses. Max. allowed: " + strconv.Itoa(maxRichListAddresses)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	stats, err := getLedgerStatsForLSMI(maxRichListAddresses, abortSignal)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	richList := stats.richList
+	if len(richList) > addresses {
+		richList = richList[:addresses]
+	}
+
+	c.JSON(http.StatusOK, GetLedgerStatsReturn{
+		RichList:         richList,
+		NonZeroAddresses: stats.nonZeroAddresses,
+		TotalBalance:     stats.totalBalance,
+		TotalSupply:      consts.TotalSupply,
+		SupplyMatches:    stats.totalBalance == consts.TotalSupply,
+		Histogram:        stats.histogram,
+		MilestoneIndex:   stats.milestoneIndex,
+	})
+}
+
+// getLedgerStatsForLSMI returns the statistics of the ledger state at the current solid milestone.
+// The statistics are only computed once per solid milestone, concurrent requests wait for the computation.
+func getLedgerStatsForLSMI(maxRichListAddresses int, abortSignal <-chan struct{}) (*ledgerStats, error) {
+
+	ledgerStatsCacheLock.Lock()
+	defer ledgerStatsCacheLock.Unlock()
+
+	if ledgerStatsCache != nil && ledgerStatsCache.milestoneIndex == tangle.GetSolidMilestoneIndex() {
+		return ledgerStatsCache, nil
+	}
+
+	balances, index, err := tangle.GetLedgerStateForLSMI(abortSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ledgerStats{
+		milestoneIndex: index,
+		histogram:      make([]*BalanceBucket, balanceBucketCount),
+	}
+
+	minBalance := uint64(1)
+	for i := range stats.histogram {
+		stats.histogram[i] = &BalanceBucket{MinBalance: minBalance, MaxBalance: minBalance*10 - 1}
+		minBalance *= 10
+	}
+
+	type addressBalance struct {
+		address string
+		balance uint64
+	}
+
+	addressBalances := make([]addressBalance, 0, len(balances))
+	for address, balance := range balances {
+		if balance == 0 {
+			continue
+		}
+
+		stats.nonZeroAddresses++
+		stats.totalBalance += balance
+
+		bucket := stats.histogram[balanceBucketIndex(balance)]
+		bucket.Addresses++
+		bucket.Balance += balance
+
+		addressBalances = append(addressBalances, addressBalance{address: address, balance: balance})
+	}
+
+	// order by balance, addresses with the same balance are ordered by address to get a stable result
+	sort.Slice(addressBalances, func(i, j int) bool {
+		if addressBalances[i].balance != addressBalances[j].balance {
+			return addressBalances[i].balance > addressBalances[j].balance
+		}
+		return addressBalances[i].address < addressBalances[j].address
+	})
+
+	if len(addressBalances) > maxRichListAddresses {
+		addressBalances = addressBalances[:maxRichListAddresses]
+	}
+
+	stats.richList = make([]*LedgerStatsAddressBalance, 0, len(addressBalances))
+	for _, entry := range addressBalances {
+		stats.richList = append(stats.richList, &LedgerStatsAddressBalance{Address: aingle.Hash(entry.address).Trytes(), Balance: entry.balance})
+	}
+
+	ledgerStatsCache = stats
+	return stats, nil
+}
+
+// balanceBucketIndex returns the index of the histogram bucket of the given balance.
+func balanceBucketIndex(balance uint64) int {
+	index := 0
+	for balance >= 10 && index < balanceBucketCount-1 {
+		balance /= 10
+		index++
+	}
+	return index
+}
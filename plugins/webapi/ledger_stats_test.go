@@ -0,0 +1,211 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+)
+
+// configureLedgerStatsTest configures empty in-memory storages and resets the cached ledger statistics.
+func configureLedgerStatsTest(t *testing.T) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Profile1GB.Caches)
+	t.Cleanup(tangle.ShutdownStorages)
+
+	tangle.ResetMilestoneIndexes()
+	t.Cleanup(tangle.ResetMilestoneIndexes)
+
+	ledgerStatsCacheLock.Lock()
+	prevLedgerStatsCache := ledgerStatsCache
+	ledgerStatsCache = nil
+	ledgerStatsCacheLock.Unlock()
+
+	t.Cleanup(func() {
+		ledgerStatsCacheLock.Lock()
+		ledgerStatsCache = prevLedgerStatsCache
+		ledgerStatsCacheLock.Unlock()
+	})
+}
+
+func ledgerStatsTestAddress(idx int) string {
+	return string(aingle.HashFromAddressTrytes(trinary.IntToTrytes(int64(idx), consts.HashTrytesSize)))
+}
+
+// storeLedgerStatsTestBalances stores the given balances as the ledger state of the given solid milestone.
+// The remaining supply is assigned to an additional address.
+func storeLedgerStatsTestBalances(t *testing.T, index milestone.Index, balances ...uint64) {
+	ledgerState := make(map[string]uint64)
+
+	remainder := uint64(consts.TotalSupply)
+	for i, balance := range balances {
+		ledgerState[ledgerStatsTestAddress(i+1)] = balance
+		remainder -= balance
+	}
+	if remainder > 0 {
+		ledgerState[ledgerStatsTestAddress(0)] = remainder
+	}
+
+	require.NoError(t, tangle.StoreLedgerBalancesInDatabase(ledgerState, index))
+	tangle.SetSolidMilestoneIndex(index, false)
+}
+
+func TestBalanceBucketIndex(t *testing.T) {
+	tests := []struct {
+		balance uint64
+		index   int
+	}{
+		{0, 0},
+		{1, 0},
+		{9, 0},
+		{10, 1},
+		{99, 1},
+		{100, 2},
+		{999999999999999, 14},
+		{1000000000000000, 15},
+		{consts.TotalSupply, 15},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.index, balanceBucketIndex(test.balance), test.balance)
+	}
+
+	// every decade is mapped to its own bucket
+	decade := uint64(1)
+	for k := 0; k < balanceBucketCount; k++ {
+		assert.Equal(t, k, balanceBucketIndex(decade), decade)
+		assert.Equal(t, k, balanceBucketIndex(decade*10-1), decade*10-1)
+		decade *= 10
+	}
+}
+
+func TestGetLedgerStatsHistogram(t *testing.T) {
+	configureLedgerStatsTest(t)
+
+	storeLedgerStatsTestBalances(t, 1, 0, 1, 9, 10, 99, 999999999999999, 1000000000000000)
+
+	stats, err := getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, stats.milestoneIndex)
+	assert.Equal(t, uint64(consts.TotalSupply), stats.totalBalance)
+	// the address without balance is not part of the ledger state
+	assert.Equal(t, 7, stats.nonZeroAddresses)
+
+	require.Len(t, stats.histogram, balanceBucketCount)
+	for i, bucket := range stats.histogram {
+		assert.Equal(t, i, balanceBucketIndex(bucket.MinBalance), i)
+		assert.Equal(t, i, balanceBucketIndex(bucket.MaxBalance), i)
+		if i > 0 {
+			assert.Equal(t, stats.histogram[i-1].MaxBalance+1, bucket.MinBalance, i)
+		}
+	}
+	assert.EqualValues(t, 1, stats.histogram[0].MinBalance)
+	assert.True(t, stats.histogram[balanceBucketCount-1].MaxBalance >= consts.TotalSupply)
+
+	expected := map[int]BalanceBucket{
+		0: {Addresses: 2, Balance: 1 + 9},
+		1: {Addresses: 2, Balance: 10 + 99},
+		// the remaining supply is below 10^15
+		14: {Addresses: 2, Balance: consts.TotalSupply - 1000000000000000 - 1 - 9 - 10 - 99},
+		15: {Addresses: 1, Balance: 1000000000000000},
+	}
+	for i, bucket := range stats.histogram {
+		assert.Equal(t, expected[i].Addresses, bucket.Addresses, i)
+		assert.Equal(t, expected[i].Balance, bucket.Balance, i)
+	}
+
+	// the total supply on a single address is part of the last bucket
+	storeLedgerStatsTestBalances(t, 2, consts.TotalSupply)
+
+	stats, err = getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.nonZeroAddresses)
+	for i, bucket := range stats.histogram {
+		if i == balanceBucketCount-1 {
+			assert.Equal(t, 1, bucket.Addresses)
+			assert.Equal(t, uint64(consts.TotalSupply), bucket.Balance)
+			continue
+		}
+		assert.Zero(t, bucket.Addresses, i)
+	}
+}
+
+func TestGetLedgerStatsCache(t *testing.T) {
+	configureLedgerStatsTest(t)
+
+	storeLedgerStatsTestBalances(t, 1, 10)
+
+	stats, err := getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.milestoneIndex)
+	assert.Equal(t, 2, stats.nonZeroAddresses)
+
+	// the ledger state is only read again for a new solid milestone
+	require.NoError(t, tangle.StoreLedgerBalancesInDatabase(map[string]uint64{ledgerStatsTestAddress(0): consts.TotalSupply}, 1))
+
+	cachedStats, err := getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+	assert.Same(t, stats, cachedStats)
+
+	storeLedgerStatsTestBalances(t, 2, 10, 20)
+
+	newStats, err := getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, stats, newStats)
+	assert.EqualValues(t, 2, newStats.milestoneIndex)
+	assert.Equal(t, 3, newStats.nonZeroAddresses)
+
+	cachedStats, err = getLedgerStatsForLSMI(100, nil)
+	require.NoError(t, err)
+	assert.Same(t, newStats, cachedStats)
+}
+
+func TestGetLedgerStats(t *testing.T) {
+	configureLedgerStatsTest(t)
+
+	prevMaxRichListAddresses := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxRichListAddresses)
+	t.Cleanup(func() { config.NodeConfig.Set(config.CfgWebAPILimitsMaxRichListAddresses, prevMaxRichListAddresses) })
+	config.NodeConfig.Set(config.CfgWebAPILimitsMaxRichListAddresses, 3)
+
+	router := newWebAPITestRouter(t, &apiToken{name: "test", admin: true})
+
+	storeLedgerStatsTestBalances(t, 1, 5, 5, 20)
+
+	getLedgerStats := func(addresses int) *GetLedgerStatsReturn {
+		resp := serveWebAPITestRequest(router, fmt.Sprintf(`{"command": "getLedgerStats", "addresses": %d}`, addresses))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		result := &GetLedgerStatsReturn{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		return result
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serveWebAPITestRequest(router, `{"command": "getLedgerStats", "addresses": 4}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWebAPITestRequest(router, `{"command": "getLedgerStats", "addresses": -1}`).Code)
+
+	result := getLedgerStats(3)
+	assert.EqualValues(t, 1, result.MilestoneIndex)
+	assert.Equal(t, 4, result.NonZeroAddresses)
+	assert.True(t, result.SupplyMatches)
+	assert.Equal(t, []*LedgerStatsAddressBalance{
+		{Address: aingle.Hash(ledgerStatsTestAddress(0)).Trytes(), Balance: consts.TotalSupply - 30},
+		{Address: aingle.Hash(ledgerStatsTestAddress(3)).Trytes(), Balance: 20},
+		// addresses with the same balance are ordered by address
+		{Address: aingle.Hash(ledgerStatsTestAddress(1)).Trytes(), Balance: 5},
+	}, result.RichList)
+
+	assert.Len(t, getLedgerStats(1).RichList, 1)
+}
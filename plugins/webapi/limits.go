@@ -0,0 +1,204 @@
+package webapi
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/metrics"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
+)
+
+const (
+	// clientLimiterIdleTimeout is the time after which the rate limiter of an idle client is removed.
+	// The bucket of a client is full again after one minute, so removing it does not change the limit.
+	clientLimiterIdleTimeout = time.Minute
+
+	// concurrencyRetryAfter is the time after which a request rejected due to the concurrency limit can be retried.
+	concurrencyRetryAfter = time.Second
+)
+
+// costClass groups commands by the load they put on the node.
+type costClass int
+
+const (
+	costClassNormal costClass = iota
+	costClassExpensive
+)
+
+func (cc costClass) String() string {
+	switch cc {
+	case costClassExpensive:
+		return "expensive"
+	default:
+		return "normal"
+	}
+}
+
+var (
+	// expensiveCommands are the lower cased commands in the expensive cost class.
+	expensiveCommands = make(map[string]struct{})
+
+	requestLimiters = make(map[costClass]*requestLimiter)
+)
+
+// clientLimiter is the rate limiter of a single client.
+type clientLimiter struct {
+	bucket   *ratelimit.TokenBucket
+	lastSeen time.Time
+}
+
+// requestLimiter limits the requests per minute of every client and the concurrently processed requests of a cost class.
+type requestLimiter struct {
+	// perMinute is the maximum number of requests per minute of a client, 0 means unlimited.
+	perMinute   int
+	clientsLock sync.Mutex
+	clients     map[string]*clientLimiter
+	lastCleanup time.Time
+	// semaphore holds a token for every request which is processed, nil if unlimited.
+	semaphore chan struct{}
+}
+
+func newRequestLimiter(perMinute int, maxConcurrent int) *requestLimiter {
+	limiter := &requestLimiter{
+		perMinute:   perMinute,
+		clients:     make(map[string]*clientLimiter),
+		lastCleanup: time.Now(),
+	}
+
+	if maxConcurrent > 0 {
+		limiter.semaphore = make(chan struct{}, maxConcurrent)
+	}
+
+	return limiter
+}
+
+// take takes a request out of the rate limit of the given client.
+// If the limit is exceeded, the duration until the request can be retried is returned.
+func (l *requestLimiter) take(client string) (bool, time.Duration) {
+	if l.perMinute <= 0 {
+		return true, 0
+	}
+
+	l.clientsLock.Lock()
+
+	now := time.Now()
+	if now.Sub(l.lastCleanup) > clientLimiterIdleTimeout {
+		// remove the limiters of idle clients
+		for key, cl := range l.clients {
+			if now.Sub(cl.lastSeen) > clientLimiterIdleTimeout {
+				delete(l.clients, key)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	cl, exists := l.clients[client]
+	if !exists {
+		cl = &clientLimiter{bucket: ratelimit.NewTokenBucket(l.perMinute)}
+		l.clients[client] = cl
+	}
+	cl.lastSeen = now
+
+	l.clientsLock.Unlock()
+
+	return cl.bucket.Take(1)
+}
+
+// acquire reserves a slot for a concurrently processed request.
+// It does not block and returns false if all slots are taken.
+func (l *requestLimiter) acquire() bool {
+	if l.semaphore == nil {
+		return true
+	}
+
+	select {
+	case l.semaphore <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot reserved with acquire.
+func (l *requestLimiter) release() {
+	if l.semaphore == nil {
+		return
+	}
+	<-l.semaphore
+}
+
+// configureRequestLimits loads the cost classes of the commands and creates the request limiters.
+func configureRequestLimits() {
+	for _, cmd := range config.NodeConfig.GetStringSlice(config.CfgWebAPILimitsExpensiveCommands) {
+		expensiveCommands[strings.ToLower(cmd)] = struct{}{}
+	}
+
+	requestLimiters[costClassNormal] = newRequestLimiter(
+		config.NodeConfig.GetInt(config.CfgWebAPILimitsRequestsPerMinute),
+		config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxConcurrentRequests))
+
+	requestLimiters[costClassExpensive] = newRequestLimiter(
+		config.NodeConfig.GetInt(config.CfgWebAPILimitsExpensiveRequestsPerMinute),
+		config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxConcurrentExpensiveRequests))
+}
+
+// costClassOf returns the cost class of the given lower cased command.
+func costClassOf(cmd string) costClass {
+	if _, expensive := expensiveCommands[cmd]; expensive {
+		return costClassExpensive
+	}
+	return costClassNormal
+}
+
+// requestClient returns the key the rate limit of the request is tracked by.
+// Requests authenticated with an API token share the limit of the token, all other requests the limit of their IP address.
+func requestClient(c *gin.Context) string {
+	if token, authenticated := apiTokenFromContext(c); authenticated {
+		return "token:" + token.name
+	}
+
+	remoteHost, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		remoteHost = c.Request.RemoteAddr
+	}
+	return "ip:" + remoteHost
+}
+
+// acquireRequestLimits enforces the rate and concurrency limits of the cost class of the given lower cased command.
+// Requests from whitelisted networks are not rate limited, but count towards the concurrency limit.
+// If the request is rejected, it is aborted with a 429 status. Otherwise the returned function
+// must be called once the request was processed.
+func acquireRequestLimits(c *gin.Context, cmd string) (func(), bool) {
+	class := costClassOf(cmd)
+	limiter := requestLimiters[class]
+
+	if !isWhitelisted(c) {
+		if ok, retryAfter := limiter.take(requestClient(c)); !ok {
+			if class == costClassExpensive {
+				metrics.SharedWebAPIMetrics.RateLimitedExpensiveRequests.Inc()
+			} else {
+				metrics.SharedWebAPIMetrics.RateLimitedRequests.Inc()
+			}
+			abortTooManyRequests(c, retryAfter, fmt.Sprintf("rate limit for %s requests exceeded", class))
+			return nil, false
+		}
+	}
+
+	if !limiter.acquire() {
+		if class == costClassExpensive {
+			metrics.SharedWebAPIMetrics.ConcurrencyLimitedExpensiveRequests.Inc()
+		} else {
+			metrics.SharedWebAPIMetrics.ConcurrencyLimitedRequests.Inc()
+		}
+		abortTooManyRequests(c, concurrencyRetryAfter, fmt.Sprintf("too many concurrent %s requests", class))
+		return nil, false
+	}
+
+	return limiter.release, true
+}
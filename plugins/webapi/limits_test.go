@@ -0,0 +1,171 @@
+package webapi
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/metrics"
+)
+
+// setRequestLimits replaces the request limiters, the expensive commands and the whitelisted networks for the test.
+func setRequestLimits(t *testing.T, normal *requestLimiter, expensive *requestLimiter, expensiveCmds ...string) {
+	prevLimiters, prevExpensiveCommands, prevWhitelistedNetworks := requestLimiters, expensiveCommands, whitelistedNetworks
+	t.Cleanup(func() {
+		requestLimiters, expensiveCommands, whitelistedNetworks = prevLimiters, prevExpensiveCommands, prevWhitelistedNetworks
+	})
+
+	requestLimiters = map[costClass]*requestLimiter{costClassNormal: normal, costClassExpensive: expensive}
+	expensiveCommands = make(map[string]struct{})
+	for _, cmd := range expensiveCmds {
+		expensiveCommands[cmd] = struct{}{}
+	}
+
+	_, localhost, err := net.ParseCIDR("127.0.0.1/32")
+	require.NoError(t, err)
+	whitelistedNetworks = []net.IPNet{*localhost}
+}
+
+// newLimitsTestRouter serves a normal and an expensive command, both are permitted for remote access.
+// The requests with the given token are authenticated with it.
+func newLimitsTestRouter(t *testing.T, token *apiToken, handler gin.HandlerFunc) *gin.Engine {
+	prevPermittedEndpoints := permitedEndpoints
+	t.Cleanup(func() { permitedEndpoints = prevPermittedEndpoints })
+	permitedEndpoints = map[string]string{"gettipinfo": "gettipinfo", "getbalances": "getbalances"}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if token != nil && c.GetHeader("X-Test-Token") != "" {
+			c.Set(apiTokenContextKey, token)
+		}
+	})
+	router.GET("/normal", permittedV1("getTipInfo"), handler)
+	router.GET("/expensive", permittedV1("getBalances"), handler)
+	return router
+}
+
+func serveLimitsTestRequest(router *gin.Engine, path string, remoteAddr string, withToken bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	if withToken {
+		req.Header.Set("X-Test-Token", "1")
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRequestLimitsRate(t *testing.T) {
+	setRequestLimits(t, newRequestLimiter(2, 0), newRequestLimiter(1, 0), "getbalances")
+	router := newLimitsTestRouter(t, &apiToken{name: "test", permissions: map[string]struct{}{"gettipinfo": {}}}, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rateLimited := metrics.SharedWebAPIMetrics.RateLimitedRequests.Load()
+	rateLimitedExpensive := metrics.SharedWebAPIMetrics.RateLimitedExpensiveRequests.Load()
+
+	// the bucket of a client allows a burst of the per minute limit
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "192.0.2.1:1000", false).Code)
+	}
+
+	resp := serveLimitsTestRequest(router, "/normal", "192.0.2.1:1001", false)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	// one token is refilled every 30 seconds
+	assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	assert.Equal(t, rateLimited+1, metrics.SharedWebAPIMetrics.RateLimitedRequests.Load())
+
+	// the expensive commands have their own limit
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/expensive", "192.0.2.1:1000", false).Code)
+	resp = serveLimitsTestRequest(router, "/expensive", "192.0.2.1:1000", false)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	assert.Equal(t, rateLimitedExpensive+1, metrics.SharedWebAPIMetrics.RateLimitedExpensiveRequests.Load())
+
+	// every client has its own bucket
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "192.0.2.2:1000", false).Code)
+
+	// the requests of an API token share the bucket of the token, independent of their address
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "192.0.2.1:1000", true).Code)
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "192.0.2.3:1000", true).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveLimitsTestRequest(router, "/normal", "192.0.2.4:1000", true).Code)
+
+	// whitelisted networks are not rate limited
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "127.0.0.1:1000", false).Code)
+	}
+}
+
+func TestRequestLimitsConcurrency(t *testing.T) {
+	setRequestLimits(t, newRequestLimiter(0, 1), newRequestLimiter(0, 1), "getbalances")
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	router := newLimitsTestRouter(t, nil, func(c *gin.Context) {
+		if c.GetHeader("X-Test-Block") != "" {
+			entered <- struct{}{}
+			<-unblock
+		}
+		c.Status(http.StatusOK)
+	})
+
+	concurrencyLimited := metrics.SharedWebAPIMetrics.ConcurrencyLimitedRequests.Load()
+
+	// the only slot of the normal cost class is held by a blocked request
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req := httptest.NewRequest(http.MethodGet, "/normal", nil)
+		req.RemoteAddr = "192.0.2.1:1000"
+		req.Header.Set("X-Test-Block", "1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked request was not processed")
+	}
+
+	resp := serveLimitsTestRequest(router, "/normal", "192.0.2.2:1000", false)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+	assert.Equal(t, concurrencyLimited+1, metrics.SharedWebAPIMetrics.ConcurrencyLimitedRequests.Load())
+
+	// whitelisted networks count towards the concurrency limit
+	assert.Equal(t, http.StatusTooManyRequests, serveLimitsTestRequest(router, "/normal", "127.0.0.1:1000", false).Code)
+
+	// the expensive commands have their own slots
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/expensive", "192.0.2.2:1000", false).Code)
+
+	// the slot is released once the request was processed
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, serveLimitsTestRequest(router, "/normal", "192.0.2.2:1000", false).Code)
+}
+
+func TestRequestLimiterCleanup(t *testing.T) {
+	limiter := newRequestLimiter(1, 0)
+
+	ok, _ := limiter.take("ip:192.0.2.1")
+	assert.True(t, ok)
+	ok, _ = limiter.take("ip:192.0.2.1")
+	assert.False(t, ok)
+	assert.Len(t, limiter.clients, 1)
+
+	// the limiters of idle clients are removed
+	limiter.clients["ip:192.0.2.1"].lastSeen = time.Now().Add(-2 * clientLimiterIdleTimeout)
+	limiter.lastCleanup = time.Now().Add(-2 * clientLimiterIdleTimeout)
+
+	ok, _ = limiter.take("ip:192.0.2.2")
+	assert.True(t, ok)
+	assert.Len(t, limiter.clients, 1)
+	assert.Contains(t, limiter.clients, "ip:192.0.2.2")
+}
@@ -171,6 +171,7 @@ func removeNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			for i, cn := range configNeighbors {
 				if strings.EqualFold(cn.ID, uri) {
 					removed = true
+					peering.Manager().UnpinKey(cn.ID)
 
 					// Delete item
 					configNeighbors[i] = configNeighbors[len(configNeighbors)-1]
@@ -64,7 +64,8 @@ func configure(plugin *node.Plugin) {
 	api.Use(corsMiddleware)
 
 	// GZIP
-	api.Use(gzip.Gzip(gzip.DefaultCompression))
+	// websocket connections are hijacked and must not be compressed by the middleware
+	api.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/" + webSocketRoute})))
 
 	// Load allowed remote access to specific HTTP API commands
 	pae := config.NodeConfig.GetStringSlice(config.CfgWebAPIPermitRemoteAccess)
@@ -93,6 +94,13 @@ func configure(plugin *node.Plugin) {
 		restAPIRoute()
 	}
 
+	// limit the requests per client and the concurrently processed requests
+	configureRequestLimits()
+
+	// authenticate requests with API tokens
+	configureAPITokens()
+	api.Use(apiTokenMiddleware)
+
 	// set basic auth if enabled
 	// TODO: replace gin with echo so we don't have to write this middleware ourselves
 	if config.NodeConfig.GetBool(config.CfgWebAPIBasicAuthEnabled) {
@@ -115,6 +123,11 @@ func configure(plugin *node.Plugin) {
 		}
 
 		api.Use(func(c *gin.Context) {
+			if _, authenticated := apiTokenFromContext(c); authenticated {
+				// the request was already authenticated with an API token
+				return
+			}
+
 			authVal := c.Request.Header.Get("Authorization")
 			if len(authVal) <= len(basicAuthPrefix) {
 				unauthorizedReq(c)
@@ -148,6 +161,12 @@ func configure(plugin *node.Plugin) {
 	if !config.NodeConfig.GetBool(config.CfgNetAutopeeringRunAsEntryNode) {
 		// WebAPI route
 		webAPIRoute()
+
+		// REST API v1 routes
+		restAPIv1Route()
+
+		// websocket subscriptions
+		configureWebSocket()
 	}
 
 	// Handle route with auth
@@ -165,6 +184,8 @@ func configure(plugin *node.Plugin) {
 func run(_ *node.Plugin) {
 	log.Info("Starting WebAPI server ...")
 
+	runAPITokensConfigWatcher()
+
 	if !config.NodeConfig.GetBool(config.CfgNetAutopeeringRunAsEntryNode) {
 		// Check for features
 		if _, ok := permitedEndpoints["attachtotangle"]; ok {
@@ -174,6 +195,8 @@ func run(_ *node.Plugin) {
 		if tangle.GetSnapshotInfo().IsSpentAddressesEnabled() {
 			features = append(features, "WereAddressesSpentFrom")
 		}
+
+		runWebSocket()
 	}
 
 	daemon.BackgroundWorker("WebAPI server", func(shutdownSignal <-chan struct{}) {
@@ -82,16 +82,32 @@ func attachToTangle(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		}
 	}
 
+	if err := attachBundle(txs, query.TrunkTransaction, query.BranchTransaction, query.MinWeightMagnitude); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	powedTxTrytes := transaction.MustTransactionsToTrytes(txs)
+
+	c.JSON(http.StatusOK, AttachToTangleReturn{Trytes: powedTxTrytes})
+}
+
+// attachBundle attaches the transactions of a bundle, sorted from the highest to the lowest index,
+// to the given trunk and branch and does the PoW for them.
+// Afterwards the transactions are sorted from the lowest to the highest index.
+func attachBundle(txs []transaction.Transaction, trunk trinary.Hash, branch trinary.Hash, mwm int) error {
+
 	var prev trinary.Hash
 	for i := 0; i < len(txs); i++ {
 
 		switch {
 		case i == 0:
-			txs[i].TrunkTransaction = query.TrunkTransaction
-			txs[i].BranchTransaction = query.BranchTransaction
+			txs[i].TrunkTransaction = trunk
+			txs[i].BranchTransaction = branch
 		default:
 			txs[i].TrunkTransaction = prev
-			txs[i].BranchTransaction = query.TrunkTransaction
+			txs[i].BranchTransaction = trunk
 		}
 
 		txs[i].AttachmentTimestamp = time.Now().UnixNano() / int64(time.Millisecond)
@@ -101,27 +117,21 @@ func attachToTangle(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		// Convert tx to trytes
 		trytes, err := transaction.TransactionToTrytes(&txs[i])
 		if err != nil {
-			e.Error = err.Error()
-			c.JSON(http.StatusInternalServerError, e)
-			return
+			return err
 		}
 
 		// Do the PoW
 		ts := time.Now()
-		txs[i].Nonce, err = pow.Handler().DoPoW(trytes, query.MinWeightMagnitude)
+		txs[i].Nonce, err = pow.Handler().DoPoW(trytes, mwm)
 		if err != nil {
-			e.Error = err.Error()
-			c.JSON(http.StatusInternalServerError, e)
-			return
+			return err
 		}
 		log.Debugf("PoW method: \"%s\", MWM: %d, took %v", pow.Handler().GetPoWType(), mwm, time.Since(ts).Truncate(time.Millisecond))
 
 		// Convert tx to trits
 		txTrits, err := transaction.TransactionToTrits(&txs[i])
 		if err != nil {
-			e.Error = err.Error()
-			c.JSON(http.StatusInternalServerError, e)
-			return
+			return err
 		}
 
 		// Calculate the transaction hash with the batched hasher
@@ -131,10 +141,8 @@ func attachToTangle(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		prev = txs[i].Hash
 
 		// Check tx
-		if !transaction.HasValidNonce(&txs[i], uint64(query.MinWeightMagnitude)) {
-			e.Error = err.Error()
-			c.JSON(http.StatusInternalServerError, e)
-			return
+		if !transaction.HasValidNonce(&txs[i], uint64(mwm)) {
+			return fmt.Errorf("invalid nonce for transaction %s", txs[i].Hash)
 		}
 	}
 
@@ -143,7 +151,5 @@ func attachToTangle(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		txs[i], txs[j] = txs[j], txs[i]
 	}
 
-	powedTxTrytes := transaction.MustTransactionsToTrytes(txs)
-
-	c.JSON(http.StatusOK, AttachToTangleReturn{Trytes: powedTxTrytes})
+	return nil
 }
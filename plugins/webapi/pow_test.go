@@ -0,0 +1,58 @@
+package webapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/bundle"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+func TestAttachBundle(t *testing.T) {
+	const mwm = 1
+
+	txs, err := bundle.Finalize(bundle.AddEntry(nil, bundle.BundleEntry{
+		Address: consts.NullHashTrytes,
+		Tag:     trinary.MustPad("TEST", consts.TagTrinarySize/3),
+		Length:  3,
+	}))
+	require.NoError(t, err)
+
+	// the transactions are attached from the highest to the lowest index
+	for i, j := 0, len(txs)-1; i < j; i, j = i+1, j-1 {
+		txs[i], txs[j] = txs[j], txs[i]
+	}
+
+	trunk := trinary.IntToTrytes(1, consts.HashTrytesSize)
+	branch := trinary.IntToTrytes(2, consts.HashTrytesSize)
+	require.NoError(t, attachBundle(txs, trunk, branch, mwm))
+
+	// afterwards the transactions are sorted from the lowest to the highest index
+	for i := range txs {
+		tx := &txs[i]
+		assert.Equal(t, uint64(i), tx.CurrentIndex)
+
+		// the hash is the hash of the attached transaction
+		assert.Equal(t, transaction.TransactionHash(tx), tx.Hash)
+		assert.True(t, transaction.HasValidNonce(tx, mwm))
+		assert.NotZero(t, tx.AttachmentTimestamp)
+
+		if i == len(txs)-1 {
+			// the head references the given trunk and branch
+			assert.Equal(t, trunk, tx.TrunkTransaction)
+			assert.Equal(t, branch, tx.BranchTransaction)
+			continue
+		}
+
+		// the other transactions reference the next transaction of the bundle and the given trunk
+		assert.Equal(t, txs[i+1].Hash, tx.TrunkTransaction)
+		assert.Equal(t, trunk, tx.BranchTransaction)
+	}
+
+	// the attached transactions form a valid bundle
+	assert.NoError(t, bundle.ValidBundle(txs))
+}
@@ -0,0 +1,257 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/iota.go/bundle"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/dag"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/tipselect"
+	"github.com/Ariwonto/aingle-alpha/plugins/gossip"
+	"github.com/Ariwonto/aingle-alpha/plugins/urts"
+)
+
+const (
+	// promotionTag is the tag of the zero value transactions issued to promote a tail transaction
+	promotionTag = "PROMOTE"
+)
+
+func init() {
+	addEndpoint("promoteTransaction", promoteTransaction, implementedAPIcalls)
+	addEndpoint("reattachTransaction", reattachTransaction, implementedAPIcalls)
+}
+
+func promoteTransaction(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &PromoteTransaction{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	tailHash, belowMaxDepth, ok := checkTailForReissue(c, query.TailTransaction)
+	if !ok {
+		return
+	}
+
+	if belowMaxDepth {
+		e.Error = "tail transaction is below max depth and must be reattached"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if token, authenticated := apiTokenFromContext(c); authenticated {
+		if !token.takePoWQuota(c, 1) {
+			return
+		}
+		defer token.refundPoWQuotaOnError(c, 1)
+	}
+
+	tips, ok := selectTipsForReissue(c)
+	if !ok {
+		return
+	}
+
+	// the promotion is a zero value bundle with a single transaction which references the tail
+	txs, err := bundle.Finalize(bundle.AddEntry(nil, bundle.BundleEntry{
+		Address: consts.NullHashTrytes,
+		Value:   0,
+		Tag:     trinary.MustPad(promotionTag, consts.TagTrinarySize/3),
+		Length:  1,
+	}))
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := attachBundle(txs, tips[0].Trytes(), tailHash.Trytes(), config.NodeConfig.GetInt(config.CfgCoordinatorMWM)); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	powedTxTrytes, ok := emitTransactions(c, txs)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, PromoteTransactionReturn{TailTransaction: txs[0].Hash, Trytes: powedTxTrytes})
+}
+
+func reattachTransaction(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &ReattachTransaction{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	tailHash, belowMaxDepth, ok := checkTailForReissue(c, query.TailTransaction)
+	if !ok {
+		return
+	}
+
+	cachedBndl := tangle.GetCachedBundleOrNil(tailHash) // bundle +1
+	if cachedBndl == nil {
+		e.Error = "bundle of the tail transaction not found"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	var txs []transaction.Transaction
+	cachedTxs := cachedBndl.GetBundle().GetTransactions() // tx +1
+	for _, cachedTx := range cachedTxs {
+		txs = append(txs, *cachedTx.GetTransaction().Tx)
+	}
+	cachedTxs.Release(true)  // tx -1
+	cachedBndl.Release(true) // bundle -1
+
+	if token, authenticated := apiTokenFromContext(c); authenticated {
+		if !token.takePoWQuota(c, len(txs)) {
+			return
+		}
+		defer token.refundPoWQuotaOnError(c, len(txs))
+	}
+
+	tips, ok := selectTipsForReissue(c)
+	if !ok {
+		return
+	}
+
+	// Sort transactions (highest to lowest index)
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].CurrentIndex > txs[j].CurrentIndex
+	})
+
+	if err := attachBundle(txs, tips[0].Trytes(), tips[1].Trytes(), config.NodeConfig.GetInt(config.CfgCoordinatorMWM)); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	powedTxTrytes, ok := emitTransactions(c, txs)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReattachTransactionReturn{TailTransaction: txs[0].Hash, BelowMaxDepth: belowMaxDepth, Trytes: powedTxTrytes})
+}
+
+// checkTailForReissue checks whether the given tail transaction can be promoted or reattached
+// and returns whether it is below max depth.
+func checkTailForReissue(c *gin.Context, tailTransaction trinary.Hash) (aingle.Hash, bool, bool) {
+	e := ErrorReturn{}
+
+	// do not reply if URTS is disabled
+	if node.IsSkipped(urts.PLUGIN) {
+		e.Error = "tipselection plugin disabled in this node"
+		c.JSON(http.StatusServiceUnavailable, e)
+		return nil, false, false
+	}
+
+	if !tangle.IsNodeSyncedWithThreshold() {
+		e.Error = ErrNodeNotSync.Error()
+		c.JSON(http.StatusServiceUnavailable, e)
+		return nil, false, false
+	}
+
+	if !guards.IsTransactionHash(tailTransaction) {
+		e.Error = "invalid tail hash supplied"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+
+	tailHash := aingle.HashFromHashTrytes(tailTransaction)
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tailHash) // meta +1
+	if cachedTxMeta == nil {
+		e.Error = "unknown tail transaction"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+	defer cachedTxMeta.Release(true) // meta -1
+
+	metadata := cachedTxMeta.GetMetadata()
+
+	if !metadata.IsTail() {
+		e.Error = "transaction is not a tail"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+
+	if !metadata.IsSolid() {
+		e.Error = "transaction is not solid"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+
+	if metadata.IsConflicting() {
+		e.Error = "transaction is conflicting"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+
+	if metadata.IsConfirmed() {
+		e.Error = "transaction is already confirmed"
+		c.JSON(http.StatusBadRequest, e)
+		return nil, false, false
+	}
+
+	belowMaxDepth := dag.IsBelowMaxDepth(cachedTxMeta.Retain(), tangle.GetSolidMilestoneIndex(), milestone.Index(config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth))) // meta +1
+
+	return tailHash, belowMaxDepth, true
+}
+
+// selectTipsForReissue selects two non-lazy tips to attach a promotion or reattachment to.
+func selectTipsForReissue(c *gin.Context) (aingle.Hashes, bool) {
+	e := ErrorReturn{}
+
+	tips, err := urts.TipSelector.SelectNonLazyTips()
+	if err != nil {
+		if err == tangle.ErrNodeNotSynced || err == tipselect.ErrNoTipsAvailable {
+			e.Error = err.Error()
+			c.JSON(http.StatusServiceUnavailable, e)
+			return nil, false
+		}
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return nil, false
+	}
+
+	return tips, true
+}
+
+// emitTransactions passes the attached transactions to the processor, which stores and broadcasts them.
+func emitTransactions(c *gin.Context, txs []transaction.Transaction) ([]trinary.Trytes, bool) {
+	e := ErrorReturn{}
+
+	powedTxTrytes := transaction.MustTransactionsToTrytes(txs)
+	for _, trytes := range powedTxTrytes {
+		if err := gossip.Processor().ValidateTransactionTrytesAndEmit(trytes); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return nil, false
+		}
+	}
+
+	return powedTxTrytes, true
+}
@@ -0,0 +1,133 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/profile"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
+	"github.com/Ariwonto/aingle-alpha/pkg/tipselect"
+	"github.com/Ariwonto/aingle-alpha/plugins/urts"
+)
+
+// configureReissueTest configures empty in-memory storages and a tip selector without tips.
+// The latest milestone is 10, the node is not synced yet.
+func configureReissueTest(t *testing.T) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Profile1GB.Caches)
+	t.Cleanup(tangle.ShutdownStorages)
+
+	prevTipSelector := urts.TipSelector
+	t.Cleanup(func() { urts.TipSelector = prevTipSelector })
+	urts.TipSelector = tipselect.New(0, 0, 0, 0, 0, 0, 0, 0, 0)
+
+	tangle.ResetMilestoneIndexes()
+	t.Cleanup(tangle.ResetMilestoneIndexes)
+	tangle.SetLatestMilestoneIndex(10)
+}
+
+// storeReissueTestTransaction stores a transaction with the given current index and returns its hash.
+// The metadata of the transaction is modified by the given function.
+func storeReissueTestTransaction(t *testing.T, idx int, currentIndex uint64, modifyMetadata func(metadata *aingle.TransactionMetadata)) trinary.Hash {
+	tx := &transaction.Transaction{
+		Hash:              trinary.IntToTrytes(int64(idx), consts.HashTrytesSize),
+		CurrentIndex:      currentIndex,
+		LastIndex:         currentIndex,
+		Bundle:            consts.NullHashTrytes,
+		TrunkTransaction:  consts.NullHashTrytes,
+		BranchTransaction: consts.NullHashTrytes,
+	}
+
+	cachedTx, newlyAdded := tangle.StoreTransactionIfAbsent(aingle.NewTransactionFromTx(tx, nil)) // tx +1
+	require.True(t, newlyAdded)
+	cachedTx.Release(true) // tx -1
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(aingle.HashFromHashTrytes(tx.Hash)) // meta +1
+	require.NotNil(t, cachedTxMeta)
+	modifyMetadata(cachedTxMeta.GetMetadata())
+	cachedTxMeta.Release(true) // meta -1
+
+	return tx.Hash
+}
+
+func TestPromoteTransaction(t *testing.T) {
+	configureReissueTest(t)
+
+	token := &apiToken{name: "test", permissions: map[string]struct{}{"promotetransaction": {}}, powLimiter: ratelimit.NewTokenBucket(1)}
+	router := newWebAPITestRouter(t, token)
+
+	promote := func(tailTransaction trinary.Hash) int {
+		return serveWebAPITestRequest(router, fmt.Sprintf(`{"command": "promoteTransaction", "tailTransaction": %q}`, tailTransaction)).Code
+	}
+
+	solid := func(metadata *aingle.TransactionMetadata) {
+		metadata.SetSolid(true)
+		// the cone of the transaction is within max depth of the solid milestone
+		metadata.SetRootSnapshotIndexes(10, 10, 10)
+	}
+
+	tail := storeReissueTestTransaction(t, 1, 0, solid)
+
+	// the node is not synced
+	assert.Equal(t, http.StatusServiceUnavailable, promote(tail))
+
+	tangle.SetSolidMilestoneIndex(10)
+	require.True(t, tangle.IsNodeSyncedWithThreshold())
+
+	assert.Equal(t, http.StatusBadRequest, promote("INVALID"))
+	assert.Equal(t, http.StatusBadRequest, promote(trinary.IntToTrytes(99, consts.HashTrytesSize)))
+	assert.Equal(t, http.StatusBadRequest, promote(storeReissueTestTransaction(t, 2, 1, solid)))
+	assert.Equal(t, http.StatusBadRequest, promote(storeReissueTestTransaction(t, 3, 0, func(_ *aingle.TransactionMetadata) {})))
+	assert.Equal(t, http.StatusBadRequest, promote(storeReissueTestTransaction(t, 4, 0, func(metadata *aingle.TransactionMetadata) {
+		solid(metadata)
+		metadata.SetConflicting(aingle.ConflictInsufficientBalance)
+	})))
+	assert.Equal(t, http.StatusBadRequest, promote(storeReissueTestTransaction(t, 5, 0, func(metadata *aingle.TransactionMetadata) {
+		solid(metadata)
+		metadata.SetConfirmed(true, 10)
+	})))
+	assert.Equal(t, http.StatusBadRequest, promote(storeReissueTestTransaction(t, 6, 0, func(metadata *aingle.TransactionMetadata) {
+		solid(metadata)
+		// the oldest root of the cone is below max depth
+		metadata.SetRootSnapshotIndexes(10, 1, 10)
+	})))
+
+	// the quota is only taken by tails which can be promoted, it is refunded if no tips are available
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusServiceUnavailable, promote(tail))
+	}
+
+	ok, _ := token.powLimiter.Take(1)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, promote(tail))
+}
+
+func TestReattachTransaction(t *testing.T) {
+	configureReissueTest(t)
+	tangle.SetSolidMilestoneIndex(10)
+
+	token := &apiToken{name: "test", permissions: map[string]struct{}{"reattachtransaction": {}}, powLimiter: ratelimit.NewTokenBucket(1)}
+	router := newWebAPITestRouter(t, token)
+
+	// the bundle of the tail is not stored
+	tail := storeReissueTestTransaction(t, 1, 0, func(metadata *aingle.TransactionMetadata) {
+		metadata.SetSolid(true)
+		metadata.SetRootSnapshotIndexes(10, 10, 10)
+	})
+	resp := serveWebAPITestRequest(router, fmt.Sprintf(`{"command": "reattachTransaction", "tailTransaction": %q}`, tail))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "bundle of the tail transaction not found")
+
+	ok, _ := token.powLimiter.Take(1)
+	assert.True(t, ok)
+}
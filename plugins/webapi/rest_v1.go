@@ -0,0 +1,334 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/plugins/peering"
+	"github.com/Ariwonto/aingle-alpha/plugins/urts"
+)
+
+const (
+	restAPIv1Base = "api/v1"
+)
+
+// restAPIv1Route registers the resource oriented routes of the REST API.
+// Every route is backed by a legacy command and is only accessible remotely if that command is permitted.
+func restAPIv1Route() {
+	v1 := api.Group(restAPIv1Base)
+
+	// GET /api/v1/info
+	v1.GET("info", permittedV1("getNodeInfo"), func(c *gin.Context) {
+		getNodeInfo(nil, c, serverShutdownSignal)
+	})
+
+	// GET /api/v1/transactions/:hash
+	v1.GET("transactions/:hash", permittedV1("getTrytes"), getTransactionV1)
+
+	// GET /api/v1/transactions/:hash/trytes
+	v1.GET("transactions/:hash/trytes", permittedV1("getTrytes"), getTransactionTrytesV1)
+
+	// GET /api/v1/bundles/:hash
+	v1.GET("bundles/:hash", permittedV1("findTransactions"), getBundleV1)
+
+	// GET /api/v1/addresses/:address/balance
+	v1.GET("addresses/:address/balance", permittedV1("getBalances"), getAddressBalanceV1)
+
+	// GET /api/v1/addresses/:address/transactions
+	v1.GET("addresses/:address/transactions", permittedV1("findTransactions"), getAddressTransactionsV1)
+
+	// GET /api/v1/milestones/:index
+	v1.GET("milestones/:index", permittedV1("getNodeInfo"), getMilestoneV1)
+
+	// GET /api/v1/tips
+	v1.GET("tips", permittedV1("getTipInfo"), getTipsV1)
+
+	// GET /api/v1/peers
+	v1.GET("peers", permittedV1("getNeighbors"), getPeersV1)
+}
+
+// permittedV1 aborts requests from networks which are not whitelisted
+// if the given command is not permitted for remote access or for the API token of the request.
+// Permitted requests are subject to the request limits of the given command.
+func permittedV1(command string) gin.HandlerFunc {
+	cmd := strings.ToLower(command)
+
+	return func(c *gin.Context) {
+		if !isRoutePermitted(c, cmd) {
+			return
+		}
+
+		release, ok := acquireRequestLimits(c, cmd)
+		if !ok {
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
+
+// isRoutePermitted returns whether the given lower cased command is permitted for the API token of the request,
+// or for remote access if the request was not authenticated with an API token and does not come from a whitelisted network.
+// Requests which are not permitted are aborted.
+func isRoutePermitted(c *gin.Context, cmd string) bool {
+	if token, authenticated := apiTokenFromContext(c); authenticated {
+		if !token.isPermitted(cmd) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorReturn{Error: fmt.Sprintf("Route [%v] is not permitted for API token '%s'", c.FullPath(), token.name)})
+			return false
+		}
+		return true
+	}
+
+	if _, permitted := permitedEndpoints[cmd]; !permitted && !isWhitelisted(c) {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorReturn{Error: fmt.Sprintf("Route [%v] is protected", c.FullPath())})
+		return false
+	}
+	return true
+}
+
+// transactionHashFromParam returns the transaction hash in the "hash" parameter of the route.
+func transactionHashFromParam(c *gin.Context) (aingle.Hash, bool) {
+	hash := strings.ToUpper(c.Param("hash"))
+	if !guards.IsTransactionHash(hash) {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: fmt.Sprintf("Invalid hash supplied: %s", hash)})
+		return nil, false
+	}
+	return aingle.HashFromHashTrytes(hash), true
+}
+
+// addressFromParam returns the address without checksum in the "address" parameter of the route.
+func addressFromParam(c *gin.Context) (trinary.Hash, bool) {
+	addr := strings.ToUpper(c.Param("address"))
+	if err := address.ValidAddress(addr); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: fmt.Sprintf("%v: %v", err, addr)})
+		return "", false
+	}
+
+	if len(addr) == 90 {
+		addr = addr[:81]
+	}
+	return addr, true
+}
+
+func getTransactionV1(c *gin.Context) {
+	txHash, ok := transactionHashFromParam(c)
+	if !ok {
+		return
+	}
+
+	cachedTx := tangle.GetCachedTransactionOrNil(txHash) // tx +1
+	if cachedTx == nil {
+		c.JSON(http.StatusNotFound, ErrorReturn{Error: fmt.Sprintf("Transaction not found: %s", txHash.Trytes())})
+		return
+	}
+	defer cachedTx.Release(true) // tx -1
+
+	metadata := cachedTx.GetMetadata()
+	confirmed, msIndex := metadata.GetConfirmed()
+
+	result := TransactionV1Return{
+		Transaction: cachedTx.GetTransaction().Tx,
+		Metadata: &TransactionMetadataV1{
+			Solid:          metadata.IsSolid(),
+			Confirmed:      confirmed,
+			Conflicting:    metadata.IsConflicting(),
+			MilestoneIndex: msIndex,
+			IsTail:         metadata.IsTail(),
+			IsHead:         metadata.IsHead(),
+		},
+	}
+
+	if metadata.IsConflicting() {
+		result.Metadata.ConflictReason = metadata.GetConflict().String()
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getTransactionTrytesV1(c *gin.Context) {
+	txHash, ok := transactionHashFromParam(c)
+	if !ok {
+		return
+	}
+
+	cachedTx := tangle.GetCachedTransactionOrNil(txHash) // tx +1
+	if cachedTx == nil {
+		c.JSON(http.StatusNotFound, ErrorReturn{Error: fmt.Sprintf("Transaction not found: %s", txHash.Trytes())})
+		return
+	}
+	defer cachedTx.Release(true) // tx -1
+
+	trytes, err := transaction.TransactionToTrytes(cachedTx.GetTransaction().Tx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorReturn{Error: fmt.Sprintf("%v: %v", ErrInternalError, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionTrytesV1Return{Hash: txHash.Trytes(), Trytes: trytes})
+}
+
+func getBundleV1(c *gin.Context) {
+	bundleHash := strings.ToUpper(c.Param("hash"))
+	if !guards.IsHash(bundleHash) {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: fmt.Sprintf("Invalid bundle hash supplied: %s", bundleHash)})
+		return
+	}
+
+	maxResults := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxFindTransactions)
+
+	cachedBndls := tangle.GetBundles(aingle.HashFromHashTrytes(bundleHash), true, maxResults) // bundle +1
+	if cachedBndls == nil {
+		c.JSON(http.StatusNotFound, ErrorReturn{Error: fmt.Sprintf("Bundle not found: %s", bundleHash)})
+		return
+	}
+	defer cachedBndls.Release(true) // bundle -1
+
+	result := BundleV1Return{
+		Bundle:    bundleHash,
+		Instances: make([]*BundleInstanceV1, 0, len(cachedBndls)),
+	}
+
+	for _, cachedBndl := range cachedBndls {
+		bndl := cachedBndl.GetBundle()
+
+		instance := &BundleInstanceV1{
+			TailTransaction: bndl.GetTailHash().Trytes(),
+			Transactions:    make([]trinary.Hash, 0),
+			Solid:           bndl.IsSolid(),
+			Valid:           bndl.IsValid(),
+			Confirmed:       bndl.IsConfirmed(),
+			Conflicting:     bndl.IsConflicting(),
+			IsMilestone:     bndl.IsMilestone(),
+		}
+		instance.Transactions = append(instance.Transactions, bndl.GetTxHashes().Trytes()...)
+
+		if instance.IsMilestone {
+			instance.MilestoneIndex = bndl.GetMilestoneIndex()
+		}
+
+		result.Instances = append(result.Instances, instance)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getAddressBalanceV1(c *gin.Context) {
+	addr, ok := addressFromParam(c)
+	if !ok {
+		return
+	}
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	if !tangle.IsNodeSynced() {
+		c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: ErrNodeNotSync.Error()})
+		return
+	}
+
+	balance, _, err := tangle.GetBalanceForAddressWithoutLocking(aingle.HashFromAddressTrytes(addr))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorReturn{Error: "Ledger state invalid"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AddressBalanceV1Return{
+		Address:        addr,
+		Balance:        balance,
+		MilestoneIndex: tangle.GetSolidMilestoneIndex(),
+	})
+}
+
+func getAddressTransactionsV1(c *gin.Context) {
+	addr, ok := addressFromParam(c)
+	if !ok {
+		return
+	}
+
+	valueOnly := strings.ToLower(c.Query("valueOnly")) == "true"
+	maxResults := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxFindTransactions)
+
+	// the cursor has the same format as the one of a findTransactions query for a single address
+	_, afterTxHash, err := decodeFindTransactionsCursor(c.Query("cursor"), 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: err.Error()})
+		return
+	}
+
+	txHashes, more := tangle.GetTransactionHashesForAddressPage(aingle.HashFromAddressTrytes(addr), valueOnly, afterTxHash, maxResults)
+
+	result := AddressTransactionsV1Return{
+		Address:      addr,
+		Transactions: make([]trinary.Hash, 0, len(txHashes)),
+	}
+	result.Transactions = append(result.Transactions, txHashes.Trytes()...)
+
+	if more {
+		result.Cursor = encodeFindTransactionsCursor(0, txHashes[len(txHashes)-1])
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getMilestoneV1(c *gin.Context) {
+	msIndex, err := strconv.ParseUint(c.Param("index"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: fmt.Sprintf("Invalid milestone index: %s", c.Param("index"))})
+		return
+	}
+
+	cachedMs := tangle.GetMilestoneOrNil(milestone.Index(msIndex)) // bundle +1
+	if cachedMs == nil {
+		c.JSON(http.StatusNotFound, ErrorReturn{Error: fmt.Sprintf("Milestone not found: %d", msIndex)})
+		return
+	}
+	defer cachedMs.Release(true) // bundle -1
+
+	cachedTailTx := cachedMs.GetBundle().GetTail() // tx +1
+	defer cachedTailTx.Release(true)               // tx -1
+
+	c.JSON(http.StatusOK, MilestoneV1Return{
+		Index:           cachedMs.GetBundle().GetMilestoneIndex(),
+		Hash:            cachedMs.GetBundle().GetMilestoneHash().Trytes(),
+		TailTransaction: cachedMs.GetBundle().GetTailHash().Trytes(),
+		Timestamp:       cachedTailTx.GetTransaction().GetTimestamp(),
+	})
+}
+
+func getTipsV1(c *gin.Context) {
+	// do not reply if URTS is disabled
+	if node.IsSkipped(urts.PLUGIN) {
+		c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: "tipselection plugin disabled in this node"})
+		return
+	}
+
+	nonLazy, semiLazy := urts.TipSelector.GetTipHashes()
+
+	result := TipsV1Return{
+		NonLazy:  make([]trinary.Hash, 0, len(nonLazy)),
+		SemiLazy: make([]trinary.Hash, 0, len(semiLazy)),
+	}
+	result.NonLazy = append(result.NonLazy, nonLazy.Trytes()...)
+	result.SemiLazy = append(result.SemiLazy, semiLazy.Trytes()...)
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getPeersV1(c *gin.Context) {
+	c.JSON(http.StatusOK, PeersV1Return{Peers: peering.Manager().PeerInfos()})
+}
@@ -0,0 +1,209 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+)
+
+var (
+	restV1TestAddress = strings.Repeat("A", consts.HashTrytesSize)
+	restV1TestBundle  = strings.Repeat("B", consts.HashTrytesSize)
+)
+
+// newRESTv1TestRouter serves the REST API routes, all requests are authenticated with the given token.
+// The routes return at most maxResults transactions.
+func newRESTv1TestRouter(t *testing.T, token *apiToken, maxResults int) *gin.Engine {
+	prevMaxResults := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxFindTransactions)
+	t.Cleanup(func() { config.NodeConfig.Set(config.CfgWebAPILimitsMaxFindTransactions, prevMaxResults) })
+	config.NodeConfig.Set(config.CfgWebAPILimitsMaxFindTransactions, maxResults)
+
+	router := newWebAPITestRouter(t, token)
+	restAPIv1Route()
+	return router
+}
+
+func serveRESTv1TestRequest(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/"+restAPIv1Base+path, nil)
+	req.RemoteAddr = "192.0.2.1:1000"
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// storeRESTv1TestTransaction stores a transaction of a bundle with a single transaction, which is solid and conflicting.
+// The bundle of the transaction is constructed.
+func storeRESTv1TestTransaction(t *testing.T, idx int) trinary.Hash {
+	// the construction of bundles checks the snapshot info whether spent addresses are enabled
+	tangle.SetSnapshotInfo(&tangle.SnapshotInfo{CoordinatorAddress: aingle.NullHashBytes, Hash: aingle.NullHashBytes})
+
+	tx, err := transaction.AsTransactionObject(strings.Repeat("9", consts.TransactionTrytesSize), trinary.IntToTrytes(int64(idx), consts.HashTrytesSize))
+	require.NoError(t, err)
+	tx.Address = restV1TestAddress
+	tx.Bundle = restV1TestBundle
+
+	cachedTx, alreadyAdded := tangle.AddTransactionToStorage(aingle.NewTransactionFromTx(tx, nil), tangle.GetLatestMilestoneIndex(), false, true, true) // tx +1
+	require.False(t, alreadyAdded)
+
+	cachedTx.GetMetadata().SetSolid(true)
+	cachedTx.GetMetadata().SetConflicting(aingle.ConflictInsufficientBalance)
+	tangle.OnTailTransactionSolid(cachedTx.Retain()) // tx pass +1
+	cachedTx.Release(true)                           // tx -1
+
+	return tx.Hash
+}
+
+func decodeRESTv1TestResponse(t *testing.T, resp *httptest.ResponseRecorder, result interface{}) {
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+}
+
+func TestRESTv1Transactions(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+	txHash := storeRESTv1TestTransaction(t, 1)
+	unknownHash := trinary.IntToTrytes(2, consts.HashTrytesSize)
+
+	for _, path := range []string{"/transactions/%s", "/transactions/%s/trytes"} {
+		resp := serveRESTv1TestRequest(router, strings.Replace(path, "%s", "INVALID", 1))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Invalid hash supplied")
+
+		resp = serveRESTv1TestRequest(router, strings.Replace(path, "%s", unknownHash, 1))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Transaction not found")
+	}
+
+	// the hash is case insensitive
+	txResult := &TransactionV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/transactions/"+strings.ToLower(txHash)), txResult)
+	assert.Equal(t, txHash, txResult.Transaction.Hash)
+	assert.True(t, txResult.Metadata.Solid)
+	assert.True(t, txResult.Metadata.Conflicting)
+	assert.Equal(t, aingle.ConflictInsufficientBalance.String(), txResult.Metadata.ConflictReason)
+	assert.True(t, txResult.Metadata.IsTail)
+	assert.True(t, txResult.Metadata.IsHead)
+
+	trytesResult := &TransactionTrytesV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/transactions/"+txHash+"/trytes"), trytesResult)
+	assert.Equal(t, txHash, trytesResult.Hash)
+	tx, err := transaction.AsTransactionObject(trytesResult.Trytes, txHash)
+	require.NoError(t, err)
+	assert.Equal(t, restV1TestAddress, tx.Address)
+}
+
+func TestRESTv1Bundles(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+	resp := serveRESTv1TestRequest(router, "/bundles/INVALID")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Invalid bundle hash supplied")
+
+	resp = serveRESTv1TestRequest(router, "/bundles/"+strings.Repeat("C", consts.HashTrytesSize))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Bundle not found")
+
+	txHash := storeRESTv1TestTransaction(t, 1)
+
+	result := &BundleV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/bundles/"+restV1TestBundle), result)
+	assert.Equal(t, restV1TestBundle, result.Bundle)
+	require.Len(t, result.Instances, 1)
+	assert.Equal(t, txHash, result.Instances[0].TailTransaction)
+	assert.Equal(t, []trinary.Hash{txHash}, result.Instances[0].Transactions)
+	assert.True(t, result.Instances[0].Solid)
+	assert.False(t, result.Instances[0].IsMilestone)
+}
+
+func TestRESTv1Addresses(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+	for _, path := range []string{"/addresses/INVALID/balance", "/addresses/INVALID/transactions"} {
+		resp := serveRESTv1TestRequest(router, path)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	}
+
+	// the node is not synced
+	resp := serveRESTv1TestRequest(router, "/addresses/"+restV1TestAddress+"/balance")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	tangle.SetSolidMilestoneIndex(10)
+
+	balanceResult := &AddressBalanceV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/addresses/"+restV1TestAddress+"/balance"), balanceResult)
+	assert.Equal(t, restV1TestAddress, balanceResult.Address)
+	assert.Equal(t, uint64(0), balanceResult.Balance)
+	assert.EqualValues(t, 10, balanceResult.MilestoneIndex)
+
+	resp = serveRESTv1TestRequest(router, "/addresses/"+restV1TestAddress+"/transactions?cursor=INVALID")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	txResult := &AddressTransactionsV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/addresses/"+restV1TestAddress+"/transactions"), txResult)
+	assert.Equal(t, restV1TestAddress, txResult.Address)
+	assert.Empty(t, txResult.Transactions)
+	assert.Empty(t, txResult.Cursor)
+
+	// the transactions are returned before they are written to the store
+	txHashes := []trinary.Hash{storeRESTv1TestTransaction(t, 1), storeRESTv1TestTransaction(t, 2)}
+
+	txResult = &AddressTransactionsV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/addresses/"+restV1TestAddress+"/transactions"), txResult)
+	assert.Equal(t, txHashes, txResult.Transactions)
+	assert.Empty(t, txResult.Cursor)
+}
+
+func TestRESTv1Milestones(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+	resp := serveRESTv1TestRequest(router, "/milestones/abc")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Invalid milestone index")
+
+	resp = serveRESTv1TestRequest(router, "/milestones/10")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Milestone not found")
+}
+
+func TestRESTv1Tips(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", admin: true}, 100)
+
+	result := &TipsV1Return{}
+	decodeRESTv1TestResponse(t, serveRESTv1TestRequest(router, "/tips"), result)
+	assert.Empty(t, result.NonLazy)
+	assert.Empty(t, result.SemiLazy)
+}
+
+func TestRESTv1Permissions(t *testing.T) {
+	configureReissueTest(t)
+	router := newRESTv1TestRouter(t, &apiToken{name: "test", permissions: map[string]struct{}{"gettrytes": {}}}, 100)
+
+	txHash := storeRESTv1TestTransaction(t, 1)
+	assert.Equal(t, http.StatusOK, serveRESTv1TestRequest(router, "/transactions/"+txHash).Code)
+
+	for _, path := range []string{"/info", "/bundles/" + restV1TestBundle, "/addresses/" + restV1TestAddress + "/balance", "/milestones/10", "/tips", "/peers"} {
+		resp := serveRESTv1TestRequest(router, path)
+		assert.Equal(t, http.StatusForbidden, resp.Code, path)
+		assert.Contains(t, resp.Body.String(), "is not permitted for API token 'test'", path)
+	}
+}
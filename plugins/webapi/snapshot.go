@@ -16,6 +16,7 @@ import (
 
 func init() {
 	addEndpoint("createSnapshotFile", createSnapshotFile, implementedAPIcalls)
+	addEndpoint("createDeltaSnapshotFile", createDeltaSnapshotFile, implementedAPIcalls)
 }
 
 func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -38,3 +39,30 @@ func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct
 
 	c.JSON(http.StatusOK, CreateSnapshotFileReturn{})
 }
+
+func createDeltaSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &CreateDeltaSnapshotFile{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if query.BaseIndex >= query.TargetIndex {
+		e.Error = fmt.Sprintf("baseIndex (%d) must be lower than targetIndex (%d)", query.BaseIndex, query.TargetIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	snapshotFilePath := filepath.Join(filepath.Dir(config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)), fmt.Sprintf("delta_%d_%d.bin", query.BaseIndex, query.TargetIndex))
+
+	if err := snapshot.CreateDeltaSnapshot(query.BaseIndex, query.TargetIndex, snapshotFilePath, abortSignal); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateDeltaSnapshotFileReturn{})
+}
@@ -0,0 +1,368 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/basicauth"
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
+	"github.com/Ariwonto/aingle-alpha/pkg/shutdown"
+)
+
+const (
+	bearerAuthPrefix   = "Bearer "
+	apiTokenContextKey = "apiToken"
+
+	// apiTokensConfigReloadDelay is the time to wait for further changes of the API tokens config file before it is reloaded.
+	apiTokensConfigReloadDelay = 100 * time.Millisecond
+
+	// permissionGroupAdmin grants access to all commands.
+	permissionGroupAdmin = "admin"
+)
+
+var (
+	// permissionGroups are the named sets of commands which can be granted to API tokens.
+	permissionGroups = map[string][]string{
+		"read": {
+			"getNodeInfo",
+			"getNodeAPIConfiguration",
+			"getBalances",
+			"getBalanceHistory",
+			"getInclusionStates",
+			"getTipInfo",
+			"getBundleStatus",
+			"getTransactionsToApprove",
+			"findTransactions",
+			"getTrytes",
+			"wereAddressesSpentFrom",
+			"getNeighbors",
+			"getLedgerHash",
+			webSocketPermission,
+		},
+		"attach": {
+			"attachToTangle",
+			"broadcastTransactions",
+			"storeTransactions",
+			"promoteTransaction",
+			"reattachTransaction",
+		},
+	}
+
+	apiTokens     []*apiToken
+	apiTokensLock syncutils.RWMutex
+
+	// apiTokensConfigWatcher watches the directory of the API tokens config file, nil if it can't be watched.
+	apiTokensConfigWatcher *fsnotify.Watcher
+)
+
+// apiToken is a named bearer token with its permissions and quotas.
+type apiToken struct {
+	name        string
+	tokenHash   string
+	tokenSalt   string
+	admin       bool
+	permissions map[string]struct{}
+	// requestLimiter limits the requests per minute, nil if unlimited.
+	requestLimiter *ratelimit.TokenBucket
+	// powLimiter limits the transactions attached per minute, nil if unlimited.
+	powLimiter *ratelimit.TokenBucket
+}
+
+// isPermitted returns whether the token grants access to the given lower cased command.
+func (t *apiToken) isPermitted(cmd string) bool {
+	if t.admin {
+		return true
+	}
+	_, permitted := t.permissions[cmd]
+	return permitted
+}
+
+// configureAPITokens loads the API tokens and watches the directory of the API tokens config file,
+// so tokens can be added or revoked without a restart, even if the file did not exist at startup.
+func configureAPITokens() {
+	loadAPITokens()
+
+	dir, _ := config.APITokensConfigLocation()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warnf("API tokens config can't be watched: %v", err)
+		return
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		log.Warnf("API tokens config can't be watched: %v", err)
+		_ = watcher.Close()
+		return
+	}
+
+	apiTokensConfigWatcher = watcher
+}
+
+// runAPITokensConfigWatcher reloads the API tokens if the API tokens config file is created, changed or removed.
+func runAPITokensConfigWatcher() {
+	if apiTokensConfigWatcher == nil {
+		return
+	}
+
+	daemon.BackgroundWorker("WebAPI[APITokens]", func(shutdownSignal <-chan struct{}) {
+		dir, name := config.APITokensConfigLocation()
+		watchAPITokensConfig(apiTokensConfigWatcher, dir, name, shutdownSignal)
+	}, shutdown.PriorityAPI)
+}
+
+// watchAPITokensConfig reloads the API tokens on changes of the config file with the given name in the watched directory
+// until the shutdown signal is received. Bursts of events, e.g. of an editor saving the file, cause a single reload.
+func watchAPITokensConfig(watcher *fsnotify.Watcher, dir string, name string, shutdownSignal <-chan struct{}) {
+	defer watcher.Close()
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-shutdownSignal:
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod || !isAPITokensConfigFile(event.Name, name) {
+				continue
+			}
+			reload = time.After(apiTokensConfigReloadDelay)
+
+		case <-reload:
+			reload = nil
+			reloadAPITokens(dir, name)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("watching the API tokens config failed: %v", err)
+		}
+	}
+}
+
+// isAPITokensConfigFile returns whether the given file is a config file with the given name and a supported extension.
+func isAPITokensConfigFile(filePath string, name string) bool {
+	fileName := filepath.Base(filePath)
+	for _, ext := range viper.SupportedExts {
+		if fileName == name+"."+ext {
+			return true
+		}
+	}
+	return false
+}
+
+// findAPITokensConfigFile returns the path of the config file with the given name in the given directory,
+// the supported extensions are checked in the same order as viper does. It returns an empty string if no file exists.
+func findAPITokensConfigFile(dir string, name string) string {
+	for _, ext := range viper.SupportedExts {
+		filePath := filepath.Join(dir, name+"."+ext)
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// reloadAPITokens reads the API tokens config file again and replaces the API tokens.
+// All tokens are revoked if the file was removed.
+func reloadAPITokens(dir string, name string) {
+	filePath := findAPITokensConfigFile(dir, name)
+	if filePath == "" {
+		log.Info("revoking all API tokens, the API tokens config was removed")
+		setAPITokens(nil)
+		return
+	}
+
+	config.APITokensConfig.SetConfigFile(filePath)
+	if err := config.APITokensConfig.ReadInConfig(); err != nil {
+		log.Warnf("reading the API tokens config failed: %v", err)
+		return
+	}
+
+	log.Info("reloading API tokens due to config change")
+	loadAPITokens()
+}
+
+// loadAPITokens replaces the API tokens with the ones in the API tokens config.
+func loadAPITokens() {
+	var tokenConfigs []config.APITokenConfig
+	if err := config.APITokensConfig.UnmarshalKey(config.CfgWebAPITokens, &tokenConfigs); err != nil {
+		log.Warnf("invalid API tokens config: %v", err)
+		return
+	}
+
+	setAPITokens(tokenConfigs)
+}
+
+// setAPITokens replaces the API tokens with the given ones.
+// The quotas of tokens with unchanged limits are kept.
+func setAPITokens(tokenConfigs []config.APITokenConfig) {
+	apiTokensLock.Lock()
+	defer apiTokensLock.Unlock()
+
+	previousTokens := make(map[string]*apiToken)
+	for _, token := range apiTokens {
+		previousTokens[token.name] = token
+	}
+
+	tokens := make([]*apiToken, 0, len(tokenConfigs))
+	for _, tokenConfig := range tokenConfigs {
+		if tokenConfig.Revoked {
+			continue
+		}
+
+		if len(tokenConfig.TokenHash) != 64 {
+			log.Warnf("ignoring API token '%s', the token hash must be 64 (sha256 hash) in length", tokenConfig.Name)
+			continue
+		}
+
+		token := &apiToken{
+			name:        tokenConfig.Name,
+			tokenHash:   tokenConfig.TokenHash,
+			tokenSalt:   tokenConfig.TokenSalt,
+			permissions: make(map[string]struct{}),
+		}
+
+		for _, permission := range tokenConfig.Permissions {
+			permission = strings.ToLower(permission)
+			if permission == permissionGroupAdmin {
+				token.admin = true
+				continue
+			}
+
+			if commands, isGroup := permissionGroups[permission]; isGroup {
+				for _, cmd := range commands {
+					token.permissions[strings.ToLower(cmd)] = struct{}{}
+				}
+				continue
+			}
+
+			if _, exists := implementedAPIcalls[permission]; !exists && permission != webSocketPermission {
+				log.Warnf("API token '%s' contains unknown command '%s'", tokenConfig.Name, permission)
+			}
+			token.permissions[permission] = struct{}{}
+		}
+
+		previous := previousTokens[token.name]
+		token.requestLimiter = keepOrCreateLimiter(previous, tokenConfig.RequestsPerMinute, func(t *apiToken) *ratelimit.TokenBucket { return t.requestLimiter })
+		token.powLimiter = keepOrCreateLimiter(previous, tokenConfig.PoWPerMinute, func(t *apiToken) *ratelimit.TokenBucket { return t.powLimiter })
+
+		tokens = append(tokens, token)
+	}
+
+	apiTokens = tokens
+	log.Infof("loaded %d API tokens", len(apiTokens))
+}
+
+// keepOrCreateLimiter returns the limiter of the previous token if the limit did not change, otherwise a new limiter.
+func keepOrCreateLimiter(previous *apiToken, perMinute int, limiter func(t *apiToken) *ratelimit.TokenBucket) *ratelimit.TokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	if previous != nil {
+		if previousLimiter := limiter(previous); previousLimiter != nil && previousLimiter.PerMinute() == perMinute {
+			return previousLimiter
+		}
+	}
+
+	return ratelimit.NewTokenBucket(perMinute)
+}
+
+// findAPIToken returns the token which matches the given bearer token.
+func findAPIToken(bearerToken string) *apiToken {
+	apiTokensLock.RLock()
+	defer apiTokensLock.RUnlock()
+
+	for _, token := range apiTokens {
+		if basicauth.VerifyPassword(bearerToken, token.tokenSalt, token.tokenHash) {
+			return token
+		}
+	}
+	return nil
+}
+
+// apiTokenMiddleware authenticates requests with a bearer token and enforces the request quota of the token.
+// Requests without a bearer token are passed on unchanged.
+func apiTokenMiddleware(c *gin.Context) {
+	authVal := c.Request.Header.Get("Authorization")
+	if !strings.HasPrefix(authVal, bearerAuthPrefix) {
+		return
+	}
+
+	token := findAPIToken(strings.TrimPrefix(authVal, bearerAuthPrefix))
+	if token == nil {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorReturn{Error: "invalid API token"})
+		return
+	}
+
+	if token.requestLimiter != nil {
+		if ok, retryAfter := token.requestLimiter.Take(1); !ok {
+			abortTooManyRequests(c, retryAfter, fmt.Sprintf("request quota of API token '%s' exceeded", token.name))
+			return
+		}
+	}
+
+	c.Set(apiTokenContextKey, token)
+}
+
+// apiTokenFromContext returns the token the request was authenticated with.
+func apiTokenFromContext(c *gin.Context) (*apiToken, bool) {
+	value, exists := c.Get(apiTokenContextKey)
+	if !exists {
+		return nil, false
+	}
+	return value.(*apiToken), true
+}
+
+// takePoWQuota takes the given amount of transactions from the PoW quota of the token.
+// The quota is taken before the PoW is done, so concurrent requests can't exceed it.
+func (t *apiToken) takePoWQuota(c *gin.Context, txCount int) bool {
+	if t.powLimiter == nil {
+		return true
+	}
+
+	if ok, retryAfter := t.powLimiter.Take(txCount); !ok {
+		abortTooManyRequests(c, retryAfter, fmt.Sprintf("PoW quota of API token '%s' exceeded", t.name))
+		return false
+	}
+	return true
+}
+
+// refundPoWQuotaOnError gives the given amount of transactions back to the PoW quota of the token
+// if the request failed, so only successfully attached transactions count towards the quota.
+func (t *apiToken) refundPoWQuotaOnError(c *gin.Context, txCount int) {
+	if t.powLimiter == nil || c.Writer.Status() == http.StatusOK {
+		return
+	}
+	t.powLimiter.Refund(txCount)
+}
+
+// abortTooManyRequests aborts the request with a 429 status and the time after which the request can be retried.
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration, reason string) {
+	retryAfterSeconds := int(retryAfter.Seconds())
+	if retryAfter%time.Second != 0 {
+		retryAfterSeconds++
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrorReturn{Error: reason})
+}
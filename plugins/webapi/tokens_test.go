@@ -0,0 +1,157 @@
+package webapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/ratelimit"
+)
+
+// newWebAPITestRouter serves the web API commands, all requests are authenticated with the given token.
+func newWebAPITestRouter(t *testing.T, token *apiToken) *gin.Engine {
+	setRequestLimits(t, newRequestLimiter(0, 0), newRequestLimiter(0, 0))
+
+	prevAPI := api
+	t.Cleanup(func() { api = prevAPI })
+
+	api = gin.New()
+	api.Use(func(c *gin.Context) {
+		c.Set(apiTokenContextKey, token)
+	})
+	webAPIRoute()
+
+	return api
+}
+
+func serveWebAPITestRequest(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1000"
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestPoWQuotaRefundOnError(t *testing.T) {
+	token := &apiToken{name: "test", admin: true, powLimiter: ratelimit.NewTokenBucket(2)}
+	router := newWebAPITestRouter(t, token)
+
+	// failed attachments don't count towards the quota
+	for i := 0; i < 5; i++ {
+		resp := serveWebAPITestRequest(router, `{"command": "attachToTangle", "trytes": []}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	}
+
+	ok, _ := token.powLimiter.Take(2)
+	assert.True(t, ok)
+
+	// the quota is checked before the PoW is done
+	resp := serveWebAPITestRequest(router, `{"command": "attachToTangle", "trytes": []}`)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+}
+
+func TestRefundPoWQuotaOnError(t *testing.T) {
+	token := &apiToken{name: "test", powLimiter: ratelimit.NewTokenBucket(1)}
+
+	refundAfter := func(status int) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Status(status)
+		token.refundPoWQuotaOnError(c, 1)
+	}
+
+	// successful requests keep the quota
+	ok, _ := token.powLimiter.Take(1)
+	assert.True(t, ok)
+	refundAfter(http.StatusOK)
+	ok, _ = token.powLimiter.Take(1)
+	assert.False(t, ok)
+
+	refundAfter(http.StatusInternalServerError)
+	ok, _ = token.powLimiter.Take(1)
+	assert.True(t, ok)
+}
+
+// apiTokenNames returns the names of the loaded API tokens.
+func apiTokenNames() []string {
+	apiTokensLock.RLock()
+	defer apiTokensLock.RUnlock()
+
+	names := make([]string, 0, len(apiTokens))
+	for _, token := range apiTokens {
+		names = append(names, token.name)
+	}
+	return names
+}
+
+func writeAPITokensConfig(t *testing.T, filePath string, names ...string) {
+	tokens := make([]string, 0, len(names))
+	for _, name := range names {
+		tokens = append(tokens, fmt.Sprintf(`{"name": %q, "tokenHash": %q, "permissions": ["read"]}`, name, strings.Repeat("0", 64)))
+	}
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(fmt.Sprintf(`{"tokens": [%s]}`, strings.Join(tokens, ","))), 0600))
+}
+
+func TestAPITokensConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apitokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	prevConfig, prevTokens := config.APITokensConfig, apiTokens
+	t.Cleanup(func() { config.APITokensConfig, apiTokens = prevConfig, prevTokens })
+	config.APITokensConfig = viper.New()
+	apiTokens = nil
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	require.NoError(t, watcher.Add(dir))
+
+	shutdownSignal := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchAPITokensConfig(watcher, dir, "apitokens", shutdownSignal)
+	}()
+	defer func() {
+		close(shutdownSignal)
+		<-done
+	}()
+
+	hasTokens := func(names ...string) func() bool {
+		return func() bool { return assert.ObjectsAreEqual(append([]string{}, names...), apiTokenNames()) }
+	}
+
+	// the file is created after the watcher was started
+	filePath := filepath.Join(dir, "apitokens.json")
+	writeAPITokensConfig(t, filePath, "first")
+	assert.Eventually(t, hasTokens("first"), 5*time.Second, 10*time.Millisecond)
+
+	// other files in the directory are ignored
+	writeAPITokensConfig(t, filepath.Join(dir, "config.json"), "other")
+
+	writeAPITokensConfig(t, filePath, "first", "second")
+	assert.Eventually(t, hasTokens("first", "second"), 5*time.Second, 10*time.Millisecond)
+
+	// removing the file revokes all tokens
+	require.NoError(t, os.Remove(filePath))
+	assert.Eventually(t, hasTokens(), 5*time.Second, 10*time.Millisecond)
+
+	// the file can be created again
+	writeAPITokensConfig(t, filePath, "third")
+	assert.Eventually(t, hasTokens("third"), 5*time.Second, 10*time.Millisecond)
+}
@@ -1,6 +1,8 @@
 package webapi
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -79,13 +81,14 @@ func findTransactions(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		return
 	}
 
-	txHashes := []string{}
-
 	if len(query.Bundles) == 0 && len(query.Addresses) == 0 && len(query.Approvees) == 0 && len(query.Tags) == 0 {
 		c.JSON(http.StatusOK, FindTransactionsReturn{Hashes: []string{}})
 		return
 	}
 
+	// every searched hash is paged through separately, in the order of the query
+	var pagedQueries []pagedHashQuery
+
 	// Searching for transactions that contains the given bundle hash
 	for _, bdl := range query.Bundles {
 		if err := trinary.ValidTrytes(bdl); err != nil {
@@ -94,7 +97,10 @@ func findTransactions(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			return
 		}
 
-		txHashes = append(txHashes, tangle.GetBundleTransactionHashes(aingle.HashFromHashTrytes(bdl), true, maxResults-len(txHashes)).Trytes()...)
+		bundleHash := aingle.HashFromHashTrytes(bdl)
+		pagedQueries = append(pagedQueries, func(afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+			return tangle.GetBundleTransactionHashesPage(bundleHash, afterTxHash, limit)
+		})
 	}
 
 	// Searching for transactions that contains the given address
@@ -109,7 +115,10 @@ func findTransactions(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			addr = addr[:81]
 		}
 
-		txHashes = append(txHashes, tangle.GetTransactionHashesForAddress(aingle.HashFromAddressTrytes(addr), query.ValueOnly, true, maxResults-len(txHashes)).Trytes()...)
+		addressHash := aingle.HashFromAddressTrytes(addr)
+		pagedQueries = append(pagedQueries, func(afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+			return tangle.GetTransactionHashesForAddressPage(addressHash, query.ValueOnly, afterTxHash, limit)
+		})
 	}
 
 	// Searching for all approvers of the given transactions
@@ -120,7 +129,10 @@ func findTransactions(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			return
 		}
 
-		txHashes = append(txHashes, tangle.GetApproverHashes(aingle.HashFromHashTrytes(approveeHash), maxResults-len(txHashes)).Trytes()...)
+		approvee := aingle.HashFromHashTrytes(approveeHash)
+		pagedQueries = append(pagedQueries, func(afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+			return tangle.GetApproverHashesPage(approvee, afterTxHash, limit)
+		})
 	}
 
 	// Searching for transactions that contain the given tag
@@ -137,10 +149,76 @@ func findTransactions(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			return
 		}
 
-		txHashes = append(txHashes, tangle.GetTagHashes(aingle.HashFromTagTrytes(tag), true, maxResults-len(txHashes)).Trytes()...)
+		txTag := aingle.HashFromTagTrytes(tag)
+		pagedQueries = append(pagedQueries, func(afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool) {
+			return tangle.GetTagHashesPage(txTag, afterTxHash, limit)
+		})
+	}
+
+	position, afterTxHash, err := decodeFindTransactionsCursor(query.Cursor, len(pagedQueries))
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	txHashes := []string{}
+	var cursor string
+
+	for i := position; i < len(pagedQueries); i++ {
+		if len(txHashes) == maxResults {
+			// the page is full, continue with the next searched hash
+			cursor = encodeFindTransactionsCursor(i, nil)
+			break
+		}
+
+		var after aingle.Hash
+		if i == position {
+			after = afterTxHash
+		}
+
+		hashes, more := pagedQueries[i](after, maxResults-len(txHashes))
+		txHashes = append(txHashes, hashes.Trytes()...)
+
+		if more {
+			cursor = encodeFindTransactionsCursor(i, hashes[len(hashes)-1])
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, FindTransactionsReturn{Hashes: txHashes, Cursor: cursor})
+}
+
+// pagedHashQuery returns at most limit hashes after the given hash and whether there are further hashes.
+type pagedHashQuery func(afterTxHash aingle.Hash, limit int) (aingle.Hashes, bool)
+
+// encodeFindTransactionsCursor encodes the position of the searched hash in the query
+// and the last returned transaction hash into an opaque cursor.
+func encodeFindTransactionsCursor(position int, lastTxHash aingle.Hash) string {
+	cursor := make([]byte, 2, 2+len(lastTxHash))
+	binary.BigEndian.PutUint16(cursor, uint16(position))
+	cursor = append(cursor, lastTxHash...)
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+// decodeFindTransactionsCursor decodes a cursor of a query with the given amount of searched hashes.
+// An empty cursor starts at the beginning of the first searched hash.
+func decodeFindTransactionsCursor(cursor string, queryCount int) (int, aingle.Hash, error) {
+	if cursor == "" {
+		return 0, nil, nil
+	}
+
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || (len(cursorBytes) != 2 && len(cursorBytes) != 2+49) {
+		return 0, nil, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+
+	position := int(binary.BigEndian.Uint16(cursorBytes[:2]))
+	if position >= queryCount {
+		return 0, nil, fmt.Errorf("cursor does not match the query: %s", cursor)
 	}
 
-	c.JSON(http.StatusOK, FindTransactionsReturn{Hashes: txHashes})
+	return position, aingle.Hash(cursorBytes[2:]), nil
 }
 
 // redirect to broadcastTransactions
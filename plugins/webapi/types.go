@@ -1,6 +1,9 @@
 package webapi
 
 import (
+	"encoding/json"
+
+	"github.com/iotaledger/iota.go/transaction"
 	"github.com/iotaledger/iota.go/trinary"
 
 	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
@@ -90,12 +93,15 @@ type FindTransactions struct {
 	Approvees  []trinary.Hash `mapstructure:"approvees"`
 	MaxResults int            `mapstructure:"maxresults"`
 	ValueOnly  bool           `json:"valueOnly"`
+	Cursor     string         `mapstructure:"cursor"`
 }
 
 // FindTransactionsReturn struct
 type FindTransactionsReturn struct {
-	Hashes   []trinary.Hash `json:"hashes"`
-	Duration int            `json:"duration"`
+	Hashes []trinary.Hash `json:"hashes"`
+	// Cursor is set if there are further results, pass it in the next request to continue.
+	Cursor   string `json:"cursor,omitempty"`
+	Duration int    `json:"duration"`
 }
 
 ///////////////////// getBalances /////////////////////////////////
@@ -124,8 +130,9 @@ type GetInclusionStates struct {
 
 // GetInclusionStatesReturn struct
 type GetInclusionStatesReturn struct {
-	States   []bool `json:"states"`
-	Duration int    `json:"duration"`
+	States    []bool   `json:"states"`
+	Conflicts []string `json:"conflicts"`
+	Duration  int      `json:"duration"`
 }
 
 ////////////////////// getNeighbors ///////////////////////////////
@@ -167,6 +174,8 @@ type GetNodeInfoReturn struct {
 	TransactionsToRequest              int             `json:"transactionsToRequest"`
 	Features                           []string        `json:"features"`
 	CoordinatorAddress                 trinary.Hash    `json:"coordinatorAddress"`
+	LedgerHash                         string          `json:"ledgerHash"`
+	LedgerHashMilestoneIndex           milestone.Index `json:"ledgerHashMilestoneIndex"`
 	Duration                           int             `json:"duration"`
 }
 
@@ -204,6 +213,40 @@ type GetTipInfoReturn struct {
 	Duration       int  `json:"duration"`
 }
 
+///////////////// getBundleStatus ////////////////////////
+
+// GetBundleStatus struct
+type GetBundleStatus struct {
+	Command string       `mapstructure:"command"`
+	Bundle  trinary.Hash `mapstructure:"bundle"`
+}
+
+// BundleTailStatus struct
+type BundleTailStatus struct {
+	TailTransaction trinary.Hash    `json:"tailTransaction"`
+	Solid           bool            `json:"solid"`
+	Complete        bool            `json:"complete"`
+	Valid           bool            `json:"valid"`
+	Confirmed       bool            `json:"confirmed"`
+	Conflicting     bool            `json:"conflicting"`
+	ConflictReason  string          `json:"conflictReason,omitempty"`
+	MilestoneIndex  milestone.Index `json:"milestoneIndex,omitempty"`
+	BelowMaxDepth   bool            `json:"belowMaxDepth"`
+	TipScore        string          `json:"tipScore"`
+	InTipPool       bool            `json:"inTipPool"`
+	ShouldPromote   bool            `json:"shouldPromote"`
+	ShouldReattach  bool            `json:"shouldReattach"`
+}
+
+// GetBundleStatusReturn struct
+type GetBundleStatusReturn struct {
+	Bundle         trinary.Hash        `json:"bundle"`
+	Confirmed      bool                `json:"confirmed"`
+	ShouldReattach bool                `json:"shouldReattach"`
+	Tails          []*BundleTailStatus `json:"tails"`
+	Duration       int                 `json:"duration"`
+}
+
 ///////////////// getTransactionsToApprove ////////////////////////
 
 // GetTransactionsToApprove struct
@@ -340,6 +383,82 @@ type GetLedgerStateReturn struct {
 	Duration       int                     `json:"duration"`
 }
 
+/////////////////// getLedgerStats ////////////////////////
+
+// GetLedgerStats struct
+type GetLedgerStats struct {
+	Command   string `mapstructure:"command"`
+	Addresses int    `mapstructure:"addresses,omitempty"`
+}
+
+// LedgerStatsAddressBalance struct
+type LedgerStatsAddressBalance struct {
+	Address trinary.Hash `json:"address"`
+	Balance uint64       `json:"balance"`
+}
+
+// BalanceBucket struct
+type BalanceBucket struct {
+	MinBalance uint64 `json:"minBalance"`
+	MaxBalance uint64 `json:"maxBalance"`
+	Addresses  int    `json:"addresses"`
+	Balance    uint64 `json:"balance"`
+}
+
+// GetLedgerStatsReturn struct
+type GetLedgerStatsReturn struct {
+	RichList         []*LedgerStatsAddressBalance `json:"richList"`
+	NonZeroAddresses int                          `json:"nonZeroAddresses"`
+	TotalBalance     uint64                       `json:"totalBalance"`
+	TotalSupply      uint64                       `json:"totalSupply"`
+	SupplyMatches    bool                         `json:"supplyMatches"`
+	Histogram        []*BalanceBucket             `json:"histogram"`
+	MilestoneIndex   milestone.Index              `json:"milestoneIndex"`
+	Duration         int                          `json:"duration"`
+}
+
+/////////////////// getLedgerHash ////////////////////////
+
+// GetLedgerHash struct
+type GetLedgerHash struct {
+	Command        string          `mapstructure:"command"`
+	MilestoneIndex milestone.Index `mapstructure:"milestoneIndex,omitempty"`
+}
+
+// GetLedgerHashReturn struct
+type GetLedgerHashReturn struct {
+	LedgerHash     string          `json:"ledgerHash"`
+	MilestoneIndex milestone.Index `json:"milestoneIndex"`
+	Duration       int             `json:"duration"`
+}
+
+/////////////////// getBalanceHistory ////////////////////////
+
+// GetBalanceHistory struct
+type GetBalanceHistory struct {
+	Command   string          `mapstructure:"command"`
+	Address   trinary.Hash    `mapstructure:"address"`
+	FromIndex milestone.Index `mapstructure:"fromIndex,omitempty"`
+	ToIndex   milestone.Index `mapstructure:"toIndex,omitempty"`
+}
+
+// BalanceChange struct
+type BalanceChange struct {
+	MilestoneIndex milestone.Index `json:"milestoneIndex"`
+	Delta          int64           `json:"delta"`
+	Balance        uint64          `json:"balance"`
+}
+
+// GetBalanceHistoryReturn struct
+type GetBalanceHistoryReturn struct {
+	Address      trinary.Hash     `json:"address"`
+	FromIndex    milestone.Index  `json:"fromIndex"`
+	ToIndex      milestone.Index  `json:"toIndex"`
+	StartBalance uint64           `json:"startBalance"`
+	Changes      []*BalanceChange `json:"changes"`
+	Duration     int              `json:"duration"`
+}
+
 /////////////////// createSnapshotFile ////////////////////////
 
 // CreateSnapshotFile struct
@@ -353,6 +472,20 @@ type CreateSnapshotFileReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// createDeltaSnapshotFile ////////////////////////
+
+// CreateDeltaSnapshotFile struct
+type CreateDeltaSnapshotFile struct {
+	Command     string          `mapstructure:"command"`
+	BaseIndex   milestone.Index `mapstructure:"baseIndex"`
+	TargetIndex milestone.Index `mapstructure:"targetIndex"`
+}
+
+// CreateDeltaSnapshotFileReturn struct
+type CreateDeltaSnapshotFileReturn struct {
+	Duration int `json:"duration"`
+}
+
 /////////////////// pruneDatabase ////////////////////////
 
 // PruneDatabase struct
@@ -367,6 +500,20 @@ type PruneDatabaseReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// createDatabaseBackup ////////////////////////
+
+// CreateDatabaseBackup struct
+type CreateDatabaseBackup struct {
+	Command    string `mapstructure:"command"`
+	TargetPath string `mapstructure:"targetPath"`
+}
+
+// CreateDatabaseBackupReturn struct
+type CreateDatabaseBackupReturn struct {
+	Path     string `json:"path"`
+	Duration int    `json:"duration"`
+}
+
 ///////////////////// getRequests /////////////////////////////////
 
 // GetRequests struct
@@ -456,3 +603,191 @@ type AddressWithBalance struct {
 	Address trinary.Hash `mapstructure:"address"`
 	Balance uint64       `mapstructure:"balance"`
 }
+
+/////////////////// promoteTransaction //////////////////////////////
+
+// PromoteTransaction struct
+type PromoteTransaction struct {
+	Command         string       `mapstructure:"command"`
+	TailTransaction trinary.Hash `mapstructure:"tailTransaction"`
+}
+
+// PromoteTransactionReturn struct
+type PromoteTransactionReturn struct {
+	TailTransaction trinary.Hash     `json:"tailTransaction"`
+	Trytes          []trinary.Trytes `json:"trytes"`
+	Duration        int              `json:"duration"`
+}
+
+/////////////////// reattachTransaction /////////////////////////////
+
+// ReattachTransaction struct
+type ReattachTransaction struct {
+	Command         string       `mapstructure:"command"`
+	TailTransaction trinary.Hash `mapstructure:"tailTransaction"`
+}
+
+// ReattachTransactionReturn struct
+type ReattachTransactionReturn struct {
+	TailTransaction trinary.Hash     `json:"tailTransaction"`
+	BelowMaxDepth   bool             `json:"belowMaxDepth"`
+	Trytes          []trinary.Trytes `json:"trytes"`
+	Duration        int              `json:"duration"`
+}
+
+/////////////////// REST API v1 ///////////////////////////////////
+
+// TransactionMetadataV1 struct
+type TransactionMetadataV1 struct {
+	Solid          bool            `json:"solid"`
+	Confirmed      bool            `json:"confirmed"`
+	Conflicting    bool            `json:"conflicting"`
+	ConflictReason string          `json:"conflictReason,omitempty"`
+	MilestoneIndex milestone.Index `json:"milestoneIndex,omitempty"`
+	IsTail         bool            `json:"isTail"`
+	IsHead         bool            `json:"isHead"`
+}
+
+// TransactionV1Return struct
+type TransactionV1Return struct {
+	Transaction *transaction.Transaction `json:"transaction"`
+	Metadata    *TransactionMetadataV1   `json:"metadata"`
+}
+
+// TransactionTrytesV1Return struct
+type TransactionTrytesV1Return struct {
+	Hash   trinary.Hash   `json:"hash"`
+	Trytes trinary.Trytes `json:"trytes"`
+}
+
+// BundleInstanceV1 struct
+type BundleInstanceV1 struct {
+	TailTransaction trinary.Hash    `json:"tailTransaction"`
+	Transactions    []trinary.Hash  `json:"transactions"`
+	Solid           bool            `json:"solid"`
+	Valid           bool            `json:"valid"`
+	Confirmed       bool            `json:"confirmed"`
+	Conflicting     bool            `json:"conflicting"`
+	IsMilestone     bool            `json:"isMilestone"`
+	MilestoneIndex  milestone.Index `json:"milestoneIndex,omitempty"`
+}
+
+// BundleV1Return struct
+type BundleV1Return struct {
+	Bundle    trinary.Hash        `json:"bundle"`
+	Instances []*BundleInstanceV1 `json:"instances"`
+}
+
+// AddressBalanceV1Return struct
+type AddressBalanceV1Return struct {
+	Address        trinary.Hash    `json:"address"`
+	Balance        uint64          `json:"balance"`
+	MilestoneIndex milestone.Index `json:"milestoneIndex"`
+}
+
+// AddressTransactionsV1Return struct
+type AddressTransactionsV1Return struct {
+	Address      trinary.Hash   `json:"address"`
+	Transactions []trinary.Hash `json:"transactions"`
+	Cursor       string         `json:"cursor,omitempty"`
+}
+
+// MilestoneV1Return struct
+type MilestoneV1Return struct {
+	Index           milestone.Index `json:"index"`
+	Hash            trinary.Hash    `json:"hash"`
+	TailTransaction trinary.Hash    `json:"tailTransaction"`
+	Timestamp       int64           `json:"timestamp"`
+}
+
+// TipsV1Return struct
+type TipsV1Return struct {
+	NonLazy  []trinary.Hash `json:"nonLazy"`
+	SemiLazy []trinary.Hash `json:"semiLazy"`
+}
+
+// PeersV1Return struct
+type PeersV1Return struct {
+	Peers []*peer.Info `json:"peers"`
+}
+
+/////////////////// websocket subscriptions ///////////////////////
+
+// WebSocketRequest is a JSON-RPC 2.0 request sent over a websocket connection
+type WebSocketRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// WebSocketSubscribeParams struct
+type WebSocketSubscribeParams struct {
+	Topic  string   `json:"topic"`
+	Filter []string `json:"filter"`
+}
+
+// WebSocketUnsubscribeParams struct
+type WebSocketUnsubscribeParams struct {
+	Subscription string `json:"subscription"`
+}
+
+// WebSocketResponse is a JSON-RPC 2.0 response sent over a websocket connection
+type WebSocketResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *WebSocketError `json:"error,omitempty"`
+}
+
+// WebSocketError is a JSON-RPC 2.0 error object
+type WebSocketError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// WebSocketNotification is a JSON-RPC 2.0 notification for a subscription
+type WebSocketNotification struct {
+	JSONRPC string                       `json:"jsonrpc"`
+	Method  string                       `json:"method"`
+	Params  *WebSocketNotificationParams `json:"params"`
+}
+
+// WebSocketNotificationParams struct
+type WebSocketNotificationParams struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// WebSocketTransactionConfirmed struct
+type WebSocketTransactionConfirmed struct {
+	Hash           trinary.Hash    `json:"hash"`
+	Bundle         trinary.Hash    `json:"bundle"`
+	Address        trinary.Hash    `json:"address,omitempty"`
+	IsTail         bool            `json:"isTail"`
+	MilestoneIndex milestone.Index `json:"milestoneIndex"`
+	Conflicting    bool            `json:"conflicting"`
+	ConflictReason string          `json:"conflictReason,omitempty"`
+}
+
+// WebSocketMilestone struct
+type WebSocketMilestone struct {
+	Index           milestone.Index `json:"index"`
+	Hash            trinary.Hash    `json:"hash"`
+	TailTransaction trinary.Hash    `json:"tailTransaction"`
+}
+
+// WebSocketMilestoneConfirmed struct
+type WebSocketMilestoneConfirmed struct {
+	Index                    milestone.Index `json:"index"`
+	TailTransaction          trinary.Hash    `json:"tailTransaction"`
+	TailsIncluded            int             `json:"tailsIncluded"`
+	TailsExcludedConflicting int             `json:"tailsExcludedConflicting"`
+	TailsExcludedZeroValue   int             `json:"tailsExcludedZeroValue"`
+	TailsReferenced          int             `json:"tailsReferenced"`
+}
+
+// WebSocketSpentAddress struct
+type WebSocketSpentAddress struct {
+	Address trinary.Hash `json:"address"`
+}
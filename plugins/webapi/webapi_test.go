@@ -0,0 +1,18 @@
+package webapi
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/iotaledger/hive.go/logger"
+)
+
+func TestMain(m *testing.M) {
+	// the plugin is not configured in the tests
+	log = logger.NewNopLogger()
+	gin.SetMode(gin.TestMode)
+
+	os.Exit(m.Run())
+}
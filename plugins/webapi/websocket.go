@@ -0,0 +1,667 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/workerpool"
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/Ariwonto/aingle-alpha/pkg/config"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/aingle"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/milestone"
+	"github.com/Ariwonto/aingle-alpha/pkg/model/tangle"
+	"github.com/Ariwonto/aingle-alpha/pkg/shutdown"
+	"github.com/Ariwonto/aingle-alpha/pkg/whiteflag"
+	tangleplugin "github.com/Ariwonto/aingle-alpha/plugins/tangle"
+)
+
+const (
+	webSocketRoute = "ws"
+
+	// webSocketPermission is the permission which grants access to the websocket subscriptions.
+	webSocketPermission = "subscribe"
+
+	webSocketWriteTimeout = 3 * time.Second
+	webSocketPongWait     = 60 * time.Second
+	webSocketPingPeriod   = webSocketPongWait / 2
+
+	webSocketWorkerCount     = 1
+	webSocketWorkerQueueSize = 10000
+
+	jsonRPCVersion = "2.0"
+
+	// JSON-RPC 2.0 error codes
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+)
+
+// the topics a websocket connection can subscribe to.
+const (
+	// confirmations of the transactions in the filter
+	webSocketTopicTransactionConfirmed = "transactionConfirmed"
+	// confirmations of the bundles in the filter, notified once per confirmed tail transaction
+	webSocketTopicBundleConfirmed = "bundleConfirmed"
+	// confirmations of transactions on the addresses in the filter
+	webSocketTopicAddressConfirmed = "addressConfirmed"
+	// new latest milestones
+	webSocketTopicLatestMilestone = "latestMilestone"
+	// confirmed milestones
+	webSocketTopicMilestoneConfirmed = "milestoneConfirmed"
+	// spent addresses, all of them if the filter is empty
+	webSocketTopicSpentAddress = "spentAddress"
+)
+
+var (
+	webSocketTopics = []string{
+		webSocketTopicTransactionConfirmed,
+		webSocketTopicBundleConfirmed,
+		webSocketTopicAddressConfirmed,
+		webSocketTopicLatestMilestone,
+		webSocketTopicMilestoneConfirmed,
+		webSocketTopicSpentAddress,
+	}
+
+	wsHub *webSocketHub
+)
+
+// webSocketHub keeps track of the websocket connections and publishes the tangle events to their subscriptions.
+type webSocketHub struct {
+	maxConnections   int
+	maxFilterEntries int
+	sendQueueSize    int
+	maxMessageSize   int64
+
+	upgrader   *websocket.Upgrader
+	workerPool *workerpool.WorkerPool
+
+	connectionsLock syncutils.RWMutex
+	connections     map[*webSocketConnection]struct{}
+	// upgrading is the amount of reserved connection slots of requests which are upgraded to websocket connections.
+	upgrading int
+
+	// subscriberCounts holds the amount of subscriptions per topic,
+	// so events without subscribers can be skipped without locking.
+	subscriberCounts map[string]*atomic.Int32
+}
+
+// webSocketConnection is a websocket connection with its subscriptions.
+type webSocketConnection struct {
+	hub  *webSocketHub
+	conn *websocket.Conn
+
+	// sendChan holds the messages which are not yet written to the connection.
+	sendChan chan interface{}
+	// closed signals the write loop to close the connection with the close code and reason.
+	closed      chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+
+	subscriptionsLock  syncutils.RWMutex
+	subscriptions      map[string]*webSocketSubscription
+	filterEntries      int
+	nextSubscriptionID uint64
+}
+
+// webSocketSubscription is a subscription to a topic, optionally filtered by hashes or addresses.
+type webSocketSubscription struct {
+	id    string
+	topic string
+	// filter contains the hashes or addresses the subscription is interested in, nil if all.
+	filter map[trinary.Hash]struct{}
+}
+
+func (s *webSocketSubscription) matches(hash trinary.Hash) bool {
+	if s.filter == nil {
+		return true
+	}
+	_, contained := s.filter[hash]
+	return contained
+}
+
+// newWebSocketHub creates a websocket hub without a worker pool to publish the tangle events.
+func newWebSocketHub(maxConnections int, maxFilterEntries int, sendQueueSize int, maxMessageSize int64) *webSocketHub {
+	h := &webSocketHub{
+		maxConnections:   maxConnections,
+		maxFilterEntries: maxFilterEntries,
+		sendQueueSize:    sendQueueSize,
+		maxMessageSize:   maxMessageSize,
+		upgrader: &websocket.Upgrader{
+			HandshakeTimeout: webSocketWriteTimeout,
+			CheckOrigin:      func(r *http.Request) bool { return true }, // allow any origin for websocket connections
+		},
+		connections:      make(map[*webSocketConnection]struct{}),
+		subscriberCounts: make(map[string]*atomic.Int32),
+	}
+
+	for _, topic := range webSocketTopics {
+		h.subscriberCounts[topic] = atomic.NewInt32(0)
+	}
+
+	return h
+}
+
+// configureWebSocket creates the websocket hub and registers the websocket route.
+// The route is not registered if the maximum number of connections is zero.
+func configureWebSocket() {
+	maxConnections := config.NodeConfig.GetInt(config.CfgWebAPIWebSocketMaxConnections)
+	if maxConnections <= 0 {
+		return
+	}
+
+	wsHub = newWebSocketHub(maxConnections,
+		config.NodeConfig.GetInt(config.CfgWebAPIWebSocketMaxFilterEntries),
+		config.NodeConfig.GetInt(config.CfgWebAPIWebSocketSendQueueSize),
+		int64(config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxBodyLengthBytes)))
+
+	wsHub.workerPool = workerpool.New(func(task workerpool.Task) {
+		switch x := task.Param(0).(type) {
+		case *tangle.CachedMetadata:
+			wsHub.publishConfirmedTx(x, task.Param(1).(milestone.Index)) // meta pass +1
+		case *tangle.CachedBundle:
+			wsHub.publishLatestMilestone(x) // bundle pass +1
+		case *whiteflag.Confirmation:
+			wsHub.publishMilestoneConfirmed(x)
+		case trinary.Hash:
+			wsHub.publishSpentAddress(x)
+		}
+		task.Return(nil)
+	}, workerpool.WorkerCount(webSocketWorkerCount), workerpool.QueueSize(webSocketWorkerQueueSize))
+
+	// GET /ws
+	api.GET(webSocketRoute, func(c *gin.Context) {
+		if !isRoutePermitted(c, webSocketPermission) {
+			return
+		}
+		wsHub.serve(c)
+	})
+}
+
+// runWebSocket publishes the tangle events to the websocket subscriptions.
+func runWebSocket() {
+	if wsHub == nil {
+		return
+	}
+
+	onTransactionConfirmed := events.NewClosure(func(cachedMeta *tangle.CachedMetadata, msIndex milestone.Index, _ int64) {
+		if !wsHub.hasSubscribers(webSocketTopicTransactionConfirmed, webSocketTopicBundleConfirmed, webSocketTopicAddressConfirmed) {
+			cachedMeta.Release(true) // meta -1
+			return
+		}
+
+		if _, added := wsHub.workerPool.TrySubmit(cachedMeta, msIndex); added { // meta pass +1
+			return // Avoid meta -1 (done inside workerpool task)
+		}
+		cachedMeta.Release(true) // meta -1
+	})
+
+	onLatestMilestoneChanged := events.NewClosure(func(cachedBndl *tangle.CachedBundle) {
+		if !wsHub.hasSubscribers(webSocketTopicLatestMilestone) {
+			cachedBndl.Release(true) // bundle -1
+			return
+		}
+
+		if _, added := wsHub.workerPool.TrySubmit(cachedBndl); added { // bundle pass +1
+			return // Avoid bundle -1 (done inside workerpool task)
+		}
+		cachedBndl.Release(true) // bundle -1
+	})
+
+	onMilestoneConfirmed := events.NewClosure(func(confirmation *whiteflag.Confirmation) {
+		if !wsHub.hasSubscribers(webSocketTopicMilestoneConfirmed) {
+			return
+		}
+		wsHub.workerPool.TrySubmit(confirmation)
+	})
+
+	onAddressSpent := events.NewClosure(func(addr trinary.Hash) {
+		if !wsHub.hasSubscribers(webSocketTopicSpentAddress) {
+			return
+		}
+		wsHub.workerPool.TrySubmit(addr)
+	})
+
+	daemon.BackgroundWorker("WebAPI[WebSocket]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting WebAPI[WebSocket] ... done")
+		tangleplugin.Events.TransactionConfirmed.Attach(onTransactionConfirmed)
+		tangleplugin.Events.LatestMilestoneChanged.Attach(onLatestMilestoneChanged)
+		tangleplugin.Events.MilestoneConfirmed.Attach(onMilestoneConfirmed)
+		tangle.Events.AddressSpent.Attach(onAddressSpent)
+		wsHub.workerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping WebAPI[WebSocket] ...")
+		tangleplugin.Events.TransactionConfirmed.Detach(onTransactionConfirmed)
+		tangleplugin.Events.LatestMilestoneChanged.Detach(onLatestMilestoneChanged)
+		tangleplugin.Events.MilestoneConfirmed.Detach(onMilestoneConfirmed)
+		tangle.Events.AddressSpent.Detach(onAddressSpent)
+		wsHub.workerPool.StopAndWait()
+		wsHub.closeAll()
+		log.Info("Stopping WebAPI[WebSocket] ... done")
+	}, shutdown.PriorityAPI)
+}
+
+// serve upgrades the request to a websocket connection and handles the requests of the connection until it is closed.
+func (h *webSocketHub) serve(c *gin.Context) {
+	if !h.reserveSlot() {
+		abortTooManyRequests(c, time.Minute, "too many websocket connections")
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.releaseSlot(nil)
+		// the upgrader already replied with an error
+		log.Debugf("websocket upgrade failed: %v", err)
+		return
+	}
+
+	wsConn := &webSocketConnection{
+		hub:           h,
+		conn:          conn,
+		sendChan:      make(chan interface{}, h.sendQueueSize),
+		closed:        make(chan struct{}),
+		subscriptions: make(map[string]*webSocketSubscription),
+	}
+	h.releaseSlot(wsConn)
+
+	go wsConn.writeLoop()
+	wsConn.readLoop()
+
+	h.connectionsLock.Lock()
+	delete(h.connections, wsConn)
+	h.connectionsLock.Unlock()
+
+	wsConn.removeSubscriptions()
+}
+
+// reserveSlot reserves a connection slot for a request which is upgraded to a websocket connection.
+// It returns false if the max amount of connections is reached.
+func (h *webSocketHub) reserveSlot() bool {
+	h.connectionsLock.Lock()
+	defer h.connectionsLock.Unlock()
+
+	if len(h.connections)+h.upgrading >= h.maxConnections {
+		return false
+	}
+	h.upgrading++
+	return true
+}
+
+// releaseSlot releases a reserved connection slot and adds the given connection in its place.
+// The connection is nil if the upgrade failed.
+func (h *webSocketHub) releaseSlot(wsConn *webSocketConnection) {
+	h.connectionsLock.Lock()
+	defer h.connectionsLock.Unlock()
+
+	h.upgrading--
+	if wsConn != nil {
+		h.connections[wsConn] = struct{}{}
+	}
+}
+
+// closeAll closes all websocket connections.
+func (h *webSocketHub) closeAll() {
+	h.connectionsLock.RLock()
+	defer h.connectionsLock.RUnlock()
+
+	for conn := range h.connections {
+		conn.close(websocket.CloseGoingAway, "node shutting down")
+	}
+}
+
+// hasSubscribers returns whether there is a subscription to any of the given topics.
+func (h *webSocketHub) hasSubscribers(topics ...string) bool {
+	for _, topic := range topics {
+		if h.subscriberCounts[topic].Load() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends the result to all subscriptions of the topic which match the given hash.
+func (h *webSocketHub) publish(topic string, hash trinary.Hash, result interface{}) {
+	if !h.hasSubscribers(topic) {
+		return
+	}
+
+	h.connectionsLock.RLock()
+	defer h.connectionsLock.RUnlock()
+
+	for conn := range h.connections {
+		conn.notify(topic, hash, result)
+	}
+}
+
+func (h *webSocketHub) publishConfirmedTx(cachedMeta *tangle.CachedMetadata, msIndex milestone.Index) {
+	cachedMeta.ConsumeMetadata(func(metadata *aingle.TransactionMetadata) { // meta -1
+		result := &WebSocketTransactionConfirmed{
+			Hash:           metadata.GetTxHash().Trytes(),
+			Bundle:         metadata.GetBundleHash().Trytes(),
+			IsTail:         metadata.IsTail(),
+			MilestoneIndex: msIndex,
+			Conflicting:    metadata.IsConflicting(),
+		}
+
+		if result.Conflicting {
+			result.ConflictReason = metadata.GetConflict().String()
+		}
+
+		if h.hasSubscribers(webSocketTopicAddressConfirmed) {
+			// the address is not part of the metadata, so the transaction is only loaded if needed
+			if cachedTx := tangle.GetCachedTransactionOrNil(metadata.GetTxHash()); cachedTx != nil { // tx +1
+				result.Address = cachedTx.GetTransaction().Tx.Address
+				cachedTx.Release(true) // tx -1
+			} else {
+				log.Warnf("%v hash: %s", tangle.ErrTransactionNotFound, result.Hash)
+			}
+		}
+
+		h.publish(webSocketTopicTransactionConfirmed, result.Hash, result)
+		if result.IsTail {
+			h.publish(webSocketTopicBundleConfirmed, result.Bundle, result)
+		}
+		if result.Address != "" {
+			h.publish(webSocketTopicAddressConfirmed, result.Address, result)
+		}
+	})
+}
+
+func (h *webSocketHub) publishLatestMilestone(cachedBndl *tangle.CachedBundle) {
+	defer cachedBndl.Release(true) // bundle -1
+
+	bndl := cachedBndl.GetBundle()
+	h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{
+		Index:           bndl.GetMilestoneIndex(),
+		Hash:            bndl.GetMilestoneHash().Trytes(),
+		TailTransaction: bndl.GetTailHash().Trytes(),
+	})
+}
+
+func (h *webSocketHub) publishMilestoneConfirmed(confirmation *whiteflag.Confirmation) {
+	h.publish(webSocketTopicMilestoneConfirmed, "", &WebSocketMilestoneConfirmed{
+		Index:                    confirmation.MilestoneIndex,
+		TailTransaction:          confirmation.MilestoneHash.Trytes(),
+		TailsIncluded:            len(confirmation.Mutations.TailsIncluded),
+		TailsExcludedConflicting: len(confirmation.Mutations.TailsExcludedConflicting),
+		TailsExcludedZeroValue:   len(confirmation.Mutations.TailsExcludedZeroValue),
+		TailsReferenced:          len(confirmation.Mutations.TailsReferenced),
+	})
+}
+
+func (h *webSocketHub) publishSpentAddress(addr trinary.Hash) {
+	h.publish(webSocketTopicSpentAddress, addr, &WebSocketSpentAddress{Address: addr})
+}
+
+// readLoop handles the requests of the connection until it is closed.
+func (c *webSocketConnection) readLoop() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(c.hub.maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(webSocketPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(webSocketPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if !c.send(c.handleRequest(data)) {
+			return
+		}
+	}
+}
+
+// writeLoop writes the queued messages to the connection and keeps the connection alive until it is closed.
+// The write loop is the only writer of the connection, it also writes the close message and closes the connection.
+func (c *webSocketConnection) writeLoop() {
+	pingTicker := time.NewTicker(webSocketPingPeriod)
+	defer pingTicker.Stop()
+
+	defer func() {
+		// the close code and reason are set before the closed channel is closed
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeReason), time.Now().Add(webSocketWriteTimeout))
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+
+		case msg := <-c.sendChan:
+			c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.close(websocket.CloseInternalServerErr, "")
+				return
+			}
+
+		case <-pingTicker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout)); err != nil {
+				c.close(websocket.CloseInternalServerErr, "")
+				return
+			}
+		}
+	}
+}
+
+// send queues the message for the connection.
+// Consumers which do not keep up with their subscriptions are disconnected once their send queue is full.
+func (c *webSocketConnection) send(msg interface{}) bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+
+	select {
+	case c.sendChan <- msg:
+		return true
+	default:
+		c.close(websocket.CloseTryAgainLater, "send queue overflow")
+		return false
+	}
+}
+
+// close signals the write loop to close the connection with the given close code and reason.
+// It does not block, so it is safe to be called while the hub is locked.
+func (c *webSocketConnection) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.closed)
+	})
+}
+
+// notify sends a notification to all subscriptions of the connection to the topic which match the given hash.
+func (c *webSocketConnection) notify(topic string, hash trinary.Hash, result interface{}) {
+	c.subscriptionsLock.RLock()
+	var matched []string
+	for _, subscription := range c.subscriptions {
+		if subscription.topic == topic && subscription.matches(hash) {
+			matched = append(matched, subscription.id)
+		}
+	}
+	c.subscriptionsLock.RUnlock()
+
+	for _, id := range matched {
+		if !c.send(&WebSocketNotification{
+			JSONRPC: jsonRPCVersion,
+			Method:  "subscription",
+			Params:  &WebSocketNotificationParams{Subscription: id, Result: result},
+		}) {
+			return
+		}
+	}
+}
+
+// handleRequest handles a JSON-RPC request and returns the response.
+func (c *webSocketConnection) handleRequest(data []byte) *WebSocketResponse {
+	request := &WebSocketRequest{}
+	if err := json.Unmarshal(data, request); err != nil {
+		return webSocketErrorResponse(nil, jsonRPCParseError, fmt.Sprintf("invalid JSON: %v", err))
+	}
+
+	if request.JSONRPC != jsonRPCVersion {
+		return webSocketErrorResponse(request.ID, jsonRPCInvalidRequest, fmt.Sprintf("unsupported JSON-RPC version: %s", request.JSONRPC))
+	}
+
+	switch request.Method {
+	case "subscribe":
+		params := &WebSocketSubscribeParams{}
+		if err := json.Unmarshal(request.Params, params); err != nil {
+			return webSocketErrorResponse(request.ID, jsonRPCInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+
+		id, err := c.subscribe(params.Topic, params.Filter)
+		if err != nil {
+			return webSocketErrorResponse(request.ID, jsonRPCInvalidParams, err.Error())
+		}
+		return &WebSocketResponse{JSONRPC: jsonRPCVersion, ID: request.ID, Result: id}
+
+	case "unsubscribe":
+		params := &WebSocketUnsubscribeParams{}
+		if err := json.Unmarshal(request.Params, params); err != nil {
+			return webSocketErrorResponse(request.ID, jsonRPCInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+		return &WebSocketResponse{JSONRPC: jsonRPCVersion, ID: request.ID, Result: c.unsubscribe(params.Subscription)}
+
+	default:
+		return webSocketErrorResponse(request.ID, jsonRPCMethodNotFound, fmt.Sprintf("method [%v] is unknown", request.Method))
+	}
+}
+
+func webSocketErrorResponse(id interface{}, code int, message string) *WebSocketResponse {
+	return &WebSocketResponse{JSONRPC: jsonRPCVersion, ID: id, Error: &WebSocketError{Code: code, Message: message}}
+}
+
+// subscribe adds a subscription to the topic and returns its id.
+func (c *webSocketConnection) subscribe(topic string, filter []string) (string, error) {
+	if _, exists := c.hub.subscriberCounts[topic]; !exists {
+		return "", fmt.Errorf("unknown topic: %s, available topics: %s", topic, strings.Join(webSocketTopics, ", "))
+	}
+
+	filterSet, err := parseWebSocketFilter(topic, filter)
+	if err != nil {
+		return "", err
+	}
+
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+
+	if c.filterEntries+len(filterSet) > c.hub.maxFilterEntries {
+		return "", fmt.Errorf("too many filter entries, max. %d per connection", c.hub.maxFilterEntries)
+	}
+
+	c.nextSubscriptionID++
+	subscription := &webSocketSubscription{
+		id:     strconv.FormatUint(c.nextSubscriptionID, 10),
+		topic:  topic,
+		filter: filterSet,
+	}
+
+	c.subscriptions[subscription.id] = subscription
+	c.filterEntries += len(filterSet)
+	c.hub.subscriberCounts[topic].Inc()
+
+	return subscription.id, nil
+}
+
+// unsubscribe removes the subscription with the given id and returns whether it existed.
+func (c *webSocketConnection) unsubscribe(id string) bool {
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+
+	subscription, exists := c.subscriptions[id]
+	if !exists {
+		return false
+	}
+
+	delete(c.subscriptions, id)
+	c.filterEntries -= len(subscription.filter)
+	c.hub.subscriberCounts[subscription.topic].Dec()
+
+	return true
+}
+
+// removeSubscriptions removes all subscriptions of the connection.
+func (c *webSocketConnection) removeSubscriptions() {
+	c.subscriptionsLock.Lock()
+	defer c.subscriptionsLock.Unlock()
+
+	for id, subscription := range c.subscriptions {
+		delete(c.subscriptions, id)
+		c.hub.subscriberCounts[subscription.topic].Dec()
+	}
+	c.filterEntries = 0
+}
+
+// parseWebSocketFilter validates the filter of a subscription to the given topic.
+// It returns nil if the subscription is not filtered.
+func parseWebSocketFilter(topic string, filter []string) (map[trinary.Hash]struct{}, error) {
+	switch topic {
+	case webSocketTopicLatestMilestone, webSocketTopicMilestoneConfirmed:
+		if len(filter) > 0 {
+			return nil, fmt.Errorf("topic %s does not support filters", topic)
+		}
+		return nil, nil
+
+	case webSocketTopicSpentAddress:
+		if len(filter) == 0 {
+			return nil, nil
+		}
+	}
+
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("topic %s requires a filter", topic)
+	}
+
+	filterSet := make(map[trinary.Hash]struct{}, len(filter))
+	for _, entry := range filter {
+		entry = strings.ToUpper(entry)
+
+		switch topic {
+		case webSocketTopicTransactionConfirmed:
+			if !guards.IsTransactionHash(entry) {
+				return nil, fmt.Errorf("invalid transaction hash supplied: %s", entry)
+			}
+
+		case webSocketTopicBundleConfirmed:
+			if !guards.IsHash(entry) {
+				return nil, fmt.Errorf("invalid bundle hash supplied: %s", entry)
+			}
+
+		case webSocketTopicAddressConfirmed, webSocketTopicSpentAddress:
+			if err := address.ValidAddress(entry); err != nil {
+				return nil, fmt.Errorf("%v: %s", err, entry)
+			}
+			if len(entry) == 90 {
+				entry = entry[:81]
+			}
+		}
+
+		filterSet[entry] = struct{}{}
+	}
+
+	return filterSet, nil
+}
@@ -0,0 +1,211 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWebSocketServer serves the websocket connections of the given hub.
+// It returns the websocket URL of the server.
+func newTestWebSocketServer(t *testing.T, h *webSocketHub) string {
+	router := gin.New()
+	router.GET("/ws", h.serve)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		h.closeAll()
+		server.Close()
+	})
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+// connectionCount returns the amount of connections and reserved connection slots of the hub.
+func (h *webSocketHub) connectionCount() int {
+	h.connectionsLock.RLock()
+	defer h.connectionsLock.RUnlock()
+	return len(h.connections) + h.upgrading
+}
+
+func TestWebSocketHubMaxConnections(t *testing.T) {
+	h := newWebSocketHub(1, 10, 10, 1024)
+	url := newTestWebSocketServer(t, h)
+
+	// a failed upgrade releases the reserved slot
+	resp, err := http.Get(strings.Replace(url, "ws", "http", 1))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, 0, h.connectionCount())
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+
+	// the limit is reached
+	_, resp, err = websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	// the slot is free again once the connection is closed
+	_ = conn.Close()
+	assert.Eventually(t, func() bool { return h.connectionCount() == 0 }, time.Second, 10*time.Millisecond)
+
+	conn, _, err = websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	_ = conn.Close()
+}
+
+func TestWebSocketHubReserveSlot(t *testing.T) {
+	h := newWebSocketHub(2, 10, 10, 1024)
+
+	// concurrent upgrades can't exceed the limit
+	assert.True(t, h.reserveSlot())
+	assert.True(t, h.reserveSlot())
+	assert.False(t, h.reserveSlot())
+
+	h.releaseSlot(nil)
+	assert.Equal(t, 1, h.connectionCount())
+	h.releaseSlot(&webSocketConnection{})
+	assert.Equal(t, 1, h.connectionCount())
+	assert.True(t, h.reserveSlot())
+	assert.False(t, h.reserveSlot())
+}
+
+// readWebSocketMessage reads the next JSON-RPC message of the connection.
+func readWebSocketMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	msg := make(map[string]interface{})
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWebSocketHubSubscribe(t *testing.T) {
+	h := newWebSocketHub(1, 10, 10, 1024)
+	url := newTestWebSocketServer(t, h)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// unknown topic
+	require.NoError(t, conn.WriteJSON(&WebSocketRequest{JSONRPC: jsonRPCVersion, ID: 1, Method: "subscribe", Params: json.RawMessage(`{"topic":"unknown"}`)}))
+	msg := readWebSocketMessage(t, conn)
+	assert.Equal(t, float64(1), msg["id"])
+	assert.NotNil(t, msg["error"])
+
+	require.NoError(t, conn.WriteJSON(&WebSocketRequest{JSONRPC: jsonRPCVersion, ID: 2, Method: "subscribe", Params: json.RawMessage(`{"topic":"latestMilestone"}`)}))
+	msg = readWebSocketMessage(t, conn)
+	assert.Equal(t, float64(2), msg["id"])
+	subscriptionID := msg["result"]
+	assert.Equal(t, "1", subscriptionID)
+	assert.True(t, h.hasSubscribers(webSocketTopicLatestMilestone))
+
+	h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{Index: 42})
+	msg = readWebSocketMessage(t, conn)
+	assert.Equal(t, "subscription", msg["method"])
+	params := msg["params"].(map[string]interface{})
+	assert.Equal(t, subscriptionID, params["subscription"])
+	assert.Equal(t, float64(42), params["result"].(map[string]interface{})["index"])
+
+	require.NoError(t, conn.WriteJSON(&WebSocketRequest{JSONRPC: jsonRPCVersion, ID: 3, Method: "unsubscribe", Params: json.RawMessage(`{"subscription":"1"}`)}))
+	msg = readWebSocketMessage(t, conn)
+	assert.Equal(t, float64(3), msg["id"])
+	assert.Equal(t, true, msg["result"])
+	assert.False(t, h.hasSubscribers(webSocketTopicLatestMilestone))
+
+	// no notifications are sent after the subscription was removed,
+	// the response to the next request is the next message.
+	h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{Index: 43})
+	require.NoError(t, conn.WriteJSON(&WebSocketRequest{JSONRPC: jsonRPCVersion, ID: 4, Method: "unsubscribe", Params: json.RawMessage(`{"subscription":"1"}`)}))
+	msg = readWebSocketMessage(t, conn)
+	assert.Equal(t, float64(4), msg["id"])
+	assert.Equal(t, false, msg["result"])
+
+	// the subscriptions are removed once the connection is closed
+	require.NoError(t, conn.WriteJSON(&WebSocketRequest{JSONRPC: jsonRPCVersion, ID: 5, Method: "subscribe", Params: json.RawMessage(`{"topic":"milestoneConfirmed"}`)}))
+	readWebSocketMessage(t, conn)
+	assert.True(t, h.hasSubscribers(webSocketTopicMilestoneConfirmed))
+
+	_ = conn.Close()
+	assert.Eventually(t, func() bool { return !h.hasSubscribers(webSocketTopicMilestoneConfirmed) }, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketHubSlowClient(t *testing.T) {
+	h := newWebSocketHub(1, 10, 1, 1024)
+
+	// the connection is registered without running its loops, so nothing is consumed from the send queue
+	serverConns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := h.upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConns <- conn
+	}))
+	defer server.Close()
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	wsConn := &webSocketConnection{
+		hub:           h,
+		conn:          <-serverConns,
+		sendChan:      make(chan interface{}, h.sendQueueSize),
+		closed:        make(chan struct{}),
+		subscriptions: make(map[string]*webSocketSubscription),
+	}
+	require.True(t, h.reserveSlot())
+	h.releaseSlot(wsConn)
+
+	_, err = wsConn.subscribe(webSocketTopicLatestMilestone, nil)
+	require.NoError(t, err)
+
+	// the second notification overflows the send queue, the hub doesn't wait for the client
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{Index: 1})
+		h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{Index: 2})
+		h.publish(webSocketTopicLatestMilestone, "", &WebSocketMilestone{Index: 3})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing is blocked by a slow client")
+	}
+
+	select {
+	case <-wsConn.closed:
+	default:
+		t.Fatal("slow client was not evicted")
+	}
+
+	// the write loop closes the connection with the close code of the eviction
+	go wsConn.writeLoop()
+
+	require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err := clientConn.ReadMessage()
+		if err == nil {
+			// queued notifications may be written before the connection is closed
+			continue
+		}
+
+		var closeErr *websocket.CloseError
+		require.True(t, errors.As(err, &closeErr), "unexpected error: %v", err)
+		assert.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+		break
+	}
+}
@@ -46,6 +46,13 @@ func onConfirmedTx(cachedMeta *tangle.CachedMetadata, msIndex milestone.Index, _
 		}
 
 		cachedTx.ConsumeTransaction(func(tx *aingle.Transaction) {
+			if metadata.IsConflicting() {
+				if err := publishConflictingTx(tx.Tx, msIndex, metadata.GetConflict()); err != nil {
+					log.Warn(err.Error())
+				}
+				return
+			}
+
 			if err := publishConfTx(tx.Tx, msIndex); err != nil {
 				log.Warn(err.Error())
 			}
@@ -54,13 +61,9 @@ func onConfirmedTx(cachedMeta *tangle.CachedMetadata, msIndex milestone.Index, _
 				log.Warn(err.Error())
 			}
 
-			addresses := GetAddressTopics()
-			for _, addr := range addresses {
-				if strings.EqualFold(tx.Tx.Address, addr) {
-					err := publishConfTxForAddress(tx.Tx, msIndex)
-					if err != nil {
-						log.Warn(err.Error())
-					}
+			if IsAddressSubscribed(tx.Tx.Address) {
+				if err := publishConfTxForAddress(tx.Tx, msIndex); err != nil {
+					log.Warn(err.Error())
 				}
 			}
 		})
@@ -159,6 +162,7 @@ func publishLSM(bndl *tangle.Bundle) error {
 }
 
 // Publish confirmed transaction
+// The transaction is also published to the address topic if the address has subscribers.
 func publishConfTx(iotaTx *transaction.Transaction, msIndex milestone.Index) error {
 
 	messages := []string{
@@ -170,7 +174,28 @@ func publishConfTx(iotaTx *transaction.Transaction, msIndex milestone.Index) err
 		iotaTx.Bundle,                         // Bundle hash
 	}
 
-	return publisher.Send(topicSN, messages)
+	if err := publisher.Send(topicSN, messages); err != nil {
+		return err
+	}
+
+	if !IsAddressSubscribed(iotaTx.Address) {
+		return nil
+	}
+	return publisher.Send(addressTopicSN(iotaTx.Address), messages)
+}
+
+// Publish transaction of a bundle which was excluded from the ledger by a milestone
+func publishConflictingTx(iotaTx *transaction.Transaction, msIndex milestone.Index, conflict aingle.Conflict) error {
+
+	messages := []string{
+		strconv.FormatInt(int64(msIndex), 10), // Index of the milestone that referenced the transaction
+		iotaTx.Hash,                           // Transaction hash
+		iotaTx.Address,                        // Address
+		iotaTx.Bundle,                         // Bundle hash
+		conflict.String(),                     // Reason why the bundle was excluded from the ledger
+	}
+
+	return publisher.Send(topicConflicting, messages)
 }
 
 // Publish confirmed trytes
@@ -207,6 +232,7 @@ func publishTxTrytes(iotaTx *transaction.Transaction) error {
 }
 
 // Publish a transaction that has recently been added to the ledger
+// The transaction is also published to the address topic if the address has subscribers.
 func publishTx(iotaTx *transaction.Transaction) error {
 
 	messages := []string{
@@ -224,7 +250,14 @@ func publishTx(iotaTx *transaction.Transaction) error {
 		iotaTx.Tag,                               // Tag
 	}
 
-	return publisher.Send(topicTX, messages)
+	if err := publisher.Send(topicTX, messages); err != nil {
+		return err
+	}
+
+	if !IsAddressSubscribed(iotaTx.Address) {
+		return nil
+	}
+	return publisher.Send(addressTopicTX(iotaTx.Address), messages)
 }
 
 // Publish a confirmed transaction for a specific address
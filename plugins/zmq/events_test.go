@@ -0,0 +1,83 @@
+package zmq
+
+import (
+	"strings"
+	"testing"
+
+	zmq "github.com/go-zeromq/zmq4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/transaction"
+)
+
+// recordingSocket records the topics of the sent messages and reports the given subscribed topics.
+type recordingSocket struct {
+	zmq.Socket
+	topics    []string
+	published []string
+}
+
+func (s *recordingSocket) Send(msg zmq.Msg) error {
+	s.published = append(s.published, strings.SplitN(string(msg.Bytes()), " ", 2)[0])
+	return nil
+}
+
+func (s *recordingSocket) Topics() []string {
+	return s.topics
+}
+
+// newEventsTestPublisher replaces the publisher with one whose sent topics are recorded.
+func newEventsTestPublisher(t *testing.T) *recordingSocket {
+	resetAddressTopics(t)
+
+	socket := &recordingSocket{}
+	prevPublisher := publisher
+	t.Cleanup(func() { publisher = prevPublisher })
+	publisher = &Publisher{socket: socket}
+
+	return socket
+}
+
+func TestPublishAddressTopics(t *testing.T) {
+	socket := newEventsTestPublisher(t)
+
+	txA := &transaction.Transaction{Hash: topicsTestAddressB, Address: topicsTestAddressA}
+	txB := &transaction.Transaction{Hash: topicsTestAddressA, Address: topicsTestAddressB}
+
+	// no address has subscribers
+	updateAddressTopics()
+	require.NoError(t, publishTx(txA))
+	require.NoError(t, publishConfTx(txA, 1))
+	assert.Equal(t, []string{topicTX, topicSN}, socket.published)
+
+	socket.topics = []string{topicLMI, topicTX, addressTopicTX(topicsTestAddressA)}
+	updateAddressTopics()
+
+	// only the subscribed address is published to its address topics
+	socket.published = nil
+	require.NoError(t, publishTx(txA))
+	require.NoError(t, publishConfTx(txA, 1))
+	require.NoError(t, publishTx(txB))
+	require.NoError(t, publishConfTx(txB, 1))
+	assert.Equal(t, []string{
+		topicTX, addressTopicTX(topicsTestAddressA),
+		topicSN, addressTopicSN(topicsTestAddressA),
+		topicTX,
+		topicSN,
+	}, socket.published)
+
+	socket.topics = nil
+	updateAddressTopics()
+
+	socket.published = nil
+	require.NoError(t, publishTx(txA))
+	assert.Equal(t, []string{topicTX}, socket.published)
+}
+
+func TestGetSpecialTopics(t *testing.T) {
+	socket := newEventsTestPublisher(t)
+
+	socket.topics = []string{topicTX, addressTopicTX(topicsTestAddressB), topicLMI, topicsTestAddressA}
+	assert.Equal(t, []string{topicsTestAddressA, addressTopicTX(topicsTestAddressB)}, GetSpecialTopics().Topics)
+}
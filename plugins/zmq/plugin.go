@@ -103,11 +103,9 @@ func run(_ *node.Plugin) {
 			cachedMeta.Release(true) // meta -1
 			return
 		}
-		// Avoid notifying for conflicting txs
-		if !cachedMeta.GetMetadata().IsConflicting() {
-			if _, added := confirmedTxWorkerPool.TrySubmit(cachedMeta, msIndex, confTime); added { // meta pass +1
-				return // Avoid meta -1 (done inside workerpool task)
-			}
+		// conflicting txs are only published on the conflicting topic
+		if _, added := confirmedTxWorkerPool.TrySubmit(cachedMeta, msIndex, confTime); added { // meta pass +1
+			return // Avoid meta -1 (done inside workerpool task)
 		}
 		cachedMeta.Release(true) // meta -1
 	})
@@ -2,10 +2,12 @@ package zmq
 
 import (
 	"sort"
+	"strings"
 	"sync"
 
 	zmq "github.com/go-zeromq/zmq4"
 	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/trinary"
 )
 
 // Topic names
@@ -20,6 +22,10 @@ const (
 	topicTxTrytes     = "trytes"
 	topicTX           = "tx"
 	topicSpentAddress = "spent_address"
+	topicConflicting  = "conflicting"
+
+	// the prefix of the "addr/<address>/tx" and "addr/<address>/sn" topics
+	topicPrefixAddress = "addr/"
 )
 
 var (
@@ -35,6 +41,7 @@ var (
 		topicTxTrytes,
 		topicTX,
 		topicSpentAddress,
+		topicConflicting,
 	}
 
 	addressTopics AddressTopics
@@ -47,8 +54,8 @@ type SpecialTopics struct {
 
 // AddressTopics stuct
 type AddressTopics struct {
-	mu         sync.Mutex
-	Addressses []string
+	mu         sync.RWMutex
+	Addressses map[trinary.Hash]struct{}
 }
 
 // GetSpecialTopics is a sortet list of special topics (e.g. Addresses)
@@ -73,16 +80,21 @@ func GetSpecialTopics() *SpecialTopics {
 	return specialTopics
 }
 
-// AddressTopics filters SpecialTopics for address topics
+// AddressTopics filters SpecialTopics for address topics.
+// Both the legacy "<address>" and the "addr/<address>/..." topics are taken into account.
 func (st *SpecialTopics) AddressTopics() {
-	addrTopic := []string{}
+	addrTopic := make(map[trinary.Hash]struct{})
 	for _, topic := range st.Topics {
+		if strings.HasPrefix(topic, topicPrefixAddress) {
+			topic = strings.SplitN(strings.TrimPrefix(topic, topicPrefixAddress), "/", 2)[0]
+		}
+
 		err := address.ValidAddress(topic)
 		if err == nil {
 			if len(topic) == 90 {
-				addrTopic = append(addrTopic, topic[:81])
+				addrTopic[topic[:81]] = struct{}{}
 			} else if len(topic) == 81 {
-				addrTopic = append(addrTopic, topic)
+				addrTopic[topic] = struct{}{}
 			}
 		}
 	}
@@ -91,11 +103,22 @@ func (st *SpecialTopics) AddressTopics() {
 	addressTopics.mu.Unlock()
 }
 
-// GetAddressTopics returns subscribed addresses
-func GetAddressTopics() []string {
-	addressTopics.mu.Lock()
-	defer addressTopics.mu.Unlock()
-	return addressTopics.Addressses
+// IsAddressSubscribed returns whether the given address has subscribers.
+func IsAddressSubscribed(addr trinary.Hash) bool {
+	addressTopics.mu.RLock()
+	defer addressTopics.mu.RUnlock()
+	_, subscribed := addressTopics.Addressses[addr]
+	return subscribed
+}
+
+// addressTopicTX returns the topic for new transactions of the given address.
+func addressTopicTX(addr trinary.Hash) string {
+	return topicPrefixAddress + addr + "/" + topicTX
+}
+
+// addressTopicSN returns the topic for confirmed transactions of the given address.
+func addressTopicSN(addr trinary.Hash) string {
+	return topicPrefixAddress + addr + "/" + topicSN
 }
 
 func updateAddressTopics() {
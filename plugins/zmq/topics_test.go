@@ -0,0 +1,82 @@
+package zmq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+var (
+	topicsTestAddressA = strings.Repeat("A", consts.HashTrytesSize)
+	topicsTestAddressB = strings.Repeat("B", consts.HashTrytesSize)
+	topicsTestAddressC = strings.Repeat("C", consts.HashTrytesSize)
+	topicsTestAddressD = strings.Repeat("D", consts.HashTrytesSize)
+)
+
+// resetAddressTopics removes the subscribed addresses for the duration of the test.
+func resetAddressTopics(t *testing.T) {
+	addressTopics.mu.Lock()
+	prevAddresses := addressTopics.Addressses
+	addressTopics.Addressses = nil
+	addressTopics.mu.Unlock()
+
+	t.Cleanup(func() {
+		addressTopics.mu.Lock()
+		addressTopics.Addressses = prevAddresses
+		addressTopics.mu.Unlock()
+	})
+}
+
+// withChecksum appends a valid checksum to the given address.
+func withChecksum(t *testing.T, addr trinary.Hash) trinary.Hash {
+	checksum, err := address.Checksum(addr)
+	require.NoError(t, err)
+	return addr + checksum
+}
+
+func TestSpecialTopicsAddressTopics(t *testing.T) {
+	resetAddressTopics(t)
+
+	st := &SpecialTopics{Topics: []string{
+		topicsTestAddressA,                                   // legacy topic
+		withChecksum(t, topicsTestAddressB),                  // legacy topic with checksum
+		addressTopicTX(topicsTestAddressC),                   // address topic
+		addressTopicSN(topicsTestAddressC),                   // same address on another topic
+		topicPrefixAddress + topicsTestAddressD,              // address topic without sub topic
+		strings.ToLower(strings.Repeat("E", 81)),             // lowercase
+		topicsTestAddressA[1:],                               // too short
+		topicsTestAddressA + strings.Repeat("9", 9),          // invalid checksum
+		topicPrefixAddress + "INVALID/" + topicTX,            // invalid address topic
+		"address/" + strings.Repeat("F", 81) + "/" + topicTX, // wrong prefix
+		topicTX,
+	}}
+	st.AddressTopics()
+
+	assert.Equal(t, map[trinary.Hash]struct{}{
+		topicsTestAddressA: {},
+		topicsTestAddressB: {},
+		topicsTestAddressC: {},
+		topicsTestAddressD: {},
+	}, addressTopics.Addressses)
+
+	for _, addr := range []trinary.Hash{topicsTestAddressA, topicsTestAddressB, topicsTestAddressC, topicsTestAddressD} {
+		assert.True(t, IsAddressSubscribed(addr), addr)
+	}
+	assert.False(t, IsAddressSubscribed(strings.Repeat("E", 81)))
+	assert.False(t, IsAddressSubscribed(strings.Repeat("F", 81)))
+
+	// the addresses are replaced on every update
+	(&SpecialTopics{Topics: []string{addressTopicTX(topicsTestAddressC)}}).AddressTopics()
+	assert.False(t, IsAddressSubscribed(topicsTestAddressA))
+	assert.True(t, IsAddressSubscribed(topicsTestAddressC))
+
+	(&SpecialTopics{}).AddressTopics()
+	assert.Empty(t, addressTopics.Addressses)
+	assert.False(t, IsAddressSubscribed(topicsTestAddressC))
+}